@@ -25,3 +25,8 @@ func startBreakSignal() <-chan struct{} {
 	}()
 	return ch
 }
+
+// sendBreakSignal delivers the break signal to a child ralphex process, as Ctrl+\ would.
+func sendBreakSignal(p *os.Process) error {
+	return p.Signal(syscall.SIGQUIT)
+}
@@ -2,8 +2,18 @@
 
 package main
 
+import (
+	"errors"
+	"os"
+)
+
 // startBreakSignal returns nil on windows — SIGQUIT is not available.
 // manual break feature is disabled on this platform.
 func startBreakSignal() <-chan struct{} {
 	return nil
 }
+
+// sendBreakSignal is not supported on windows, which has no SIGQUIT.
+func sendBreakSignal(*os.Process) error {
+	return errors.New("break signal is not supported on windows")
+}
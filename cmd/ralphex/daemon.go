@@ -0,0 +1,514 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/notify"
+	"github.com/umputun/ralphex/pkg/plan"
+	"github.com/umputun/ralphex/pkg/processor"
+	"github.com/umputun/ralphex/pkg/progress"
+	"github.com/umputun/ralphex/pkg/queue"
+	"github.com/umputun/ralphex/pkg/web"
+)
+
+// planNameRe matches the file name of an inline plan submitted to the daemon, without .md.
+var planNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// applyServeCommand handles the "serve" subcommand, a synonym of --serve. returns the remaining
+// positional arguments; a file named "serve" in the current directory keeps its plan-file meaning.
+func applyServeCommand(o *opts, args []string) []string {
+	if len(args) == 0 || args[0] != "serve" || fileExists(args[0]) {
+		return args
+	}
+	o.Serve = true
+	return args[1:]
+}
+
+// runDaemon runs serve --daemon: the web dashboard together with the run API, executing plans
+// submitted for the registered repositories until ctx is canceled. every run gets its own worktree
+// and child ralphex process, like a queued plan, with at most --parallel runs in flight. runs are not
+// executed in-process because a worktree run changes the working directory of the whole process;
+// the dashboard streams each run from its progress file.
+func runDaemon(ctx context.Context, o opts, cfg *config.Config, colors *progress.Colors, notifySvc *notify.Service) error {
+	if cfg.WebToken == "" {
+		return errors.New("serve --daemon requires an API token: set web_token in config, --web-token or RALPHEX_WEB_TOKEN")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locate ralphex executable: %w", err)
+	}
+	d, err := newDaemon(o, cfg, colors, notifySvc, exe, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	dashboard := web.NewDashboard(web.DashboardConfig{
+		Port:     o.Port,
+		Host:     o.Host,
+		Colors:   colors,
+		Token:    cfg.WebToken,
+		User:     cfg.WebUser,
+		Password: cfg.WebPassword,
+		TLS:      webTLS(cfg),
+	}, nil)
+	d.start(ctx)
+	err = dashboard.RunDaemon(ctx, d.roots(), d)
+	d.wait() // interrupted runs get queueStopTimeout to record their progress
+	if err != nil {
+		return fmt.Errorf("run daemon: %w", err)
+	}
+	return nil
+}
+
+// daemon executes the runs of serve --daemon, implements web.RunController. each registered
+// repository has its own queueRunner, serializing the git operations on its main checkout.
+type daemon struct {
+	o        opts
+	cfg      *config.Config
+	notify   *notify.Service
+	parallel int
+	repos    map[string]*queueRunner // by repository name
+	names    []string                // sorted repository names
+
+	mu      sync.Mutex
+	ctx     context.Context // parent of all runs, nil until start
+	runs    []*daemonRun    // in submission order
+	lastID  int
+	running int
+	wg      sync.WaitGroup
+}
+
+// daemonRun is a run submitted to the daemon. all fields are guarded by daemon.mu.
+type daemonRun struct {
+	info     web.RunInfo
+	repo     *queueRunner
+	plan     queue.Plan
+	cancel   context.CancelFunc // set once running
+	proc     *os.Process        // child process, set once started
+	stdin    io.Writer          // child's stdin, resumes a paused run
+	paused   bool               // the child waits at the pause prompt of a task iteration
+	canceled bool
+}
+
+// newDaemon opens the repositories registered in cfg.DaemonRepos, the current one when none are.
+func newDaemon(o opts, cfg *config.Config, colors *progress.Colors, notifySvc *notify.Service, exe string,
+	out io.Writer) (*daemon, error) {
+	specs := cfg.DaemonRepos
+	if len(specs) == 0 {
+		if !fileExists(".git") {
+			return nil, errors.New("serve --daemon needs a repository: set daemon_repos in config, pass --repo name=path or run from a repo root")
+		}
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("get working directory: %w", err)
+		}
+		specs = []string{filepath.Base(cwd) + "=" + cwd}
+	}
+
+	d := &daemon{o: o, cfg: cfg, notify: notifySvc, parallel: max(o.Parallel, 1), repos: map[string]*queueRunner{}}
+	outMu := &sync.Mutex{}
+	for _, spec := range specs {
+		name, path, err := config.ParseDaemonRepo(spec)
+		if err != nil {
+			return nil, fmt.Errorf("daemon repos: %w", err)
+		}
+		if _, dup := d.repos[name]; dup {
+			return nil, fmt.Errorf("duplicate daemon repo %q", name)
+		}
+		svc, err := git.NewService(path, colors.Info(), cfg.VcsCommand)
+		if err != nil {
+			return nil, fmt.Errorf("open daemon repo %s: %w", name, err)
+		}
+		svc.SetCommitTrailer(cfg.CommitTrailer)
+		if igErr := svc.EnsureLocalGitignore(); igErr != nil {
+			return nil, fmt.Errorf("ensure gitignore of %s: %w", name, igErr)
+		}
+		autoDetected := svc.GetDefaultBranch()
+		req := executePlanRequest{
+			Mode:          processor.ModeFull,
+			GitSvc:        svc,
+			Config:        cfg,
+			Colors:        colors,
+			DefaultBranch: resolveDefaultBranch("", cfg.DefaultBranch, autoDetected),
+			BaseRef:       resolveDefaultBranch(o.BaseRef, cfg.DefaultBranch, autoDetected),
+		}
+		d.repos[name] = &queueRunner{o: o, req: req, exe: exe, dir: svc.Root(), prefix: name + "/", out: out,
+			outMu: outMu, stats: map[string]git.DiffStats{}}
+		d.names = append(d.names, name)
+	}
+	slices.Sort(d.names)
+	return d, nil
+}
+
+// roots returns the root directories of the registered repositories, watched by the dashboard.
+func (d *daemon) roots() []string {
+	res := make([]string, 0, len(d.names))
+	for _, name := range d.names {
+		res = append(res, d.repos[name].dir)
+	}
+	return res
+}
+
+// start begins executing queued runs; runs are interrupted once ctx is canceled.
+func (d *daemon) start(ctx context.Context) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.ctx = ctx
+	d.schedule()
+}
+
+// wait blocks until all started runs have finished.
+func (d *daemon) wait() {
+	d.wg.Wait()
+}
+
+// Repos returns the registered repository names.
+func (d *daemon) Repos() []string {
+	return slices.Clone(d.names)
+}
+
+// Submit validates a run request and queues the run. an inline plan is written to the plans
+// directory of the repository first; it must not overwrite an existing plan.
+func (d *daemon) Submit(req web.RunRequest) (web.RunInfo, error) {
+	repo, ok := d.repos[req.Repo]
+	if !ok {
+		return web.RunInfo{}, fmt.Errorf("%w: unknown repo %q", web.ErrInvalidRun, req.Repo)
+	}
+	if (req.PlanFile == "") == (req.Markdown == "") {
+		return web.RunInfo{}, fmt.Errorf("%w: set exactly one of plan_file and markdown", web.ErrInvalidRun)
+	}
+	path, err := d.planPath(repo.dir, req)
+	if err != nil {
+		return web.RunInfo{}, err
+	}
+	branch := plan.ExtractBranchName(path)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.ctx != nil && d.ctx.Err() != nil {
+		return web.RunInfo{}, errors.New("daemon is shutting down")
+	}
+	for _, r := range d.runs {
+		if r.repo == repo && r.info.Branch == branch && (r.info.State == web.RunQueued || r.info.State == web.RunRunning) {
+			return web.RunInfo{}, fmt.Errorf("%w: run %s of branch %s is already %s", web.ErrInvalidRun, r.info.ID, branch, r.info.State)
+		}
+	}
+	if req.Markdown != "" {
+		if err := writeInlinePlan(path, req.Markdown); err != nil {
+			return web.RunInfo{}, err
+		}
+	}
+	// pending plans are uncommitted until their run commits them, and must not block each other
+	repo.gitMu.Lock()
+	repo.req.GitSvc.AllowDirtyPaths(path)
+	repo.gitMu.Unlock()
+
+	mode := processor.ModeFull
+	if req.TasksOnly || d.o.TasksOnly {
+		mode = processor.ModeTasksOnly
+	}
+	rel, err := filepath.Rel(repo.dir, path)
+	if err != nil {
+		rel = path
+	}
+	d.lastID++
+	r := &daemonRun{repo: repo, plan: queue.Plan{Path: path, Branch: branch}, info: web.RunInfo{
+		ID:          strconv.Itoa(d.lastID),
+		Repo:        req.Repo,
+		PlanFile:    filepath.ToSlash(rel),
+		Branch:      branch,
+		Mode:        string(mode),
+		State:       web.RunQueued,
+		SessionID:   web.SessionIDForPath(filepath.Join(repo.dir, progress.FilePath(path, string(mode)))),
+		SubmittedAt: time.Now(),
+	}}
+	d.runs = append(d.runs, r)
+	d.schedule()
+	return r.info, nil
+}
+
+// planPath resolves the plan of a run request to an absolute path inside the repository at root.
+// an inline plan goes to the plans directory, named after req.Name or the plan's title.
+func (d *daemon) planPath(root string, req web.RunRequest) (string, error) {
+	if req.PlanFile != "" {
+		if !filepath.IsLocal(req.PlanFile) {
+			return "", fmt.Errorf("%w: plan_file %q must be a relative path inside the repository", web.ErrInvalidRun, req.PlanFile)
+		}
+		path := filepath.Join(root, req.PlanFile)
+		if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+			return "", fmt.Errorf("%w: plan file %s not found", web.ErrInvalidRun, req.PlanFile)
+		}
+		return path, nil
+	}
+
+	name := strings.TrimSuffix(req.Name, ".md")
+	if name == "" {
+		name = inlinePlanName(req.Markdown)
+		if name == "" {
+			return "", fmt.Errorf("%w: inline plan needs a name or a title", web.ErrInvalidRun)
+		}
+	}
+	if !planNameRe.MatchString(name) {
+		return "", fmt.Errorf("%w: invalid plan name %q", web.ErrInvalidRun, name)
+	}
+	dir := d.cfg.PlansDir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	return filepath.Join(dir, name+".md"), nil
+}
+
+// inlinePlanName derives a plan file name from the title of an inline plan, empty without a title.
+func inlinePlanName(markdown string) string {
+	p, err := plan.ParsePlan(markdown)
+	if err != nil || p.Title == "" {
+		return ""
+	}
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(p.Title) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	name := sb.String()
+	if len(name) > 50 {
+		name = strings.TrimRight(name[:50], "-")
+	}
+	return name
+}
+
+// writeInlinePlan creates the plan file of an inline plan, failing when it already exists.
+func writeInlinePlan(path, markdown string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("create plans dir: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600) //nolint:gosec // path is validated by planPath
+	if err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return fmt.Errorf("%w: plan %s already exists", web.ErrInvalidRun, filepath.Base(path))
+		}
+		return fmt.Errorf("create plan: %w", err)
+	}
+	if _, err = f.WriteString(markdown); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("write plan: %w", err)
+	}
+	if err = f.Close(); err != nil {
+		return fmt.Errorf("close plan: %w", err)
+	}
+	return nil
+}
+
+// schedule starts queued runs in submission order while fewer than --parallel are running.
+// must be called with mu held.
+func (d *daemon) schedule() {
+	if d.ctx == nil {
+		return
+	}
+	for _, r := range d.runs {
+		if d.running >= d.parallel || d.ctx.Err() != nil {
+			return
+		}
+		if r.info.State != web.RunQueued {
+			continue
+		}
+		ctx, cancel := context.WithCancel(d.ctx)
+		r.cancel = cancel
+		r.info.State = web.RunRunning
+		r.info.StartedAt = time.Now()
+		d.running++
+		d.wg.Add(1)
+		go d.execute(ctx, r)
+	}
+}
+
+// execute runs r to completion, records its outcome, sends its notification and starts the next
+// queued run.
+func (d *daemon) execute(ctx context.Context, r *daemonRun) {
+	defer d.wg.Done()
+	runErr := d.runPlan(ctx, r)
+
+	d.mu.Lock()
+	r.proc, r.stdin, r.paused = nil, nil, false
+	r.info.FinishedAt = time.Now()
+	switch {
+	case r.canceled || ctx.Err() != nil:
+		r.info.State = web.RunCanceled
+	case runErr != nil:
+		r.info.State = web.RunFailed
+		r.info.Error = runErr.Error()
+	default:
+		r.info.State = web.RunCompleted
+	}
+	r.cancel()
+	info := r.info
+	d.running--
+	d.schedule()
+	d.mu.Unlock()
+
+	d.notify.Send(context.Background(), d.notifyResult(r, info))
+}
+
+// runPlan runs r in its own worktree like a queued plan, keeping the child's process and stdin
+// for break and resume.
+func (d *daemon) runPlan(ctx context.Context, r *daemonRun) error {
+	q := r.repo
+	wt, err := q.prepare(r.plan)
+	if err != nil {
+		return err
+	}
+	o := d.o
+	if r.info.Mode == string(processor.ModeTasksOnly) {
+		o.TasksOnly, o.Pipeline = true, "" // --pipeline conflicts with --tasks-only
+	}
+	cmd, w := q.command(ctx, r.plan, wt, o)
+	w.onLine = func(line string) { d.trackPause(r, line) }
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return q.finish(r.plan, wt, w, fmt.Errorf("open stdin: %w", err))
+	}
+	if err = cmd.Start(); err != nil {
+		return q.finish(r.plan, wt, w, err)
+	}
+	d.mu.Lock()
+	r.proc, r.stdin = cmd.Process, stdin
+	d.mu.Unlock()
+	return q.finish(r.plan, wt, w, cmd.Wait())
+}
+
+// trackPause marks r paused while its child waits at the pause prompt. a break during a review
+// loop ends the loop without a prompt, and any later output means the child is not waiting.
+func (d *daemon) trackPause(r *daemonRun, line string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	r.paused = strings.Contains(line, pausePrompt)
+}
+
+// notifyResult builds the notification of a finished run.
+func (d *daemon) notifyResult(r *daemonRun, info web.RunInfo) notify.Result {
+	r.repo.gitMu.Lock()
+	stats := r.repo.stats[r.plan.Path]
+	r.repo.gitMu.Unlock()
+	res := notify.Result{Status: "success", Mode: info.Mode, PlanFile: r.plan.Path, Branch: info.Branch,
+		Duration: formatElapsed(info.FinishedAt.Sub(info.StartedAt)), Files: stats.Files,
+		Additions: stats.Additions, Deletions: stats.Deletions}
+	if info.State != web.RunCompleted {
+		res.Status = "failure"
+		res.Error = info.Error
+		if info.State == web.RunCanceled {
+			res.Error = "run canceled"
+		}
+	}
+	return res
+}
+
+// Runs returns all runs in submission order.
+func (d *daemon) Runs() []web.RunInfo {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	res := make([]web.RunInfo, 0, len(d.runs))
+	for _, r := range d.runs {
+		res = append(res, r.info)
+	}
+	return res
+}
+
+// Run returns the run with the given id.
+func (d *daemon) Run(id string) (web.RunInfo, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	r, err := d.find(id)
+	if err != nil {
+		return web.RunInfo{}, err
+	}
+	return r.info, nil
+}
+
+// Cancel drops a queued run or interrupts a running one.
+func (d *daemon) Cancel(id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	r, err := d.find(id)
+	if err != nil {
+		return err
+	}
+	switch r.info.State {
+	case web.RunQueued:
+		r.info.State = web.RunCanceled
+		r.info.FinishedAt = time.Now()
+		return nil
+	case web.RunRunning:
+		r.canceled = true
+		r.cancel()
+		return nil
+	default:
+		return fmt.Errorf("%w: run %s is %s", web.ErrRunNotActive, id, r.info.State)
+	}
+}
+
+// Break sends the break signal to a running run, as Ctrl+\ would: a task iteration pauses until
+// Resume, a review loop ends. the run counts as paused once its child shows the pause prompt.
+func (d *daemon) Break(id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	r, err := d.find(id)
+	if err != nil {
+		return err
+	}
+	if r.proc == nil {
+		return fmt.Errorf("%w: run %s has no running process", web.ErrRunNotActive, id)
+	}
+	if err := sendBreakSignal(r.proc); err != nil {
+		return fmt.Errorf("send break to run %s: %w", id, err)
+	}
+	return nil
+}
+
+// Resume continues a run paused by Break, as Enter would.
+func (d *daemon) Resume(id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	r, err := d.find(id)
+	if err != nil {
+		return err
+	}
+	if !r.paused || r.stdin == nil {
+		return fmt.Errorf("%w: run %s is not paused", web.ErrRunNotActive, id)
+	}
+	if _, err := io.WriteString(r.stdin, "\n"); err != nil {
+		return fmt.Errorf("resume run %s: %w", id, err)
+	}
+	r.paused = false
+	return nil
+}
+
+// find returns the run with the given id. must be called with mu held.
+func (d *daemon) find(id string) (*daemonRun, error) {
+	for _, r := range d.runs {
+		if r.info.ID == id {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", web.ErrRunNotFound, id)
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/web"
+)
+
+// newTestDaemon opens a daemon for a fresh test repository registered as "api".
+func newTestDaemon(t *testing.T, o opts, exe string) (*daemon, string, *bytes.Buffer) {
+	t.Helper()
+	dir := setupTestRepo(t)
+	cfg := &config.Config{PlansDir: "docs/plans", MovePlanOnCompletion: true, DaemonRepos: []string{"api=" + dir}}
+	var out bytes.Buffer
+	d, err := newDaemon(o, cfg, testColors(), nil, exe, &out)
+	require.NoError(t, err)
+	return d, d.repos["api"].dir, &out
+}
+
+func TestApplyServeCommand(t *testing.T) {
+	t.Run("serve command", func(t *testing.T) {
+		var o opts
+		rest := applyServeCommand(&o, []string{"serve"})
+		assert.Empty(t, rest)
+		assert.True(t, o.Serve)
+	})
+
+	t.Run("plan file argument", func(t *testing.T) {
+		var o opts
+		rest := applyServeCommand(&o, []string{"docs/plans/a.md"})
+		assert.Equal(t, []string{"docs/plans/a.md"}, rest)
+		assert.False(t, o.Serve)
+	})
+
+	t.Run("file named serve stays a plan file", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		require.NoError(t, os.WriteFile("serve", []byte("# plan\n"), 0o600))
+		var o opts
+		rest := applyServeCommand(&o, []string{"serve"})
+		assert.Equal(t, []string{"serve"}, rest)
+		assert.False(t, o.Serve)
+	})
+}
+
+func TestDaemonFlags(t *testing.T) {
+	t.Run("token and repos override config", func(t *testing.T) {
+		cfg := &config.Config{WebToken: "cfg", DaemonRepos: []string{"old=/srv/old"}}
+		o := parseTestOpts(t, "--serve", "--daemon", "--web-token", "cli", "--repo", "api=/srv/api", "--repo", "web=/srv/web")
+		require.NoError(t, applyCLIOverrides(o, cfg))
+		assert.Equal(t, "cli", cfg.WebToken)
+		assert.Equal(t, []string{"api=/srv/api", "web=/srv/web"}, cfg.DaemonRepos)
+	})
+
+	t.Run("token from env", func(t *testing.T) {
+		t.Setenv("RALPHEX_WEB_TOKEN", "env")
+		cfg := &config.Config{}
+		require.NoError(t, applyCLIOverrides(parseTestOpts(t, "--serve", "--daemon"), cfg))
+		assert.Equal(t, "env", cfg.WebToken)
+	})
+
+	t.Run("invalid repo", func(t *testing.T) {
+		err := applyCLIOverrides(parseTestOpts(t, "--repo", "api"), &config.Config{})
+		require.ErrorContains(t, err, "--repo")
+	})
+}
+
+func TestNewDaemon(t *testing.T) {
+	t.Run("registered repos", func(t *testing.T) {
+		api, web := setupTestRepo(t), setupTestRepo(t)
+		cfg := &config.Config{DaemonRepos: []string{"web=" + web, "api=" + api}}
+		d, err := newDaemon(opts{}, cfg, testColors(), nil, "ralphex", &bytes.Buffer{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"api", "web"}, d.Repos())
+		assert.Len(t, d.roots(), 2)
+		assert.Equal(t, 1, d.parallel)
+	})
+
+	t.Run("current repo by default", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		t.Chdir(dir)
+		d, err := newDaemon(opts{}, &config.Config{}, testColors(), nil, "ralphex", &bytes.Buffer{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{filepath.Base(dir)}, d.Repos())
+	})
+
+	t.Run("no repository", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		_, err := newDaemon(opts{}, &config.Config{}, testColors(), nil, "ralphex", &bytes.Buffer{})
+		require.ErrorContains(t, err, "needs a repository")
+	})
+
+	t.Run("duplicate name", func(t *testing.T) {
+		dir := setupTestRepo(t)
+		cfg := &config.Config{DaemonRepos: []string{"api=" + dir, "api=" + dir}}
+		_, err := newDaemon(opts{}, cfg, testColors(), nil, "ralphex", &bytes.Buffer{})
+		require.ErrorContains(t, err, `duplicate daemon repo "api"`)
+	})
+
+	t.Run("token required", func(t *testing.T) {
+		err := runDaemon(context.Background(), opts{Serve: true, Daemon: true}, &config.Config{}, testColors(), nil)
+		require.ErrorContains(t, err, "requires an API token")
+	})
+}
+
+func TestDaemon_Submit(t *testing.T) {
+	d, root, _ := newTestDaemon(t, opts{}, "ralphex")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "docs", "plans"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "docs", "plans", "existing.md"), []byte("# Existing\n"), 0o600))
+
+	t.Run("invalid requests", func(t *testing.T) {
+		tests := []struct {
+			name string
+			req  web.RunRequest
+			err  string
+		}{
+			{name: "unknown repo", req: web.RunRequest{Repo: "nope", PlanFile: "a.md"}, err: `unknown repo "nope"`},
+			{name: "no plan", req: web.RunRequest{Repo: "api"}, err: "exactly one of"},
+			{name: "both plans", req: web.RunRequest{Repo: "api", PlanFile: "a.md", Markdown: "# A\n"}, err: "exactly one of"},
+			{name: "plan outside repo", req: web.RunRequest{Repo: "api", PlanFile: "../a.md"}, err: "relative path inside"},
+			{name: "absolute plan", req: web.RunRequest{Repo: "api", PlanFile: "/etc/passwd"}, err: "relative path inside"},
+			{name: "missing plan", req: web.RunRequest{Repo: "api", PlanFile: "docs/plans/missing.md"}, err: "not found"},
+			{name: "plan is a dir", req: web.RunRequest{Repo: "api", PlanFile: "docs/plans"}, err: "not found"},
+			{name: "untitled inline plan", req: web.RunRequest{Repo: "api", Markdown: "no title\n"}, err: "needs a name or a title"},
+			{name: "bad name", req: web.RunRequest{Repo: "api", Markdown: "# A\n", Name: "../x"}, err: "invalid plan name"},
+			{name: "bad name with extension", req: web.RunRequest{Repo: "api", Markdown: "# A\n", Name: "a b.md"}, err: `invalid plan name "a b"`},
+			{name: "existing inline plan", req: web.RunRequest{Repo: "api", Markdown: "# A\n", Name: "existing.md"}, err: "already exists"},
+		}
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := d.Submit(tc.req)
+				require.ErrorIs(t, err, web.ErrInvalidRun)
+				assert.ErrorContains(t, err, tc.err)
+			})
+		}
+		assert.Empty(t, d.Runs())
+	})
+
+	t.Run("inline plan", func(t *testing.T) {
+		info, err := d.Submit(web.RunRequest{Repo: "api", Markdown: "# Fix Login\n", Name: "fix-login"})
+		require.NoError(t, err)
+		assert.Equal(t, "1", info.ID)
+		assert.Equal(t, "docs/plans/fix-login.md", info.PlanFile)
+		assert.Equal(t, "fix-login", info.Branch)
+		assert.Equal(t, "full", info.Mode)
+		assert.Equal(t, web.RunQueued, info.State, "runs wait until the daemon starts")
+		assert.True(t, strings.HasPrefix(info.SessionID, "fix-login-"), info.SessionID)
+		data, err := os.ReadFile(filepath.Join(root, "docs", "plans", "fix-login.md"))
+		require.NoError(t, err)
+		assert.Equal(t, "# Fix Login\n", string(data))
+	})
+
+	t.Run("name from title", func(t *testing.T) {
+		info, err := d.Submit(web.RunRequest{Repo: "api", Markdown: "# Add Rate-Limiting (v2)!\n", TasksOnly: true})
+		require.NoError(t, err)
+		assert.Equal(t, "docs/plans/add-rate-limiting-v2.md", info.PlanFile)
+		assert.Equal(t, "tasks-only", info.Mode)
+	})
+
+	t.Run("plan file", func(t *testing.T) {
+		info, err := d.Submit(web.RunRequest{Repo: "api", PlanFile: "docs/plans/existing.md"})
+		require.NoError(t, err)
+		assert.Equal(t, "3", info.ID)
+		assert.Equal(t, "existing", info.Branch)
+	})
+
+	t.Run("branch already queued", func(t *testing.T) {
+		_, err := d.Submit(web.RunRequest{Repo: "api", PlanFile: "docs/plans/existing.md"})
+		require.ErrorIs(t, err, web.ErrInvalidRun)
+		assert.ErrorContains(t, err, "run 3 of branch existing is already queued")
+	})
+
+	runs := d.Runs()
+	require.Len(t, runs, 3)
+	assert.Equal(t, []string{"1", "2", "3"}, []string{runs[0].ID, runs[1].ID, runs[2].ID})
+}
+
+func TestDaemon_QueuedRunActions(t *testing.T) {
+	d, root, _ := newTestDaemon(t, opts{}, "ralphex")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "docs", "plans"), 0o750))
+	info, err := d.Submit(web.RunRequest{Repo: "api", Markdown: "# A\n", Name: "a"})
+	require.NoError(t, err)
+
+	require.ErrorIs(t, d.Break(info.ID), web.ErrRunNotActive)
+	require.ErrorIs(t, d.Resume(info.ID), web.ErrRunNotActive)
+	require.NoError(t, d.Cancel(info.ID))
+	got, err := d.Run(info.ID)
+	require.NoError(t, err)
+	assert.Equal(t, web.RunCanceled, got.State)
+	assert.False(t, got.FinishedAt.IsZero())
+	require.ErrorIs(t, d.Cancel(info.ID), web.ErrRunNotActive)
+
+	_, err = d.Run("42")
+	require.ErrorIs(t, err, web.ErrRunNotFound)
+	require.ErrorIs(t, d.Cancel("42"), web.ErrRunNotFound)
+
+	// a canceled run frees its branch for a new submission
+	_, err = d.Submit(web.RunRequest{Repo: "api", PlanFile: "docs/plans/a.md"})
+	require.NoError(t, err)
+}
+
+func TestDaemon_Execute(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts and SIGQUIT")
+	}
+	// fake ralphex: the plan path is the last argument, its name selects the behavior
+	exe := filepath.Join(t.TempDir(), "ralphex")
+	writeExecutable(t, exe, `#!/bin/sh
+for plan; do :; done
+case "$plan" in
+*fail*) echo "error: boom"; exit 1 ;;
+*pause*) trap 'echo got break; echo "session interrupted. press Enter to continue, Ctrl+C to abort"' QUIT
+  echo ready; until read -r line; do :; done; echo resumed ;;
+*review*) trap 'echo review loop ended' QUIT; echo ready; until read -r line; do :; done; echo "stdin: $line" ;;
+*slow*) echo ready; exec sleep 30 ;;
+esac
+echo "ran $*"
+`)
+	d, root, out := newTestDaemon(t, opts{Parallel: 2, MaxIterations: 7}, exe)
+	output := func() string {
+		d.repos["api"].outMu.Lock()
+		defer d.repos["api"].outMu.Unlock()
+		return out.String()
+	}
+	state := func(id string) web.RunState {
+		info, err := d.Run(id)
+		require.NoError(t, err)
+		return info.State
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	d.start(ctx)
+
+	ok, err := d.Submit(web.RunRequest{Repo: "api", Markdown: "# OK\n", Name: "ok"})
+	require.NoError(t, err)
+	failed, err := d.Submit(web.RunRequest{Repo: "api", Markdown: "# Fail\n", Name: "fail"})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return state(ok.ID) == web.RunCompleted && state(failed.ID) == web.RunFailed },
+		10*time.Second, 20*time.Millisecond)
+
+	info, err := d.Run(failed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "boom", info.Error)
+	assert.Contains(t, output(), "[api/ok] ran --max-iterations 7 --queue-worktree "+filepath.Join(root, ".ralphex", "worktrees", "ok"))
+	assert.Contains(t, output(), "[api/fail] error: boom")
+	assert.FileExists(t, filepath.Join(root, "docs", "plans", "completed", "ok.md"), "completed plan is archived")
+	assert.NoDirExists(t, filepath.Join(root, ".ralphex", "worktrees", "ok"))
+
+	t.Run("break and resume", func(t *testing.T) {
+		run, err := d.Submit(web.RunRequest{Repo: "api", Markdown: "# Pause\n", Name: "pause"})
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return strings.Contains(output(), "[api/pause] ready") }, 10*time.Second, 20*time.Millisecond)
+		require.ErrorIs(t, d.Resume(run.ID), web.ErrRunNotActive, "resume needs a break first")
+		require.NoError(t, d.Break(run.ID))
+		require.Eventually(t, func() bool { return d.Resume(run.ID) == nil }, 10*time.Second, 20*time.Millisecond,
+			"paused once the child shows the pause prompt")
+		require.ErrorIs(t, d.Resume(run.ID), web.ErrRunNotActive, "resumed run is not paused")
+		require.Eventually(t, func() bool { return state(run.ID) == web.RunCompleted }, 10*time.Second, 20*time.Millisecond)
+		assert.Contains(t, output(), "[api/pause] got break")
+		assert.Contains(t, output(), "[api/pause] resumed")
+	})
+
+	t.Run("break in review loop then resume", func(t *testing.T) {
+		run, err := d.Submit(web.RunRequest{Repo: "api", Markdown: "# Review\n", Name: "review"})
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return strings.Contains(output(), "[api/review] ready") }, 10*time.Second, 20*time.Millisecond)
+		require.NoError(t, d.Break(run.ID))
+		require.Eventually(t, func() bool { return strings.Contains(output(), "[api/review] review loop ended") },
+			10*time.Second, 20*time.Millisecond)
+		require.ErrorIs(t, d.Resume(run.ID), web.ErrRunNotActive, "a break that ended a review loop didn't pause the run")
+		require.NoError(t, d.Cancel(run.ID))
+		require.Eventually(t, func() bool { return state(run.ID) == web.RunCanceled }, 10*time.Second, 20*time.Millisecond)
+		assert.NotContains(t, output(), "[api/review] stdin:", "no stray newline sent to the child")
+	})
+
+	t.Run("cancel running", func(t *testing.T) {
+		run, err := d.Submit(web.RunRequest{Repo: "api", Markdown: "# Slow\n", Name: "slow"})
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return strings.Contains(output(), "[api/slow] ready") }, 10*time.Second, 20*time.Millisecond)
+		require.NoError(t, d.Cancel(run.ID))
+		require.Eventually(t, func() bool { return state(run.ID) == web.RunCanceled }, 10*time.Second, 20*time.Millisecond)
+		assert.NoDirExists(t, filepath.Join(root, ".ralphex", "worktrees", "slow"))
+	})
+
+	cancel()
+	d.wait()
+	_, err = d.Submit(web.RunRequest{Repo: "api", Markdown: "# Late\n", Name: "late"})
+	require.ErrorContains(t, err, "shutting down")
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/umputun/ralphex/pkg/findings"
+)
+
+// exportCommand is the subcommand converting a findings ledger for CI tools.
+const exportCommand = "export-findings"
+
+// applyExportCommand handles the "export-findings [branch|plan|ledger]" subcommand and returns the remaining args.
+func applyExportCommand(o *opts, args []string) []string {
+	if len(args) == 0 || args[0] != exportCommand || fileExists(args[0]) {
+		return args
+	}
+	o.exportCmd = true
+	if len(args) > 1 {
+		o.exportRef = args[1]
+		return args[2:]
+	}
+	return args[1:]
+}
+
+// showBanner reports whether the version banner is printed on startup. it is skipped for shell
+// completion, for export-findings, whose report may go to stdout, and for the rendered prompts.
+func showBanner(args []string) bool {
+	return os.Getenv("GO_FLAGS_COMPLETION") == "" && !slices.Contains(args, exportCommand) &&
+		!slices.Contains(args, promptsCommand)
+}
+
+// exportFindingsRequest holds the inputs of export-findings resolved from the repository.
+type exportFindingsRequest struct {
+	Root   string // repository root holding .ralphex/findings
+	Branch string // current branch, used when no ref is given
+	Ref    string // branch, plan file or ledger file to export
+	Format string
+	Output string // output file, empty for w
+}
+
+// runExportFindings writes the ledger selected by the request in the requested format to the output file or w.
+func runExportFindings(req exportFindingsRequest, w io.Writer) error {
+	ref := cmp.Or(req.Ref, req.Branch)
+	if ref == "" {
+		return errors.New("export findings: no branch checked out, pass a branch, plan file or ledger file")
+	}
+	format, err := findings.ParseFormat(cmp.Or(req.Format, string(findings.FormatSARIF)))
+	if err != nil {
+		return fmt.Errorf("export findings: %w", err)
+	}
+	path, err := findings.Find(req.Root, ref)
+	if err != nil {
+		return fmt.Errorf("export findings: %w", err)
+	}
+	ledger, err := findings.Load(path)
+	if err != nil {
+		return fmt.Errorf("export findings: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := ledger.Export(&buf, format); err != nil {
+		return fmt.Errorf("export %s: %w", path, err)
+	}
+	if req.Output != "" {
+		if err := os.WriteFile(req.Output, buf.Bytes(), 0o600); err != nil {
+			return fmt.Errorf("write findings export: %w", err)
+		}
+		return nil
+	}
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("write findings export: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/findings"
+)
+
+func TestApplyExportCommand(t *testing.T) {
+	t.Run("with ref", func(t *testing.T) {
+		var o opts
+		rest := applyExportCommand(&o, []string{"export-findings", "feature"})
+		assert.Empty(t, rest)
+		assert.True(t, o.exportCmd)
+		assert.Equal(t, "feature", o.exportRef)
+	})
+
+	t.Run("without ref", func(t *testing.T) {
+		var o opts
+		rest := applyExportCommand(&o, []string{"export-findings"})
+		assert.Empty(t, rest)
+		assert.True(t, o.exportCmd)
+		assert.Empty(t, o.exportRef)
+	})
+
+	t.Run("plan file argument", func(t *testing.T) {
+		var o opts
+		rest := applyExportCommand(&o, []string{"docs/plans/a.md"})
+		assert.Equal(t, []string{"docs/plans/a.md"}, rest)
+		assert.False(t, o.exportCmd)
+	})
+
+	t.Run("format flags", func(t *testing.T) {
+		o := parseTestOpts(t, "--format", "junit", "--output", "report.xml")
+		assert.Equal(t, "junit", o.FindingsFormat)
+		assert.Equal(t, "report.xml", o.FindingsOutput)
+		assert.Empty(t, parseTestOpts(t).FindingsFormat, "runExportFindings defaults to sarif")
+	})
+}
+
+func TestShowBanner(t *testing.T) {
+	assert.True(t, showBanner([]string{"--review"}))
+	assert.False(t, showBanner([]string{"export-findings", "--format", "sarif"}))
+	t.Setenv("GO_FLAGS_COMPLETION", "1")
+	assert.False(t, showBanner(nil))
+}
+
+func TestRunExportFindings(t *testing.T) {
+	root := t.TempDir()
+	ledger := &findings.Ledger{Branch: "feature", Findings: []findings.Finding{
+		{File: "a.go", Line: 3, Agent: "quality", Verdict: findings.VerdictConfirmed, Description: "race"},
+	}}
+	path := filepath.Join(root, findings.Dir, "feature-plan.json")
+	require.NoError(t, ledger.Save(path))
+
+	t.Run("current branch to stdout", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, runExportFindings(exportFindingsRequest{Root: root, Branch: "feature"}, &buf))
+		assert.Contains(t, buf.String(), `"version": "2.1.0"`)
+		assert.Contains(t, buf.String(), `"ruleId": "quality"`)
+	})
+
+	t.Run("plan ref to file", func(t *testing.T) {
+		out := filepath.Join(t.TempDir(), "findings.md")
+		var buf bytes.Buffer
+		req := exportFindingsRequest{Root: root, Branch: "master", Ref: "docs/plans/feature-plan.md", Format: "markdown", Output: out}
+		require.NoError(t, runExportFindings(req, &buf))
+		assert.Empty(t, buf.String())
+		data, err := os.ReadFile(out) //nolint:gosec // test file
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "| confirmed |  | a.go:3 | quality | race |  |")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		err := runExportFindings(exportFindingsRequest{Root: root}, &bytes.Buffer{})
+		require.ErrorContains(t, err, "no branch checked out")
+		err = runExportFindings(exportFindingsRequest{Root: root, Branch: "other"}, &bytes.Buffer{})
+		require.ErrorContains(t, err, `no findings ledger for branch "other"`)
+		err = runExportFindings(exportFindingsRequest{Root: root, Branch: "feature", Format: "pdf"}, &bytes.Buffer{})
+		require.ErrorContains(t, err, "unknown findings format")
+	})
+}
@@ -2,6 +2,7 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
@@ -19,6 +20,8 @@ import (
 	"github.com/jessevdk/go-flags"
 
 	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/findings"
 	"github.com/umputun/ralphex/pkg/git"
 	"github.com/umputun/ralphex/pkg/input"
 	"github.com/umputun/ralphex/pkg/notify"
@@ -39,22 +42,36 @@ type opts struct {
 	ReviewModel             string        `long:"review-model" description:"model for review phases as model[:effort] (falls back to --task-model)"`
 	ClaudeCommand           string        `long:"claude-command" description:"override claude-compatible command for this run"`
 	ClaudeArgs              string        `long:"claude-args" description:"override claude-compatible command args for this run"`
-	ExternalReviewTool      string        `long:"external-review-tool" choice:"codex" choice:"custom" choice:"none" description:"override external review tool for this run"`
+	ExternalReviewTool      string        `long:"external-review-tool" description:"override external review tools for this run (codex, http, custom, custom:<name>, none; comma-separated to run several)"`
 	CustomReviewScript      string        `long:"custom-review-script" description:"override custom external review script for this run"`
 	Review                  bool          `short:"r" long:"review" description:"skip task execution, run full review pipeline"`
+	Range                   string        `long:"range" description:"with --review: review the commit range A..B in a scratch worktree"`
+	Patch                   string        `long:"patch" description:"with --review: review a patch or mailbox file applied on the default branch in a scratch worktree"`
+	ReportOnly              bool          `long:"report-only" description:"with --review: report findings without fixing, committing or the review loop"`
 	ExternalOnly            bool          `short:"e" long:"external-only" description:"skip tasks and first review, run only external review loop"`
 	CodexOnly               bool          `short:"c" long:"codex-only" description:"alias for --external-only (deprecated)"`
 	TasksOnly               bool          `short:"t" long:"tasks-only" description:"run only task phase, skip all reviews"`
+	Pipeline                string        `long:"pipeline" description:"comma-separated stage order for this run, e.g. task,review_first,external,review_loop,finalize"`
 	BaseRef                 string        `short:"b" long:"base-ref" description:"override default branch for review diffs (branch name or commit hash)"`
 	Wait                    time.Duration `long:"wait" description:"wait duration on rate limit before retry (e.g. 1h, 30m)"`
 	SessionTimeout          time.Duration `long:"session-timeout" description:"per-session timeout for task/review executor (e.g. 30m, 1h); external review in Claude mode excluded"`
 	IdleTimeout             time.Duration `long:"idle-timeout" description:"kill claude/codex executor session after no output for this duration (e.g. 5m, 10m)"`
 	SkipFinalize            bool          `long:"skip-finalize" description:"skip finalize step even if enabled in config"`
+	Validate                bool          `long:"validate" description:"run the plan's validation commands after every task iteration and re-run the task on failure"`
+	MaxRunCost              float64       `long:"max-run-cost" description:"stop or downgrade the run once its cost reaches this many USD (0 = unlimited)"`
+	MaxRunTokens            int64         `long:"max-run-tokens" description:"stop or downgrade the run once it used this many tokens (0 = unlimited)"`
+	MaxPhaseCost            float64       `long:"max-phase-cost" description:"stop or downgrade the run once a single phase cost reaches this many USD (0 = unlimited)"`
+	BudgetAction            string        `long:"budget-action" choice:"stop" choice:"downgrade" description:"action on a crossed budget limit: stop the run or switch to budget_model"`
 	PreserveAnthropicAPIKey bool          `long:"preserve-anthropic-api-key" description:"pass ANTHROPIC_API_KEY through to claude (for users authenticating Claude Code via API key rather than OAuth/keychain)"`
+	Record                  string        `long:"record" description:"record every executor session of the run to this directory for --executor replay:<dir>"`
+	Executor                string        `long:"executor" description:"replay:<dir> replays the sessions recorded by --record instead of running claude or codex"`
 	Codex                   bool          `long:"codex" description:"use codex CLI as the executor for task, review, and finalize phases (skips external review)"`
 	PassClaudeMd            bool          `long:"pass-claude-md" description:"pass project CLAUDE.md to codex via project_doc_fallback_filenames; user-level ~/.claude/CLAUDE.md is NOT auto-passed but a one-time setup hint is shown (codex executor only)"`
 	Worktree                bool          `long:"worktree" description:"run in isolated git worktree"`
 	Branch                  string        `long:"branch" description:"override branch name for worktree/branch creation (default: derived from plan filename)"`
+	Queue                   string        `long:"queue" description:"run every pending plan in the directory, each in its own worktree, ordered by depends_on"`
+	Parallel                int           `long:"parallel" default:"1" description:"maximum number of queued plans run concurrently"`
+	QueueWorktree           string        `long:"queue-worktree" hidden:"true" description:"run the plan in a worktree prepared by --queue or serve --daemon"`
 	PlanDescription         string        `long:"plan" description:"create plan interactively (enter plan description)"`
 	Debug                   bool          `short:"d" long:"debug" description:"enable debug logging"`
 	NoColor                 bool          `long:"no-color" description:"disable color output"`
@@ -63,10 +80,22 @@ type opts struct {
 	Port                    int           `short:"p" long:"port" default:"8080" description:"web dashboard port"`
 	Host                    string        `long:"host" default:"127.0.0.1" env:"RALPHEX_WEB_HOST" description:"web dashboard listen address"`
 	Watch                   []string      `short:"w" long:"watch" description:"directories to watch for progress files (repeatable)"`
+	Daemon                  bool          `long:"daemon" description:"with --serve: run plans submitted through the authenticated run API"`
+	Repos                   []string      `long:"repo" description:"repository accepted by --daemon as name=path (repeatable)"`
+	WebToken                string        `long:"web-token" env:"RALPHEX_WEB_TOKEN" description:"bearer token required by every dashboard route, the run API and dashboard controls"`
+	WebUser                 string        `long:"web-user" env:"RALPHEX_WEB_USER" description:"basic auth user required by every dashboard route"`
+	WebPassword             string        `long:"web-password" env:"RALPHEX_WEB_PASSWORD" description:"basic auth password of --web-user"`
+	TLSCert                 string        `long:"tls-cert" env:"RALPHEX_TLS_CERT" description:"serve the dashboard over HTTPS with this PEM certificate (requires --tls-key)"`
+	TLSKey                  string        `long:"tls-key" env:"RALPHEX_TLS_KEY" description:"PEM private key of --tls-cert"`
+	TLSSelfSigned           bool          `long:"tls-self-signed" env:"RALPHEX_TLS_SELF_SIGNED" description:"serve the dashboard over HTTPS with a generated self-signed certificate"`
 	Init                    bool          `long:"init" description:"initialize local .ralphex/ config directory in current project"`
 	Reset                   bool          `long:"reset" description:"interactively reset global config to embedded defaults"`
 	DumpDefaults            string        `long:"dump-defaults" description:"extract raw embedded defaults to specified directory"`
 	ConfigDir               string        `long:"config-dir" env:"RALPHEX_CONFIG_DIR" description:"custom config directory"`
+	FindingsFormat          string        `long:"format" choice:"sarif" choice:"junit" choice:"markdown" choice:"codequality" description:"export-findings output format"`
+	FindingsOutput          string        `long:"output" description:"export-findings output file (default: stdout)"`
+	Phase                   string        `long:"phase" description:"prompts render: render only this phase (make_plan, task, review_first, codex_review, codex, custom_review, custom_eval, review_second, review_report, finalize)"`
+	WriteDir                string        `long:"write-dir" description:"prompts render: write each rendered prompt to <phase>.<syntax>.txt in this directory"`
 
 	PlanFile string `positional-arg-name:"plan-file" description:"path to plan file (optional, uses fzf if omitted)"`
 
@@ -79,6 +108,15 @@ type opts struct {
 	claudeArgsSet         bool
 	externalReviewToolSet bool
 	customReviewScriptSet bool
+
+	maxRunCostSet   bool
+	maxRunTokensSet bool
+	maxPhaseCostSet bool
+
+	queueCmd   bool   // set by the "queue [dir]" subcommand
+	exportCmd  bool   // set by the "export-findings [ref]" subcommand
+	exportRef  string // branch, plan file or ledger file of export-findings
+	promptsCmd bool   // set by the "prompts render [plan]" subcommand
 }
 
 // markFlagsSet detects which duration flags were explicitly provided on the CLI
@@ -94,6 +132,9 @@ func (o *opts) markFlagsSet(parser *flags.Parser) {
 	o.claudeArgsSet = isFlagSet(parser, "claude-args")
 	o.externalReviewToolSet = isFlagSet(parser, "external-review-tool")
 	o.customReviewScriptSet = isFlagSet(parser, "custom-review-script")
+	o.maxRunCostSet = isFlagSet(parser, "max-run-cost")
+	o.maxRunTokensSet = isFlagSet(parser, "max-run-tokens")
+	o.maxPhaseCostSet = isFlagSet(parser, "max-phase-cost")
 }
 
 var revision = "unknown"
@@ -145,6 +186,8 @@ type startupInfo struct {
 	CodexReviewModel        string // resolved model for codex review phase; shown only when it differs from CodexModel
 	CodexReviewEffort       string // resolved reasoning effort for codex review phase; shown only when it differs from CodexEffort
 	CodexSandbox            string // resolved sandbox for codex executor; always non-empty when Executor == codex
+	Pipeline                []string
+	PlanSettings            []string // key=value settings applied from the plan frontmatter
 }
 
 // executePlanRequest holds parameters for plan execution.
@@ -160,9 +203,15 @@ type executePlanRequest struct {
 	BaseRef        string // base reference for review diffs and templates (--base-ref override or DefaultBranch)
 	NotifySvc      *notify.Service
 	BranchOverride string              // branch name override (--branch flag); empty = derive from plan filename
+	ReviewTarget   string              // reviewed commit range or patch (--range, --patch); empty = current branch
 	WtCleanup      *worktreeCleanupFn  // worktree cleanup for interrupt handler; nil when not in worktree mode
 	ProgressLog    *progress.Logger    // pre-created logger (worktree mode); nil in normal mode
 	PhaseHolder    *status.PhaseHolder // pre-created holder (worktree mode); nil in normal mode
+	PlanSettings   []string            // key=value settings applied from the plan frontmatter, for the banner
+
+	// ExternalReviewToolSet marks external_review_tool set by the plan frontmatter, an explicit
+	// choice like --external-review-tool that overrides codex_enabled = false
+	ExternalReviewToolSet bool
 }
 
 // worktreeCleanupFn holds a worktree cleanup function with mutex for safe cross-goroutine access.
@@ -188,7 +237,7 @@ func (c *worktreeCleanupFn) call() {
 }
 
 func main() {
-	if os.Getenv("GO_FLAGS_COMPLETION") == "" {
+	if showBanner(os.Args[1:]) {
 		fmt.Printf("ralphex %s\n", resolveVersion())
 	}
 
@@ -209,7 +258,8 @@ func main() {
 		os.Exit(0)
 	}
 
-	// handle positional argument
+	// handle "queue [dir]", "serve", "export-findings [ref]" and "prompts render" subcommands and positional argument
+	args = applyPromptsCommand(&o, applyExportCommand(&o, applyServeCommand(&o, applyQueueCommand(&o, args))))
 	if len(args) > 0 {
 		o.PlanFile = args[0]
 	}
@@ -248,6 +298,9 @@ func run(ctx context.Context, o opts) error {
 	if err := validateFlags(o); err != nil {
 		return err
 	}
+	if err := resolveRecordDirs(&o); err != nil {
+		return err
+	}
 
 	// handle early-exit flags (before full config load)
 	if done, err := handleEarlyFlags(o); err != nil || done {
@@ -266,6 +319,21 @@ func run(ctx context.Context, o opts) error {
 	// create colors from config (all colors guaranteed populated via fallback)
 	colors := progress.NewColors(cfg.Colors)
 
+	// export-findings converts a findings ledger of the repository, no executor involved
+	if o.exportCmd {
+		gitSvc, gitErr := openGitService(colors, cfg.VcsCommand)
+		if gitErr != nil {
+			return fmt.Errorf("open git repo: %w", gitErr)
+		}
+		return runExportFindings(exportFindingsRequest{Root: gitSvc.Root(), Branch: runBranch(gitSvc), Ref: o.exportRef,
+			Format: o.FindingsFormat, Output: o.FindingsOutput}, os.Stdout)
+	}
+
+	// prompts render prints the prompts of the current config, no executor involved
+	if o.promptsCmd {
+		return runRenderPrompts(renderPromptsRequestFor(o, cfg, colors), os.Stdout)
+	}
+
 	// create notification service (nil if no channels configured)
 	notifySvc, err := notify.New(cfg.NotifyParams, stderrLog{})
 	if err != nil {
@@ -283,12 +351,30 @@ func run(ctx context.Context, o opts) error {
 	// codex itself is checked here so absence is reported up-front rather than
 	// as a cryptic exec failure on the first task.
 	depCheck := checkClaudeDep
-	if cfg.Executor == config.ExecutorCodex {
+	switch {
+	case cfg.Executor == config.ExecutorCodex:
 		depCheck = checkCodexDep
+	case cfg.AgentProvider != "":
+		depCheck = checkProviderDep
 	}
-	if depErr := depCheck(cfg); depErr != nil {
+	// a replayed run doesn't call the executors, only its recordings must be readable
+	if dir := replayDir(o); dir != "" {
+		if replayErr := processor.CheckReplayDir(dir); replayErr != nil {
+			return fmt.Errorf("check --executor replay: %w", replayErr)
+		}
+	} else if depErr := depCheck(cfg); depErr != nil {
 		return depErr
 	}
+	if o.Record != "" {
+		if recErr := processor.PrepareRecordDir(o.Record); recErr != nil {
+			return fmt.Errorf("prepare --record: %w", recErr)
+		}
+	}
+
+	// daemon mode runs plans submitted through the run API for its registered repositories
+	if o.Daemon {
+		return runDaemon(ctx, o, cfg, colors, notifySvc)
+	}
 
 	// require running from repo root.
 	// when using a non-git vcs command, skip the .git check — rely on NewService's
@@ -319,6 +405,20 @@ func run(ctx context.Context, o opts) error {
 
 	mode := determineMode(o)
 
+	// queue mode runs every pending plan of a directory, each in its own worktree
+	if isQueueRun(o) {
+		return runQueue(ctx, o, executePlanRequest{
+			Mode:          mode,
+			GitSvc:        gitSvc,
+			Config:        cfg,
+			Colors:        colors,
+			DefaultBranch: defaultBranch,
+			BaseRef:       baseRef,
+			NotifySvc:     notifySvc,
+			WtCleanup:     wtCleanup,
+		})
+	}
+
 	// create plan selector for use by plan selection and plan mode
 	selector := plan.NewSelector(cfg.PlansDir, colors)
 
@@ -365,6 +465,22 @@ func selectAndExecutePlan(ctx context.Context, o opts, req executePlanRequest, s
 	}
 
 	req.PlanFile = planFile
+	if req.PlanSettings, err = applyPlanSettings(o, &req, os.Stderr); err != nil {
+		return err
+	}
+
+	// commit range or patch review runs in a scratch worktree, the plan file only gives context
+	if o.Range != "" || o.Patch != "" {
+		return runReviewTarget(ctx, o, req)
+	}
+
+	// plan of a queue: the queue prepared the worktree and removes it, archives the plan
+	// and sends a single notification for all plans
+	if o.QueueWorktree != "" {
+		req.NotifySvc = nil
+		req.Config.MovePlanOnCompletion = false
+		return runInWorktree(ctx, o, req, o.QueueWorktree, false, false)
+	}
 
 	// worktree mode: create worktree, chdir into it, run execution from there.
 	if req.Config.WorktreeEnabled && planFile != "" && modeRequiresBranch(req.Mode) {
@@ -486,17 +602,24 @@ func setupProgressLogger(o opts, req executePlanRequest, branch string) (progres
 // sendNotification sends a completion or failure notification.
 // uses context.Background() because the parent ctx may be canceled (e.g. SIGINT),
 // and the notification timeout is applied inside Send() independently.
-func sendNotification(req executePlanRequest, branch, elapsed string, stats git.DiffStats, runErr error) {
-	req.NotifySvc.Send(context.Background(), buildNotifyResult(req, branch, elapsed, stats, runErr))
+func sendNotification(req executePlanRequest, branch, elapsed string, stats git.DiffStats, usage executor.Usage, runErr error) {
+	req.NotifySvc.Send(context.Background(), buildNotifyResult(req, branch, elapsed, stats, usage, runErr))
 }
 
 // buildNotifyResult constructs a notify.Result from execution parameters.
-func buildNotifyResult(req executePlanRequest, branch, elapsed string, stats git.DiffStats, runErr error) notify.Result {
+// usage is reported for both outcomes, a failed run still spent its tokens.
+func buildNotifyResult(req executePlanRequest, branch, elapsed string, stats git.DiffStats, usage executor.Usage,
+	runErr error) notify.Result {
 	result := notify.Result{
-		Mode:     string(req.Mode),
-		PlanFile: req.PlanFile,
-		Branch:   branch,
-		Duration: elapsed,
+		Mode:                string(req.Mode),
+		PlanFile:            req.PlanFile,
+		Branch:              branch,
+		Duration:            elapsed,
+		InputTokens:         usage.InputTokens,
+		OutputTokens:        usage.OutputTokens,
+		CacheCreationTokens: usage.CacheCreationTokens,
+		CacheReadTokens:     usage.CacheReadTokens,
+		CostUSD:             usage.CostUSD,
 	}
 	if runErr != nil {
 		result.Status = "failure"
@@ -517,8 +640,8 @@ func buildNotifyResult(req executePlanRequest, branch, elapsed string, stats git
 // what the planMoveErr note points at: it is non-nil only when the archive was attempted
 // and failed, and it goes last because the warning at the failure site is well above the
 // completion line.
-func displayStats(req executePlanRequest, baseLog *progress.Logger, stats git.DiffStats, elapsed, branch string,
-	planMoved bool, planMoveErr error) {
+func displayStats(req executePlanRequest, baseLog *progress.Logger, stats git.DiffStats, usage executor.Usage,
+	elapsed, branch string, planMoved bool, planMoveErr error) {
 	if stats.Files > 0 {
 		baseLog.LogDiffStats(stats.Files, stats.Additions, stats.Deletions)
 		req.Colors.Info().Printf("\ncompleted in %s (%d files, +%d/-%d lines)\n",
@@ -526,6 +649,9 @@ func displayStats(req executePlanRequest, baseLog *progress.Logger, stats git.Di
 	} else {
 		req.Colors.Info().Printf("\ncompleted in %s\n", elapsed)
 	}
+	if !usage.IsZero() {
+		req.Colors.Info().Printf("  usage: %s\n", usage)
+	}
 
 	planPath := ""
 	if req.PlanFile != "" {
@@ -562,8 +688,8 @@ func keepDashboardAlive(ctx context.Context, o opts, req executePlanRequest, clo
 		return
 	}
 	closeLog()
-	req.Colors.Info().Printf("web dashboard still running at http://%s:%d (press Ctrl+C to exit)\n",
-		web.ConnectHost(o.Host), o.Port)
+	req.Colors.Info().Printf("web dashboard still running at %s (press Ctrl+C to exit)\n",
+		web.DashboardURL(o.Host, o.Port, webTLS(req.Config)))
 	<-ctx.Done()
 }
 
@@ -573,6 +699,9 @@ func keepDashboardAlive(ctx context.Context, o opts, req executePlanRequest, clo
 // when req.MainGitSvc is set, uses it for plan file operations (plan is in main repo).
 func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 	branch := getCurrentBranch(req.GitSvc)
+	if req.ReviewTarget != "" {
+		branch = req.BranchOverride // scratch worktree of --range/--patch has a detached HEAD
+	}
 
 	// set up progress logger and phase holder
 	plr, err := setupProgressLogger(o, req, branch)
@@ -581,9 +710,18 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 	}
 	defer plr.closeLog()
 
+	// the dashboard controls abort a running run by canceling runCtx with ErrUserAborted
+	runCtx, abortRun := context.WithCancelCause(ctx)
+	defer abortRun(nil)
+
 	// wrap logger with broadcast logger if --serve is enabled
 	var runnerLog processor.Logger = plr.baseLog
+	var control *web.RunControl
 	if o.Serve {
+		// dashboard controls are enabled only with a token, as anyone reaching the port could steer the run
+		if req.Config.WebToken != "" {
+			control = web.NewRunControl(plr.holder, func() { abortRun(processor.ErrUserAborted) })
+		}
 		params := runHeaderParams(o, req.Config, req.Mode)
 		dashboard := web.NewDashboard(web.DashboardConfig{
 			BaseLog:         plr.baseLog,
@@ -595,6 +733,11 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 			WatchDirs:       o.Watch,
 			ConfigWatchDirs: req.Config.WatchDirs,
 			Colors:          req.Colors,
+			Token:           req.Config.WebToken,
+			User:            req.Config.WebUser,
+			Password:        req.Config.WebPassword,
+			TLS:             webTLS(req.Config),
+			Control:         control,
 		}, plr.holder)
 		var dashErr error
 		runnerLog, dashErr = dashboard.Start(ctx)
@@ -631,6 +774,8 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 		CodexReviewModel:        codex.reviewModel,
 		CodexReviewEffort:       codex.reviewEffort,
 		CodexSandbox:            req.Config.CodexExecutorSandbox(),
+		Pipeline:                req.Config.Pipeline,
+		PlanSettings:            req.PlanSettings,
 	}, req.Colors)
 	if codex.maxDropped {
 		req.Colors.Warn().Printf("codex does not support 'max' reasoning effort; ignoring (valid: low, medium, high, xhigh)\n")
@@ -639,13 +784,25 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 	// create and run the runner
 	r := createRunner(req, o, runnerLog, plr.holder)
 
-	// listen for SIGQUIT (Ctrl+\) for manual break during task and review loops
-	if breakCh := startBreakSignal(); breakCh != nil {
+	// listen for SIGQUIT (Ctrl+\) for manual break during task and review loops,
+	// merged with the dashboard controls when enabled
+	breakCh := startBreakSignal()
+	switch {
+	case control != nil:
+		r.SetBreakCh(control.BreakCh(breakCh))
+		r.SetPauseHandler(control.PauseHandler(makePauseHandler(os.Stdin, os.Stdout)))
+	case breakCh != nil:
 		r.SetBreakCh(breakCh)
 		r.SetPauseHandler(makePauseHandler(os.Stdin, os.Stdout))
 	}
 
-	if runErr := r.Run(ctx); runErr != nil {
+	runErr := r.Run(runCtx)
+	if runErr != nil && errors.Is(context.Cause(runCtx), processor.ErrUserAborted) {
+		runErr = processor.ErrUserAborted
+	}
+	usage := r.Usage().Total
+	plr.baseLog.SetUsage(usage)
+	if runErr != nil {
 		// mark logger as failed so Close writes "Failed:" footer, preserving history
 		// for restart. Applies to ErrUserAborted too — user aborts are not completions.
 		// abort keeps the raw error in the footer (self-descriptive); real failures
@@ -657,7 +814,7 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 		}
 		wrapped := fmt.Errorf("runner: %w", runErr)
 		plr.baseLog.SetFailed(wrapped)
-		sendNotification(req, branch, plr.baseLog.Elapsed(), git.DiffStats{}, runErr)
+		sendNotification(req, branch, plr.baseLog.Elapsed(), git.DiffStats{}, usage, runErr)
 		return wrapped
 	}
 
@@ -670,14 +827,14 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 		fmt.Fprintf(os.Stderr, "warning: failed to get diff stats: %v\n", statsErr)
 	}
 
-	sendNotification(req, branch, elapsed, stats, nil)
+	sendNotification(req, branch, elapsed, stats, usage, nil)
 
 	// move completed plan to completed/ directory.
 	// use MainGitSvc+MainPlanFile when available (worktree mode) because the plan file is in the main repo.
 	// track actual success so the completion summary reflects where the plan really lives.
 	planMoved, planMoveErr := archivePlan(req, plr.baseLog)
 
-	displayStats(req, plr.baseLog, stats, elapsed, branch, planMoved, planMoveErr)
+	displayStats(req, plr.baseLog, stats, usage, elapsed, branch, planMoved, planMoveErr)
 	keepDashboardAlive(ctx, o, req, plr.closeLog)
 
 	return nil
@@ -686,29 +843,38 @@ func executePlan(ctx context.Context, o opts, req executePlanRequest) error {
 // runWithWorktree creates a worktree, creates the progress logger (before chdir so it lands
 // in the main repo), chdirs into the worktree, and runs executePlan. On return the worktree
 // is cleaned up and CWD is restored. req.WtCleanup is populated for interrupt handler use.
-func runWithWorktree(ctx context.Context, o opts, req executePlanRequest) (err error) {
+func runWithWorktree(ctx context.Context, o opts, req executePlanRequest) error {
 	wtPath, planNeedsCommit, err := req.GitSvc.CreateWorktreeForPlan(req.PlanFile, req.DefaultBranch, req.BranchOverride)
 	if err != nil {
 		return fmt.Errorf("create worktree: %w", err)
 	}
+	return runInWorktree(ctx, o, req, wtPath, planNeedsCommit, true)
+}
+
+// runInWorktree runs executePlan inside the worktree at wtPath, see runWithWorktree.
+// owned worktrees are removed on return; a worktree prepared by a --queue run is left
+// for the queue to remove.
+func runInWorktree(ctx context.Context, o opts, req executePlanRequest, wtPath string, planNeedsCommit, owned bool) (err error) {
+	removeWorktree := func(format string) {
+		if !owned {
+			return
+		}
+		if rmErr := req.GitSvc.RemoveWorktree(wtPath); rmErr != nil {
+			fmt.Fprintf(os.Stderr, format, rmErr)
+		}
+	}
 
 	// register early cleanup so the interrupt handler's force-exit path (os.Exit after 5s)
 	// can remove the worktree even during setup. overwritten with full cleanup after chdir.
 	// RemoveWorktree is idempotent, so double-call from both early and safety-net defer is safe.
-	req.WtCleanup.set(func() {
-		if rmErr := req.GitSvc.RemoveWorktree(wtPath); rmErr != nil {
-			fmt.Fprintf(os.Stderr, "warning: failed to remove worktree: %v\n", rmErr)
-		}
-	})
+	req.WtCleanup.set(func() { removeWorktree("warning: failed to remove worktree: %v\n") })
 
 	// safety net: remove worktree if setup fails before main cleanup is registered.
 	// once main cleanup takes over (setupDone=true), this defer becomes a no-op.
 	setupDone := false
 	defer func() {
 		if !setupDone {
-			if rmErr := req.GitSvc.RemoveWorktree(wtPath); rmErr != nil {
-				fmt.Fprintf(os.Stderr, "warning: failed to remove worktree after setup error: %v\n", rmErr)
-			}
+			removeWorktree("warning: failed to remove worktree after setup error: %v\n")
 		}
 	}()
 
@@ -765,9 +931,7 @@ func runWithWorktree(ctx context.Context, o opts, req executePlanRequest) (err e
 			if chdirErr := os.Chdir(origDir); chdirErr != nil {
 				fmt.Fprintf(os.Stderr, "warning: failed to restore working directory: %v\n", chdirErr)
 			}
-			if rmErr := req.GitSvc.RemoveWorktree(wtPath); rmErr != nil {
-				fmt.Fprintf(os.Stderr, "warning: failed to remove worktree: %v\n", rmErr)
-			}
+			removeWorktree("warning: failed to remove worktree: %v\n")
 		})
 	}
 	setupDone = true // disable safety-net defer, main cleanup takes over
@@ -790,24 +954,30 @@ func runWithWorktree(ctx context.Context, o opts, req executePlanRequest) (err e
 
 	// a relative plan path leaves wtPlanFile empty; it still resolves correctly from inside
 	// the worktree, so run with it unchanged.
+	// a scratch review worktree may predate the plan, the main repo copy is used then.
 	runPlanFile := wtPlanFile
-	if runPlanFile == "" {
+	if runPlanFile == "" || !fileExists(runPlanFile) {
 		runPlanFile = req.PlanFile
 	}
 
 	return executePlan(ctx, o, executePlanRequest{
-		PlanFile:      runPlanFile,
-		MainPlanFile:  req.PlanFile, // original path in main repo for MovePlanToCompleted
-		Mode:          req.Mode,
-		GitSvc:        wtGitSvc,
-		MainGitSvc:    req.GitSvc,
-		Config:        req.Config,
-		Colors:        req.Colors,
-		DefaultBranch: req.DefaultBranch,
-		BaseRef:       req.BaseRef,
-		NotifySvc:     req.NotifySvc,
-		ProgressLog:   baseLog,
-		PhaseHolder:   holder,
+		PlanFile:       runPlanFile,
+		MainPlanFile:   req.PlanFile, // original path in main repo for MovePlanToCompleted
+		Mode:           req.Mode,
+		GitSvc:         wtGitSvc,
+		MainGitSvc:     req.GitSvc,
+		Config:         req.Config,
+		Colors:         req.Colors,
+		DefaultBranch:  req.DefaultBranch,
+		BaseRef:        req.BaseRef,
+		NotifySvc:      req.NotifySvc,
+		BranchOverride: req.BranchOverride,
+		ReviewTarget:   req.ReviewTarget,
+		ProgressLog:    baseLog,
+		PhaseHolder:    holder,
+		PlanSettings:   req.PlanSettings,
+
+		ExternalReviewToolSet: req.ExternalReviewToolSet,
 	})
 }
 
@@ -854,6 +1024,18 @@ func checkClaudeDep(cfg *config.Config) error {
 	return nil
 }
 
+// checkProviderDep checks that the agent_provider file is valid and its command is available in PATH.
+func checkProviderDep(cfg *config.Config) error {
+	p, err := executor.LoadProvider(cfg.AgentProvider)
+	if err != nil {
+		return fmt.Errorf("agent_provider: %w", err)
+	}
+	if _, err := exec.LookPath(p.Command); err != nil {
+		return fmt.Errorf("%s not found in PATH; install it or fix command in %s", p.Command, cfg.AgentProvider)
+	}
+	return nil
+}
+
 // checkCodexDep checks that the codex command is available in PATH.
 // used when executor=codex (--codex) so codex absence is reported up-front
 // with a clean message rather than a cryptic exec error on the first task.
@@ -871,16 +1053,20 @@ func checkCodexDep(cfg *config.Config) error {
 // isWatchOnlyMode returns true if running in watch-only mode.
 // watch-only mode runs the web dashboard without executing any plan.
 func isWatchOnlyMode(o opts, configWatchDirs []string) bool {
-	return o.Serve && o.PlanFile == "" && o.PlanDescription == "" && (len(o.Watch) > 0 || len(configWatchDirs) > 0)
+	return o.Serve && !o.Daemon && o.PlanFile == "" && o.PlanDescription == "" && (len(o.Watch) > 0 || len(configWatchDirs) > 0)
 }
 
 // runWatchOnly starts the web dashboard in watch-only mode without plan execution.
 func runWatchOnly(ctx context.Context, o opts, cfg *config.Config, colors *progress.Colors) error {
 	dirs := web.ResolveWatchDirs(o.Watch, cfg.WatchDirs)
 	dashboard := web.NewDashboard(web.DashboardConfig{
-		Port:   o.Port,
-		Host:   o.Host,
-		Colors: colors,
+		Port:     o.Port,
+		Host:     o.Host,
+		Colors:   colors,
+		Token:    cfg.WebToken,
+		User:     cfg.WebUser,
+		Password: cfg.WebPassword,
+		TLS:      webTLS(cfg),
 	}, nil)
 	if watchErr := dashboard.RunWatchOnly(ctx, dirs); watchErr != nil {
 		return fmt.Errorf("run watch-only mode: %w", watchErr)
@@ -910,12 +1096,16 @@ func modeRequiresBranch(mode processor.Mode) bool {
 	return mode == processor.ModeFull || mode == processor.ModeTasksOnly
 }
 
+// pausePrompt is printed by the pause handler while it waits for Enter. the daemon watches a child's
+// output for it to tell a paused task iteration from a break that ended a review loop.
+const pausePrompt = "session interrupted. press Enter to continue, Ctrl+C to abort"
+
 // makePauseHandler returns a context-aware pause handler for task loop breaks.
 // on break, prints a message and waits for Enter to resume or context cancellation to abort.
 // stdin read runs in a goroutine so the handler responds to Ctrl+C (SIGINT) promptly.
 func makePauseHandler(stdin io.Reader, stdout io.Writer) func(ctx context.Context) bool {
 	return func(ctx context.Context) bool {
-		fmt.Fprintln(stdout, "\nsession interrupted. press Enter to continue, Ctrl+C to abort")
+		fmt.Fprintln(stdout, "\n"+pausePrompt)
 
 		resultCh := make(chan bool, 1)
 		go func() {
@@ -978,12 +1168,96 @@ func validateFlags(o opts) error {
 	if o.IdleTimeout < 0 {
 		return fmt.Errorf("--idle-timeout must be non-negative, got %s", o.IdleTimeout)
 	}
+	if o.MaxRunCost < 0 || o.MaxRunTokens < 0 || o.MaxPhaseCost < 0 {
+		return errors.New("--max-run-cost, --max-run-tokens and --max-phase-cost must be non-negative")
+	}
+	if o.ReportOnly && (!o.Review || o.ExternalOnly || o.CodexOnly || o.TasksOnly) {
+		return errors.New("--report-only requires --review and conflicts with --external-only, --codex-only and --tasks-only")
+	}
+	if err := validateReviewTarget(o); err != nil {
+		return err
+	}
+	if o.Pipeline != "" && (o.Review || o.ExternalOnly || o.CodexOnly || o.TasksOnly || o.PlanDescription != "") {
+		return errors.New("--pipeline conflicts with --review, --external-only, --codex-only, --tasks-only and --plan")
+	}
+	if isQueueRun(o) && (o.PlanFile != "" || o.PlanDescription != "" || o.Review || o.ExternalOnly || o.CodexOnly ||
+		o.Serve || o.QueueWorktree != "") {
+		return errors.New("--queue conflicts with plan file argument, --plan, --review, --external-only, --codex-only and --serve")
+	}
+	if o.Parallel < 0 {
+		return fmt.Errorf("--parallel must be non-negative, got %d", o.Parallel)
+	}
+	if o.QueueWorktree != "" && o.PlanFile == "" {
+		return errors.New("--queue-worktree requires a plan file argument")
+	}
+	if o.Daemon && !o.Serve {
+		return errors.New("--daemon requires --serve or the serve command")
+	}
+	if o.Daemon && (o.PlanFile != "" || o.PlanDescription != "" || o.Review || o.ExternalOnly || o.CodexOnly ||
+		o.QueueWorktree != "") {
+		return errors.New("--daemon conflicts with plan file argument, --plan, --review, --external-only and --codex-only")
+	}
+	if err := validateRecordFlags(o); err != nil {
+		return err
+	}
+	if !o.exportCmd && (o.FindingsFormat != "" || o.FindingsOutput != "") {
+		return errors.New("--format and --output only apply to the export-findings command")
+	}
+	if !o.promptsCmd && (o.Phase != "" || o.WriteDir != "") {
+		return errors.New("--phase and --write-dir only apply to the prompts render command")
+	}
 	// --codex / --pass-claude-md / --external-only / --codex-only / --external-review-tool
 	// mutual-exclusion checks are deferred to applyCodexOverrides, which runs after the
 	// config-file merge so that executor=codex coming from config is also enforced.
 	return nil
 }
 
+// validateRecordFlags checks --record and --executor, a recording covers the sessions of a single run.
+func validateRecordFlags(o opts) error {
+	if o.Executor != "" && replayDir(o) == "" {
+		return fmt.Errorf("--executor must be replay:<dir>, got %q", o.Executor)
+	}
+	if o.Record == "" && o.Executor == "" {
+		return nil
+	}
+	if o.Record != "" && o.Executor != "" {
+		return errors.New("--record conflicts with --executor replay:<dir>")
+	}
+	if isQueueRun(o) || o.Daemon {
+		return errors.New("--record and --executor replay:<dir> conflict with --queue and --daemon")
+	}
+	return nil
+}
+
+// resolveRecordDirs makes the --record and --executor replay:<dir> directories absolute, a worktree
+// run changes the working directory before the runner opens them.
+func resolveRecordDirs(o *opts) error {
+	if o.Record != "" {
+		dir, err := filepath.Abs(o.Record)
+		if err != nil {
+			return fmt.Errorf("resolve --record: %w", err)
+		}
+		o.Record = dir
+	}
+	if dir := replayDir(*o); dir != "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return fmt.Errorf("resolve --executor replay: %w", err)
+		}
+		o.Executor = "replay:" + abs
+	}
+	return nil
+}
+
+// replayDir returns the directory of --executor replay:<dir>, empty when not replaying.
+func replayDir(o opts) string {
+	dir, ok := strings.CutPrefix(o.Executor, "replay:")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(dir)
+}
+
 // createRunner creates a processor.Runner with the given configuration.
 func createRunner(req executePlanRequest, o opts, log processor.Logger, holder *status.PhaseHolder) *processor.Runner {
 	// --codex-only mode forces codex enabled regardless of config
@@ -1003,6 +1277,12 @@ func createRunner(req executePlanRequest, o opts, log processor.Logger, holder *
 		reviewPatience = o.ReviewPatience
 	}
 
+	// pipeline replaces the default run only; mode flags keep their presets
+	var pipeline []string
+	if req.Mode == processor.ModeFull {
+		pipeline = req.Config.Pipeline
+	}
+
 	r := processor.New(processor.Config{
 		PlanFile:              req.PlanFile,
 		ProgressPath:          log.Path(),
@@ -1015,12 +1295,20 @@ func createRunner(req executePlanRequest, o opts, log processor.Logger, holder *
 		IterationDelayMs:      req.Config.IterationDelayMs,
 		TaskRetryCount:        req.Config.TaskRetryCount,
 		CodexEnabled:          codexEnabled,
-		ExternalReviewToolSet: o.externalReviewToolSet,
+		ExternalReviewToolSet: o.externalReviewToolSet || req.ExternalReviewToolSet,
 		FinalizeEnabled:       req.Config.FinalizeEnabled,
 		DefaultBranch:         req.BaseRef,
+		ReviewTarget:          req.ReviewTarget,
+		Pipeline:              pipeline,
+		ReportOnly:            o.ReportOnly && req.Mode == processor.ModeReview,
+		FindingsPath:          findingsLedgerPath(req),
+		FindingsExport:        req.Config.FindingsExport,
+		Branch:                runBranch(req.GitSvc),
 		TaskModel:             resolveSpec(o.TaskModel, req.Config.TaskModel),
 		ReviewModel:           resolveReviewSpec(o, req.Config),
 		AppConfig:             req.Config,
+		RecordDir:             o.Record,
+		ReplayDir:             replayDir(o),
 	}, log, holder)
 	if req.GitSvc != nil {
 		r.SetGitChecker(req.GitSvc)
@@ -1028,6 +1316,38 @@ func createRunner(req executePlanRequest, o opts, log processor.Logger, holder *
 	return r
 }
 
+// findingsLedgerPath returns the findings ledger of the run, kept in the main repository's .ralphex/findings
+// like the progress log, so a worktree run doesn't leave it in the worktree. empty without a git service.
+func findingsLedgerPath(req executePlanRequest) string {
+	if req.GitSvc == nil {
+		return ""
+	}
+	root, planFile := req.GitSvc.Root(), req.PlanFile
+	if req.MainGitSvc != nil {
+		root = req.MainGitSvc.Root()
+	}
+	if req.MainPlanFile != "" {
+		planFile = req.MainPlanFile
+	}
+	var branch string
+	if planFile == "" {
+		branch = cmp.Or(req.BranchOverride, getCurrentBranch(req.GitSvc))
+	}
+	return findings.Path(root, planFile, branch)
+}
+
+// runBranch returns the branch checked out for the run, empty without a git service or on a detached HEAD.
+func runBranch(gitSvc *git.Service) string {
+	if gitSvc == nil {
+		return ""
+	}
+	branch, err := gitSvc.CurrentBranch()
+	if err != nil {
+		return ""
+	}
+	return branch
+}
+
 func printStartupInfo(info startupInfo, colors *progress.Colors) {
 	if info.Mode == processor.ModePlan {
 		colors.Info().Printf("starting interactive plan creation\n")
@@ -1048,6 +1368,12 @@ func printStartupInfo(info startupInfo, colors *progress.Colors) {
 	}
 	colors.Info().Printf("starting ralphex loop (max %d iterations)%s\n", info.MaxIterations, modeStr)
 	displayMeta(colors, 0, info.PlanFile, info.Branch, info.ProgressPath)
+	if info.Mode == processor.ModeFull && len(info.Pipeline) > 0 {
+		colors.Info().Printf("pipeline: %s\n", strings.Join(info.Pipeline, " → "))
+	}
+	if len(info.PlanSettings) > 0 {
+		colors.Info().Printf("plan settings: %s\n", strings.Join(info.PlanSettings, ", "))
+	}
 	printExecutorInfo(info, colors)
 	if info.PreserveAnthropicAPIKey {
 		colors.Warn().Printf("auth: ANTHROPIC_API_KEY passthrough enabled\n")
@@ -1250,8 +1576,20 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest, selector *
 		req.Colors.Warn().Printf("codex does not support 'max' reasoning effort; ignoring (valid: low, medium, high, xhigh)\n")
 	}
 
-	// create input collector
-	collector := input.NewTerminalCollector(o.NoColor)
+	// create input collector; with --serve questions and drafts are answered in the terminal or the dashboard
+	var collector processor.InputCollector = input.NewTerminalCollector(o.NoColor)
+	var runnerLog processor.Logger = baseLog
+	stopDashboard := func() {}
+	if o.Serve {
+		webInput := web.NewInputCollector(input.NewTerminalCollector(o.NoColor))
+		dashLog, stop, dashErr := startPlanDashboard(ctx, o, req, branch, baseLog, holder, webInput)
+		if dashErr != nil {
+			planCreationErr = dashErr
+			return dashErr
+		}
+		defer stop()
+		collector, runnerLog, stopDashboard = webInput, dashLog, stop
+	}
 
 	// record start time for finding the created plan
 	startTime := time.Now()
@@ -1267,11 +1605,15 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest, selector *
 		DefaultBranch:    req.BaseRef,
 		TaskModel:        resolvePlanSpec(o, req.Config),
 		AppConfig:        req.Config,
-	}, baseLog, holder)
+		RecordDir:        o.Record,
+		ReplayDir:        replayDir(o),
+	}, runnerLog, holder)
 	r.SetInputCollector(collector)
 
 	// run the plan creation loop
-	if runErr := r.Run(ctx); runErr != nil {
+	runErr := r.Run(ctx)
+	baseLog.SetUsage(r.Usage().Total)
+	if runErr != nil {
 		wrapped := fmt.Errorf("plan creation: %w", runErr)
 		planCreationErr = wrapped
 		return wrapped
@@ -1305,6 +1647,7 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest, selector *
 	}
 
 	// continue with plan implementation
+	stopDashboard()
 	req.Colors.Info().Printf("\ncontinuing with plan implementation...\n")
 
 	// worktree mode: create worktree and run from there
@@ -1340,6 +1683,43 @@ func runPlanMode(ctx context.Context, o opts, req executePlanRequest, selector *
 	})
 }
 
+// startPlanDashboard starts the web dashboard of plan creation, answering questions and drafts through in.
+// the returned stop shuts it down and waits for the port to be released, so the dashboard of the
+// following plan execution can take it over; it is safe to call more than once.
+func startPlanDashboard(ctx context.Context, o opts, req executePlanRequest, branch string, baseLog *progress.Logger,
+	holder *status.PhaseHolder, in *web.InputCollector) (processor.Logger, func(), error) {
+	dashCtx, cancel := context.WithCancel(ctx)
+	params := runHeaderParams(o, req.Config, processor.ModePlan)
+	dashboard := web.NewDashboard(web.DashboardConfig{
+		BaseLog:         baseLog,
+		Port:            o.Port,
+		Host:            o.Host,
+		Branch:          branch,
+		RunParams:       web.FormatRunParams(params.Executor, params.PlanModel, params.TaskModel, params.ReviewModel),
+		WatchDirs:       o.Watch,
+		ConfigWatchDirs: req.Config.WatchDirs,
+		Colors:          req.Colors,
+		Token:           req.Config.WebToken,
+		User:            req.Config.WebUser,
+		Password:        req.Config.WebPassword,
+		TLS:             webTLS(req.Config),
+		Input:           in,
+	}, holder)
+	dashLog, err := dashboard.Start(dashCtx)
+	if err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("start dashboard: %w", err)
+	}
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			cancel()
+			<-dashboard.Done()
+		})
+	}
+	return dashLog, stop, nil
+}
+
 // runReset runs the interactive config reset flow.
 func runReset(configDir string, stdin io.Reader, stdout io.Writer) error {
 	_, err := config.Reset(configDir, stdin, stdout)
@@ -1545,6 +1925,9 @@ func applyCLIOverrides(o opts, cfg *config.Config) error {
 	if o.Worktree {
 		cfg.WorktreeEnabled = true
 	}
+	if o.Validate {
+		cfg.ValidationEnabled = true
+	}
 	if o.Wait > 0 || (o.Wait == 0 && o.waitSet) {
 		cfg.WaitOnLimit = o.Wait
 		cfg.WaitOnLimitSet = true
@@ -1565,12 +1948,81 @@ func applyCLIOverrides(o opts, cfg *config.Config) error {
 		cfg.ClaudeArgsSet = true
 	}
 	if o.externalReviewToolSet {
-		cfg.ExternalReviewTool = o.ExternalReviewTool
+		tools, err := config.ParseExternalReviewTools(o.ExternalReviewTool)
+		if err != nil {
+			return fmt.Errorf("--external-review-tool: %w", err)
+		}
+		cfg.ExternalReviewTool = strings.Join(tools, ",")
 	}
 	if o.customReviewScriptSet {
 		cfg.CustomReviewScript = o.CustomReviewScript
 	}
-	return applyCodexOverrides(o, cfg, os.Stderr)
+	if o.maxRunCostSet {
+		cfg.MaxRunCost = o.MaxRunCost
+	}
+	if o.maxRunTokensSet {
+		cfg.MaxRunTokens = o.MaxRunTokens
+	}
+	if o.maxPhaseCostSet {
+		cfg.MaxPhaseCost = o.MaxPhaseCost
+	}
+	if o.BudgetAction != "" {
+		cfg.BudgetAction = o.BudgetAction
+	}
+	if o.WebToken != "" {
+		cfg.WebToken = o.WebToken
+	}
+	if o.WebUser != "" {
+		cfg.WebUser = o.WebUser
+	}
+	if o.WebPassword != "" {
+		cfg.WebPassword = o.WebPassword
+	}
+	if o.TLSCert != "" {
+		cfg.WebTLSCert = o.TLSCert
+	}
+	if o.TLSKey != "" {
+		cfg.WebTLSKey = o.TLSKey
+	}
+	if o.TLSSelfSigned {
+		cfg.WebTLSSelfSigned = true
+	}
+	if cfg.WebUser != "" && cfg.WebPassword == "" {
+		return errors.New("web_user requires web_password (--web-password or RALPHEX_WEB_PASSWORD)")
+	}
+	if err := webTLS(cfg).Validate(); err != nil {
+		return fmt.Errorf("web dashboard TLS: %w", err)
+	}
+	if len(o.Repos) > 0 {
+		repos := make([]string, 0, len(o.Repos))
+		for _, r := range o.Repos {
+			name, path, err := config.ParseDaemonRepo(r)
+			if err != nil {
+				return fmt.Errorf("--repo: %w", err)
+			}
+			repos = append(repos, name+"="+path)
+		}
+		cfg.DaemonRepos = repos
+	}
+	if o.Pipeline != "" {
+		stages, err := config.ParsePipeline(o.Pipeline)
+		if err != nil {
+			return fmt.Errorf("--pipeline: %w", err)
+		}
+		cfg.Pipeline = stages
+	}
+	if err := applyCodexOverrides(o, cfg, os.Stderr); err != nil {
+		return err
+	}
+	if _, err := cfg.ExternalReviewTools(); err != nil {
+		return fmt.Errorf("external review: %w", err)
+	}
+	return nil
+}
+
+// webTLS returns the TLS settings of the web dashboard from cfg.
+func webTLS(cfg *config.Config) web.TLSConfig {
+	return web.TLSConfig{CertFile: cfg.WebTLSCert, KeyFile: cfg.WebTLSKey, SelfSigned: cfg.WebTLSSelfSigned}
 }
 
 // applyCodexOverrides applies --codex / --pass-claude-md CLI flags and resolves config-file precedence.
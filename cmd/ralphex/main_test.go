@@ -4,10 +4,14 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"net"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -18,6 +22,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/executor"
 	"github.com/umputun/ralphex/pkg/git"
 	gitmocks "github.com/umputun/ralphex/pkg/git/mocks"
 	"github.com/umputun/ralphex/pkg/notify"
@@ -25,6 +30,7 @@ import (
 	"github.com/umputun/ralphex/pkg/processor"
 	"github.com/umputun/ralphex/pkg/progress"
 	"github.com/umputun/ralphex/pkg/status"
+	"github.com/umputun/ralphex/pkg/web"
 )
 
 // captureStdout runs fn while redirecting os.Stdout (and the fatih/color Output
@@ -201,6 +207,7 @@ func TestIsWatchOnlyMode(t *testing.T) {
 		{name: "no_serve_with_watch", opts: opts{Watch: []string{"/tmp"}}, configWatchDirs: nil, expected: false},
 		{name: "serve_with_plan_file", opts: opts{Serve: true, Watch: []string{"/tmp"}, PlanFile: "plan.md"}, configWatchDirs: nil, expected: false},
 		{name: "serve_with_plan_description", opts: opts{Serve: true, Watch: []string{"/tmp"}, PlanDescription: "add feature"}, configWatchDirs: nil, expected: false},
+		{name: "serve_daemon_with_watch", opts: opts{Serve: true, Daemon: true, Watch: []string{"/tmp"}}, configWatchDirs: nil, expected: false},
 	}
 
 	for _, tc := range tests {
@@ -515,6 +522,33 @@ func TestCheckCodexDep(t *testing.T) {
 	})
 }
 
+func TestCheckProviderDep(t *testing.T) {
+	writeProvider := func(t *testing.T, command string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "provider.json")
+		content := `{"command": "` + command + `", "text": [{"path": "text"}]}`
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	t.Run("valid_provider", func(t *testing.T) {
+		require.NoError(t, checkProviderDep(&config.Config{AgentProvider: writeProvider(t, "sh")}))
+	})
+
+	t.Run("command_not_found", func(t *testing.T) {
+		path := writeProvider(t, "nonexistent-agent-12345")
+		err := checkProviderDep(&config.Config{AgentProvider: path})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "nonexistent-agent-12345 not found in PATH")
+		assert.Contains(t, err.Error(), path)
+	})
+
+	t.Run("invalid_provider_file", func(t *testing.T) {
+		err := checkProviderDep(&config.Config{AgentProvider: filepath.Join(t.TempDir(), "missing.json")})
+		require.ErrorContains(t, err, "agent_provider: read provider file")
+	})
+}
+
 func TestCreateRunner(t *testing.T) {
 	t.Run("creates_runner_without_panic", func(t *testing.T) {
 		tmpDir := t.TempDir()
@@ -721,6 +755,120 @@ func TestPreserveAnthropicAPIKeyFlag(t *testing.T) {
 	})
 }
 
+func TestValidateFlag(t *testing.T) {
+	t.Run("flag enables validation gate", func(t *testing.T) {
+		cfg := &config.Config{}
+		o := parseTestOpts(t, "--validate")
+
+		require.NoError(t, applyCLIOverrides(o, cfg))
+
+		assert.True(t, cfg.ValidationEnabled)
+	})
+
+	t.Run("absent flag preserves config", func(t *testing.T) {
+		cfg := &config.Config{ValidationEnabled: true}
+		o := parseTestOpts(t)
+
+		require.NoError(t, applyCLIOverrides(o, cfg))
+
+		assert.True(t, cfg.ValidationEnabled)
+	})
+}
+
+func TestBudgetFlags(t *testing.T) {
+	t.Run("flags override config", func(t *testing.T) {
+		cfg := &config.Config{MaxRunCost: 20, MaxRunTokens: 1000, BudgetAction: config.BudgetActionStop}
+		o := parseTestOpts(t, "--max-run-cost", "5.5", "--max-run-tokens", "2000000", "--max-phase-cost", "2",
+			"--budget-action", "downgrade")
+
+		require.NoError(t, applyCLIOverrides(o, cfg))
+
+		assert.InDelta(t, 5.5, cfg.MaxRunCost, 1e-9)
+		assert.Equal(t, int64(2000000), cfg.MaxRunTokens)
+		assert.InDelta(t, 2.0, cfg.MaxPhaseCost, 1e-9)
+		assert.Equal(t, config.BudgetActionDowngrade, cfg.BudgetAction)
+	})
+
+	t.Run("explicit zero disables config limit", func(t *testing.T) {
+		cfg := &config.Config{MaxRunCost: 20, MaxPhaseCost: 5}
+		o := parseTestOpts(t, "--max-run-cost", "0")
+
+		require.NoError(t, applyCLIOverrides(o, cfg))
+
+		assert.Zero(t, cfg.MaxRunCost)
+		assert.InDelta(t, 5.0, cfg.MaxPhaseCost, 1e-9)
+	})
+
+	t.Run("invalid budget action rejected", func(t *testing.T) {
+		var o opts
+		_, err := flags.NewParser(&o, flags.None).ParseArgs([]string{"--budget-action", "pause"})
+		require.Error(t, err)
+	})
+}
+
+func TestPipelineFlag(t *testing.T) {
+	t.Run("flag overrides config", func(t *testing.T) {
+		cfg := &config.Config{Pipeline: []string{"task"}}
+		o := parseTestOpts(t, "--pipeline", "task, security_audit,finalize")
+
+		require.NoError(t, applyCLIOverrides(o, cfg))
+
+		assert.Equal(t, []string{"task", "security_audit", "finalize"}, cfg.Pipeline)
+	})
+
+	t.Run("config kept without flag", func(t *testing.T) {
+		cfg := &config.Config{Pipeline: []string{"task", "finalize"}}
+		o := parseTestOpts(t)
+
+		require.NoError(t, applyCLIOverrides(o, cfg))
+
+		assert.Equal(t, []string{"task", "finalize"}, cfg.Pipeline)
+	})
+
+	t.Run("invalid stage rejected", func(t *testing.T) {
+		o := parseTestOpts(t, "--pipeline", "task,../x")
+		err := applyCLIOverrides(o, &config.Config{})
+		require.ErrorContains(t, err, "--pipeline")
+	})
+}
+
+func TestWebAccessFlags(t *testing.T) {
+	t.Run("flags override config", func(t *testing.T) {
+		cfg := &config.Config{WebUser: "cfg", WebPassword: "cfg", WebTLSCert: "/cfg/cert.pem", WebTLSKey: "/cfg/key.pem"}
+		o := parseTestOpts(t, "--web-user", "admin", "--web-password", "pass", "--tls-cert", "/srv/cert.pem",
+			"--tls-key", "/srv/key.pem")
+
+		require.NoError(t, applyCLIOverrides(o, cfg))
+
+		assert.Equal(t, "admin", cfg.WebUser)
+		assert.Equal(t, "pass", cfg.WebPassword)
+		assert.Equal(t, web.TLSConfig{CertFile: "/srv/cert.pem", KeyFile: "/srv/key.pem"}, webTLS(cfg))
+	})
+
+	t.Run("env and self-signed", func(t *testing.T) {
+		t.Setenv("RALPHEX_WEB_PASSWORD", "env")
+		cfg := &config.Config{WebUser: "admin"}
+		o := parseTestOpts(t, "--tls-self-signed")
+
+		require.NoError(t, applyCLIOverrides(o, cfg))
+
+		assert.Equal(t, "env", cfg.WebPassword)
+		assert.True(t, webTLS(cfg).SelfSigned)
+	})
+
+	t.Run("user without password", func(t *testing.T) {
+		err := applyCLIOverrides(parseTestOpts(t, "--web-user", "admin"), &config.Config{})
+		require.ErrorContains(t, err, "web_password")
+	})
+
+	t.Run("incomplete TLS", func(t *testing.T) {
+		err := applyCLIOverrides(parseTestOpts(t, "--tls-cert", "/srv/cert.pem"), &config.Config{})
+		require.ErrorContains(t, err, "TLS")
+		err = applyCLIOverrides(parseTestOpts(t, "--tls-self-signed"), &config.Config{WebTLSCert: "c", WebTLSKey: "k"})
+		require.ErrorContains(t, err, "self-signed")
+	})
+}
+
 func TestProviderOverrideFlags(t *testing.T) {
 	t.Run("claude_command_overrides_config", func(t *testing.T) {
 		cfg := &config.Config{ClaudeCommand: "configured-claude"}
@@ -759,6 +907,26 @@ func TestProviderOverrideFlags(t *testing.T) {
 		assert.Equal(t, "custom", cfg.ExternalReviewTool)
 	})
 
+	t.Run("external_review_tool_list", func(t *testing.T) {
+		cfg := &config.Config{ExternalReviewTool: "codex", CustomReviewScripts: []string{"gemini=/s/gemini.sh"}}
+		o := parseTestOpts(t, "--external-review-tool", "codex, custom:gemini")
+
+		require.NoError(t, applyCLIOverrides(o, cfg))
+
+		assert.Equal(t, "codex,custom:gemini", cfg.ExternalReviewTool)
+	})
+
+	t.Run("external_review_tool_invalid", func(t *testing.T) {
+		err := applyCLIOverrides(parseTestOpts(t, "--external-review-tool", "codex,claude"), &config.Config{})
+		require.ErrorContains(t, err, `--external-review-tool: invalid external review tool "claude"`)
+
+		err = applyCLIOverrides(parseTestOpts(t, "--external-review-tool", "custom:gemini"), &config.Config{})
+		require.ErrorContains(t, err, `external review tool "custom:gemini" has no script in custom_review_scripts`)
+
+		err = applyCLIOverrides(parseTestOpts(t, "--external-review-tool", "http"), &config.Config{})
+		require.ErrorContains(t, err, "external review tool http needs http_review_base_url and http_review_model")
+	})
+
 	t.Run("custom_review_script_overrides_config", func(t *testing.T) {
 		cfg := &config.Config{CustomReviewScript: "/configured/review.sh"}
 		o := parseTestOpts(t, "--custom-review-script", "/tmp/review.sh")
@@ -985,6 +1153,40 @@ func TestValidateFlags(t *testing.T) {
 		{name: "negative_idle_timeout_is_invalid", opts: opts{IdleTimeout: -5 * time.Minute}, wantErr: true, errMsg: "non-negative"},
 		{name: "positive_idle_timeout_is_valid", opts: opts{IdleTimeout: 5 * time.Minute}, wantErr: false},
 		{name: "zero_idle_timeout_is_valid", opts: opts{IdleTimeout: 0}, wantErr: false},
+		{name: "negative_max_run_cost_is_invalid", opts: opts{MaxRunCost: -1}, wantErr: true, errMsg: "non-negative"},
+		{name: "negative_max_run_tokens_is_invalid", opts: opts{MaxRunTokens: -1}, wantErr: true, errMsg: "non-negative"},
+		{name: "negative_max_phase_cost_is_invalid", opts: opts{MaxPhaseCost: -0.5}, wantErr: true, errMsg: "non-negative"},
+		{name: "review_report_only_is_valid", opts: opts{Review: true, ReportOnly: true}, wantErr: false},
+		{name: "report_only_without_review_is_invalid", opts: opts{ReportOnly: true}, wantErr: true, errMsg: "--report-only requires --review"},
+		{name: "report_only_with_external_only_is_invalid", opts: opts{Review: true, ReportOnly: true, ExternalOnly: true}, wantErr: true, errMsg: "--report-only requires --review"},
+		{name: "export_flags_with_export_command_are_valid", opts: opts{exportCmd: true, FindingsFormat: "junit", FindingsOutput: "r.xml"}, wantErr: false},
+		{name: "format_without_export_command_is_invalid", opts: opts{FindingsFormat: "junit"}, wantErr: true, errMsg: "only apply to the export-findings command"},
+		{name: "output_without_export_command_is_invalid", opts: opts{FindingsOutput: "r.xml"}, wantErr: true, errMsg: "only apply to the export-findings command"},
+		{name: "prompts_flags_with_prompts_command_are_valid", opts: opts{promptsCmd: true, Phase: "task", WriteDir: "out"}, wantErr: false},
+		{name: "phase_without_prompts_command_is_invalid", opts: opts{Phase: "task"}, wantErr: true, errMsg: "only apply to the prompts render command"},
+		{name: "write_dir_without_prompts_command_is_invalid", opts: opts{WriteDir: "out"}, wantErr: true, errMsg: "only apply to the prompts render command"},
+		{name: "pipeline_alone_is_valid", opts: opts{Pipeline: "task,finalize"}, wantErr: false},
+		{name: "pipeline_with_review_conflicts", opts: opts{Pipeline: "task", Review: true}, wantErr: true, errMsg: "--pipeline conflicts"},
+		{name: "pipeline_with_tasks_only_conflicts", opts: opts{Pipeline: "task", TasksOnly: true}, wantErr: true, errMsg: "--pipeline conflicts"},
+		{name: "queue_alone_is_valid", opts: opts{Queue: "docs/plans", Parallel: 2}, wantErr: false},
+		{name: "queue_with_tasks_only_is_valid", opts: opts{Queue: "docs/plans", TasksOnly: true}, wantErr: false},
+		{name: "queue_with_plan_file_conflicts", opts: opts{Queue: "docs/plans", PlanFile: "docs/plans/a.md"}, wantErr: true, errMsg: "--queue conflicts"},
+		{name: "queue_command_with_review_conflicts", opts: opts{queueCmd: true, Review: true}, wantErr: true, errMsg: "--queue conflicts"},
+		{name: "queue_with_serve_conflicts", opts: opts{Queue: "docs/plans", Serve: true}, wantErr: true, errMsg: "--queue conflicts"},
+		{name: "negative_parallel_is_invalid", opts: opts{Parallel: -1}, wantErr: true, errMsg: "--parallel"},
+		{name: "queue_worktree_with_plan_file_is_valid", opts: opts{QueueWorktree: "/tmp/wt", PlanFile: "a.md"}, wantErr: false},
+		{name: "queue_worktree_without_plan_file_is_invalid", opts: opts{QueueWorktree: "/tmp/wt"}, wantErr: true, errMsg: "requires a plan file"},
+		{name: "daemon_with_serve_is_valid", opts: opts{Serve: true, Daemon: true, Parallel: 2, TasksOnly: true}, wantErr: false},
+		{name: "daemon_without_serve_is_invalid", opts: opts{Daemon: true}, wantErr: true, errMsg: "--daemon requires --serve"},
+		{name: "daemon_with_plan_file_conflicts", opts: opts{Serve: true, Daemon: true, PlanFile: "a.md"}, wantErr: true, errMsg: "--daemon conflicts"},
+		{name: "daemon_with_review_conflicts", opts: opts{Serve: true, Daemon: true, Review: true}, wantErr: true, errMsg: "--daemon conflicts"},
+		{name: "record_is_valid", opts: opts{Record: "sessions"}, wantErr: false},
+		{name: "executor_replay_is_valid", opts: opts{Executor: "replay:sessions", Codex: true}, wantErr: false},
+		{name: "executor_unknown_is_invalid", opts: opts{Executor: "claude"}, wantErr: true, errMsg: "--executor must be replay:<dir>"},
+		{name: "executor_replay_without_dir_is_invalid", opts: opts{Executor: "replay:"}, wantErr: true, errMsg: "--executor must be replay:<dir>"},
+		{name: "record_with_replay_conflicts", opts: opts{Record: "a", Executor: "replay:b"}, wantErr: true, errMsg: "--record conflicts"},
+		{name: "record_with_queue_conflicts", opts: opts{Record: "a", Queue: "docs/plans"}, wantErr: true, errMsg: "conflict with --queue"},
+		{name: "replay_with_daemon_conflicts", opts: opts{Executor: "replay:a", Serve: true, Daemon: true}, wantErr: true, errMsg: "conflict with --queue"},
 		{name: "codex_alone_is_valid", opts: opts{Codex: true}, wantErr: false},
 		{name: "codex_with_pass_claude_md_is_valid", opts: opts{Codex: true, PassClaudeMd: true}, wantErr: false},
 		// the --codex / --external-only / --codex-only / --external-review-tool / --pass-claude-md
@@ -1657,6 +1859,17 @@ func TestPrintStartupInfo(t *testing.T) {
 		assert.Contains(t, out, "review reasoning effort: (inherits ~/.codex/config.toml)")
 		assert.NotContains(t, out, "review model:", "review model line omitted when model matches task")
 	})
+
+	t.Run("shows plan settings", func(t *testing.T) {
+		info := startupInfo{PlanFile: "/path/to/plan.md", Branch: "infra-vpc", Mode: processor.ModeFull, MaxIterations: 80,
+			ProgressPath: "progress.txt", PlanSettings: []string{"branch=infra-vpc", "max_iterations=80"}}
+		out := captureStdout(t, func() { printStartupInfo(info, colors) })
+		assert.Contains(t, out, "plan settings: branch=infra-vpc, max_iterations=80")
+
+		info.PlanSettings = nil
+		out = captureStdout(t, func() { printStartupInfo(info, colors) })
+		assert.NotContains(t, out, "plan settings")
+	})
 }
 
 func TestToRelPath(t *testing.T) {
@@ -2778,6 +2991,57 @@ func TestRunWithWorktree_RecordsWorktreePlanInHeader(t *testing.T) {
 	assert.Contains(t, string(content), "Plan: "+planPath+"\n", "the main-checkout path stays recorded as the fallback")
 }
 
+func TestRun_ReplayInWorktree(t *testing.T) {
+	dir := setupTestRepo(t)
+	origDir, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(origDir) })
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "docs", "plans"), 0o750))
+	planPath := filepath.Join(dir, "docs", "plans", "wt-replay.md")
+	require.NoError(t, os.WriteFile(planPath, []byte("# WT Replay\n\n### Task 1: first\n- [ ] task 1\n"), 0o600))
+	runGit(t, dir, "add", "docs/plans/wt-replay.md")
+	runGit(t, dir, "commit", "-m", "add wt replay plan")
+
+	// the recording sits outside the worktree and is passed relative to the main checkout
+	recDir := filepath.Join(t.TempDir(), "rec")
+	require.NoError(t, os.MkdirAll(recDir, 0o750))
+	rec := `{"seq": 1, "tool": "claude", "phase": "task", "prompt": "", "result": {"output": "replayed task output"}}`
+	require.NoError(t, os.WriteFile(filepath.Join(recDir, "0001-claude.json"), []byte(rec), 0o600))
+	relRecDir, err := filepath.Rel(dir, recDir)
+	require.NoError(t, err)
+
+	o := opts{Worktree: true, PlanFile: planPath, Executor: "replay:" + relRecDir, MaxIterations: 1, NoColor: true,
+		ConfigDir: t.TempDir()}
+	err = run(t.Context(), o)
+	require.Error(t, err, "the single recorded session doesn't finish the plan")
+	assert.NotContains(t, err.Error(), "replay directory", "the recording is found after the worktree chdir")
+
+	content, readErr := os.ReadFile(filepath.Join(dir, ".ralphex", "progress", "progress-wt-replay.txt")) //nolint:gosec // test
+	require.NoError(t, readErr)
+	assert.Contains(t, string(content), "replaying claude session 1 from "+recDir)
+	assert.Contains(t, string(content), "replayed task output")
+}
+
+func TestResolveRecordDirs(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	o := opts{Record: "rec"}
+	require.NoError(t, resolveRecordDirs(&o))
+	assert.Equal(t, filepath.Join(wd, "rec"), o.Record)
+
+	o = opts{Executor: "replay: rec"}
+	require.NoError(t, resolveRecordDirs(&o))
+	assert.Equal(t, "replay:"+filepath.Join(wd, "rec"), o.Executor)
+
+	o = opts{}
+	require.NoError(t, resolveRecordDirs(&o))
+	assert.Empty(t, o.Record)
+	assert.Empty(t, o.Executor)
+}
+
 func TestWorktreePlanFile(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -2920,8 +3184,8 @@ func TestSendNotification(t *testing.T) {
 	t.Run("nil_service_is_noop", func(t *testing.T) {
 		req := executePlanRequest{Mode: processor.ModeFull, PlanFile: "test.md"}
 		// should not panic with nil NotifySvc
-		sendNotification(req, "main", "5s", git.DiffStats{}, nil)
-		sendNotification(req, "main", "5s", git.DiffStats{}, errors.New("test error"))
+		sendNotification(req, "main", "5s", git.DiffStats{}, executor.Usage{}, nil)
+		sendNotification(req, "main", "5s", git.DiffStats{}, executor.Usage{}, errors.New("test error"))
 	})
 }
 
@@ -2929,7 +3193,7 @@ func TestBuildNotifyResult(t *testing.T) {
 	t.Run("success_result", func(t *testing.T) {
 		req := executePlanRequest{Mode: processor.ModeFull, PlanFile: "plan.md"}
 		stats := git.DiffStats{Files: 3, Additions: 100, Deletions: 20}
-		result := buildNotifyResult(req, "feature-branch", "1m30s", stats, nil)
+		result := buildNotifyResult(req, "feature-branch", "1m30s", stats, executor.Usage{}, nil)
 
 		assert.Equal(t, "success", result.Status)
 		assert.Equal(t, "full", result.Mode)
@@ -2944,7 +3208,8 @@ func TestBuildNotifyResult(t *testing.T) {
 
 	t.Run("failure_result", func(t *testing.T) {
 		req := executePlanRequest{Mode: processor.ModeReview, PlanFile: "review.md"}
-		result := buildNotifyResult(req, "main", "45s", git.DiffStats{}, errors.New("runner failed"))
+		usage := executor.Usage{InputTokens: 1000, OutputTokens: 200, CacheReadTokens: 5000, CostUSD: 0.42}
+		result := buildNotifyResult(req, "main", "45s", git.DiffStats{}, usage, errors.New("runner failed"))
 
 		assert.Equal(t, "failure", result.Status)
 		assert.Equal(t, "review", result.Mode)
@@ -2952,6 +3217,10 @@ func TestBuildNotifyResult(t *testing.T) {
 		assert.Equal(t, "main", result.Branch)
 		assert.Equal(t, "45s", result.Duration)
 		assert.Equal(t, "runner failed", result.Error)
+		assert.Equal(t, int64(1000), result.InputTokens)
+		assert.Equal(t, int64(200), result.OutputTokens)
+		assert.Equal(t, int64(5000), result.CacheReadTokens)
+		assert.InDelta(t, 0.42, result.CostUSD, 1e-9)
 		assert.Zero(t, result.Files)
 		assert.Zero(t, result.Additions)
 		assert.Zero(t, result.Deletions)
@@ -2972,7 +3241,7 @@ func TestDisplayStats(t *testing.T) {
 
 		req := executePlanRequest{PlanFile: "docs/plans/feature.md", Colors: colors}
 		stats := git.DiffStats{Files: 5, Additions: 200, Deletions: 50}
-		displayStats(req, baseLog, stats, "2m15s", "feature-branch", false, nil)
+		displayStats(req, baseLog, stats, executor.Usage{}, "2m15s", "feature-branch", false, nil)
 	})
 
 	t.Run("without_diff_stats", func(t *testing.T) {
@@ -2987,7 +3256,7 @@ func TestDisplayStats(t *testing.T) {
 		defer func() { _ = baseLog.Close() }()
 
 		req := executePlanRequest{Colors: colors}
-		displayStats(req, baseLog, git.DiffStats{}, "30s", "main", false, nil)
+		displayStats(req, baseLog, git.DiffStats{}, executor.Usage{}, "30s", "main", false, nil)
 	})
 
 	t.Run("with_main_plan_file", func(t *testing.T) {
@@ -3006,7 +3275,7 @@ func TestDisplayStats(t *testing.T) {
 			MainPlanFile: "docs/plans/feature.md",
 			Colors:       colors,
 		}
-		displayStats(req, baseLog, git.DiffStats{Files: 1, Additions: 10, Deletions: 5}, "10s", "feature-wt", false, nil)
+		displayStats(req, baseLog, git.DiffStats{Files: 1, Additions: 10, Deletions: 5}, executor.Usage{}, "10s", "feature-wt", false, nil)
 	})
 
 	// plan-path display must reflect the actual location of the plan file:
@@ -3082,7 +3351,7 @@ func TestDisplayStats(t *testing.T) {
 				req.Colors = colors
 
 				output := captureStdout(t, func() {
-					displayStats(req, baseLog, git.DiffStats{}, "1s", "main", tc.planMoved, tc.planMoveErr)
+					displayStats(req, baseLog, git.DiffStats{}, executor.Usage{}, "1s", "main", tc.planMoved, tc.planMoveErr)
 				})
 				assert.Contains(t, output, "  plan: "+tc.wantPath+"\n")
 				if tc.wantNote {
@@ -3149,7 +3418,7 @@ func TestKeepDashboardAlive(t *testing.T) {
 
 	t.Run("blocks_until_context_canceled", func(t *testing.T) {
 		colors := testColors()
-		req := executePlanRequest{Colors: colors}
+		req := executePlanRequest{Colors: colors, Config: &config.Config{}}
 		closeCalled := false
 		closeLog := func() { closeCalled = true }
 
@@ -3161,6 +3430,41 @@ func TestKeepDashboardAlive(t *testing.T) {
 	})
 }
 
+func TestStartPlanDashboard(t *testing.T) {
+	t.Chdir(t.TempDir())
+	colors := testColors()
+	holder := &status.PhaseHolder{}
+	baseLog, err := progress.NewLogger(progress.Config{PlanDescription: "add caching", Mode: "plan", Branch: "master", NoColor: true},
+		colors, holder)
+	require.NoError(t, err)
+	defer baseLog.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	require.NoError(t, ln.Close())
+
+	o := opts{Serve: true, Port: port, Host: "127.0.0.1"}
+	req := executePlanRequest{Config: &config.Config{}, Colors: colors}
+	dashLog, stop, err := startPlanDashboard(t.Context(), o, req, "master", baseLog, holder, web.NewInputCollector(nil))
+	require.NoError(t, err)
+	assert.Equal(t, baseLog.Path(), dashLog.Path())
+
+	resp, err := http.Post(fmt.Sprintf("http://127.0.0.1:%d/api/input/1/answer", port), "application/json",
+		strings.NewReader(`{"answer":"a"}`))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusConflict, resp.StatusCode, "input endpoints are served, nothing is pending yet")
+
+	stop()
+	stop() // idempotent
+
+	// the execution dashboard can take over the port
+	ln, err = net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	require.NoError(t, err)
+	require.NoError(t, ln.Close())
+}
+
 func TestMakePauseHandler_EnterResumes(t *testing.T) {
 	stdin := bytes.NewReader([]byte("\n"))
 	var stdout bytes.Buffer
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/plan"
+)
+
+// applyPlanSettings merges the YAML frontmatter settings of the selected plan file into the run.
+// precedence: explicit CLI flag > plan frontmatter > config files. plan values go to the config
+// and the request, so every later consumer sees them. returns the applied settings as key=value
+// pairs for the startup banner.
+func applyPlanSettings(o opts, req *executePlanRequest, warnW io.Writer) ([]string, error) {
+	if req.PlanFile == "" {
+		return nil, nil
+	}
+	s, err := plan.ReadSettings(req.PlanFile)
+	if err != nil {
+		return nil, fmt.Errorf("plan settings: %w", err)
+	}
+
+	var applied []string
+	cfg := req.Config
+	if s.Branch != "" && o.Branch == "" {
+		req.BranchOverride = s.Branch
+		applied = append(applied, "branch="+s.Branch)
+	}
+	if s.BaseRef != "" && o.BaseRef == "" {
+		req.BaseRef = s.BaseRef
+		applied = append(applied, "base_ref="+s.BaseRef)
+	}
+	if s.TaskModel != "" && o.TaskModel == "" {
+		cfg.TaskModel = s.TaskModel
+		applied = append(applied, "task_model="+s.TaskModel)
+	}
+	if s.ReviewModel != "" && o.ReviewModel == "" {
+		cfg.ReviewModel = s.ReviewModel
+		applied = append(applied, "review_model="+s.ReviewModel)
+	}
+	if s.MaxIterations > 0 && o.MaxIterations == 0 {
+		cfg.MaxIterations = s.MaxIterations
+		cfg.MaxIterationsSet = true
+		applied = append(applied, "max_iterations="+strconv.Itoa(s.MaxIterations))
+	}
+	if s.ExternalReviewTool != "" && !o.externalReviewToolSet {
+		tools, err := config.ParseExternalReviewTools(s.ExternalReviewTool)
+		if err != nil {
+			return nil, fmt.Errorf("plan settings: external_review_tool: %w", err)
+		}
+		tool := strings.Join(tools, ",")
+		if cfg.Executor == config.ExecutorCodex && tool != "none" {
+			fmt.Fprintf(warnW, "warning: plan external_review_tool=%q ignored because executor=codex\n", tool)
+		} else {
+			cfg.ExternalReviewTool = tool
+			req.ExternalReviewToolSet = true
+			applied = append(applied, "external_review_tool="+tool)
+		}
+	}
+	if s.Finalize != nil && !o.SkipFinalize {
+		cfg.FinalizeEnabled = *s.Finalize
+		applied = append(applied, "finalize="+strconv.FormatBool(*s.Finalize))
+	}
+	if s.Worktree != nil && !o.Worktree {
+		cfg.WorktreeEnabled = *s.Worktree
+		applied = append(applied, "worktree="+strconv.FormatBool(*s.Worktree))
+	}
+	return applied, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+)
+
+func TestApplyPlanSettings(t *testing.T) {
+	writePlan := func(t *testing.T, content string) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "plan.md")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+	const allSettings = "---\nbranch: infra-vpc\ntask_model: opus:high\nreview_model: sonnet\nmax_iterations: 80\n" +
+		"external_review_tool: custom\nfinalize: false\nworktree: true\nbase_ref: develop\n---\n# Plan\n"
+
+	t.Run("plan overrides config", func(t *testing.T) {
+		cfg := &config.Config{TaskModel: "haiku", MaxIterations: 30, MaxIterationsSet: true, ExternalReviewTool: "codex",
+			FinalizeEnabled: true}
+		req := executePlanRequest{PlanFile: writePlan(t, allSettings), Config: cfg, BaseRef: "master"}
+		applied, err := applyPlanSettings(opts{}, &req, &bytes.Buffer{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"branch=infra-vpc", "base_ref=develop", "task_model=opus:high", "review_model=sonnet",
+			"max_iterations=80", "external_review_tool=custom", "finalize=false", "worktree=true"}, applied)
+		assert.Equal(t, "infra-vpc", req.BranchOverride)
+		assert.Equal(t, "develop", req.BaseRef)
+		assert.Equal(t, "opus:high", cfg.TaskModel)
+		assert.Equal(t, "sonnet", cfg.ReviewModel)
+		assert.Equal(t, 80, resolveMaxIterations(0, cfg))
+		assert.Equal(t, "custom", cfg.ExternalReviewTool)
+		assert.True(t, req.ExternalReviewToolSet, "plan tool overrides codex_enabled = false like the cli flag")
+		assert.False(t, cfg.FinalizeEnabled)
+		assert.True(t, cfg.WorktreeEnabled)
+	})
+
+	t.Run("cli flags override plan", func(t *testing.T) {
+		cfg := &config.Config{ExternalReviewTool: "none", FinalizeEnabled: false, WorktreeEnabled: true}
+		req := executePlanRequest{PlanFile: writePlan(t, allSettings), Config: cfg, BaseRef: "main",
+			BranchOverride: "cli-branch"}
+		o := opts{Branch: "cli-branch", BaseRef: "main", TaskModel: "haiku", ReviewModel: "haiku", MaxIterations: 5,
+			ExternalReviewTool: "none", externalReviewToolSet: true, SkipFinalize: true, Worktree: true}
+		applied, err := applyPlanSettings(o, &req, &bytes.Buffer{})
+		require.NoError(t, err)
+		assert.Empty(t, applied)
+		assert.Equal(t, "cli-branch", req.BranchOverride)
+		assert.Equal(t, "main", req.BaseRef)
+		assert.Empty(t, cfg.TaskModel, "cli model is resolved from opts, config untouched")
+		assert.Equal(t, "none", cfg.ExternalReviewTool)
+		assert.False(t, req.ExternalReviewToolSet, "the cli flag marks it on its own")
+		assert.False(t, cfg.FinalizeEnabled)
+		assert.True(t, cfg.WorktreeEnabled)
+	})
+
+	t.Run("plan without frontmatter", func(t *testing.T) {
+		cfg := &config.Config{TaskModel: "haiku"}
+		req := executePlanRequest{PlanFile: writePlan(t, "# Plan\n### Task 1: a\n- [ ] x\n"), Config: cfg}
+		applied, err := applyPlanSettings(opts{}, &req, &bytes.Buffer{})
+		require.NoError(t, err)
+		assert.Empty(t, applied)
+		assert.Equal(t, "haiku", cfg.TaskModel)
+	})
+
+	t.Run("no plan file", func(t *testing.T) {
+		applied, err := applyPlanSettings(opts{}, &executePlanRequest{Config: &config.Config{}}, &bytes.Buffer{})
+		require.NoError(t, err)
+		assert.Empty(t, applied)
+	})
+
+	t.Run("external review tool skipped under codex", func(t *testing.T) {
+		cfg := &config.Config{Executor: config.ExecutorCodex, ExternalReviewTool: "none"}
+		req := executePlanRequest{PlanFile: writePlan(t, "---\nexternal_review_tool: codex\n---\n"), Config: cfg}
+		var warn bytes.Buffer
+		applied, err := applyPlanSettings(opts{}, &req, &warn)
+		require.NoError(t, err)
+		assert.Empty(t, applied)
+		assert.Equal(t, "none", cfg.ExternalReviewTool)
+		assert.False(t, req.ExternalReviewToolSet)
+		assert.Contains(t, warn.String(), `plan external_review_tool="codex" ignored because executor=codex`)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		req := executePlanRequest{PlanFile: writePlan(t, "---\nbrnch: x\n---\n"), Config: &config.Config{}}
+		_, err := applyPlanSettings(opts{}, &req, &bytes.Buffer{})
+		require.ErrorContains(t, err, `plan settings: unknown plan frontmatter key "brnch"`)
+
+		req = executePlanRequest{PlanFile: writePlan(t, "---\nexternal_review_tool: bogus\n---\n"), Config: &config.Config{}}
+		_, err = applyPlanSettings(opts{}, &req, &bytes.Buffer{})
+		require.ErrorContains(t, err, "plan settings: external_review_tool:")
+	})
+}
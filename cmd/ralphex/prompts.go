@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/processor"
+	"github.com/umputun/ralphex/pkg/progress"
+)
+
+// promptsCommand is the subcommand group of prompt tooling, "prompts render" prints the rendered prompts.
+const promptsCommand = "prompts"
+
+// applyPromptsCommand handles the "prompts render [plan]" subcommand and returns the remaining args.
+func applyPromptsCommand(o *opts, args []string) []string {
+	if len(args) < 2 || args[0] != promptsCommand || args[1] != "render" || fileExists(args[0]) {
+		return args
+	}
+	o.promptsCmd = true
+	return args[2:]
+}
+
+// renderPromptsRequest holds the inputs of prompts render resolved from the config and repository.
+type renderPromptsRequest struct {
+	Config   processor.Config
+	Phase    string // single phase to render, empty for all
+	WriteDir string // directory receiving <phase>.<syntax>.txt files, empty to print to w
+}
+
+// renderPromptsRequestFor resolves the prompt variables of prompts render like a run of the plan would.
+// outside a git repository the default branch falls back to the configured one.
+func renderPromptsRequestFor(o opts, cfg *config.Config, colors *progress.Colors) renderPromptsRequest {
+	var autoDetected string
+	if gitSvc, err := openGitService(colors, cfg.VcsCommand); err == nil {
+		autoDetected = gitSvc.GetDefaultBranch()
+	}
+	mode := determineMode(o)
+	return renderPromptsRequest{Phase: o.Phase, WriteDir: o.WriteDir, Config: processor.Config{
+		PlanFile:        o.PlanFile,
+		PlanDescription: o.PlanDescription,
+		ProgressPath:    progress.FilePath(o.PlanFile, string(mode)),
+		Mode:            mode,
+		DefaultBranch:   resolveDefaultBranch(o.BaseRef, cfg.DefaultBranch, autoDetected),
+		AppConfig:       cfg,
+	}}
+}
+
+// runRenderPrompts renders the prompts of the request, printing them to w or writing them to WriteDir.
+// template errors, unknown variables and missing agents are reported to w and fail the command, so it can guard prompt changes in CI.
+func runRenderPrompts(req renderPromptsRequest, w io.Writer) error {
+	var phases []string
+	if req.Phase != "" {
+		phases = []string{req.Phase}
+	}
+	rendered, err := processor.RenderPrompts(req.Config, phases)
+	if err != nil {
+		return fmt.Errorf("render prompts: %w", err)
+	}
+	if req.WriteDir != "" {
+		if err := os.MkdirAll(req.WriteDir, 0o750); err != nil {
+			return fmt.Errorf("create prompts dir: %w", err)
+		}
+	}
+
+	var failed int
+	for _, p := range rendered {
+		name := p.Phase + "." + p.Syntax + ".txt"
+		if req.WriteDir != "" {
+			if err := os.WriteFile(filepath.Join(req.WriteDir, name), []byte(p.Text), 0o600); err != nil {
+				return fmt.Errorf("write rendered prompt: %w", err)
+			}
+			fmt.Fprintf(w, "wrote %s\n", filepath.Join(req.WriteDir, name))
+		} else {
+			fmt.Fprintf(w, "--- %s prompt (%s syntax) ---\n%s\n\n", p.Phase, p.Syntax, strings.TrimRight(p.Text, "\n"))
+		}
+		if p.Error != "" {
+			fmt.Fprintf(w, "%s (%s): template error: %s\n", p.Phase, p.Syntax, p.Error)
+		}
+		if len(p.Unknown) > 0 {
+			fmt.Fprintf(w, "%s (%s): unknown variables: %s\n", p.Phase, p.Syntax, strings.Join(p.Unknown, ", "))
+		}
+		if len(p.Missing) > 0 {
+			fmt.Fprintf(w, "%s (%s): missing agents: %s\n", p.Phase, p.Syntax, strings.Join(p.Missing, ", "))
+		}
+		if p.Error != "" || len(p.Unknown) > 0 || len(p.Missing) > 0 {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("render prompts: %d prompts with template errors, unknown variables or missing agents", failed)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	flags "github.com/jessevdk/go-flags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/processor"
+)
+
+func TestApplyPromptsCommand(t *testing.T) {
+	t.Run("with plan", func(t *testing.T) {
+		var o opts
+		rest := applyPromptsCommand(&o, []string{"prompts", "render", "docs/plans/a.md"})
+		assert.Equal(t, []string{"docs/plans/a.md"}, rest)
+		assert.True(t, o.promptsCmd)
+	})
+
+	t.Run("without render", func(t *testing.T) {
+		var o opts
+		rest := applyPromptsCommand(&o, []string{"prompts"})
+		assert.Equal(t, []string{"prompts"}, rest)
+		assert.False(t, o.promptsCmd)
+	})
+
+	t.Run("flags", func(t *testing.T) {
+		o := parseTestOpts(t, "--phase", "task", "--write-dir", "out")
+		assert.Equal(t, "task", o.Phase)
+		assert.Equal(t, "out", o.WriteDir)
+	})
+
+	t.Run("phase help lists every prompt phase", func(t *testing.T) {
+		var o opts
+		opt := flags.NewParser(&o, flags.Default).FindOptionByLongName("phase")
+		require.NotNil(t, opt)
+		for _, ph := range processor.PromptPhases {
+			assert.Contains(t, opt.Description, ph)
+		}
+	})
+
+	assert.False(t, showBanner([]string{"prompts", "render"}))
+}
+
+func TestRunRenderPrompts(t *testing.T) {
+	cfgDir := t.TempDir()
+	cfg, err := config.Load(cfgDir)
+	require.NoError(t, err)
+	procCfg := processor.Config{PlanFile: "docs/plans/feature.md", DefaultBranch: "main", AppConfig: cfg}
+
+	t.Run("print one phase", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, runRenderPrompts(renderPromptsRequest{Config: procCfg, Phase: "task"}, &buf))
+		assert.Contains(t, buf.String(), "--- task prompt (claude syntax) ---\n")
+		assert.Contains(t, buf.String(), "--- task prompt (codex syntax) ---\n")
+		assert.Contains(t, buf.String(), "docs/plans/feature.md")
+		assert.NotContains(t, buf.String(), "review_first")
+	})
+
+	t.Run("write dir", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "prompts")
+		var buf bytes.Buffer
+		require.NoError(t, runRenderPrompts(renderPromptsRequest{Config: procCfg, WriteDir: dir}, &buf))
+		files, globErr := filepath.Glob(filepath.Join(dir, "*.txt"))
+		require.NoError(t, globErr)
+		assert.Len(t, files, 2*len(processor.PromptPhases))
+		data, readErr := os.ReadFile(filepath.Join(dir, "review_first.codex.txt"))
+		require.NoError(t, readErr)
+		assert.Contains(t, string(data), "spawn_agent(")
+		assert.Contains(t, buf.String(), "wrote "+filepath.Join(dir, "task.claude.txt"))
+	})
+
+	t.Run("unknown variables fail", func(t *testing.T) {
+		broken := *cfg
+		broken.FinalizePrompt = "finalize {{GOAL}} {{CODEX_OUTPUT}} {{agent:ghost}}"
+		req := renderPromptsRequest{Config: procCfg, Phase: "finalize"}
+		req.Config.AppConfig = &broken
+		var buf bytes.Buffer
+		err := runRenderPrompts(req, &buf)
+		require.EqualError(t, err, "render prompts: 2 prompts with template errors, unknown variables or missing agents")
+		assert.Contains(t, buf.String(), "finalize (claude): unknown variables: {{CODEX_OUTPUT}}\n")
+		assert.Contains(t, buf.String(), "finalize (codex): missing agents: ghost\n")
+	})
+
+	t.Run("template errors fail", func(t *testing.T) {
+		broken := *cfg
+		broken.FinalizePrompt = "finalize {{GOAL}} {{BRANCH}}"
+		req := renderPromptsRequest{Config: procCfg, Phase: "finalize"}
+		req.Config.AppConfig = &broken
+		var buf bytes.Buffer
+		err := runRenderPrompts(req, &buf)
+		require.EqualError(t, err, "render prompts: 2 prompts with template errors, unknown variables or missing agents")
+		assert.Contains(t, buf.String(), `finalize (claude): template error: parse prompt template: template: finalize.txt:1: function "BRANCH" not defined`)
+	})
+
+	t.Run("unknown phase", func(t *testing.T) {
+		err := runRenderPrompts(renderPromptsRequest{Config: procCfg, Phase: "bogus"}, &bytes.Buffer{})
+		require.ErrorContains(t, err, `unknown prompt phase "bogus"`)
+	})
+}
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/notify"
+	"github.com/umputun/ralphex/pkg/plan"
+	"github.com/umputun/ralphex/pkg/queue"
+)
+
+// queueStopTimeout bounds how long a queued plan's process may take to stop after an interrupt.
+const queueStopTimeout = 15 * time.Second
+
+// isQueueRun returns true when plans are run from a queue (--queue or the queue subcommand).
+func isQueueRun(o opts) bool {
+	return o.Queue != "" || o.queueCmd
+}
+
+// applyQueueCommand handles the "queue [dir]" subcommand. returns the remaining positional
+// arguments; a file named "queue" in the current directory keeps its plan-file meaning.
+func applyQueueCommand(o *opts, args []string) []string {
+	if len(args) == 0 || args[0] != "queue" || fileExists(args[0]) {
+		return args
+	}
+	o.queueCmd = true
+	if len(args) > 1 {
+		o.Queue = args[1]
+		return args[2:]
+	}
+	return args[1:]
+}
+
+// runQueue runs every pending plan of the queue directory. each plan gets its own worktree and
+// runs in a child ralphex process, with at most --parallel plans in flight; a plan with depends_on
+// starts once its prerequisites succeeded, from the merge of their branches. ends with a summary
+// table and a single notification covering all plans.
+func runQueue(ctx context.Context, o opts, req executePlanRequest) error {
+	dir := o.Queue
+	if dir == "" {
+		dir = req.Config.PlansDir
+	}
+	// glob only direct plan files; completed/ is not matched recursively.
+	paths, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return fmt.Errorf("list queued plans: %w", err)
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("%w: %s", plan.ErrNoPlansFound, dir)
+	}
+	plans, err := queue.Load(paths)
+	if err != nil {
+		return fmt.Errorf("load queue: %w", err)
+	}
+
+	isDefault, err := req.GitSvc.IsDefaultBranch(req.DefaultBranch)
+	if err != nil {
+		return fmt.Errorf("check current branch: %w", err)
+	}
+	if !isDefault {
+		return fmt.Errorf("queue must run from the default branch %s", req.DefaultBranch)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locate ralphex executable: %w", err)
+	}
+
+	// queued plans are uncommitted on the default branch until each run commits its own copy,
+	// so they must not block worktree creation for each other
+	req.GitSvc.AllowDirtyPaths(paths...)
+	if igErr := req.GitSvc.EnsureLocalGitignore(); igErr != nil {
+		return fmt.Errorf("ensure gitignore: %w", igErr)
+	}
+
+	parallel := max(o.Parallel, 1)
+	info := req.Colors.Info()
+	info.Printf("queue: %d plans from %s, up to %d in parallel\n", len(plans), dir, parallel)
+	for _, p := range plans {
+		line := "  " + p.Name()
+		if len(p.DependsOn) > 0 {
+			deps := make([]string, 0, len(p.DependsOn))
+			for _, d := range p.DependsOn {
+				deps = append(deps, filepath.Base(d))
+			}
+			line += " (after " + strings.Join(deps, ", ") + ")"
+		}
+		info.Printf("%s\n", line)
+	}
+
+	q := &queueRunner{o: o, req: req, exe: exe, out: os.Stdout, outMu: &sync.Mutex{}, plans: plans,
+		stats: map[string]git.DiffStats{}}
+	start := time.Now()
+	results := queue.Run(ctx, plans, parallel, q.runPlan)
+
+	fmt.Fprintln(os.Stdout)
+	printQueueSummary(os.Stdout, results)
+	req.NotifySvc.Send(context.Background(), buildQueueNotifyResult(dir, results, q.stats, formatElapsed(time.Since(start))))
+
+	if failed := queue.Failed(results); len(failed) > 0 {
+		return fmt.Errorf("%d of %d queued plans did not complete", len(failed), len(results))
+	}
+	return nil
+}
+
+// queueRunner runs the plans of a queue, or of one repository of serve --daemon. git operations
+// on the main repo are serialized by gitMu, output of concurrent plans by outMu.
+type queueRunner struct {
+	o      opts
+	req    executePlanRequest
+	exe    string    // ralphex executable started for every plan
+	dir    string    // working directory of the child processes, empty for the current one
+	prefix string    // output prefix before the plan's branch, e.g. the daemon's repo name
+	out    io.Writer // destination of the plans' prefixed output
+	outMu  *sync.Mutex
+	plans  []queue.Plan
+
+	gitMu sync.Mutex
+	stats map[string]git.DiffStats // plan path -> diff stats of succeeded plans, guarded by gitMu
+}
+
+// queuedWorktree is the worktree prepared for one queued plan.
+type queuedWorktree struct {
+	path     string
+	svc      *git.Service
+	baseRef  string // base ref passed to the plan run, empty to use the queue's one
+	diffBase string // ref the plan's diff stats are taken against
+}
+
+// runPlan prepares the plan's worktree, runs the plan in a child process and archives it on
+// success, implements queue.RunFunc.
+func (q *queueRunner) runPlan(ctx context.Context, p queue.Plan) error {
+	wt, err := q.prepare(p)
+	if err != nil {
+		return err
+	}
+	cmd, w := q.command(ctx, p, wt, q.o)
+	return q.finish(p, wt, w, cmd.Run())
+}
+
+// command builds the child process running the plan in its prepared worktree with the flags of o.
+// the returned writer receives the child's output and must be flushed through finish.
+func (q *queueRunner) command(ctx context.Context, p queue.Plan, wt queuedWorktree, o opts) (*exec.Cmd, *prefixWriter) {
+	args := append(queueChildArgs(o), "--queue-worktree", wt.path)
+	if wt.baseRef != "" {
+		args = append(args, "--base-ref", wt.baseRef)
+	}
+	args = append(args, p.Path)
+	cmd := exec.CommandContext(ctx, q.exe, args...) //nolint:gosec // re-executes ralphex itself
+	cmd.Dir = q.dir
+	// interrupt rather than kill, so the plan run can record its progress and stop cleanly
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	cmd.WaitDelay = queueStopTimeout
+	w := &prefixWriter{out: q.out, mu: q.outMu, prefix: "[" + q.prefix + p.Branch + "] "}
+	cmd.Stdout, cmd.Stderr = w, w
+	return cmd, w
+}
+
+// finish removes the plan's worktree after its child process ended with runErr, records the diff
+// stats and archives the plan on success. returns the plan's failure reason.
+func (q *queueRunner) finish(p queue.Plan, wt queuedWorktree, w *prefixWriter, runErr error) error {
+	w.Flush()
+
+	q.gitMu.Lock()
+	defer q.gitMu.Unlock()
+	if runErr == nil {
+		if stats, statsErr := wt.svc.DiffStats(wt.diffBase); statsErr == nil {
+			q.stats[p.Path] = stats
+		}
+	}
+	if rmErr := q.req.GitSvc.RemoveWorktree(wt.path); rmErr != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to remove worktree %s: %v\n", wt.path, rmErr)
+	}
+	if runErr != nil {
+		if w.lastErr != "" {
+			return errors.New(w.lastErr)
+		}
+		return fmt.Errorf("run plan: %w", runErr)
+	}
+	if q.req.Config.MovePlanOnCompletion {
+		if mvErr := q.req.GitSvc.MovePlanToCompleted(p.Path); mvErr != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to move plan %s to completed: %v\n", p.Name(), mvErr)
+		}
+	}
+	return nil
+}
+
+// prepare creates the plan's worktree, commits the plan on its branch and merges in the branches
+// of its prerequisites. a plan with prerequisites gets the merged HEAD as base ref unless --base-ref
+// is set, so reviews and diff stats cover only its own changes.
+func (q *queueRunner) prepare(p queue.Plan) (wt queuedWorktree, err error) {
+	q.gitMu.Lock()
+	defer q.gitMu.Unlock()
+
+	path, planNeedsCommit, err := q.req.GitSvc.CreateWorktreeForPlan(p.Path, q.req.DefaultBranch, "")
+	if err != nil {
+		return wt, fmt.Errorf("create worktree: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rmErr := q.req.GitSvc.RemoveWorktree(path); rmErr != nil {
+				fmt.Fprintf(os.Stderr, "warning: failed to remove worktree after setup error: %v\n", rmErr)
+			}
+		}
+	}()
+
+	svc, err := git.NewService(path, q.req.Colors.Info(), q.req.Config.VcsCommand)
+	if err != nil {
+		return wt, fmt.Errorf("open worktree git service: %w", err)
+	}
+	svc.SetCommitTrailer(q.req.Config.CommitTrailer)
+	if planNeedsCommit {
+		if err = svc.CommitPlanFile(p.Path, q.req.GitSvc.Root()); err != nil {
+			return wt, fmt.Errorf("commit plan in worktree: %w", err)
+		}
+	}
+
+	wt = queuedWorktree{path: path, svc: svc, diffBase: q.req.BaseRef}
+	for _, dep := range p.DependsOn {
+		if err = svc.MergeBranch(q.branch(dep)); err != nil {
+			return wt, fmt.Errorf("merge prerequisite %s: %w", filepath.Base(dep), err)
+		}
+	}
+	if len(p.DependsOn) > 0 && q.o.BaseRef == "" {
+		var head string
+		if head, err = svc.HeadHash(); err != nil {
+			return wt, fmt.Errorf("get merged head: %w", err)
+		}
+		wt.baseRef, wt.diffBase = head, head
+	}
+	return wt, nil
+}
+
+// branch returns the branch of the queued plan at path.
+func (q *queueRunner) branch(path string) string {
+	for _, p := range q.plans {
+		if p.Path == path {
+			return p.Branch
+		}
+	}
+	return plan.ExtractBranchName(path)
+}
+
+// queueChildArgs returns the flags passed on to every plan run of a queue. the queue's own flags,
+// the plan selection and the web dashboard are not passed, and neither is the base ref of plans
+// with prerequisites, which the queue sets itself.
+func queueChildArgs(o opts) []string {
+	var args []string
+	addInt := func(name string, v int) {
+		if v != 0 {
+			args = append(args, "--"+name, strconv.Itoa(v))
+		}
+	}
+	addString := func(name, v string, set bool) {
+		if v != "" || set {
+			args = append(args, "--"+name+"="+v)
+		}
+	}
+	addBool := func(name string, v bool) {
+		if v {
+			args = append(args, "--"+name)
+		}
+	}
+	addDuration := func(name string, v time.Duration, set bool) {
+		if v != 0 || set {
+			args = append(args, "--"+name, v.String())
+		}
+	}
+	addFloat := func(name string, v float64, set bool) {
+		if v != 0 || set {
+			args = append(args, "--"+name, strconv.FormatFloat(v, 'f', -1, 64))
+		}
+	}
+
+	addInt("max-iterations", o.MaxIterations)
+	addInt("max-external-iterations", o.MaxExternalIterations)
+	addInt("review-patience", o.ReviewPatience)
+	addString("plan-model", o.PlanModel, false)
+	addString("task-model", o.TaskModel, false)
+	addString("review-model", o.ReviewModel, false)
+	addString("claude-command", o.ClaudeCommand, o.claudeCommandSet)
+	addString("claude-args", o.ClaudeArgs, o.claudeArgsSet)
+	addString("external-review-tool", o.ExternalReviewTool, false)
+	addString("custom-review-script", o.CustomReviewScript, o.customReviewScriptSet)
+	addBool("tasks-only", o.TasksOnly)
+	addString("pipeline", o.Pipeline, false)
+	addString("base-ref", o.BaseRef, false)
+	addDuration("wait", o.Wait, o.waitSet)
+	addDuration("session-timeout", o.SessionTimeout, o.sessionTimeoutSet)
+	addDuration("idle-timeout", o.IdleTimeout, o.idleTimeoutSet)
+	addBool("skip-finalize", o.SkipFinalize)
+	addBool("validate", o.Validate)
+	addFloat("max-run-cost", o.MaxRunCost, o.maxRunCostSet)
+	if o.MaxRunTokens != 0 || o.maxRunTokensSet {
+		args = append(args, "--max-run-tokens", strconv.FormatInt(o.MaxRunTokens, 10))
+	}
+	addFloat("max-phase-cost", o.MaxPhaseCost, o.maxPhaseCostSet)
+	addString("budget-action", o.BudgetAction, false)
+	addBool("preserve-anthropic-api-key", o.PreserveAnthropicAPIKey)
+	addBool("codex", o.Codex)
+	addBool("pass-claude-md", o.PassClaudeMd)
+	addBool("debug", o.Debug)
+	addBool("no-color", o.NoColor)
+	addString("config-dir", o.ConfigDir, false)
+	return args
+}
+
+// prefixWriter prefixes every line of a queued plan's output, keeping output of concurrent plans
+// attributable. complete lines are written to out under mu, which all plans of a queue share.
+type prefixWriter struct {
+	out     io.Writer
+	mu      *sync.Mutex
+	prefix  string
+	buf     []byte
+	lastErr string            // message of the last "error: " line, the plan's failure reason
+	onLine  func(line string) // called with every output line before it is written, can be nil
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.writeLine(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes the pending partial line, if any.
+func (w *prefixWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.writeLine(string(w.buf))
+		w.buf = nil
+	}
+}
+
+func (w *prefixWriter) writeLine(line string) {
+	if msg, ok := strings.CutPrefix(line, "error: "); ok {
+		w.lastErr = msg
+	}
+	if w.onLine != nil {
+		w.onLine(line)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fmt.Fprintf(w.out, "%s%s\n", w.prefix, line)
+}
+
+// printQueueSummary writes the per-plan outcome table of a queue.
+func printQueueSummary(out io.Writer, results []queue.Result) {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PLAN\tBRANCH\tSTATUS\tDURATION\tERROR")
+	for _, r := range results {
+		dur, errMsg := "-", ""
+		if r.Status != queue.StatusSkipped {
+			dur = formatElapsed(r.Duration)
+		}
+		if r.Err != nil {
+			errMsg = r.Err.Error()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.Plan.Name(), r.Plan.Branch, r.Status, dur, errMsg)
+	}
+	_ = tw.Flush()
+}
+
+// buildQueueNotifyResult constructs the single notification of a queue. diff stats are summed
+// over the succeeded plans.
+func buildQueueNotifyResult(dir string, results []queue.Result, stats map[string]git.DiffStats, elapsed string) notify.Result {
+	result := notify.Result{Status: "success", Mode: "queue", PlanFile: dir, Duration: elapsed}
+	for _, r := range results {
+		pr := notify.PlanResult{PlanFile: r.Plan.Path, Branch: r.Plan.Branch, Status: string(r.Status)}
+		if r.Status != queue.StatusSkipped {
+			pr.Duration = formatElapsed(r.Duration)
+		}
+		if r.Err != nil {
+			pr.Error = r.Err.Error()
+		}
+		result.Plans = append(result.Plans, pr)
+		st := stats[r.Plan.Path]
+		result.Files += st.Files
+		result.Additions += st.Additions
+		result.Deletions += st.Deletions
+	}
+	if failed := queue.Failed(results); len(failed) > 0 {
+		result.Status = "failure"
+		result.Error = fmt.Sprintf("%d of %d queued plans did not complete", len(failed), len(results))
+	}
+	return result
+}
+
+// formatElapsed formats a duration like progress.Logger.Elapsed.
+func formatElapsed(d time.Duration) string {
+	if d >= time.Hour {
+		return strings.TrimSuffix(d.Truncate(time.Minute).String(), "0s")
+	}
+	return d.Truncate(time.Second).String()
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/queue"
+)
+
+func TestApplyQueueCommand(t *testing.T) {
+	t.Run("queue with dir", func(t *testing.T) {
+		var o opts
+		rest := applyQueueCommand(&o, []string{"queue", "docs/plans"})
+		assert.Empty(t, rest)
+		assert.True(t, o.queueCmd)
+		assert.Equal(t, "docs/plans", o.Queue)
+		assert.True(t, isQueueRun(o))
+	})
+
+	t.Run("queue without dir", func(t *testing.T) {
+		var o opts
+		rest := applyQueueCommand(&o, []string{"queue"})
+		assert.Empty(t, rest)
+		assert.True(t, o.queueCmd)
+		assert.Empty(t, o.Queue)
+		assert.True(t, isQueueRun(o))
+	})
+
+	t.Run("plan file argument", func(t *testing.T) {
+		var o opts
+		rest := applyQueueCommand(&o, []string{"docs/plans/a.md"})
+		assert.Equal(t, []string{"docs/plans/a.md"}, rest)
+		assert.False(t, isQueueRun(o))
+	})
+
+	t.Run("file named queue stays a plan file", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+		require.NoError(t, os.WriteFile("queue", []byte("# plan\n"), 0o600))
+		var o opts
+		rest := applyQueueCommand(&o, []string{"queue"})
+		assert.Equal(t, []string{"queue"}, rest)
+		assert.False(t, isQueueRun(o))
+	})
+
+	t.Run("queue flag", func(t *testing.T) {
+		o := parseTestOpts(t, "--queue", "docs/plans", "--parallel", "3")
+		assert.True(t, isQueueRun(o))
+		assert.Equal(t, 3, o.Parallel)
+	})
+
+	t.Run("parallel defaults to one", func(t *testing.T) {
+		o := parseTestOpts(t, "--queue", "docs/plans")
+		assert.Equal(t, 1, o.Parallel)
+	})
+}
+
+func TestQueueChildArgs(t *testing.T) {
+	t.Run("no flags", func(t *testing.T) {
+		assert.Empty(t, queueChildArgs(parseTestOpts(t, "--queue", "docs/plans", "--parallel", "2")))
+	})
+
+	t.Run("pass-through flags", func(t *testing.T) {
+		o := parseTestOpts(t, "--queue", "docs/plans", "-m", "10", "--task-model", "opus:high", "--tasks-only",
+			"--session-timeout", "30m", "--idle-timeout", "0", "--max-run-cost", "2.5", "--max-run-tokens", "1000",
+			"--budget-action", "downgrade", "--claude-args", "", "--validate", "--no-color", "--config-dir", "/cfg", "--serve")
+		assert.Equal(t, []string{"--max-iterations", "10", "--task-model=opus:high", "--claude-args=", "--tasks-only",
+			"--session-timeout", "30m0s", "--idle-timeout", "0s", "--validate", "--max-run-cost", "2.5",
+			"--max-run-tokens", "1000", "--budget-action=downgrade", "--no-color", "--config-dir=/cfg"},
+			queueChildArgs(o))
+	})
+
+	t.Run("args parse back", func(t *testing.T) {
+		o := parseTestOpts(t, "--queue", "docs/plans", "--review-model", "sonnet", "--wait", "1h", "--codex",
+			"--pipeline", "task,finalize", "--base-ref", "develop")
+		child := parseTestOpts(t, append(queueChildArgs(o), "--queue-worktree", "/wt", "docs/plans/a.md")...)
+		assert.Equal(t, "sonnet", child.ReviewModel)
+		assert.Equal(t, time.Hour, child.Wait)
+		assert.True(t, child.waitSet)
+		assert.True(t, child.Codex)
+		assert.Equal(t, "task,finalize", child.Pipeline)
+		assert.Equal(t, "develop", child.BaseRef)
+		assert.Equal(t, "/wt", child.QueueWorktree)
+		assert.Equal(t, "docs/plans/a.md", child.PlanFile)
+		assert.False(t, isQueueRun(child))
+		require.NoError(t, validateFlags(child))
+	})
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	var mu sync.Mutex
+	w := &prefixWriter{out: &out, mu: &mu, prefix: "[a] "}
+
+	_, err := w.Write([]byte("first line\nsecond "))
+	require.NoError(t, err)
+	assert.Equal(t, "[a] first line\n", out.String())
+
+	_, err = w.Write([]byte("part\nerror: boom\nerror: run failed\ntail"))
+	require.NoError(t, err)
+	w.Flush()
+	assert.Equal(t, "[a] first line\n[a] second part\n[a] error: boom\n[a] error: run failed\n[a] tail\n", out.String())
+	assert.Equal(t, "run failed", w.lastErr)
+}
+
+func TestQueueSummary(t *testing.T) {
+	plans := []queue.Plan{
+		{Path: "docs/plans/a.md", Branch: "a"},
+		{Path: "docs/plans/b.md", Branch: "b"},
+		{Path: "docs/plans/c.md", Branch: "c", DependsOn: []string{"docs/plans/b.md"}},
+	}
+	results := []queue.Result{
+		{Plan: plans[0], Status: queue.StatusSuccess, Duration: 90 * time.Second},
+		{Plan: plans[1], Status: queue.StatusFailed, Err: errors.New("task 2 failed"), Duration: 2 * time.Hour},
+		{Plan: plans[2], Status: queue.StatusSkipped, Err: errors.New("prerequisite b.md did not succeed")},
+	}
+
+	t.Run("table", func(t *testing.T) {
+		var out bytes.Buffer
+		printQueueSummary(&out, results)
+		lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+		require.Len(t, lines, 4)
+		assert.Equal(t, []string{"PLAN", "BRANCH", "STATUS", "DURATION", "ERROR"}, strings.Fields(lines[0]))
+		assert.Equal(t, []string{"a.md", "a", "success", "1m30s"}, strings.Fields(lines[1]))
+		assert.Contains(t, lines[2], "failed")
+		assert.Contains(t, lines[2], "2h0m")
+		assert.Contains(t, lines[2], "task 2 failed")
+		assert.Contains(t, lines[3], "skipped")
+		assert.Contains(t, lines[3], " - ")
+	})
+
+	t.Run("notification", func(t *testing.T) {
+		stats := map[string]git.DiffStats{"docs/plans/a.md": {Files: 2, Additions: 10, Deletions: 3}}
+		res := buildQueueNotifyResult(filepath.Join("docs", "plans"), results, stats, "2h1m")
+		assert.Equal(t, "failure", res.Status)
+		assert.Equal(t, "queue", res.Mode)
+		assert.Equal(t, "docs/plans", res.PlanFile)
+		assert.Equal(t, "2h1m", res.Duration)
+		assert.Equal(t, "2 of 3 queued plans did not complete", res.Error)
+		assert.Equal(t, 2, res.Files)
+		assert.Equal(t, 10, res.Additions)
+		assert.Equal(t, 3, res.Deletions)
+		require.Len(t, res.Plans, 3)
+		assert.Equal(t, "success", res.Plans[0].Status)
+		assert.Equal(t, "1m30s", res.Plans[0].Duration)
+		assert.Equal(t, "task 2 failed", res.Plans[1].Error)
+		assert.Empty(t, res.Plans[2].Duration)
+	})
+
+	t.Run("all succeeded", func(t *testing.T) {
+		res := buildQueueNotifyResult("docs/plans", results[:1], nil, "1m30s")
+		assert.Equal(t, "success", res.Status)
+		assert.Empty(t, res.Error)
+	})
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/git"
+)
+
+// reviewTarget describes changes reviewed in a scratch worktree instead of the current branch:
+// a commit range (--range) or a patch file (--patch).
+type reviewTarget struct {
+	name  string // scratch worktree name, also names the progress log and findings ledger
+	base  string // commit the review diffs against, {{DEFAULT_BRANCH}} of the prompts
+	head  string // commit the scratch worktree is checked out at
+	patch string // absolute path of the patch applied on head, empty for a range
+	goal  string // {{GOAL}} of the prompts
+}
+
+// unsafeNameChars matches characters not kept in scratch worktree names.
+var unsafeNameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// resolveReviewTarget resolves --range or --patch against the repository. a patch applies on baseRef.
+func resolveReviewTarget(o opts, gitSvc *git.Service, baseRef string) (reviewTarget, error) {
+	if o.Range != "" {
+		base, head, err := gitSvc.ResolveRange(o.Range)
+		if err != nil {
+			return reviewTarget{}, fmt.Errorf("--range: %w", err)
+		}
+		return reviewTarget{name: "review-" + base[:min(7, len(base))] + "-" + head[:min(7, len(head))],
+			base: base, head: head, goal: "commit range " + o.Range}, nil
+	}
+
+	patch, err := filepath.Abs(o.Patch)
+	if err != nil {
+		return reviewTarget{}, fmt.Errorf("--patch: %w", err)
+	}
+	if !fileExists(patch) {
+		return reviewTarget{}, fmt.Errorf("--patch: file not found: %s", o.Patch)
+	}
+	base, err := gitSvc.ResolveCommit(baseRef)
+	if err != nil {
+		return reviewTarget{}, fmt.Errorf("--patch: %w", err)
+	}
+	stem := strings.TrimSuffix(filepath.Base(patch), filepath.Ext(patch))
+	return reviewTarget{name: "review-" + strings.Trim(unsafeNameChars.ReplaceAllString(stem, "-"), "-"),
+		base: base, head: base, patch: patch, goal: fmt.Sprintf("patch %s applied on %s", filepath.Base(patch), baseRef)}, nil
+}
+
+// runReviewTarget reviews a commit range or patch in a scratch worktree with a detached HEAD, leaving the
+// branches of the main repository untouched. a report-only review removes the worktree when done; otherwise
+// the worktree is kept with the review fixes committed on top of the reviewed changes.
+func runReviewTarget(ctx context.Context, o opts, req executePlanRequest) error {
+	target, err := resolveReviewTarget(o, req.GitSvc, req.BaseRef)
+	if err != nil {
+		return err
+	}
+	wtPath, err := req.GitSvc.CreateReviewWorktree(target.name, target.head)
+	if err != nil {
+		return fmt.Errorf("create review worktree: %w", err)
+	}
+
+	if target.patch != "" {
+		if err := applyReviewPatch(req, wtPath, target.patch); err != nil {
+			if rmErr := req.GitSvc.RemoveWorktree(wtPath); rmErr != nil {
+				fmt.Fprintf(os.Stderr, "warning: failed to remove worktree: %v\n", rmErr)
+			}
+			return err
+		}
+	}
+
+	req.BaseRef = target.base
+	req.ReviewTarget = target.goal
+	req.BranchOverride = target.name
+	err = runInWorktree(ctx, o, req, wtPath, false, o.ReportOnly)
+	if !o.ReportOnly {
+		req.Colors.Info().Printf("review worktree kept at %s, remove it with: git worktree remove %s\n",
+			toRelPath(wtPath), toRelPath(wtPath))
+	}
+	return err
+}
+
+// applyReviewPatch commits the patch on the detached HEAD of the scratch worktree.
+func applyReviewPatch(req executePlanRequest, wtPath, patch string) error {
+	wtGitSvc, err := git.NewService(wtPath, req.Colors.Info(), req.Config.VcsCommand)
+	if err != nil {
+		return fmt.Errorf("open review worktree: %w", err)
+	}
+	if err := wtGitSvc.ApplyPatch(patch); err != nil {
+		return fmt.Errorf("--patch: %w", err)
+	}
+	return nil
+}
+
+// validateReviewTarget checks --range and --patch against the other flags.
+func validateReviewTarget(o opts) error {
+	if o.Range == "" && o.Patch == "" {
+		return nil
+	}
+	if o.Range != "" && o.Patch != "" {
+		return errors.New("--range conflicts with --patch")
+	}
+	if !o.Review || o.ExternalOnly || o.CodexOnly || o.TasksOnly {
+		return errors.New("--range and --patch require --review")
+	}
+	if o.Range != "" && o.BaseRef != "" {
+		return errors.New("--range conflicts with --base-ref, the range sets the diff base")
+	}
+	return nil
+}
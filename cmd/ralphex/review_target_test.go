@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/processor"
+)
+
+func TestResolveReviewTarget(t *testing.T) {
+	dir := setupTestRepo(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\n"), 0o600))
+	runGit(t, dir, "add", "a.txt")
+	runGit(t, dir, "commit", "-m", "add a")
+	gitSvc, err := git.NewService(dir, noopLogger())
+	require.NoError(t, err)
+	revParse := func(ref string) string {
+		out, err := exec.Command("git", "-C", dir, "rev-parse", ref).Output()
+		require.NoError(t, err)
+		return strings.TrimSpace(string(out))
+	}
+
+	t.Run("range", func(t *testing.T) {
+		target, err := resolveReviewTarget(opts{Range: "HEAD~1..HEAD"}, gitSvc, "master")
+		require.NoError(t, err)
+		assert.Equal(t, revParse("HEAD~1"), target.base)
+		assert.Equal(t, revParse("HEAD"), target.head)
+		assert.Equal(t, "review-"+target.base[:7]+"-"+target.head[:7], target.name)
+		assert.Equal(t, "commit range HEAD~1..HEAD", target.goal)
+		assert.Empty(t, target.patch)
+	})
+
+	t.Run("patch", func(t *testing.T) {
+		patch := filepath.Join(t.TempDir(), "fix login #42.diff")
+		require.NoError(t, os.WriteFile(patch, []byte("diff"), 0o600))
+		target, err := resolveReviewTarget(opts{Patch: patch}, gitSvc, "master")
+		require.NoError(t, err)
+		assert.Equal(t, "review-fix-login-42", target.name)
+		assert.Equal(t, revParse("master"), target.base)
+		assert.Equal(t, target.base, target.head)
+		assert.Equal(t, patch, target.patch)
+		assert.Equal(t, "patch fix login #42.diff applied on master", target.goal)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := resolveReviewTarget(opts{Range: "nope..HEAD"}, gitSvc, "master")
+		require.ErrorContains(t, err, "--range: resolve range start")
+		_, err = resolveReviewTarget(opts{Patch: "/nonexistent/fix.diff"}, gitSvc, "master")
+		require.ErrorContains(t, err, "--patch: file not found")
+	})
+}
+
+func TestValidateReviewTarget(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   opts
+		errMsg string
+	}{
+		{name: "none", opts: opts{}},
+		{name: "range with review", opts: opts{Review: true, Range: "a..b"}},
+		{name: "patch with report-only review", opts: opts{Review: true, ReportOnly: true, Patch: "fix.diff", BaseRef: "develop"}},
+		{name: "range and patch", opts: opts{Review: true, Range: "a..b", Patch: "fix.diff"}, errMsg: "--range conflicts with --patch"},
+		{name: "range without review", opts: opts{Range: "a..b"}, errMsg: "require --review"},
+		{name: "patch with external-only", opts: opts{Review: true, ExternalOnly: true, Patch: "fix.diff"}, errMsg: "require --review"},
+		{name: "range with base-ref", opts: opts{Review: true, Range: "a..b", BaseRef: "develop"}, errMsg: "--range conflicts with --base-ref"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateReviewTarget(tc.opts)
+			if tc.errMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.errMsg)
+		})
+	}
+	assert.Equal(t, "a..b", parseTestOpts(t, "--review", "--range", "a..b").Range)
+	assert.Equal(t, "fix.diff", parseTestOpts(t, "--review", "--patch", "fix.diff").Patch)
+}
+
+func TestRunReviewTarget_PatchFailureRemovesWorktree(t *testing.T) {
+	dir := setupTestRepo(t)
+	gitSvc, err := git.NewService(dir, noopLogger())
+	require.NoError(t, err)
+	patch := filepath.Join(t.TempDir(), "broken.diff")
+	require.NoError(t, os.WriteFile(patch, []byte("diff --git a/missing.txt b/missing.txt\n--- a/missing.txt\n+++ b/missing.txt\n@@ -1 +1 @@\n-old\n+new\n"), 0o600))
+
+	req := executePlanRequest{Mode: processor.ModeReview, GitSvc: gitSvc, Config: &config.Config{}, Colors: testColors(), BaseRef: "master"}
+	err = runReviewTarget(t.Context(), opts{Review: true, Patch: patch}, req)
+	require.ErrorContains(t, err, "--patch: apply patch broken.diff")
+	assert.NoDirExists(t, filepath.Join(dir, ".ralphex", "worktrees", "review-broken"))
+}
@@ -25,6 +25,7 @@ const (
 	customReviewPromptFile = "custom_review.txt"
 	customEvalPromptFile   = "custom_eval.txt"
 	codexReviewPromptFile  = "codex_review.txt"
+	reviewReportPromptFile = "review_report.txt"
 )
 
 // Executor mode constants for the Config.Executor field.
@@ -37,6 +38,13 @@ const (
 	ExecutorCodex  = "codex"
 )
 
+// budget action constants for the Config.BudgetAction field.
+// an empty BudgetAction behaves as BudgetActionStop.
+const (
+	BudgetActionStop      = "stop"      // end the run after the session that crossed a limit
+	BudgetActionDowngrade = "downgrade" // switch to BudgetModel once, then stop on the next crossing
+)
+
 // Config holds all configuration settings for ralphex.
 // Fields ending in *Set mostly track whether that field was explicitly set in config.
 // This allows distinguishing explicit false/0 from "not set", enabling proper
@@ -51,6 +59,8 @@ type Config struct {
 	TaskModel     string `json:"task_model"`   // model[:effort] spec for task execution (e.g., "opus", "opus:high", ":medium")
 	ReviewModel   string `json:"review_model"` // model[:effort] spec for review phases (falls back to TaskModel)
 
+	AgentProvider string `json:"agent_provider"` // provider file running an agent CLI in place of claude_command
+
 	CodexEnabled         bool   `json:"codex_enabled"`
 	CodexEnabledSet      bool   `json:"-"` // tracks if codex_enabled was explicitly set in config
 	CodexCommand         string `json:"codex_command"`
@@ -61,9 +71,16 @@ type Config struct {
 	CodexSandbox         string `json:"codex_sandbox"`
 	CodexSandboxSet      bool   `json:"-"` // tracks if codex_sandbox was explicitly set outside embedded defaults
 
-	ExternalReviewTool    string `json:"external_review_tool"` // "codex", "custom", or "none"
-	ExternalReviewToolSet bool   `json:"-"`                    // tracks if external_review_tool was explicitly set in user config (not embedded default)
-	CustomReviewScript    string `json:"custom_review_script"` // path to custom review script
+	ExternalReviewTool    string   `json:"external_review_tool"`  // comma-separated "codex", "http", "custom", "custom:<name>", or "none"
+	ExternalReviewToolSet bool     `json:"-"`                     // tracks if external_review_tool was explicitly set in user config (not embedded default)
+	CustomReviewScript    string   `json:"custom_review_script"`  // path to custom review script
+	CustomReviewScripts   []string `json:"custom_review_scripts"` // name=path scripts of custom:<name> tools
+
+	HTTPReviewBaseURL     string        `json:"http_review_base_url"`     // OpenAI-compatible API base URL, e.g. http://localhost:11434/v1
+	HTTPReviewModel       string        `json:"http_review_model"`        // model of the http review tool
+	HTTPReviewAPIKeyEnv   string        `json:"http_review_api_key_env"`  // env var holding the API key, empty for keyless servers
+	HTTPReviewChunkTokens int           `json:"http_review_chunk_tokens"` // approximate diff tokens per request, 0 = default
+	HTTPReviewIdleTimeout time.Duration `json:"http_review_idle_timeout"` // abort a request receiving no data for this long, 0 = default
 
 	IterationDelayMs      int  `json:"iteration_delay_ms"`
 	IterationDelayMsSet   bool `json:"-"` // tracks if iteration_delay_ms was explicitly set in config
@@ -74,6 +91,8 @@ type Config struct {
 	MaxExternalIterations int  `json:"max_external_iterations"`
 	ReviewPatience        int  `json:"review_patience"`
 
+	TaskEscalation []string `json:"task_escalation"` // model[:effort] specs of the retries of a failed task, in order
+
 	FinalizeEnabled    bool `json:"finalize_enabled"`
 	FinalizeEnabledSet bool `json:"-"` // tracks if finalize_enabled was explicitly set in config
 
@@ -87,11 +106,20 @@ type Config struct {
 	WorktreeEnabled    bool `json:"worktree_enabled"`
 	WorktreeEnabledSet bool `json:"-"` // tracks if use_worktree was explicitly set in config
 
-	PlansDir      string   `json:"plans_dir"`
-	WatchDirs     []string `json:"watch_dirs"`     // directories to watch for progress files
-	DefaultBranch string   `json:"default_branch"` // override auto-detected default branch
-	VcsCommand    string   `json:"vcs_command"`    // custom VCS command (default: "git")
-	CommitTrailer string   `json:"commit_trailer"` // trailer line to append to all commits
+	PlansDir         string   `json:"plans_dir"`
+	WatchDirs        []string `json:"watch_dirs"`          // directories to watch for progress files
+	Pipeline         []string `json:"pipeline"`            // stage order of a default run (empty = built-in preset)
+	FindingsExport   []string `json:"findings_export"`     // formats the findings ledger is exported to at the end of a run
+	WebToken         string   `json:"-"`                   // token required by the web API (secret, never serialized)
+	WebUser          string   `json:"web_user"`            // basic auth user of the web dashboard
+	WebPassword      string   `json:"-"`                   // basic auth password of the web dashboard (secret, never serialized)
+	WebTLSCert       string   `json:"web_tls_cert"`        // certificate file for HTTPS
+	WebTLSKey        string   `json:"web_tls_key"`         // private key file for HTTPS
+	WebTLSSelfSigned bool     `json:"web_tls_self_signed"` // serve HTTPS with a certificate generated at startup
+	DaemonRepos      []string `json:"daemon_repos"`        // name=path repositories accepted by serve --daemon
+	DefaultBranch    string   `json:"default_branch"`      // override auto-detected default branch
+	VcsCommand       string   `json:"vcs_command"`         // custom VCS command (default: "git")
+	CommitTrailer    string   `json:"commit_trailer"`      // trailer line to append to all commits
 
 	ClaudeErrorPatterns []string `json:"claude_error_patterns"` // patterns to detect in claude output (e.g., rate limit messages)
 	CodexErrorPatterns  []string `json:"codex_error_patterns"`  // patterns to detect in codex output (e.g., rate limit messages)
@@ -110,6 +138,29 @@ type Config struct {
 	IdleTimeout    time.Duration `json:"idle_timeout"`
 	IdleTimeoutSet bool          `json:"-"` // tracks if idle_timeout was explicitly set in config
 
+	// harness-enforced validation gate: run the plan's validation commands after each task iteration
+	ValidationEnabled       bool          `json:"validation_enabled"`
+	ValidationTimeout       time.Duration `json:"validation_timeout"`     // per-command timeout
+	ValidationRetryCount    int           `json:"validation_retry_count"` // fix iterations allowed per failure streak
+	ValidationRetryCountSet bool          `json:"-"`                      // tracks if validation_retry_count was explicitly set in config
+
+	// run budget limits, checked after every executor session (0 = unlimited)
+	MaxRunCost   float64 `json:"max_run_cost"`   // USD, summed over the whole run
+	MaxRunTokens int64   `json:"max_run_tokens"` // all tokens including cache reads and writes
+	MaxPhaseCost float64 `json:"max_phase_cost"` // USD, summed per phase (task, review, codex, ...)
+	BudgetAction string  `json:"budget_action"`  // BudgetActionStop (default) or BudgetActionDowngrade
+	BudgetModel  string  `json:"budget_model"`   // model[:effort] spec used after a downgrade
+
+	// lifecycle hooks: shell commands run with a JSON context on stdin (empty = disabled)
+	HookPrePhase   string        `json:"hook_pre_phase"`   // phase start; non-zero exit fails the run
+	HookPostPhase  string        `json:"hook_post_phase"`  // phase end; non-zero exit fails the run
+	HookPreTask    string        `json:"hook_pre_task"`    // before every task iteration; non-zero exit vetoes it
+	HookPostTask   string        `json:"hook_post_task"`   // after every successful task iteration
+	HookPreReview  string        `json:"hook_pre_review"`  // review phase start; non-zero exit fails the run
+	HookOnFailure  string        `json:"hook_on_failure"`  // run failed; exit code is only logged
+	HookOnComplete string        `json:"hook_on_complete"` // run completed; non-zero exit fails the run
+	HookTimeout    time.Duration `json:"hook_timeout"`     // per-hook timeout
+
 	// notification parameters
 	NotifyParams notify.Params `json:"-"`
 
@@ -126,6 +177,11 @@ type Config struct {
 	CustomReviewPrompt string `json:"-"`
 	CustomEvalPrompt   string `json:"-"`
 	CodexReviewPrompt  string `json:"-"`
+	ReviewReportPrompt string `json:"-"`
+
+	// prompt templates: snippets of prompts/partials/ and the [prompt_vars] user variables
+	PromptPartials map[string]string `json:"-"`
+	PromptVars     map[string]string `json:"prompt_vars"`
 
 	// custom agents (loaded separately from files)
 	CustomAgents []CustomAgent `json:"-"`
@@ -289,6 +345,7 @@ func loadConfigFromDirs(globalDir, localDir string) (*Config, error) {
 	c := &Config{
 		ClaudeCommand:           values.ClaudeCommand,
 		ClaudeArgs:              values.ClaudeArgs,
+		AgentProvider:           values.AgentProvider,
 		PlanModel:               values.PlanModel,
 		TaskModel:               values.TaskModel,
 		ReviewModel:             values.ReviewModel,
@@ -304,10 +361,17 @@ func loadConfigFromDirs(globalDir, localDir string) (*Config, error) {
 		ExternalReviewTool:      values.ExternalReviewTool,
 		ExternalReviewToolSet:   values.ExternalReviewToolSet,
 		CustomReviewScript:      values.CustomReviewScript,
+		CustomReviewScripts:     values.CustomReviewScripts,
+		HTTPReviewBaseURL:       values.HTTPReviewBaseURL,
+		HTTPReviewModel:         values.HTTPReviewModel,
+		HTTPReviewAPIKeyEnv:     values.HTTPReviewAPIKeyEnv,
+		HTTPReviewChunkTokens:   values.HTTPReviewChunkTokens,
+		HTTPReviewIdleTimeout:   values.HTTPReviewIdleTimeout,
 		IterationDelayMs:        values.IterationDelayMs,
 		IterationDelayMsSet:     values.IterationDelayMsSet,
 		TaskRetryCount:          values.TaskRetryCount,
 		TaskRetryCountSet:       values.TaskRetryCountSet,
+		TaskEscalation:          values.TaskEscalation,
 		MaxIterations:           values.MaxIterations,
 		MaxIterationsSet:        values.MaxIterationsSet,
 		MaxExternalIterations:   values.MaxExternalIterations,
@@ -325,6 +389,15 @@ func loadConfigFromDirs(globalDir, localDir string) (*Config, error) {
 		VcsCommand:              values.VcsCommand,
 		CommitTrailer:           values.CommitTrailer,
 		WatchDirs:               values.WatchDirs,
+		Pipeline:                values.Pipeline,
+		FindingsExport:          values.FindingsExport,
+		WebToken:                values.WebToken,
+		WebUser:                 values.WebUser,
+		WebPassword:             values.WebPassword,
+		WebTLSCert:              values.WebTLSCert,
+		WebTLSKey:               values.WebTLSKey,
+		WebTLSSelfSigned:        values.WebTLSSelfSigned,
+		DaemonRepos:             values.DaemonRepos,
 		ClaudeErrorPatterns:     values.ClaudeErrorPatterns,
 		CodexErrorPatterns:      values.CodexErrorPatterns,
 		ClaudeLimitPatterns:     values.ClaudeLimitPatterns,
@@ -336,6 +409,23 @@ func loadConfigFromDirs(globalDir, localDir string) (*Config, error) {
 		SessionTimeoutSet:       values.SessionTimeoutSet,
 		IdleTimeout:             values.IdleTimeout,
 		IdleTimeoutSet:          values.IdleTimeoutSet,
+		ValidationEnabled:       values.ValidationEnabled,
+		ValidationTimeout:       values.ValidationTimeout,
+		ValidationRetryCount:    values.ValidationRetryCount,
+		ValidationRetryCountSet: values.ValidationRetryCountSet,
+		MaxRunCost:              values.MaxRunCost,
+		MaxRunTokens:            values.MaxRunTokens,
+		MaxPhaseCost:            values.MaxPhaseCost,
+		BudgetAction:            values.BudgetAction,
+		BudgetModel:             values.BudgetModel,
+		HookPrePhase:            values.HookPrePhase,
+		HookPostPhase:           values.HookPostPhase,
+		HookPreTask:             values.HookPreTask,
+		HookPostTask:            values.HookPostTask,
+		HookPreReview:           values.HookPreReview,
+		HookOnFailure:           values.HookOnFailure,
+		HookOnComplete:          values.HookOnComplete,
+		HookTimeout:             values.HookTimeout,
 		NotifyParams: notify.Params{
 			Channels:      values.NotifyChannels,
 			OnError:       values.NotifyOnError,
@@ -365,6 +455,9 @@ func loadConfigFromDirs(globalDir, localDir string) (*Config, error) {
 		CustomReviewPrompt: prompts.CustomReview,
 		CustomEvalPrompt:   prompts.CustomEval,
 		CodexReviewPrompt:  prompts.CodexReview,
+		ReviewReportPrompt: prompts.ReviewReport,
+		PromptPartials:     prompts.Partials,
+		PromptVars:         values.PromptVars,
 		CustomAgents:       agents,
 		configDir:          globalDir,
 		localDir:           localDir,
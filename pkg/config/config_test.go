@@ -30,11 +30,12 @@ func Test_defaultsFS_PromptFiles(t *testing.T) {
 		file     string
 		contains []string
 	}{
-		{file: "defaults/prompts/task.txt", contains: []string{"{{PLAN_FILE}}", "{{PROGRESS_FILE}}", "RALPHEX:ALL_TASKS_DONE", "RALPHEX:TASK_FAILED", "Success criteria", "Task sections", "### Task N:", "mark them [x]", "do not loop indefinitely"}},
+		{file: "defaults/prompts/task.txt", contains: []string{"{{PLAN_FILE}}", "{{PROGRESS_FILE}}", "RALPHEX:ALL_TASKS_DONE", "RALPHEX:TASK_FAILED", "Success criteria", "Task sections", "### Task N:", "mark them [x]", "do not loop indefinitely", "{{TASK_BODY}}", "{{COMPLETED_TASKS}}"}},
 		{file: "defaults/prompts/review_first.txt", contains: []string{"{{GOAL}}", "{{PROGRESS_FILE}}", "RALPHEX:REVIEW_DONE", "{{agent:quality}}", "{{agent:testing}}"}},
 		{file: "defaults/prompts/review_second.txt", contains: []string{"{{GOAL}}", "{{PROGRESS_FILE}}", "RALPHEX:REVIEW_DONE", "{{agent:quality}}", "{{agent:implementation}}"}},
 		{file: "defaults/prompts/codex.txt", contains: []string{"{{CODEX_OUTPUT}}", "RALPHEX:CODEX_REVIEW_DONE", "Codex reviewed"}},
 		{file: "defaults/prompts/codex_review.txt", contains: []string{"{{DIFF_INSTRUCTION}}", "{{PROGRESS_FILE}}", "{{PREVIOUS_REVIEW_CONTEXT}}", "{{PLAN_FILE}}"}},
+		{file: "defaults/prompts/review_report.txt", contains: []string{"{{GOAL}}", "{{REVIEW_AGENTS}}", "{{EXTERNAL_REVIEW}}", "RALPHEX:FINDINGS", "RALPHEX:TASK_FAILED"}},
 	}
 
 	for _, tc := range testCases {
@@ -59,6 +60,7 @@ func Test_defaultsFS_AllFilesPresent(t *testing.T) {
 		"defaults/prompts/review_second.txt",
 		"defaults/prompts/codex.txt",
 		"defaults/prompts/codex_review.txt",
+		"defaults/prompts/review_report.txt",
 	}
 
 	for _, file := range expectedFiles {
@@ -1014,6 +1016,56 @@ custom_review_script = /path/to/my-review.sh
 	assert.Equal(t, "/path/to/my-review.sh", cfg.CustomReviewScript)
 }
 
+func TestLoad_ExternalReviewToolList(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), "ralphex")
+	require.NoError(t, os.MkdirAll(configDir, 0o700))
+	configContent := `
+external_review_tool = codex, custom:gemini
+custom_review_scripts = gemini=/path/to/gemini.sh, opencode=/path/to/opencode.sh
+`
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte(configContent), 0o600))
+
+	cfg, err := Load(configDir)
+	require.NoError(t, err)
+	assert.Equal(t, "codex,custom:gemini", cfg.ExternalReviewTool)
+	assert.Equal(t, []string{"gemini=/path/to/gemini.sh", "opencode=/path/to/opencode.sh"}, cfg.CustomReviewScripts)
+
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte("external_review_tool = codex, none\n"), 0o600))
+	_, err = Load(configDir)
+	require.ErrorContains(t, err, "invalid external_review_tool")
+}
+
+func TestLoad_HTTPReview(t *testing.T) {
+	configDir := filepath.Join(t.TempDir(), "ralphex")
+	require.NoError(t, os.MkdirAll(configDir, 0o700))
+	configContent := `
+external_review_tool = http
+http_review_base_url = http://localhost:11434/v1/
+http_review_model = qwen2.5-coder:32b
+http_review_api_key_env = OLLAMA_KEY
+http_review_chunk_tokens = 8000
+http_review_idle_timeout = 90s
+`
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte(configContent), 0o600))
+
+	cfg, err := Load(configDir)
+	require.NoError(t, err)
+	assert.Equal(t, "http", cfg.ExternalReviewTool)
+	assert.Equal(t, "http://localhost:11434/v1", cfg.HTTPReviewBaseURL, "trailing slash trimmed")
+	assert.Equal(t, "qwen2.5-coder:32b", cfg.HTTPReviewModel)
+	assert.Equal(t, "OLLAMA_KEY", cfg.HTTPReviewAPIKeyEnv)
+	assert.Equal(t, 8000, cfg.HTTPReviewChunkTokens)
+	assert.Equal(t, 90*time.Second, cfg.HTTPReviewIdleTimeout)
+
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte("http_review_chunk_tokens = -1\n"), 0o600))
+	_, err = Load(configDir)
+	require.ErrorContains(t, err, "invalid http_review_chunk_tokens")
+
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte("http_review_idle_timeout = soon\n"), 0o600))
+	_, err = Load(configDir)
+	require.ErrorContains(t, err, "invalid http_review_idle_timeout")
+}
+
 func TestLoad_ExternalReviewToolDefaults(t *testing.T) {
 	tmpDir := t.TempDir()
 	configDir := filepath.Join(tmpDir, "ralphex")
@@ -1446,6 +1498,137 @@ func TestLocalConfig_LocalOverridesIdleTimeout(t *testing.T) {
 	assert.True(t, cfg.IdleTimeoutSet)
 }
 
+func TestLoad_ValidationGate(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, "ralphex")
+	require.NoError(t, os.MkdirAll(configDir, 0o700))
+
+	configContent := "validation_enabled = true\nvalidation_timeout = 90s\nvalidation_retry_count = 2"
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte(configContent), 0o600))
+
+	cfg, err := Load(configDir)
+	require.NoError(t, err)
+
+	assert.True(t, cfg.ValidationEnabled)
+	assert.Equal(t, 90*time.Second, cfg.ValidationTimeout)
+	assert.Equal(t, 2, cfg.ValidationRetryCount)
+	assert.True(t, cfg.ValidationRetryCountSet)
+}
+
+func TestLoad_ValidationGate_DefaultDisabled(t *testing.T) {
+	cfg, err := Load(t.TempDir())
+	require.NoError(t, err)
+
+	assert.False(t, cfg.ValidationEnabled)
+	assert.Zero(t, cfg.ValidationTimeout)
+	assert.False(t, cfg.ValidationRetryCountSet)
+}
+
+func TestLoad_BudgetLimits(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, "ralphex")
+	require.NoError(t, os.MkdirAll(configDir, 0o700))
+
+	configContent := "max_run_cost = 12.5\nmax_run_tokens = 2000000\nmax_phase_cost = 4\nbudget_action = downgrade\nbudget_model = sonnet:low"
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte(configContent), 0o600))
+
+	cfg, err := Load(configDir)
+	require.NoError(t, err)
+
+	assert.InDelta(t, 12.5, cfg.MaxRunCost, 1e-9)
+	assert.Equal(t, int64(2000000), cfg.MaxRunTokens)
+	assert.InDelta(t, 4.0, cfg.MaxPhaseCost, 1e-9)
+	assert.Equal(t, BudgetActionDowngrade, cfg.BudgetAction)
+	assert.Equal(t, "sonnet:low", cfg.BudgetModel)
+}
+
+func TestLoad_Pipeline(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, "ralphex")
+	require.NoError(t, os.MkdirAll(configDir, 0o700))
+
+	configContent := "pipeline = task, external, security_audit, finalize"
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte(configContent), 0o600))
+
+	cfg, err := Load(configDir)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"task", "external", "security_audit", "finalize"}, cfg.Pipeline)
+}
+
+func TestLoad_Daemon(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, "ralphex")
+	require.NoError(t, os.MkdirAll(configDir, 0o700))
+
+	configContent := "web_token = s3cret\ndaemon_repos = app=/srv/app, docs = /srv/docs"
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte(configContent), 0o600))
+
+	cfg, err := Load(configDir)
+	require.NoError(t, err)
+
+	assert.Equal(t, "s3cret", cfg.WebToken)
+	assert.Equal(t, []string{"app=/srv/app", "docs=/srv/docs"}, cfg.DaemonRepos)
+}
+
+func TestLoad_WebAccess(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, "ralphex")
+	require.NoError(t, os.MkdirAll(configDir, 0o700))
+
+	configContent := "web_user = team\nweb_password = hunter2\nweb_tls_cert = /etc/ralphex/cert.pem\n" +
+		"web_tls_key = /etc/ralphex/key.pem\nweb_tls_self_signed = true"
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte(configContent), 0o600))
+
+	cfg, err := Load(configDir)
+	require.NoError(t, err)
+
+	assert.Equal(t, "team", cfg.WebUser)
+	assert.Equal(t, "hunter2", cfg.WebPassword)
+	assert.Equal(t, "/etc/ralphex/cert.pem", cfg.WebTLSCert)
+	assert.Equal(t, "/etc/ralphex/key.pem", cfg.WebTLSKey)
+	assert.True(t, cfg.WebTLSSelfSigned)
+
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte("web_tls_self_signed = maybe"), 0o600))
+	_, err = Load(configDir)
+	require.ErrorContains(t, err, "invalid web_tls_self_signed")
+}
+
+func TestLoad_FindingsExport(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, "ralphex")
+	require.NoError(t, os.MkdirAll(configDir, 0o700))
+
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte("findings_export = SARIF, markdown,"), 0o600))
+	cfg, err := Load(configDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sarif", "markdown"}, cfg.FindingsExport)
+
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte("findings_export = sarif, pdf"), 0o600))
+	_, err = Load(configDir)
+	require.ErrorContains(t, err, "invalid findings_export")
+}
+
+func TestLoad_Hooks(t *testing.T) {
+	tmpDir := t.TempDir()
+	configDir := filepath.Join(tmpDir, "ralphex")
+	require.NoError(t, os.MkdirAll(configDir, 0o700))
+
+	configContent := "hook_pre_task = ./scripts/license-check.sh\nhook_post_phase = make coverage-gate\n" +
+		"hook_on_complete = ./deploy-preview.sh\nhook_timeout = 2m"
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config"), []byte(configContent), 0o600))
+
+	cfg, err := Load(configDir)
+	require.NoError(t, err)
+
+	assert.Equal(t, "./scripts/license-check.sh", cfg.HookPreTask)
+	assert.Equal(t, "make coverage-gate", cfg.HookPostPhase)
+	assert.Equal(t, "./deploy-preview.sh", cfg.HookOnComplete)
+	assert.Empty(t, cfg.HookPrePhase)
+	assert.Empty(t, cfg.HookOnFailure)
+	assert.Equal(t, 2*time.Minute, cfg.HookTimeout)
+}
+
 func TestConfig_CodexExecutorSandbox(t *testing.T) {
 	tests := []struct {
 		name string
@@ -1497,6 +1680,9 @@ func TestConfig_JSONShape(t *testing.T) {
 		WorktreeEnabled:         true,
 		PlansDir:                "docs/plans",
 		WatchDirs:               []string{"a", "b"},
+		Pipeline:                []string{"task", "finalize"},
+		WebToken:                "secret",
+		DaemonRepos:             []string{"app=/srv/app"},
 		DefaultBranch:           "main",
 		VcsCommand:              "git",
 		CommitTrailer:           "Co-authored-by: x <x@y>",
@@ -1508,6 +1694,22 @@ func TestConfig_JSONShape(t *testing.T) {
 		WaitOnLimit:             time.Hour,
 		SessionTimeout:          30 * time.Minute,
 		IdleTimeout:             5 * time.Minute,
+		ValidationEnabled:       true,
+		ValidationTimeout:       10 * time.Minute,
+		ValidationRetryCount:    3,
+		MaxRunCost:              10,
+		MaxRunTokens:            1000,
+		MaxPhaseCost:            2,
+		BudgetAction:            BudgetActionStop,
+		BudgetModel:             "haiku",
+		HookPrePhase:            "pre-phase",
+		HookPostPhase:           "post-phase",
+		HookPreTask:             "pre-task",
+		HookPostTask:            "post-task",
+		HookPreReview:           "pre-review",
+		HookOnFailure:           "on-failure",
+		HookOnComplete:          "on-complete",
+		HookTimeout:             time.Minute,
 	}
 
 	data, err := json.Marshal(c)
@@ -1517,15 +1719,22 @@ func TestConfig_JSONShape(t *testing.T) {
 	require.NoError(t, json.Unmarshal(data, &got))
 
 	wantKeys := []string{
-		"claude_command", "claude_args", "plan_model", "task_model", "review_model",
+		"claude_command", "claude_args", "plan_model", "task_model", "review_model", "agent_provider",
 		"codex_enabled", "codex_command", "codex_model", "codex_reasoning_effort",
-		"codex_timeout_ms", "codex_sandbox", "external_review_tool", "custom_review_script",
-		"iteration_delay_ms", "task_retry_count", "max_iterations", "max_external_iterations",
+		"codex_timeout_ms", "codex_sandbox", "external_review_tool", "custom_review_script", "custom_review_scripts",
+		"http_review_base_url", "http_review_model", "http_review_api_key_env", "http_review_chunk_tokens",
+		"http_review_idle_timeout",
+		"iteration_delay_ms", "task_retry_count", "task_escalation", "max_iterations", "max_external_iterations",
 		"review_patience", "finalize_enabled", "preserve_anthropic_api_key", "executor",
 		"pass_claude_md", "move_plan_on_completion", "worktree_enabled", "plans_dir",
-		"watch_dirs", "default_branch", "vcs_command", "commit_trailer",
+		"watch_dirs", "pipeline", "findings_export", "web_user", "web_tls_cert", "web_tls_key", "web_tls_self_signed",
+		"daemon_repos", "default_branch", "vcs_command", "commit_trailer",
 		"claude_error_patterns", "codex_error_patterns", "claude_limit_patterns",
 		"codex_limit_patterns", "claude_retry_patterns", "wait_on_limit", "session_timeout", "idle_timeout",
+		"validation_enabled", "validation_timeout", "validation_retry_count",
+		"max_run_cost", "max_run_tokens", "max_phase_cost", "budget_action", "budget_model",
+		"hook_pre_phase", "hook_post_phase", "hook_pre_task", "hook_post_task", "hook_pre_review",
+		"hook_on_failure", "hook_on_complete", "hook_timeout", "prompt_vars",
 	}
 
 	gotKeys := make([]string, 0, len(got))
@@ -1541,7 +1750,8 @@ func TestConfig_JSONShape(t *testing.T) {
 	assert.JSONEq(t, `["r1"]`, string(got["claude_retry_patterns"]))
 
 	// the *Set sentinels and the loaded-from-files fields carry json:"-" and must be absent
-	for _, absent := range []string{"claude_args_set", "wait_on_limit_set", "notify_params", "colors", "task_prompt"} {
+	for _, absent := range []string{"claude_args_set", "wait_on_limit_set", "notify_params", "colors", "task_prompt", "web_token",
+		"web_password"} {
 		_, present := got[absent]
 		assert.False(t, present, "unexpected json key %q present", absent)
 	}
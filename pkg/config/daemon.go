@@ -0,0 +1,20 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseDaemonRepo splits a "name=path" repository registration of serve --daemon.
+// a leading ~ in the path is expanded to the home directory.
+func ParseDaemonRepo(s string) (name, path string, err error) {
+	name, path, ok := strings.Cut(s, "=")
+	name, path = strings.TrimSpace(name), strings.TrimSpace(path)
+	if !ok || name == "" || path == "" {
+		return "", "", fmt.Errorf("invalid daemon repo %q, expected name=path", s)
+	}
+	if strings.ContainsAny(name, "/\\ ") {
+		return "", "", fmt.Errorf("invalid daemon repo name %q: must not contain slashes or spaces", name)
+	}
+	return name, expandTilde(path), nil
+}
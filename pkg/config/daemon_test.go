@@ -0,0 +1,42 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDaemonRepo(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	tests := []struct {
+		in       string
+		wantName string
+		wantPath string
+		wantErr  string
+	}{
+		{in: "app=/srv/app", wantName: "app", wantPath: "/srv/app"},
+		{in: " app = /srv/my app ", wantName: "app", wantPath: "/srv/my app"},
+		{in: "app=~/src/app", wantName: "app", wantPath: filepath.Join(home, "src/app")},
+		{in: "app", wantErr: "expected name=path"},
+		{in: "=/srv/app", wantErr: "expected name=path"},
+		{in: "app=", wantErr: "expected name=path"},
+		{in: "my app=/srv/app", wantErr: "daemon repo name"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			name, path, err := ParseDaemonRepo(tc.in)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantName, name)
+			assert.Equal(t, tc.wantPath, path)
+		})
+	}
+}
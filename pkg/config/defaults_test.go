@@ -255,7 +255,7 @@ func TestDefaultsInstaller_installDefaultFiles_Prompts(t *testing.T) {
 	installer := &defaultsInstaller{embedFS: defaultsFS}
 	require.NoError(t, installer.installDefaultFiles(promptsDir, "defaults/prompts", "prompt"))
 
-	expectedPrompts := []string{"task.txt", "review_first.txt", "review_second.txt", "codex.txt", "make_plan.txt", "finalize.txt", "custom_review.txt", "custom_eval.txt", "codex_review.txt"}
+	expectedPrompts := []string{"task.txt", "review_first.txt", "review_second.txt", "codex.txt", "make_plan.txt", "finalize.txt", "custom_review.txt", "custom_eval.txt", "codex_review.txt", "review_report.txt"}
 	for _, prompt := range expectedPrompts {
 		promptPath := filepath.Join(promptsDir, prompt)
 		assert.FileExists(t, promptPath, "prompt file %s should be installed", prompt)
@@ -297,7 +297,7 @@ func TestDefaultsInstaller_Install_InstallsPromptFiles(t *testing.T) {
 	require.NoError(t, installer.Install(configDir))
 
 	promptsDir := filepath.Join(configDir, "prompts")
-	expectedPrompts := []string{"task.txt", "review_first.txt", "review_second.txt", "codex.txt", "make_plan.txt", "finalize.txt", "custom_review.txt", "custom_eval.txt", "codex_review.txt"}
+	expectedPrompts := []string{"task.txt", "review_first.txt", "review_second.txt", "codex.txt", "make_plan.txt", "finalize.txt", "custom_review.txt", "custom_eval.txt", "codex_review.txt", "review_report.txt"}
 
 	for _, prompt := range expectedPrompts {
 		promptPath := filepath.Join(promptsDir, prompt)
@@ -1302,7 +1302,7 @@ func TestInitLocal(t *testing.T) {
 		// verify .gitignore for runtime artifacts
 		igData, err := os.ReadFile(filepath.Join(localDir, ".gitignore")) //nolint:gosec // test
 		require.NoError(t, err)
-		assert.Equal(t, ".gitignore\nprogress/\nworktrees/\n", string(igData))
+		assert.Equal(t, ".gitignore\nprogress/\nworktrees/\nfindings/\n", string(igData))
 	})
 
 	t.Run("second call preserves existing customized files", func(t *testing.T) {
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// stageNameRe matches a pipeline stage name; custom stage names double as prompt file names.
+var stageNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ParsePipeline splits a comma-separated pipeline definition into stage names.
+// only the syntax is checked here; which stages exist is up to the processor.
+func ParsePipeline(s string) ([]string, error) {
+	var stages []string
+	for p := range strings.SplitSeq(s, ",") {
+		name := strings.TrimSpace(p)
+		if name == "" {
+			continue
+		}
+		if !stageNameRe.MatchString(name) {
+			return nil, fmt.Errorf("invalid pipeline stage %q: use lowercase letters, digits, '_' and '-'", name)
+		}
+		stages = append(stages, name)
+	}
+	if len(stages) == 0 && strings.TrimSpace(s) != "" {
+		return nil, fmt.Errorf("invalid pipeline %q: no stages", s)
+	}
+	return stages, nil
+}
+
+// StagePrompt loads the prompt of a custom pipeline stage from prompts/<name>.txt,
+// looking in the local project config first, then in the global config directory.
+func (c *Config) StagePrompt(name string) (string, error) {
+	if !stageNameRe.MatchString(name) {
+		return "", fmt.Errorf("invalid pipeline stage %q", name)
+	}
+	pl := newPromptLoader(defaultsFS)
+	for _, dir := range []string{c.localDir, c.configDir} {
+		if dir == "" {
+			continue
+		}
+		content, err := pl.loadPromptFile(filepath.Join(dir, "prompts", name+".txt"))
+		if err != nil {
+			return "", err
+		}
+		if content != "" {
+			return content, nil
+		}
+	}
+	return "", fmt.Errorf("no prompts/%s.txt found for pipeline stage %q", name, name)
+}
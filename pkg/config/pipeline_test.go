@@ -0,0 +1,65 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePipeline(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr string
+	}{
+		{name: "empty", input: "", want: nil},
+		{name: "stages with spaces", input: " task, review_first ,external,finalize",
+			want: []string{"task", "review_first", "external", "finalize"}},
+		{name: "repeated and custom", input: "review_first,security-audit,review_first",
+			want: []string{"review_first", "security-audit", "review_first"}},
+		{name: "empty items skipped", input: "task,,finalize", want: []string{"task", "finalize"}},
+		{name: "only separators", input: " , ", wantErr: "no stages"},
+		{name: "path traversal", input: "task,../evil", wantErr: "invalid pipeline stage"},
+		{name: "upper case", input: "Task", wantErr: "invalid pipeline stage"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParsePipeline(tc.input)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestConfig_StagePrompt(t *testing.T) {
+	globalDir, localDir := t.TempDir(), t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(globalDir, "prompts"), 0o700))
+	require.NoError(t, os.MkdirAll(filepath.Join(localDir, "prompts"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "prompts", "audit.txt"), []byte("global audit"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "prompts", "docs.txt"), []byte("global docs"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "prompts", "audit.txt"), []byte("local audit"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "prompts", "docs.txt"), []byte("# only\n# comments\n"), 0o600))
+	c := &Config{configDir: globalDir, localDir: localDir}
+
+	got, err := c.StagePrompt("audit")
+	require.NoError(t, err)
+	assert.Equal(t, "local audit", got, "local prompt wins")
+
+	got, err = c.StagePrompt("docs")
+	require.NoError(t, err)
+	assert.Equal(t, "global docs", got, "all-comment local prompt falls back to global")
+
+	_, err = c.StagePrompt("missing")
+	require.ErrorContains(t, err, "no prompts/missing.txt")
+
+	_, err = c.StagePrompt("../audit")
+	require.ErrorContains(t, err, "invalid pipeline stage")
+}
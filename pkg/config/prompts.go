@@ -22,6 +22,8 @@ type Prompts struct {
 	CustomReview string
 	CustomEval   string
 	CodexReview  string
+	ReviewReport string
+	Partials     map[string]string // shared snippets of prompts/partials/<name>.txt, by name
 }
 
 // promptLoader implements PromptLoader with embedded filesystem fallback.
@@ -84,9 +86,48 @@ func (p *promptLoader) Load(localDir, globalDir string) (Prompts, error) {
 		return Prompts{}, fmt.Errorf("load codex_review prompt: %w", err)
 	}
 
+	prompts.ReviewReport, err = p.loadPromptWithLocalFallback(localDir, globalDir, reviewReportPromptFile)
+	if err != nil {
+		return Prompts{}, fmt.Errorf("load review_report prompt: %w", err)
+	}
+
+	prompts.Partials, err = p.loadPartials(localDir, globalDir)
+	if err != nil {
+		return Prompts{}, fmt.Errorf("load prompt partials: %w", err)
+	}
+
 	return prompts, nil
 }
 
+// loadPartials loads the prompt snippets of the partials/ subdirectory, included by prompt templates
+// as {{template "name" .}}. a local partial replaces the global one of the same name.
+func (p *promptLoader) loadPartials(localDir, globalDir string) (map[string]string, error) {
+	var partials map[string]string
+	for _, dir := range []string{globalDir, localDir} {
+		if dir == "" {
+			continue
+		}
+		files, err := filepath.Glob(filepath.Join(dir, "partials", "*.txt"))
+		if err != nil {
+			return nil, fmt.Errorf("list partials: %w", err)
+		}
+		for _, f := range files {
+			content, err := p.loadPromptFile(f)
+			if err != nil {
+				return nil, err
+			}
+			if content == "" {
+				continue
+			}
+			if partials == nil {
+				partials = map[string]string{}
+			}
+			partials[strings.TrimSuffix(filepath.Base(f), ".txt")] = content
+		}
+	}
+	return partials, nil
+}
+
 // loadPromptWithLocalFallback loads a prompt file with fallback chain: local → global → embedded.
 // localDir can be empty to skip local lookup.
 func (p *promptLoader) loadPromptWithLocalFallback(localDir, globalDir, filename string) (string, error) {
@@ -116,6 +116,28 @@ func TestPromptLoader_Load_LocalOverridesGlobal(t *testing.T) {
 	assert.Equal(t, "global review first", prompts.ReviewFirst)
 }
 
+func TestPromptLoader_Load_Partials(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalDir := filepath.Join(tmpDir, "global", "prompts")
+	localDir := filepath.Join(tmpDir, "local", "prompts")
+	require.NoError(t, os.MkdirAll(filepath.Join(globalDir, "partials"), 0o700))
+	require.NoError(t, os.MkdirAll(filepath.Join(localDir, "partials"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "partials", "commit.txt"), []byte("global commit rules\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "partials", "tests.txt"), []byte("run the tests"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(globalDir, "partials", "notes.md"), []byte("not a partial"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "partials", "commit.txt"), []byte("local commit rules"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(localDir, "partials", "empty.txt"), []byte("# comment\n# only\n"), 0o600))
+
+	loader := newPromptLoader(defaultsFS)
+	prompts, err := loader.Load(localDir, globalDir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"commit": "local commit rules", "tests": "run the tests"}, prompts.Partials)
+
+	prompts, err = loader.Load("", filepath.Join(tmpDir, "missing"))
+	require.NoError(t, err)
+	assert.Nil(t, prompts.Partials)
+}
+
 func TestPromptLoader_Load_LocalFallbackToEmbedded(t *testing.T) {
 	tmpDir := t.TempDir()
 	globalDir := filepath.Join(tmpDir, "global", "prompts")
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CustomToolPrefix prefixes a custom review tool named in custom_review_scripts, e.g. custom:gemini.
+const CustomToolPrefix = "custom:"
+
+// ParseExternalReviewTools splits a comma-separated external_review_tool value into tool names.
+// accepted tools are codex, http (http_review_* settings), custom (custom_review_script) and
+// custom:<name> (custom_review_scripts);
+// none disables external review and can't be combined with other tools.
+func ParseExternalReviewTools(s string) ([]string, error) {
+	var tools []string
+	for p := range strings.SplitSeq(s, ",") {
+		tool := strings.TrimSpace(p)
+		if tool == "" {
+			continue
+		}
+		name, named := strings.CutPrefix(tool, CustomToolPrefix)
+		switch {
+		case tool == "codex" || tool == "http" || tool == "custom" || tool == "none":
+		case named && stageNameRe.MatchString(name):
+		default:
+			return nil, fmt.Errorf("invalid external review tool %q: use codex, http, custom, custom:<name> or none", tool)
+		}
+		if slices.Contains(tools, tool) {
+			return nil, fmt.Errorf("external review tool %q listed twice", tool)
+		}
+		tools = append(tools, tool)
+	}
+	if len(tools) > 1 && slices.Contains(tools, "none") {
+		return nil, fmt.Errorf("invalid external review tools %q: none can't be combined with other tools", s)
+	}
+	return tools, nil
+}
+
+// ParseCustomReviewScript splits a "name=path" entry of custom_review_scripts.
+// a leading ~ in the path is expanded to the home directory.
+func ParseCustomReviewScript(s string) (name, path string, err error) {
+	name, path, ok := strings.Cut(s, "=")
+	name, path = strings.TrimSpace(name), strings.TrimSpace(path)
+	if !ok || name == "" || path == "" {
+		return "", "", fmt.Errorf("invalid custom review script %q, expected name=path", s)
+	}
+	if !stageNameRe.MatchString(name) {
+		return "", "", fmt.Errorf("invalid custom review script name %q: use lowercase letters, digits, '_' and '-'", name)
+	}
+	return name, expandTilde(path), nil
+}
+
+// CustomReviewScriptFor returns the script of a custom:<name> external review tool, empty when not configured.
+func (c *Config) CustomReviewScriptFor(name string) string {
+	for _, entry := range c.CustomReviewScripts {
+		if n, path, ok := strings.Cut(entry, "="); ok && n == name {
+			return path
+		}
+	}
+	return ""
+}
+
+// ExternalReviewTools returns the configured external review tools and checks that every
+// custom:<name> tool has a script in custom_review_scripts and the http tool has an endpoint and model.
+func (c *Config) ExternalReviewTools() ([]string, error) {
+	tools, err := ParseExternalReviewTools(c.ExternalReviewTool)
+	if err != nil {
+		return nil, err
+	}
+	for _, tool := range tools {
+		if name, ok := strings.CutPrefix(tool, CustomToolPrefix); ok && c.CustomReviewScriptFor(name) == "" {
+			return nil, fmt.Errorf("external review tool %q has no script in custom_review_scripts", tool)
+		}
+		if tool == "http" && (c.HTTPReviewBaseURL == "" || c.HTTPReviewModel == "") {
+			return nil, errors.New("external review tool http needs http_review_base_url and http_review_model")
+		}
+	}
+	return tools, nil
+}
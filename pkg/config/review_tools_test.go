@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExternalReviewTools(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr string
+	}{
+		{in: "codex", want: []string{"codex"}},
+		{in: "none", want: []string{"none"}},
+		{in: "", want: nil},
+		{in: " codex, custom:opencode ,custom:gemini,", want: []string{"codex", "custom:opencode", "custom:gemini"}},
+		{in: "custom,custom:gemini", want: []string{"custom", "custom:gemini"}},
+		{in: "http, codex", want: []string{"http", "codex"}},
+		{in: "claude", wantErr: `invalid external review tool "claude"`},
+		{in: "custom:", wantErr: `invalid external review tool "custom:"`},
+		{in: "custom:Gemini", wantErr: `invalid external review tool "custom:Gemini"`},
+		{in: "codex,codex", wantErr: `external review tool "codex" listed twice`},
+		{in: "codex,none", wantErr: "none can't be combined"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := ParseExternalReviewTools(tc.in)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestParseCustomReviewScript(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	name, path, err := ParseCustomReviewScript(" gemini = ~/scripts/gemini.sh ")
+	require.NoError(t, err)
+	assert.Equal(t, "gemini", name)
+	assert.Equal(t, filepath.Join(home, "scripts/gemini.sh"), path)
+
+	_, _, err = ParseCustomReviewScript("gemini")
+	require.ErrorContains(t, err, "expected name=path")
+	_, _, err = ParseCustomReviewScript("my review=/x.sh")
+	require.ErrorContains(t, err, `invalid custom review script name "my review"`)
+}
+
+func TestConfig_ExternalReviewTools(t *testing.T) {
+	c := &Config{ExternalReviewTool: "codex,custom:gemini", CustomReviewScripts: []string{"gemini=/s/gemini.sh"}}
+	tools, err := c.ExternalReviewTools()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"codex", "custom:gemini"}, tools)
+	assert.Equal(t, "/s/gemini.sh", c.CustomReviewScriptFor("gemini"))
+	assert.Empty(t, c.CustomReviewScriptFor("opencode"))
+
+	c.ExternalReviewTool = "custom:opencode"
+	_, err = c.ExternalReviewTools()
+	require.ErrorContains(t, err, `external review tool "custom:opencode" has no script in custom_review_scripts`)
+
+	c.ExternalReviewTool = "http"
+	c.HTTPReviewBaseURL = "http://localhost:11434/v1"
+	_, err = c.ExternalReviewTools()
+	require.ErrorContains(t, err, "external review tool http needs http_review_base_url and http_review_model")
+	c.HTTPReviewModel = "qwen"
+	tools, err = c.ExternalReviewTools()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http"}, tools)
+}
@@ -3,11 +3,15 @@ package config
 import (
 	"embed"
 	"fmt"
+	"math"
 	"os"
+	"regexp"
 	"strings"
 	"time"
 
 	"gopkg.in/ini.v1"
+
+	"github.com/umputun/ralphex/pkg/findings"
 )
 
 // Values holds scalar configuration values.
@@ -17,6 +21,7 @@ import (
 type Values struct {
 	ClaudeCommand              string
 	ClaudeArgs                 string
+	AgentProvider              string // path to a provider file running an agent CLI instead of claude_command
 	PlanModel                  string // model for plan creation (falls back to TaskModel if empty)
 	TaskModel                  string // model for task execution (e.g., "fable", "opus", "sonnet", "haiku")
 	ReviewModel                string // model for review phases (falls back to TaskModel if empty)
@@ -42,13 +47,43 @@ type Values struct {
 	SessionTimeoutSet          bool          // tracks if session_timeout was explicitly set
 	IdleTimeout                time.Duration // kill session after no output for this duration
 	IdleTimeoutSet             bool          // tracks if idle_timeout was explicitly set
-	ExternalReviewTool         string        // "codex", "custom", or "none"
+	ExternalReviewTool         string        // comma-separated tools: "codex", "http", "custom", "custom:<name>", or "none"
 	ExternalReviewToolSet      bool          // tracks if external_review_tool was explicitly set in user config (not embedded default)
 	CustomReviewScript         string        // path to custom review script (when ExternalReviewTool = "custom")
+	CustomReviewScripts        []string      // name=path scripts of custom:<name> external review tools
+	HTTPReviewBaseURL          string        // OpenAI-compatible API base URL of the http review tool
+	HTTPReviewModel            string        // model of the http review tool
+	HTTPReviewAPIKeyEnv        string        // env var holding the API key of the http review tool
+	HTTPReviewChunkTokens      int           // approximate diff tokens per http review request
+	HTTPReviewIdleTimeout      time.Duration // abort an http review request receiving no data for this duration
 	IterationDelayMs           int
 	IterationDelayMsSet        bool // tracks if iteration_delay_ms was explicitly set
 	TaskRetryCount             int
-	TaskRetryCountSet          bool // tracks if task_retry_count was explicitly set
+	TaskRetryCountSet          bool     // tracks if task_retry_count was explicitly set
+	TaskEscalation             []string // model[:effort] specs of the retries of a failed task, in order
+	ValidationEnabled          bool
+	ValidationEnabledSet       bool          // tracks if validation_enabled was explicitly set
+	ValidationTimeout          time.Duration // per-command timeout for plan validation commands
+	ValidationTimeoutSet       bool          // tracks if validation_timeout was explicitly set
+	ValidationRetryCount       int
+	ValidationRetryCountSet    bool // tracks if validation_retry_count was explicitly set
+	MaxRunCost                 float64
+	MaxRunCostSet              bool // tracks if max_run_cost was explicitly set
+	MaxRunTokens               int64
+	MaxRunTokensSet            bool // tracks if max_run_tokens was explicitly set
+	MaxPhaseCost               float64
+	MaxPhaseCostSet            bool   // tracks if max_phase_cost was explicitly set
+	BudgetAction               string // "stop" or "downgrade" when a budget limit is crossed ("" = stop)
+	BudgetModel                string // model[:effort] spec switched to by budget_action = downgrade
+	HookPrePhase               string // shell command run when a phase starts
+	HookPostPhase              string // shell command run when a phase ends
+	HookPreTask                string // shell command run before every task iteration
+	HookPostTask               string // shell command run after every successful task iteration
+	HookPreReview              string // shell command run when a review phase starts
+	HookOnFailure              string // shell command run when the run fails
+	HookOnComplete             string // shell command run when the run completes
+	HookTimeout                time.Duration
+	HookTimeoutSet             bool // tracks if hook_timeout was explicitly set
 	MaxIterations              int
 	MaxIterationsSet           bool // tracks if max_iterations was explicitly set
 	MaxExternalIterations      int  // override external review iteration limit (0 = auto)
@@ -70,6 +105,19 @@ type Values struct {
 	PlansDir                   string
 	DefaultBranch              string   // override auto-detected default branch
 	WatchDirs                  []string // directories to watch for progress files
+	Pipeline                   []string // stage order of a default run (empty = built-in preset)
+	FindingsExport             []string // formats the findings ledger is exported to at the end of a run
+	WebToken                   string   // token required by the web API
+	WebUser                    string   // basic auth user of the web dashboard
+	WebPassword                string   // basic auth password of the web dashboard
+	WebTLSCert                 string   // certificate file for HTTPS
+	WebTLSKey                  string   // private key file for HTTPS
+	WebTLSSelfSigned           bool     // serve HTTPS with a generated self-signed certificate
+	WebTLSSelfSignedSet        bool     // tracks if web_tls_self_signed was explicitly set
+	DaemonRepos                []string // name=path repositories accepted by serve --daemon
+
+	// user-defined prompt template variables from the [prompt_vars] section, {{.Vars.name}} in prompts
+	PromptVars map[string]string
 
 	// notification settings
 	NotifyChannels        []string // channels to use: telegram, email, webhook, slack, custom
@@ -203,6 +251,9 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 	if key, err := section.GetKey("claude_args"); err == nil {
 		values.ClaudeArgs = key.String()
 	}
+	if key, err := section.GetKey("agent_provider"); err == nil {
+		values.AgentProvider = expandTilde(key.String())
+	}
 	if key, err := section.GetKey("plan_model"); err == nil {
 		values.PlanModel = key.String()
 	}
@@ -251,12 +302,47 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 
 	// external review settings
 	if key, err := section.GetKey("external_review_tool"); err == nil {
-		values.ExternalReviewTool = key.String()
+		tools, toolsErr := ParseExternalReviewTools(key.String())
+		if toolsErr != nil {
+			return Values{}, fmt.Errorf("invalid external_review_tool: %w", toolsErr)
+		}
+		values.ExternalReviewTool = strings.Join(tools, ",")
 		values.ExternalReviewToolSet = true
 	}
 	if key, err := section.GetKey("custom_review_script"); err == nil {
 		values.CustomReviewScript = expandTilde(key.String())
 	}
+	for _, entry := range vl.parseCommaSeparated(section, "custom_review_scripts") {
+		name, path, scriptErr := ParseCustomReviewScript(entry)
+		if scriptErr != nil {
+			return Values{}, scriptErr
+		}
+		values.CustomReviewScripts = append(values.CustomReviewScripts, name+"="+path)
+	}
+	if key, err := section.GetKey("http_review_base_url"); err == nil {
+		values.HTTPReviewBaseURL = strings.TrimRight(key.String(), "/")
+	}
+	if key, err := section.GetKey("http_review_model"); err == nil {
+		values.HTTPReviewModel = key.String()
+	}
+	if key, err := section.GetKey("http_review_api_key_env"); err == nil {
+		values.HTTPReviewAPIKeyEnv = key.String()
+	}
+	if key, err := section.GetKey("http_review_chunk_tokens"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return Values{}, fmt.Errorf("invalid http_review_chunk_tokens: %w", intErr)
+		}
+		if val < 0 {
+			return Values{}, fmt.Errorf("invalid http_review_chunk_tokens: must be non-negative, got %d", val)
+		}
+		values.HTTPReviewChunkTokens = val
+	}
+	if d, ok, err := vl.parseDurationKey(section, "http_review_idle_timeout"); err != nil {
+		return Values{}, err
+	} else if ok {
+		values.HTTPReviewIdleTimeout = d
+	}
 
 	// timing settings
 	if key, err := section.GetKey("iteration_delay_ms"); err == nil {
@@ -281,6 +367,14 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 		values.TaskRetryCount = val
 		values.TaskRetryCountSet = true
 	}
+	// task escalation model specs (comma-separated)
+	if key, err := section.GetKey("task_escalation"); err == nil {
+		for spec := range strings.SplitSeq(key.String(), ",") {
+			if spec = strings.TrimSpace(spec); spec != "" {
+				values.TaskEscalation = append(values.TaskEscalation, spec)
+			}
+		}
+	}
 	if key, err := section.GetKey("max_iterations"); err == nil {
 		val, intErr := key.Int()
 		if intErr != nil {
@@ -391,6 +485,62 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 	// watch directories (comma-separated)
 	values.WatchDirs = vl.parseCommaSeparated(section, "watch_dirs")
 
+	if key, err := section.GetKey("web_token"); err == nil {
+		values.WebToken = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("web_user"); err == nil {
+		values.WebUser = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("web_password"); err == nil {
+		values.WebPassword = strings.TrimSpace(key.String())
+	}
+	if key, err := section.GetKey("web_tls_cert"); err == nil {
+		values.WebTLSCert = expandTilde(strings.TrimSpace(key.String()))
+	}
+	if key, err := section.GetKey("web_tls_key"); err == nil {
+		values.WebTLSKey = expandTilde(strings.TrimSpace(key.String()))
+	}
+	if key, err := section.GetKey("web_tls_self_signed"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return Values{}, fmt.Errorf("invalid web_tls_self_signed: %w", boolErr)
+		}
+		values.WebTLSSelfSigned = val
+		values.WebTLSSelfSignedSet = true
+	}
+
+	// daemon repositories (comma-separated name=path)
+	for _, repo := range vl.parseCommaSeparated(section, "daemon_repos") {
+		name, path, repoErr := ParseDaemonRepo(repo)
+		if repoErr != nil {
+			return Values{}, repoErr
+		}
+		values.DaemonRepos = append(values.DaemonRepos, name+"="+path)
+	}
+
+	// pipeline stages (comma-separated)
+	if key, err := section.GetKey("pipeline"); err == nil {
+		stages, pipelineErr := ParsePipeline(key.String())
+		if pipelineErr != nil {
+			return Values{}, pipelineErr
+		}
+		values.Pipeline = stages
+	}
+
+	// findings export formats (comma-separated)
+	if key, err := section.GetKey("findings_export"); err == nil {
+		for p := range strings.SplitSeq(key.String(), ",") {
+			if strings.TrimSpace(p) == "" {
+				continue
+			}
+			format, formatErr := findings.ParseFormat(p)
+			if formatErr != nil {
+				return Values{}, fmt.Errorf("invalid findings_export: %w", formatErr)
+			}
+			values.FindingsExport = append(values.FindingsExport, string(format))
+		}
+	}
+
 	// notification settings
 	if err := vl.parseNotifyValues(section, &values); err != nil {
 		return Values{}, err
@@ -429,9 +579,150 @@ func (vl *valuesLoader) parseValuesFromBytes(data []byte) (Values, error) {
 		values.IdleTimeoutSet = true
 	}
 
+	// validation gate settings
+	if err := vl.parseValidationValues(section, &values); err != nil {
+		return Values{}, err
+	}
+
+	// budget limits
+	if err := vl.parseBudgetValues(section, &values); err != nil {
+		return Values{}, err
+	}
+
+	// lifecycle hooks
+	if err := vl.parseHookValues(section, &values); err != nil {
+		return Values{}, err
+	}
+
+	// user-defined prompt variables
+	if err := vl.parsePromptVars(cfg.Section("prompt_vars"), &values); err != nil {
+		return Values{}, err
+	}
+
 	return values, nil
 }
 
+// promptVarNameRe matches the names allowed in [prompt_vars], usable as {{.Vars.name}} in prompt templates
+var promptVarNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// parsePromptVars parses the user-defined prompt variables of the [prompt_vars] section.
+func (vl *valuesLoader) parsePromptVars(section *ini.Section, values *Values) error {
+	for _, key := range section.Keys() {
+		if !promptVarNameRe.MatchString(key.Name()) {
+			return fmt.Errorf("invalid prompt_vars name %q, use letters, digits and underscores", key.Name())
+		}
+		if values.PromptVars == nil {
+			values.PromptVars = map[string]string{}
+		}
+		values.PromptVars[key.Name()] = strings.TrimSpace(key.String())
+	}
+	return nil
+}
+
+// parseValidationValues parses the harness validation gate settings.
+func (vl *valuesLoader) parseValidationValues(section *ini.Section, values *Values) error {
+	if key, err := section.GetKey("validation_enabled"); err == nil {
+		val, boolErr := key.Bool()
+		if boolErr != nil {
+			return fmt.Errorf("invalid validation_enabled: %w", boolErr)
+		}
+		values.ValidationEnabled = val
+		values.ValidationEnabledSet = true
+	}
+	if d, ok, err := vl.parseDurationKey(section, "validation_timeout"); err != nil {
+		return err
+	} else if ok {
+		values.ValidationTimeout = d
+		values.ValidationTimeoutSet = true
+	}
+	if key, err := section.GetKey("validation_retry_count"); err == nil {
+		val, intErr := key.Int()
+		if intErr != nil {
+			return fmt.Errorf("invalid validation_retry_count: %w", intErr)
+		}
+		if val < 0 {
+			return fmt.Errorf("invalid validation_retry_count: must be non-negative, got %d", val)
+		}
+		values.ValidationRetryCount = val
+		values.ValidationRetryCountSet = true
+	}
+	return nil
+}
+
+// parseBudgetValues parses run budget limits and the action taken when one is crossed.
+func (vl *valuesLoader) parseBudgetValues(section *ini.Section, values *Values) error {
+	for _, k := range []struct {
+		name string
+		dst  *float64
+		set  *bool
+	}{
+		{name: "max_run_cost", dst: &values.MaxRunCost, set: &values.MaxRunCostSet},
+		{name: "max_phase_cost", dst: &values.MaxPhaseCost, set: &values.MaxPhaseCostSet},
+	} {
+		key, err := section.GetKey(k.name)
+		if err != nil {
+			continue
+		}
+		val, floatErr := key.Float64()
+		if floatErr != nil {
+			return fmt.Errorf("invalid %s: %w", k.name, floatErr)
+		}
+		if val < 0 || math.IsNaN(val) || math.IsInf(val, 0) {
+			return fmt.Errorf("invalid %s: must be a non-negative number, got %v", k.name, val)
+		}
+		*k.dst, *k.set = val, true
+	}
+	if key, err := section.GetKey("max_run_tokens"); err == nil {
+		val, intErr := key.Int64()
+		if intErr != nil {
+			return fmt.Errorf("invalid max_run_tokens: %w", intErr)
+		}
+		if val < 0 {
+			return fmt.Errorf("invalid max_run_tokens: must be non-negative, got %d", val)
+		}
+		values.MaxRunTokens = val
+		values.MaxRunTokensSet = true
+	}
+	if key, err := section.GetKey("budget_action"); err == nil {
+		v := strings.TrimSpace(key.String())
+		if v != "" && v != BudgetActionStop && v != BudgetActionDowngrade {
+			return fmt.Errorf("invalid budget_action %q: must be %q or %q", v, BudgetActionStop, BudgetActionDowngrade)
+		}
+		values.BudgetAction = v
+	}
+	if key, err := section.GetKey("budget_model"); err == nil {
+		values.BudgetModel = strings.TrimSpace(key.String())
+	}
+	return nil
+}
+
+// parseHookValues parses lifecycle hook commands and their timeout.
+func (vl *valuesLoader) parseHookValues(section *ini.Section, values *Values) error {
+	for _, k := range []struct {
+		name string
+		dst  *string
+	}{
+		{name: "hook_pre_phase", dst: &values.HookPrePhase},
+		{name: "hook_post_phase", dst: &values.HookPostPhase},
+		{name: "hook_pre_task", dst: &values.HookPreTask},
+		{name: "hook_post_task", dst: &values.HookPostTask},
+		{name: "hook_pre_review", dst: &values.HookPreReview},
+		{name: "hook_on_failure", dst: &values.HookOnFailure},
+		{name: "hook_on_complete", dst: &values.HookOnComplete},
+	} {
+		if key, err := section.GetKey(k.name); err == nil {
+			*k.dst = strings.TrimSpace(key.String())
+		}
+	}
+	if d, ok, err := vl.parseDurationKey(section, "hook_timeout"); err != nil {
+		return err
+	} else if ok {
+		values.HookTimeout = d
+		values.HookTimeoutSet = true
+	}
+	return nil
+}
+
 // parseDurationKey parses a non-negative duration from the named INI key.
 // ok is false (with nil error) when the key is absent or empty, so the caller
 // leaves both the value and its *Set sentinel untouched.
@@ -461,6 +752,9 @@ func (dst *Values) mergeFrom(src *Values) {
 	if src.ClaudeArgs != "" {
 		dst.ClaudeArgs = src.ClaudeArgs
 	}
+	if src.AgentProvider != "" {
+		dst.AgentProvider = src.AgentProvider
+	}
 	if src.PlanModel != "" {
 		dst.PlanModel = src.PlanModel
 	}
@@ -508,9 +802,56 @@ func (dst *Values) mergeFrom(src *Values) {
 	if src.CustomReviewScript != "" {
 		dst.CustomReviewScript = src.CustomReviewScript
 	}
+	if len(src.CustomReviewScripts) > 0 {
+		dst.CustomReviewScripts = src.CustomReviewScripts
+	}
+	if src.HTTPReviewBaseURL != "" {
+		dst.HTTPReviewBaseURL = src.HTTPReviewBaseURL
+	}
+	if src.HTTPReviewModel != "" {
+		dst.HTTPReviewModel = src.HTTPReviewModel
+	}
+	if src.HTTPReviewAPIKeyEnv != "" {
+		dst.HTTPReviewAPIKeyEnv = src.HTTPReviewAPIKeyEnv
+	}
+	if src.HTTPReviewChunkTokens > 0 {
+		dst.HTTPReviewChunkTokens = src.HTTPReviewChunkTokens
+	}
+	if src.HTTPReviewIdleTimeout > 0 {
+		dst.HTTPReviewIdleTimeout = src.HTTPReviewIdleTimeout
+	}
 	dst.mergeExecutionFrom(src)
 	dst.mergeExtraFrom(src)
 	dst.mergeNotifyFrom(src)
+	dst.mergeHooksFrom(src)
+	for name, v := range src.PromptVars { // per variable, local overrides global
+		if dst.PromptVars == nil {
+			dst.PromptVars = map[string]string{}
+		}
+		dst.PromptVars[name] = v
+	}
+}
+
+// mergeHooksFrom merges lifecycle hook settings from src into dst.
+// called from mergeFrom to manage cyclomatic complexity.
+func (dst *Values) mergeHooksFrom(src *Values) {
+	for _, h := range []struct{ dst, src *string }{
+		{&dst.HookPrePhase, &src.HookPrePhase},
+		{&dst.HookPostPhase, &src.HookPostPhase},
+		{&dst.HookPreTask, &src.HookPreTask},
+		{&dst.HookPostTask, &src.HookPostTask},
+		{&dst.HookPreReview, &src.HookPreReview},
+		{&dst.HookOnFailure, &src.HookOnFailure},
+		{&dst.HookOnComplete, &src.HookOnComplete},
+	} {
+		if *h.src != "" {
+			*h.dst = *h.src
+		}
+	}
+	if src.HookTimeoutSet {
+		dst.HookTimeout = src.HookTimeout
+		dst.HookTimeoutSet = true
+	}
 }
 
 // mergeExecutionFrom merges execution-related fields from src into dst.
@@ -524,6 +865,39 @@ func (dst *Values) mergeExecutionFrom(src *Values) {
 		dst.TaskRetryCount = src.TaskRetryCount
 		dst.TaskRetryCountSet = true
 	}
+	if len(src.TaskEscalation) > 0 {
+		dst.TaskEscalation = src.TaskEscalation
+	}
+	if src.ValidationEnabledSet {
+		dst.ValidationEnabled = src.ValidationEnabled
+		dst.ValidationEnabledSet = true
+	}
+	if src.ValidationTimeoutSet {
+		dst.ValidationTimeout = src.ValidationTimeout
+		dst.ValidationTimeoutSet = true
+	}
+	if src.ValidationRetryCountSet {
+		dst.ValidationRetryCount = src.ValidationRetryCount
+		dst.ValidationRetryCountSet = true
+	}
+	if src.MaxRunCostSet {
+		dst.MaxRunCost = src.MaxRunCost
+		dst.MaxRunCostSet = true
+	}
+	if src.MaxRunTokensSet {
+		dst.MaxRunTokens = src.MaxRunTokens
+		dst.MaxRunTokensSet = true
+	}
+	if src.MaxPhaseCostSet {
+		dst.MaxPhaseCost = src.MaxPhaseCost
+		dst.MaxPhaseCostSet = true
+	}
+	if src.BudgetAction != "" {
+		dst.BudgetAction = src.BudgetAction
+	}
+	if src.BudgetModel != "" {
+		dst.BudgetModel = src.BudgetModel
+	}
 	if src.MaxIterationsSet {
 		dst.MaxIterations = src.MaxIterations
 		dst.MaxIterationsSet = true
@@ -578,6 +952,34 @@ func (dst *Values) mergeExtraFrom(src *Values) {
 	if len(src.WatchDirs) > 0 {
 		dst.WatchDirs = src.WatchDirs
 	}
+	if len(src.Pipeline) > 0 {
+		dst.Pipeline = src.Pipeline
+	}
+	if len(src.FindingsExport) > 0 {
+		dst.FindingsExport = src.FindingsExport
+	}
+	if src.WebToken != "" {
+		dst.WebToken = src.WebToken
+	}
+	if src.WebUser != "" {
+		dst.WebUser = src.WebUser
+	}
+	if src.WebPassword != "" {
+		dst.WebPassword = src.WebPassword
+	}
+	if src.WebTLSCert != "" {
+		dst.WebTLSCert = src.WebTLSCert
+	}
+	if src.WebTLSKey != "" {
+		dst.WebTLSKey = src.WebTLSKey
+	}
+	if src.WebTLSSelfSignedSet {
+		dst.WebTLSSelfSigned = src.WebTLSSelfSigned
+		dst.WebTLSSelfSignedSet = true
+	}
+	if len(src.DaemonRepos) > 0 {
+		dst.DaemonRepos = src.DaemonRepos
+	}
 	if len(src.ClaudeErrorPatterns) > 0 {
 		dst.ClaudeErrorPatterns = src.ClaudeErrorPatterns
 	}
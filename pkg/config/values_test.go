@@ -183,6 +183,18 @@ func TestValuesLoader_Load_InvalidConfig(t *testing.T) {
 		{name: "invalid review_patience", config: "review_patience = abc", errPart: "review_patience"},
 		{name: "invalid wait_on_limit", config: "wait_on_limit = not-a-duration", errPart: "wait_on_limit"},
 		{name: "negative wait_on_limit", config: "wait_on_limit = -30m", errPart: "wait_on_limit"},
+		{name: "invalid validation_enabled", config: "validation_enabled = maybe", errPart: "validation_enabled"},
+		{name: "invalid validation_timeout", config: "validation_timeout = soon", errPart: "validation_timeout"},
+		{name: "negative validation_retry_count", config: "validation_retry_count = -1", errPart: "validation_retry_count"},
+		{name: "invalid max_run_cost", config: "max_run_cost = lots", errPart: "max_run_cost"},
+		{name: "negative max_run_cost", config: "max_run_cost = -5", errPart: "max_run_cost"},
+		{name: "nan max_phase_cost", config: "max_phase_cost = NaN", errPart: "max_phase_cost"},
+		{name: "negative max_run_tokens", config: "max_run_tokens = -1", errPart: "max_run_tokens"},
+		{name: "invalid budget_action", config: "budget_action = panic", errPart: "budget_action"},
+		{name: "invalid hook_timeout", config: "hook_timeout = soon", errPart: "hook_timeout"},
+		{name: "invalid pipeline stage", config: "pipeline = task, ../review", errPart: "pipeline stage"},
+		{name: "daemon repo without path", config: "daemon_repos = app", errPart: "expected name=path"},
+		{name: "daemon repo name with slash", config: "daemon_repos = a/b=/srv/app", errPart: "daemon repo name"},
 	}
 
 	for _, tc := range tests {
@@ -305,6 +317,87 @@ func TestValuesLoader_Load_LocalOverridesTaskRetryCount(t *testing.T) {
 	assert.True(t, values.TaskRetryCountSet)
 }
 
+func TestValuesLoader_Load_TaskEscalation(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("task_escalation = sonnet, opus, opus:max"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("task_retry_count = 2"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sonnet", "opus", "opus:max"}, values.TaskEscalation)
+
+	require.NoError(t, os.WriteFile(localConfig, []byte("task_escalation = gpt-5.5:xhigh,"), 0o600))
+	values, err = loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpt-5.5:xhigh"}, values.TaskEscalation, "local list replaces global")
+}
+
+func TestValuesLoader_Load_ValidationSettings(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	require.NoError(t, os.WriteFile(globalConfig, []byte("validation_enabled = true\nvalidation_timeout = 5m\nvalidation_retry_count = 4"), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("validation_retry_count = 0"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+
+	assert.True(t, values.ValidationEnabled)
+	assert.True(t, values.ValidationEnabledSet)
+	assert.Equal(t, 5*time.Minute, values.ValidationTimeout)
+	assert.Equal(t, 0, values.ValidationRetryCount, "local explicit zero overrides global")
+	assert.True(t, values.ValidationRetryCountSet)
+}
+
+func TestValuesLoader_Load_BudgetSettings(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	global := "max_run_cost = 25.5\nmax_run_tokens = 5000000\nmax_phase_cost = 8\nbudget_action = downgrade\nbudget_model = haiku"
+	require.NoError(t, os.WriteFile(globalConfig, []byte(global), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("max_phase_cost = 0"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+
+	assert.InDelta(t, 25.5, values.MaxRunCost, 1e-9)
+	assert.True(t, values.MaxRunCostSet)
+	assert.Equal(t, int64(5000000), values.MaxRunTokens)
+	assert.Zero(t, values.MaxPhaseCost, "local explicit zero overrides global")
+	assert.True(t, values.MaxPhaseCostSet)
+	assert.Equal(t, "downgrade", values.BudgetAction)
+	assert.Equal(t, "haiku", values.BudgetModel)
+}
+
+func TestValuesLoader_Load_HookSettings(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalConfig := filepath.Join(tmpDir, "global")
+	localConfig := filepath.Join(tmpDir, "local")
+
+	global := "hook_pre_task = global-pre-task\nhook_on_failure = global-on-failure\nhook_timeout = 1m"
+	require.NoError(t, os.WriteFile(globalConfig, []byte(global), 0o600))
+	require.NoError(t, os.WriteFile(localConfig, []byte("hook_pre_task = local-pre-task\nhook_pre_review = local-pre-review"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localConfig, globalConfig)
+	require.NoError(t, err)
+
+	assert.Equal(t, "local-pre-task", values.HookPreTask, "local overrides global")
+	assert.Equal(t, "local-pre-review", values.HookPreReview)
+	assert.Equal(t, "global-on-failure", values.HookOnFailure, "global kept when local unset")
+	assert.Empty(t, values.HookOnComplete)
+	assert.Equal(t, time.Minute, values.HookTimeout)
+	assert.True(t, values.HookTimeoutSet)
+}
+
 func TestValuesLoader_Load_LocalOverridesFinalizeEnabled(t *testing.T) {
 	tmpDir := t.TempDir()
 	globalConfig := filepath.Join(tmpDir, "global")
@@ -1312,6 +1405,48 @@ func TestValuesLoader_Load_CustomReviewScript_NoTildeNoChange(t *testing.T) {
 	assert.Equal(t, "/absolute/path/to/script.sh", values.CustomReviewScript)
 }
 
+func TestValuesLoader_Load_AgentProvider(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalPath := filepath.Join(tmpDir, "global")
+	localPath := filepath.Join(tmpDir, "local")
+	require.NoError(t, os.WriteFile(globalPath, []byte("agent_provider = ~/.config/ralphex/providers/codex.json\n"), 0o600))
+	require.NoError(t, os.WriteFile(localPath, []byte("agent_provider = /repo/.ralphex/opencode.json\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load("", globalPath)
+	require.NoError(t, err)
+	home, homeErr := os.UserHomeDir()
+	require.NoError(t, homeErr)
+	assert.Equal(t, home+"/.config/ralphex/providers/codex.json", values.AgentProvider)
+
+	values, err = loader.Load(localPath, globalPath)
+	require.NoError(t, err)
+	assert.Equal(t, "/repo/.ralphex/opencode.json", values.AgentProvider, "local config overrides global")
+}
+
+func TestValuesLoader_Load_PromptVars(t *testing.T) {
+	tmpDir := t.TempDir()
+	globalPath := filepath.Join(tmpDir, "global")
+	localPath := filepath.Join(tmpDir, "local")
+	require.NoError(t, os.WriteFile(globalPath, []byte("max_iterations = 10\n\n[prompt_vars]\n"+
+		"test_command = make test\nteam = core\n"), 0o600))
+	require.NoError(t, os.WriteFile(localPath, []byte("[prompt_vars]\nteam = payments\n"), 0o600))
+
+	loader := newValuesLoader(defaultsFS)
+	values, err := loader.Load(localPath, globalPath)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"test_command": "make test", "team": "payments"}, values.PromptVars)
+	assert.Equal(t, 10, values.MaxIterations, "keys before the section stay in the default section")
+
+	values, err = loader.Load("", "")
+	require.NoError(t, err)
+	assert.Empty(t, values.PromptVars)
+
+	require.NoError(t, os.WriteFile(localPath, []byte("[prompt_vars]\ntest-command = make\n"), 0o600))
+	_, err = loader.Load(localPath, globalPath)
+	require.ErrorContains(t, err, `invalid prompt_vars name "test-command"`)
+}
+
 func TestExpandTilde(t *testing.T) {
 	home, homeErr := os.UserHomeDir()
 	require.NoError(t, homeErr)
@@ -272,7 +272,7 @@ func (e *CodexExecutor) Run(ctx context.Context, prompt string) Result {
 	// after wait() so the tailer keeps following until the rollout file is
 	// guaranteed complete and the final assistant line is not dropped.
 	tailCancel()
-	<-tailDone
+	usage := <-tailDone
 
 	// detect signal in stdout (the actual response)
 	signal := detectSignal(stdoutContent)
@@ -281,7 +281,9 @@ func (e *CodexExecutor) Run(ctx context.Context, prompt string) Result {
 	// mirrors the ClaudeExecutor idle-timeout completion path so callers see uniform behavior.
 	if e.IdleTimeout > 0 && execCtx.Err() != nil && ctx.Err() == nil {
 		e.logDroppedIdleErrors(stdoutErr, waitErr)
-		return e.idleTimeoutResult(stdoutContent, signal, stderrRes)
+		res := e.idleTimeoutResult(stdoutContent, signal, stderrRes)
+		res.Usage = usage
+		return res
 	}
 
 	finalErr := e.finalError(ctx, stderrRes, stdoutErr, waitErr)
@@ -292,12 +294,12 @@ func (e *CodexExecutor) Run(ctx context.Context, prompt string) Result {
 	// skip pattern checks on context cancellation — cancellation must propagate as-is.
 	if finalErr != nil && ctx.Err() == nil {
 		if patternErr := e.checkPatterns(stdoutContent, stderrRes); patternErr != nil {
-			return Result{Output: stdoutContent, Signal: signal, Error: patternErr}
+			return Result{Output: stdoutContent, Signal: signal, Error: patternErr, Usage: usage}
 		}
 	}
 
 	// return stdout content as the result (the actual answer from codex)
-	return Result{Output: stdoutContent, Signal: signal, Error: finalErr}
+	return Result{Output: stdoutContent, Signal: signal, Error: finalErr, Usage: usage}
 }
 
 // finalError reconciles stderr/stdout/wait errors into the single error returned
@@ -621,20 +623,25 @@ func (e *CodexExecutor) extractSessionID(line string) string {
 // startRolloutTail spawns the rollout-tail goroutine and returns a cancel
 // function plus a done channel. tail goroutine waits for the session id on
 // sessionIDCh, then follows codex's session rollout file until the returned
-// cancel is called. caller must invoke tailCancel and wait on tailDone before
-// returning so the tailer drains remaining file content and exits cleanly.
+// cancel is called. caller must invoke tailCancel and receive from tailDone before
+// returning so the tailer drains remaining file content and exits cleanly; the
+// received value is the session's token usage from the rollout (zero if unknown).
 // the goroutine is a no-op when OutputHandler is nil — extracted from Run()
 // to keep its cyclomatic complexity in check.
-func (e *CodexExecutor) startRolloutTail(parent context.Context, sessionIDCh <-chan string, idleTouch func()) (context.CancelFunc, <-chan struct{}) {
+func (e *CodexExecutor) startRolloutTail(parent context.Context, sessionIDCh <-chan string, idleTouch func()) (context.CancelFunc, <-chan Usage) {
 	tailCtx, tailCancel := context.WithCancel(parent)
-	done := make(chan struct{})
+	done := make(chan Usage, 1)
 	go func() {
-		defer close(done)
+		var usage Usage
+		defer func() {
+			done <- usage
+			close(done)
+		}()
 		select {
 		case <-tailCtx.Done():
 			return
 		case id := <-sessionIDCh:
-			e.tailRolloutFile(tailCtx, id, idleTouch)
+			usage = e.tailRolloutFile(tailCtx, id, idleTouch)
 		}
 	}()
 	return tailCancel, done
@@ -673,10 +680,11 @@ func (e *CodexExecutor) findRolloutFile(ctx context.Context, sessionID string) s
 // parses each event, and emits human-readable progress lines via OutputHandler.
 // runs until ctx is canceled. on cancellation, drains any remaining buffered
 // lines before returning so late writes (e.g. codex flushing the final
-// assistant message just before exit) are not lost.
-func (e *CodexExecutor) tailRolloutFile(ctx context.Context, sessionID string, idleTouch func()) {
+// assistant message just before exit) are not lost. returns the last cumulative
+// token_count seen in the rollout.
+func (e *CodexExecutor) tailRolloutFile(ctx context.Context, sessionID string, idleTouch func()) (usage Usage) {
 	if e.OutputHandler == nil {
-		return
+		return usage
 	}
 	path := e.findRolloutFile(ctx, sessionID)
 	if path == "" {
@@ -685,12 +693,12 @@ func (e *CodexExecutor) tailRolloutFile(ctx context.Context, sessionID string, i
 		if ctx.Err() == nil {
 			log.Printf("codex rollout file not found for session %s; assistant output streaming disabled for this session", sessionID)
 		}
-		return
+		return usage
 	}
 	f, err := os.Open(path) //nolint:gosec // path comes from codex's own session id
 	if err != nil {
 		log.Printf("codex rollout file open failed (%s): %v; assistant output streaming disabled for this session", path, err)
-		return
+		return usage
 	}
 	defer func() { _ = f.Close() }()
 
@@ -718,6 +726,8 @@ func (e *CodexExecutor) tailRolloutFile(ctx context.Context, sessionID string, i
 					}
 					if msg := e.formatRolloutEvent(acc[:i]); msg != "" {
 						e.OutputHandler(msg)
+					} else if u, ok := parseCodexTokenCount(acc[:i]); ok {
+						usage = u
 					}
 					acc = acc[i+1:]
 				}
@@ -737,7 +747,7 @@ func (e *CodexExecutor) tailRolloutFile(ctx context.Context, sessionID string, i
 		case <-ctx.Done():
 			// final drain after codex exits — pick up any late-flushed events
 			drainOnce()
-			return
+			return usage
 		case <-time.After(200 * time.Millisecond):
 		}
 	}
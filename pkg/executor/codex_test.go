@@ -1841,6 +1841,7 @@ func TestCodexExecutor_tailRolloutFile_streamsAssistantMessages(t *testing.T) {
 	preEvents := []string{
 		`{"type":"response_item","payload":{"type":"message","role":"assistant","content":[{"type":"output_text","text":"first reply"}]}}`,
 		`{"type":"response_item","payload":{"type":"reasoning","summary":[{"type":"summary_text","text":"**Inspecting the diff**"}]}}`,
+		`{"type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":1500,"cached_input_tokens":1000,"output_tokens":200}}}}`,
 	}
 	for _, ev := range preEvents {
 		_, writeErr := f.WriteString(ev + "\n")
@@ -1849,9 +1850,10 @@ func TestCodexExecutor_tailRolloutFile_streamsAssistantMessages(t *testing.T) {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	tailDone := make(chan struct{})
+	var usage Usage
 	go func() {
 		defer close(tailDone)
-		e.tailRolloutFile(ctx, sessionID, nil)
+		usage = e.tailRolloutFile(ctx, sessionID, nil)
 	}()
 
 	// wait briefly for initial drain (assistant text + reasoning title)
@@ -1878,6 +1880,8 @@ func TestCodexExecutor_tailRolloutFile_streamsAssistantMessages(t *testing.T) {
 	assert.Contains(t, final[0], "first reply")
 	assert.Contains(t, final[1], "Inspecting the diff", "reasoning title streams from the rollout, stripped of **")
 	assert.Contains(t, final[2], "late reply after first read")
+	assert.Equal(t, Usage{InputTokens: 500, OutputTokens: 200, CacheReadTokens: 1000}, usage,
+		"token_count is not echoed but returned as session usage")
 }
 
 func TestCodexExecutor_findRolloutFile(t *testing.T) {
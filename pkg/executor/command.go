@@ -0,0 +1,81 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// commandWaitDelay bounds how long Wait blocks on output pipes held open by
+// descendants that escaped the process group kill.
+const commandWaitDelay = 2 * time.Second
+
+// CommandResult holds the outcome of a shell command run by RunShellCommand.
+type CommandResult struct {
+	Command  string        // command line as passed to the shell
+	Output   string        // combined stdout and stderr
+	ExitCode int           // process exit code, -1 when the command did not exit normally
+	Duration time.Duration // wall-clock time spent running the command
+	TimedOut bool          // true when the command was killed by the timeout
+	Error    error         // non-nil when the command failed to start, exited non-zero, or timed out
+}
+
+// RunShellCommand runs command via "sh -c" in its own process group, capturing combined output.
+// a positive timeout bounds the run; on timeout or ctx cancellation the whole process group is killed.
+func RunShellCommand(ctx context.Context, command string, timeout time.Duration) CommandResult {
+	return RunShellCommandWithInput(ctx, command, nil, timeout)
+}
+
+// RunShellCommandWithInput is RunShellCommand with stdin fed from input (nil = no stdin).
+func RunShellCommandWithInput(ctx context.Context, command string, input []byte, timeout time.Duration) CommandResult {
+	res := CommandResult{Command: command, ExitCode: -1}
+	if err := ctx.Err(); err != nil {
+		res.Error = fmt.Errorf("context already canceled: %w", err)
+		return res
+	}
+
+	runCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// use exec.Command (not CommandContext) because cancellation kills the whole process group
+	cmd := exec.Command("sh", "-c", command) //nolint:gosec,noctx // command comes from the plan file by design
+	setupProcessGroup(cmd)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if input != nil {
+		cmd.Stdin = bytes.NewReader(input)
+	}
+	cmd.WaitDelay = commandWaitDelay
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		res.Error = fmt.Errorf("start command: %w", err)
+		return res
+	}
+	cleanup := newProcessGroupCleanup(cmd, runCtx.Done())
+	waitErr := cleanup.Wait()
+	res.Duration = time.Since(start)
+	res.Output = out.String()
+	if cmd.ProcessState != nil {
+		res.ExitCode = cmd.ProcessState.ExitCode()
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		res.Error = fmt.Errorf("context error: %w", ctx.Err())
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		res.TimedOut = true
+		res.Error = fmt.Errorf("command timed out after %s", timeout)
+	case waitErr != nil:
+		res.Error = fmt.Errorf("command failed with exit code %d: %w", res.ExitCode, waitErr)
+	}
+	return res
+}
@@ -0,0 +1,55 @@
+//go:build unix
+
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunShellCommand(t *testing.T) {
+	t.Run("success captures combined output", func(t *testing.T) {
+		res := RunShellCommand(t.Context(), "echo out; echo err >&2", time.Minute)
+		require.NoError(t, res.Error)
+		assert.Equal(t, 0, res.ExitCode)
+		assert.False(t, res.TimedOut)
+		assert.Contains(t, res.Output, "out\n")
+		assert.Contains(t, res.Output, "err\n")
+		assert.Equal(t, "echo out; echo err >&2", res.Command)
+	})
+
+	t.Run("non-zero exit is an error", func(t *testing.T) {
+		res := RunShellCommand(t.Context(), "echo broken; exit 3", time.Minute)
+		require.Error(t, res.Error)
+		assert.Equal(t, 3, res.ExitCode)
+		assert.False(t, res.TimedOut)
+		assert.Contains(t, res.Output, "broken")
+		assert.Contains(t, res.Error.Error(), "exit code 3")
+	})
+
+	t.Run("timeout kills the command", func(t *testing.T) {
+		start := time.Now()
+		res := RunShellCommand(t.Context(), "sleep 10", 100*time.Millisecond)
+		require.Error(t, res.Error)
+		assert.True(t, res.TimedOut)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+		res := RunShellCommand(ctx, "echo hi", 0)
+		require.ErrorIs(t, res.Error, context.Canceled)
+		assert.False(t, res.TimedOut)
+	})
+}
+
+func TestRunShellCommandWithInput(t *testing.T) {
+	res := RunShellCommandWithInput(t.Context(), "cat; echo done", []byte(`{"event":"pre_task"}`), time.Minute)
+	require.NoError(t, res.Error)
+	assert.Equal(t, "{\"event\":\"pre_task\"}done\n", res.Output)
+}
@@ -1,7 +1,9 @@
 package executor
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -10,15 +12,16 @@ import (
 )
 
 // CustomRunner abstracts command execution for custom review scripts.
+// stdin is passed to the script's standard input.
 // Returns stdout reader and a wait function for completion.
 type CustomRunner interface {
-	Run(ctx context.Context, script, promptFile string) (stdout io.Reader, wait func() error, err error)
+	Run(ctx context.Context, script, promptFile string, stdin io.Reader) (stdout io.Reader, wait func() error, err error)
 }
 
 // execCustomRunner is the default command runner using os/exec.
 type execCustomRunner struct{}
 
-func (r *execCustomRunner) Run(ctx context.Context, script, promptFile string) (io.Reader, func() error, error) {
+func (r *execCustomRunner) Run(ctx context.Context, script, promptFile string, stdin io.Reader) (io.Reader, func() error, error) {
 	// check context before starting to avoid spawning a process that will be immediately killed
 	if err := ctx.Err(); err != nil {
 		return nil, nil, fmt.Errorf("context already canceled: %w", err)
@@ -27,6 +30,8 @@ func (r *execCustomRunner) Run(ctx context.Context, script, promptFile string) (
 	// use exec.Command (not CommandContext) because we handle cancellation ourselves
 	// to ensure the entire process group is killed, not just the direct child
 	cmd := exec.Command(script, promptFile) //nolint:noctx // intentional: we handle context cancellation via process group kill
+	cmd.Stdin = stdin
+	cmd.Env = append(os.Environ(), fmt.Sprintf("RALPHEX_REVIEW_PROTOCOL=%d", ReviewProtocolVersion))
 
 	// create new process group so we can kill all descendants on cleanup
 	setupProcessGroup(cmd)
@@ -66,6 +71,13 @@ func (e *CustomExecutor) SetRunner(r CustomRunner) {
 // The script receives the path to the prompt file as its single argument.
 // Output is streamed line-by-line to OutputHandler.
 func (e *CustomExecutor) Run(ctx context.Context, promptContent string) Result {
+	return e.RunRequest(ctx, promptContent, ReviewRequest{})
+}
+
+// RunRequest executes the custom review script like Run and writes req as JSON to the script's stdin.
+// a JSON review response of the script is rendered as text findings, with its done flag as the
+// CODEX_REVIEW_DONE signal and its usage block as the session usage. other output is used as-is.
+func (e *CustomExecutor) RunRequest(ctx context.Context, promptContent string, req ReviewRequest) Result {
 	if e.Script == "" {
 		return Result{Error: errors.New("custom review script not configured")}
 	}
@@ -86,12 +98,21 @@ func (e *CustomExecutor) Run(ctx context.Context, promptContent string) Result {
 		return Result{Error: fmt.Errorf("close prompt file: %w", closeErr)}
 	}
 
+	req.Version, req.PromptFile = ReviewProtocolVersion, promptPath
+	if req.ChangedFiles == nil {
+		req.ChangedFiles = []string{} // scripts always get a list, never null
+	}
+	reqData, err := json.Marshal(req)
+	if err != nil {
+		return Result{Error: fmt.Errorf("marshal review request: %w", err)}
+	}
+
 	runner := e.runner
 	if runner == nil {
 		runner = &execCustomRunner{}
 	}
 
-	stdout, wait, err := runner.Run(ctx, e.Script, promptPath)
+	stdout, wait, err := runner.Run(ctx, e.Script, promptPath, bytes.NewReader(reqData))
 	if err != nil {
 		return Result{Error: fmt.Errorf("start custom script: %w", err)}
 	}
@@ -138,7 +159,22 @@ func (e *CustomExecutor) Run(ctx context.Context, promptContent string) Result {
 		}
 	}
 
-	return Result{Output: output, Signal: signal, Error: finalErr}
+	if finalErr != nil {
+		return Result{Output: output, Signal: signal, Error: finalErr}
+	}
+	resp, ok, err := parseReviewResponse(output)
+	if err != nil {
+		return Result{Output: output, Signal: signal, Error: fmt.Errorf("custom script response: %w", err)}
+	}
+	if !ok {
+		return Result{Output: output, Signal: signal}
+	}
+	text := resp.Text()
+	var usage Usage
+	if resp.Usage != nil {
+		usage = *resp.Usage
+	}
+	return Result{Output: text, Signal: detectSignal(text), Usage: usage}
 }
 
 // processOutput reads stdout line-by-line, streams to OutputHandler, and detects signals.
@@ -2,8 +2,11 @@ package executor
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -14,9 +17,17 @@ import (
 // mockCustomRunner implements CustomRunner for testing.
 type mockCustomRunner struct {
 	runFunc func(ctx context.Context, script, promptFile string) (io.Reader, func() error, error)
+	stdin   string // captured script stdin
 }
 
-func (m *mockCustomRunner) Run(ctx context.Context, script, promptFile string) (io.Reader, func() error, error) {
+func (m *mockCustomRunner) Run(ctx context.Context, script, promptFile string, stdin io.Reader) (io.Reader, func() error, error) {
+	if stdin != nil {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, nil, err
+		}
+		m.stdin = string(data)
+	}
 	return m.runFunc(ctx, script, promptFile)
 }
 
@@ -320,12 +331,99 @@ func TestCustomExecutor_Run_PassesPromptToScript(t *testing.T) {
 	assert.Contains(t, capturedPromptFile, "ralphex-custom-prompt-", "temp file should have expected prefix")
 }
 
+func TestCustomExecutor_RunRequest_WritesRequestToStdin(t *testing.T) {
+	var capturedPromptFile string
+	mock := &mockCustomRunner{
+		runFunc: func(_ context.Context, _, promptFile string) (io.Reader, func() error, error) {
+			capturedPromptFile = promptFile
+			return strings.NewReader("ok"), func() error { return nil }, nil
+		},
+	}
+	e := &CustomExecutor{Script: "/path/to/script.sh", runner: mock}
+
+	req := ReviewRequest{DiffCommand: "git diff", BaseRef: "main", ChangedFiles: []string{"a.go", "b.go"},
+		PlanFile: "docs/plans/x.md", Iteration: 2, PreviousEvaluation: "fixed"}
+	result := e.RunRequest(context.Background(), "prompt", req)
+	require.NoError(t, result.Error)
+
+	var got ReviewRequest
+	require.NoError(t, json.Unmarshal([]byte(mock.stdin), &got))
+	req.Version, req.PromptFile = ReviewProtocolVersion, capturedPromptFile
+	assert.Equal(t, req, got)
+
+	t.Run("empty request", func(t *testing.T) {
+		result := e.Run(context.Background(), "prompt")
+		require.NoError(t, result.Error)
+		assert.Contains(t, mock.stdin, `"version":1`)
+		assert.Contains(t, mock.stdin, `"changed_files":[]`)
+		assert.NotContains(t, mock.stdin, "plan_file")
+	})
+}
+
+func TestCustomExecutor_RunRequest_Response(t *testing.T) {
+	tests := []struct {
+		name       string
+		output     string
+		wantOutput string
+		wantSignal string
+		wantUsage  Usage
+		wantErr    string
+	}{
+		{name: "json findings",
+			output: `{"version": 1, "summary": "two issues", "findings": [` +
+				`{"file": "a.go", "line": 3, "severity": "major", "description": "nil deref"}, {"file": "b.go", "description": "unused"}], ` +
+				`"usage": {"input_tokens": 10, "output_tokens": 5, "cost_usd": 0.01}}`,
+			wantOutput: "two issues\n\n- a.go:3 - [major] nil deref\n- b.go - unused\n",
+			wantUsage:  Usage{InputTokens: 10, OutputTokens: 5, CostUSD: 0.01}},
+		{name: "json done after log lines",
+			output:     "checking...\n{\"version\": 1, \"findings\": [], \"done\": true}\n",
+			wantOutput: "NO ISSUES FOUND\n<<<RALPHEX:CODEX_REVIEW_DONE>>>\n",
+			wantSignal: "<<<RALPHEX:CODEX_REVIEW_DONE>>>"},
+		{name: "plain text", output: "a.go:3 - nil deref\n", wantOutput: "a.go:3 - nil deref\n"},
+		{name: "json without version is text", output: `{"findings": []}`, wantOutput: "{\"findings\": []}\n"},
+		{name: "unsupported version", output: `{"version": 2, "findings": []}`, wantErr: "unsupported review protocol version 2"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mock := &mockCustomRunner{
+				runFunc: func(_ context.Context, _, _ string) (io.Reader, func() error, error) {
+					return strings.NewReader(tc.output), func() error { return nil }, nil
+				},
+			}
+			e := &CustomExecutor{Script: "/path/to/script.sh", runner: mock}
+
+			result := e.RunRequest(context.Background(), "prompt", ReviewRequest{Iteration: 1})
+			if tc.wantErr != "" {
+				require.Error(t, result.Error)
+				assert.Contains(t, result.Error.Error(), tc.wantErr)
+				return
+			}
+			require.NoError(t, result.Error)
+			assert.Equal(t, tc.wantOutput, result.Output)
+			assert.Equal(t, tc.wantSignal, result.Signal)
+			assert.Equal(t, tc.wantUsage, result.Usage)
+		})
+	}
+}
+
+func TestExecCustomRunner_Run_StdinAndEnv(t *testing.T) {
+	runner := &execCustomRunner{}
+	script := filepath.Join(t.TempDir(), "review.sh")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\necho \"$RALPHEX_REVIEW_PROTOCOL $1\"\ncat\n"), 0o700))
+	stdout, wait, err := runner.Run(context.Background(), script, "prompt.txt", strings.NewReader("request"))
+	require.NoError(t, err)
+	data, err := io.ReadAll(stdout)
+	require.NoError(t, err)
+	require.NoError(t, wait())
+	assert.Equal(t, "1 prompt.txt\nrequest", string(data))
+}
+
 func TestExecCustomRunner_Run(t *testing.T) {
 	// test the real runner with a simple command
 	runner := &execCustomRunner{}
 
 	// use echo which writes to stdout
-	stdout, wait, err := runner.Run(context.Background(), "echo", "hello")
+	stdout, wait, err := runner.Run(context.Background(), "echo", "hello", nil)
 
 	require.NoError(t, err)
 	require.NotNil(t, stdout)
@@ -345,7 +443,7 @@ func TestExecCustomRunner_Run_CommandNotFound(t *testing.T) {
 	runner := &execCustomRunner{}
 
 	// use a command that doesn't exist
-	stdout, wait, err := runner.Run(context.Background(), "/nonexistent-script-12345", "arg")
+	stdout, wait, err := runner.Run(context.Background(), "/nonexistent-script-12345", "arg", nil)
 
 	// should fail at start or wait
 	if err != nil {
@@ -363,7 +461,7 @@ func TestExecCustomRunner_Run_ContextAlreadyCanceled(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 
-	_, _, err := runner.Run(ctx, "echo", "hello")
+	_, _, err := runner.Run(ctx, "echo", "hello", nil)
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "context already canceled")
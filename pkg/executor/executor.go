@@ -9,6 +9,7 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 	"time"
 
@@ -24,6 +25,8 @@ type Result struct {
 	Signal       string // detected signal (COMPLETED, FAILED, etc.) or empty
 	Error        error  // execution error if any
 	IdleTimedOut bool   // true when idle timeout fired (derived context canceled, parent alive)
+	Usage        Usage  // tokens and cost reported by the session, zero when unknown
+	SessionID    string // agent session id reported by a provider's session_id rule, empty when unknown
 }
 
 const recentBlockCount = 10 // number of recent text blocks to keep for pattern matching
@@ -197,6 +200,27 @@ func stripFlag(args []string, flag string) []string {
 	return result
 }
 
+// claudeReadOnlyTools are the tools allowed by the read-only permission profile: reading and
+// searching files, launching review agents and read-only git commands.
+var claudeReadOnlyTools = []string{"Read", "Grep", "Glob", "LS", "Task", "TodoWrite",
+	"Bash(git diff:*)", "Bash(git log:*)", "Bash(git show:*)", "Bash(git status:*)",
+	"Bash(git blame:*)", "Bash(git rev-parse:*)", "Bash(git ls-files:*)"}
+
+// claudeWriteTools are the file editing tools denied by the read-only permission profile.
+var claudeWriteTools = []string{"Edit", "MultiEdit", "Write", "NotebookEdit"}
+
+// readOnlyArgs replaces the permission flags of args with the read-only profile. without
+// --dangerously-skip-permissions, a non-interactive session is denied every tool not allowed here.
+func readOnlyArgs(args []string) []string {
+	args = slices.DeleteFunc(slices.Clone(args), func(a string) bool { return a == "--dangerously-skip-permissions" })
+	for _, flag := range []string{"--permission-mode", "--allowedTools", "--allowed-tools", "--disallowedTools", "--disallowed-tools"} {
+		args = stripFlag(args, flag)
+	}
+	return append(args, "--permission-mode", "default",
+		"--allowedTools", strings.Join(claudeReadOnlyTools, ","),
+		"--disallowedTools", strings.Join(claudeWriteTools, ","))
+}
+
 // claudeChildEnv builds the environment for a child claude process. CLAUDECODE is always
 // stripped to prevent nested-session errors. ANTHROPIC_API_KEY is stripped unless
 // preserveAPIKey is true; preserving it is required for users who authenticate Claude Code
@@ -245,6 +269,9 @@ type streamEvent struct {
 		Text string `json:"text"`
 	} `json:"delta"`
 	Result json.RawMessage `json:"result"` // can be string or object with "output" field
+	// session accounting, present on the final "result" event
+	TotalCostUSD float64           `json:"total_cost_usd"`
+	Usage        *claudeEventUsage `json:"usage"`
 	// subagent (Task tool) progress: newer Claude Code streams subagent activity as
 	// system/task_started (the agent's task title) and system/task_progress (per step)
 	// events whose description names the action; the subagent type is intentionally
@@ -266,6 +293,7 @@ type ClaudeExecutor struct {
 	RetryPatterns  []string          // patterns to detect transient errors that should retry like timeouts
 	IdleTimeout    time.Duration     // kill session after this duration of no output, zero = disabled
 	PreserveAPIKey bool              // when true, ANTHROPIC_API_KEY is passed through to the child; default false strips it
+	ReadOnly       bool              // read-only permission profile: no file edits, only read-only git commands
 	cmdRunner      CommandRunner     // for testing, nil uses default
 	nowFn          func() time.Time  // for testing throttle timing, nil uses time.Now
 }
@@ -300,6 +328,9 @@ func (e *ClaudeExecutor) Run(ctx context.Context, prompt string) Result {
 			"--verbose",
 		}
 	}
+	if e.ReadOnly {
+		args = readOnlyArgs(args)
+	}
 	// inject --model flag if a model override is configured;
 	// strip any existing --model from args to avoid duplicate/conflicting flags
 	if e.Model != "" {
@@ -353,7 +384,7 @@ func (e *ClaudeExecutor) Run(ctx context.Context, prompt string) Result {
 	// and avoid false "no changes detected" exits in review loops.
 	if e.IdleTimeout > 0 && execCtx.Err() != nil && ctx.Err() == nil {
 		if patternErr := e.patternError(result.RecentText, result.Signal); patternErr != nil {
-			return Result{Output: result.Output, RecentText: result.RecentText, Signal: result.Signal, Error: patternErr}
+			return Result{Output: result.Output, RecentText: result.RecentText, Signal: result.Signal, Error: patternErr, Usage: result.Usage}
 		}
 		result.Error = nil
 		result.IdleTimedOut = true
@@ -363,7 +394,7 @@ func (e *ClaudeExecutor) Run(ctx context.Context, prompt string) Result {
 	if waitErr != nil {
 		// check if it was context cancellation
 		if ctx.Err() != nil {
-			return Result{Output: result.Output, RecentText: result.RecentText, Signal: result.Signal, Error: ctx.Err()}
+			return Result{Output: result.Output, RecentText: result.RecentText, Signal: result.Signal, Error: ctx.Err(), Usage: result.Usage}
 		}
 		if result.Output == "" {
 			return Result{Error: fmt.Errorf("claude exited with error: %w", waitErr)}
@@ -376,7 +407,7 @@ func (e *ClaudeExecutor) Run(ctx context.Context, prompt string) Result {
 	}
 
 	if patternErr := e.patternError(result.RecentText, result.Signal); patternErr != nil {
-		return Result{Output: result.Output, RecentText: result.RecentText, Signal: result.Signal, Error: patternErr}
+		return Result{Output: result.Output, RecentText: result.RecentText, Signal: result.Signal, Error: patternErr, Usage: result.Usage}
 	}
 
 	return result
@@ -411,6 +442,7 @@ func (e *ClaudeExecutor) parseStream(ctx context.Context, r io.Reader, idleTouch
 	var recentBlocks [recentBlockCount]string
 	var blockIdx int
 	var lastProgress time.Time // throttle window for subagent heartbeat lines
+	var usage Usage            // session accounting from the final result event
 
 	err := readLines(ctx, r, func(line string) {
 		idleTouch() // reset idle timer on every line of pipe activity
@@ -456,6 +488,10 @@ func (e *ClaudeExecutor) parseStream(ctx context.Context, r io.Reader, idleTouch
 			return
 		}
 
+		if u, ok := resultUsage(&event); ok {
+			usage = u
+		}
+
 		text := e.extractText(&event)
 		if text != "" {
 			output.WriteString(text)
@@ -487,11 +523,11 @@ func (e *ClaudeExecutor) parseStream(ctx context.Context, r io.Reader, idleTouch
 	}
 
 	if err != nil {
-		return Result{Output: output.String(), RecentText: recent.String(), Signal: signal,
+		return Result{Output: output.String(), RecentText: recent.String(), Signal: signal, Usage: usage,
 			Error: fmt.Errorf("stream read: %w", err)}
 	}
 
-	return Result{Output: output.String(), RecentText: recent.String(), Signal: signal}
+	return Result{Output: output.String(), RecentText: recent.String(), Signal: signal, Usage: usage}
 }
 
 // subagentLine formats a one-line heartbeat for a subagent (Task tool) system
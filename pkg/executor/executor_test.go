@@ -539,6 +539,34 @@ func TestClaudeExecutor_Run_WithCustomArgs(t *testing.T) {
 	assert.Equal(t, []string{"--custom-arg", "--another-arg", "value", "--print"}, capturedArgs)
 }
 
+func TestClaudeExecutor_Run_ReadOnly(t *testing.T) {
+	var capturedArgs []string
+	mock := &mocks.CommandRunnerMock{
+		RunFunc: func(_ context.Context, _ string, args ...string) (io.Reader, func() error, error) {
+			capturedArgs = args
+			return strings.NewReader(`{"type":"content_block_delta","delta":{"type":"text_delta","text":"ok"}}`), func() error { return nil }, nil
+		},
+	}
+	tools := strings.Join(claudeReadOnlyTools, ",")
+
+	t.Run("default args", func(t *testing.T) {
+		e := &ClaudeExecutor{cmdRunner: mock, ReadOnly: true}
+		require.NoError(t, e.Run(context.Background(), "review").Error)
+		assert.Equal(t, []string{"--output-format", "stream-json", "--verbose", "--permission-mode", "default",
+			"--allowedTools", tools, "--disallowedTools", "Edit,MultiEdit,Write,NotebookEdit", "--print"}, capturedArgs)
+		assert.Contains(t, tools, "Bash(git diff:*)")
+		assert.NotContains(t, tools, "Bash(git commit")
+	})
+
+	t.Run("custom permission flags replaced", func(t *testing.T) {
+		e := &ClaudeExecutor{cmdRunner: mock, ReadOnly: true,
+			Args: "--dangerously-skip-permissions --permission-mode acceptEdits --allowedTools Bash --verbose"}
+		require.NoError(t, e.Run(context.Background(), "review").Error)
+		assert.Equal(t, []string{"--verbose", "--permission-mode", "default",
+			"--allowedTools", tools, "--disallowedTools", "Edit,MultiEdit,Write,NotebookEdit", "--print"}, capturedArgs)
+	})
+}
+
 func TestClaudeExecutor_Run_WithExplicitEmptyArgs(t *testing.T) {
 	var capturedArgs []string
 	mock := &mocks.CommandRunnerMock{
@@ -0,0 +1,465 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// prompt delivery methods of a provider.
+const (
+	PromptStdin = "stdin" // prompt is written to the command's stdin (default)
+	PromptArg   = "arg"   // prompt replaces {{prompt}} in the args, or is appended as the last arg
+	PromptFile  = "file"  // prompt is written to a temp file replacing {{prompt_file}}, or appended as the last arg
+)
+
+// Provider describes how to run a non-Claude agent CLI and read its JSONL output.
+// it is loaded from a JSON provider file, see LoadProvider.
+type Provider struct {
+	Name         string            `json:"name"`           // shown in errors, defaults to the command
+	Command      string            `json:"command"`        // agent CLI to run
+	Args         []string          `json:"args"`           // args template, may use {{prompt}} and {{prompt_file}}
+	ReadOnlyArgs []string          `json:"read_only_args"` // args used instead of Args for report-only review
+	ModelArgs    []string          `json:"model_args"`     // appended when a model is set, {{model}} is replaced
+	EffortArgs   []string          `json:"effort_args"`    // appended when an effort is set, {{effort}} is replaced
+	Prompt       string            `json:"prompt"`         // prompt delivery: stdin, arg or file
+	Env          map[string]string `json:"env"`            // extra environment of the command
+	HelpCommand  string            `json:"help_command"`   // suggested on limit and error pattern matches
+	Text         []ProviderRule    `json:"text"`           // events carrying assistant text
+	Result       *ProviderRule     `json:"result"`         // final result event, used as output when no text was streamed
+	SessionID    *ProviderRule     `json:"session_id"`     // event carrying the session id
+	Error        *ProviderRule     `json:"error"`          // event reporting an error
+}
+
+// ProviderRule picks a value out of matching JSON events. When maps dotted paths to the
+// expected values, e.g. {"type": "item.completed", "item.type": "agent_message"}, and Path
+// names the value to read, e.g. "item.text". a path segment "[]" joins all elements of an array.
+type ProviderRule struct {
+	When    map[string]string `json:"when"`
+	Path    string            `json:"path"`
+	Newline bool              `json:"newline"` // append a newline to values that don't end with one
+}
+
+// LoadProvider reads and validates a provider file.
+func LoadProvider(path string) (*Provider, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // provider file path comes from user config
+	if err != nil {
+		return nil, fmt.Errorf("read provider file: %w", err)
+	}
+	var p Provider
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("parse provider file %s: %w", path, err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("invalid provider file %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+func (p *Provider) validate() error {
+	if p.Command == "" {
+		return errors.New("command is required")
+	}
+	if p.Name == "" {
+		p.Name = p.Command
+	}
+	switch p.Prompt {
+	case "":
+		p.Prompt = PromptStdin
+	case PromptStdin, PromptArg, PromptFile:
+	default:
+		return fmt.Errorf("invalid prompt %q: use stdin, arg or file", p.Prompt)
+	}
+	if len(p.Text) == 0 && p.Result == nil {
+		return errors.New("at least one text or result rule is required")
+	}
+	rules := map[string]*ProviderRule{"result": p.Result, "session_id": p.SessionID, "error": p.Error}
+	for i := range p.Text {
+		rules[fmt.Sprintf("text[%d]", i)] = &p.Text[i]
+	}
+	for name, r := range rules {
+		if r != nil && r.Path == "" {
+			return fmt.Errorf("%s rule has no path", name)
+		}
+	}
+	return nil
+}
+
+// execGenericRunner runs the provider command with an optional stdin and extra environment.
+type execGenericRunner struct {
+	stdin io.Reader
+	env   map[string]string
+}
+
+func (r *execGenericRunner) Run(ctx context.Context, name string, args ...string) (io.Reader, func() error, error) {
+	// check context before starting to avoid spawning a process that will be immediately killed
+	if err := ctx.Err(); err != nil {
+		return nil, nil, fmt.Errorf("context already canceled: %w", err)
+	}
+
+	// use exec.Command (not CommandContext) because we handle cancellation ourselves
+	// to ensure the entire process group is killed, not just the direct child
+	cmd := exec.Command(name, args...) //nolint:noctx // intentional: we handle context cancellation via process group kill
+	cmd.Env = filterEnv(os.Environ(), "CLAUDECODE")
+	for k, v := range r.env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	if r.stdin != nil {
+		cmd.Stdin = r.stdin
+	}
+
+	// create new process group so we can kill all descendants on cleanup
+	setupProcessGroup(cmd)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("create stdout pipe: %w", err)
+	}
+	// merge stderr into stdout, so error and limit patterns see the CLI's error messages
+	cmd.Stderr = cmd.Stdout
+	if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("start command: %w", err)
+	}
+
+	// setup process group cleanup with graceful shutdown on context cancellation
+	cleanup := newProcessGroupCleanup(cmd, ctx.Done())
+
+	return stdout, cleanup.Wait, nil
+}
+
+// GenericExecutor runs an agent CLI described by a provider file and reads its JSONL output
+// with the provider's rules, so new CLIs don't need a wrapper translating to claude stream-json.
+// signal detection, idle timeout and error/limit/retry patterns work as in ClaudeExecutor.
+type GenericExecutor struct {
+	ProviderFile  string            // path to the provider file, loaded on each run
+	Model         string            // model override, passed with the provider's model_args
+	Effort        string            // reasoning effort override, passed with the provider's effort_args
+	OutputHandler func(text string) // called for each text chunk, can be nil
+	Debug         bool              // enable debug output
+	ErrorPatterns []string          // patterns to detect in output (e.g., rate limit messages)
+	LimitPatterns []string          // patterns to detect rate limits (checked before error patterns)
+	RetryPatterns []string          // patterns to detect transient errors that should retry like timeouts
+	IdleTimeout   time.Duration     // kill session after this duration of no output, zero = disabled
+	ReadOnly      bool              // use the provider's read_only_args for report-only review
+	cmdRunner     CommandRunner     // for testing, nil uses default
+}
+
+// Run executes the provider command with the given prompt and parses its JSONL output.
+func (e *GenericExecutor) Run(ctx context.Context, prompt string) Result {
+	p, err := LoadProvider(e.ProviderFile)
+	if err != nil {
+		return Result{Error: err}
+	}
+
+	args, cleanupPrompt, err := e.buildArgs(p, prompt)
+	if err != nil {
+		return Result{Error: err}
+	}
+	defer cleanupPrompt()
+
+	runner := e.cmdRunner
+	if runner == nil {
+		gr := &execGenericRunner{env: p.Env}
+		if p.Prompt == PromptStdin {
+			gr.stdin = strings.NewReader(prompt)
+		}
+		runner = gr
+	}
+
+	// idle timeout works as in ClaudeExecutor.Run: the timer is reset on each output line
+	execCtx := ctx
+	idleTouch := func() {}
+	if e.IdleTimeout > 0 {
+		var idleCancel context.CancelFunc
+		execCtx, idleCancel = context.WithCancel(ctx)
+		defer idleCancel()
+		timer := time.AfterFunc(e.IdleTimeout, idleCancel)
+		defer timer.Stop()
+		idleTouch = func() { timer.Reset(e.IdleTimeout) }
+	}
+
+	stdout, wait, err := runner.Run(execCtx, p.Command, args...)
+	if err != nil {
+		return Result{Error: err}
+	}
+
+	result, errText := e.parseStream(execCtx, p, stdout, idleTouch)
+	waitErr := wait()
+
+	if e.IdleTimeout > 0 && execCtx.Err() != nil && ctx.Err() == nil {
+		if patternErr := e.patternError(p, result.RecentText, result.Signal); patternErr != nil {
+			result.Error = patternErr
+			return result
+		}
+		result.Error = nil
+		result.IdleTimedOut = true
+		return result
+	}
+
+	if waitErr != nil {
+		if ctx.Err() != nil {
+			result.Error = ctx.Err()
+			return result
+		}
+		if result.Output == "" {
+			return Result{Error: fmt.Errorf("%s exited with error: %w", p.Name, waitErr), SessionID: result.SessionID}
+		}
+		// same as claude: a signal means the work was done despite the exit code
+		if result.Signal == "" {
+			result.Error = fmt.Errorf("%s exited with error: %w", p.Name, waitErr)
+		}
+	}
+
+	if patternErr := e.patternError(p, result.RecentText, result.Signal); patternErr != nil {
+		result.Error = patternErr
+		return result
+	}
+	if errText != "" && result.Signal == "" && result.Error == nil {
+		result.Error = fmt.Errorf("%s reported error: %s", p.Name, errText)
+	}
+	return result
+}
+
+// buildArgs expands the provider's args template and delivers the prompt as configured.
+// the returned cleanup removes the prompt file of the file delivery method.
+func (e *GenericExecutor) buildArgs(p *Provider, prompt string) (args []string, cleanup func(), err error) {
+	cleanup = func() {}
+	tmpl := p.Args
+	if e.ReadOnly {
+		if len(p.ReadOnlyArgs) == 0 {
+			return nil, cleanup, fmt.Errorf("provider %s has no read_only_args, required for report-only review", p.Name)
+		}
+		tmpl = p.ReadOnlyArgs
+	}
+
+	vars := []string{"{{model}}", e.Model, "{{effort}}", e.Effort}
+	placeholder := ""
+	switch p.Prompt {
+	case PromptArg:
+		placeholder = "{{prompt}}"
+		vars = append(vars, placeholder, prompt)
+	case PromptFile:
+		f, ferr := os.CreateTemp("", "ralphex-prompt-*.md")
+		if ferr != nil {
+			return nil, cleanup, fmt.Errorf("create prompt file: %w", ferr)
+		}
+		cleanup = func() { _ = os.Remove(f.Name()) }
+		_, werr := f.WriteString(prompt)
+		if cerr := f.Close(); werr == nil {
+			werr = cerr
+		}
+		if werr != nil {
+			cleanup()
+			return nil, func() {}, fmt.Errorf("write prompt file: %w", werr)
+		}
+		placeholder = "{{prompt_file}}"
+		vars = append(vars, placeholder, f.Name())
+	}
+
+	tmpl = slices.Clone(tmpl)
+	if e.Model != "" {
+		tmpl = append(tmpl, p.ModelArgs...)
+	}
+	if e.Effort != "" {
+		tmpl = append(tmpl, p.EffortArgs...)
+	}
+
+	// a single-pass replacer, so placeholders inside the prompt itself are left alone
+	replacer := strings.NewReplacer(vars...)
+	placed := false
+	for _, a := range tmpl {
+		if placeholder != "" && strings.Contains(a, placeholder) {
+			placed = true
+		}
+		args = append(args, replacer.Replace(a))
+	}
+	// without a placeholder the prompt (or its file) goes last, like a positional argument
+	if placeholder != "" && !placed {
+		args = append(args, vars[len(vars)-1])
+	}
+	return args, cleanup, nil
+}
+
+func (e *GenericExecutor) patternError(p *Provider, recentText, signal string) error {
+	// see ClaudeExecutor.patternError for why retry patterns are skipped when a signal is present
+	if signal == "" {
+		if pattern := matchPattern(recentText, e.RetryPatterns); pattern != "" {
+			return &RetryPatternError{Pattern: pattern}
+		}
+	}
+	if pattern := matchPattern(recentText, e.LimitPatterns); pattern != "" {
+		return &LimitPatternError{Pattern: pattern, HelpCmd: p.HelpCommand}
+	}
+	if pattern := matchPattern(recentText, e.ErrorPatterns); pattern != "" {
+		return &PatternMatchError{Pattern: pattern, HelpCmd: p.HelpCommand}
+	}
+	return nil
+}
+
+// parseStream reads the provider's JSONL output and applies its rules to every event.
+// non-JSON lines (e.g. stderr) are passed through as text. returns the result and the
+// text of the last error event, empty when the provider reported no error.
+func (e *GenericExecutor) parseStream(ctx context.Context, p *Provider, r io.Reader, idleTouch func()) (Result, string) {
+	var output strings.Builder
+	var signal, sessionID, resultText, errText string
+	var recentBlocks [recentBlockCount]string
+	var blockIdx int
+
+	emit := func(text string) {
+		output.WriteString(text)
+		if e.OutputHandler != nil {
+			e.OutputHandler(text)
+		}
+		recentBlocks[blockIdx%recentBlockCount] = text
+		blockIdx++
+		if sig := detectSignal(text); sig != "" {
+			signal = sig
+		}
+	}
+
+	err := readLines(ctx, r, func(line string) {
+		idleTouch()
+		if line == "" {
+			return
+		}
+
+		var event any
+		if jsonErr := json.Unmarshal([]byte(line), &event); jsonErr != nil {
+			if e.Debug {
+				log.Printf("[debug] non-JSON line: %s", line)
+			}
+			emit(line + "\n")
+			return
+		}
+
+		for _, rule := range p.Text {
+			if text, ok := rule.apply(event); ok && text != "" {
+				emit(text)
+			}
+		}
+		if text, ok := p.Result.apply(event); ok {
+			resultText = text
+		}
+		if id, ok := p.SessionID.apply(event); ok && id != "" && sessionID == "" {
+			sessionID = id
+			if e.Debug {
+				log.Printf("[debug] %s session id: %s", p.Name, id)
+			}
+		}
+		if text, ok := p.Error.apply(event); ok && text != "" {
+			errText = strings.TrimSpace(text)
+			emit(text)
+		}
+	})
+
+	// the final result is the output only when nothing was streamed, like claude's result event
+	if output.Len() == 0 && resultText != "" {
+		emit(resultText)
+	}
+
+	var recent strings.Builder
+	start := blockIdx % recentBlockCount
+	for i := range recentBlockCount {
+		if b := recentBlocks[(start+i)%recentBlockCount]; b != "" {
+			recent.WriteString(b)
+			recent.WriteString("\n")
+		}
+	}
+
+	res := Result{Output: output.String(), RecentText: recent.String(), Signal: signal, SessionID: sessionID}
+	if err != nil {
+		res.Error = fmt.Errorf("stream read: %w", err)
+	}
+	return res, errText
+}
+
+// apply returns the rule's value in event, ok is false when the event doesn't match the rule.
+func (r *ProviderRule) apply(event any) (string, bool) {
+	if r == nil {
+		return "", false
+	}
+	for path, want := range r.When {
+		got, ok := jsonPath(event, path)
+		if !ok || got != want {
+			return "", false
+		}
+	}
+	v, ok := jsonPath(event, r.Path)
+	if !ok {
+		return "", false
+	}
+	if r.Newline && v != "" && !strings.HasSuffix(v, "\n") {
+		v += "\n"
+	}
+	return v, true
+}
+
+// jsonPath returns the value at a dotted path of a decoded JSON value as a string. numeric
+// segments index arrays and a "[]" segment joins the values of all array elements.
+func jsonPath(v any, path string) (string, bool) {
+	segments := strings.Split(path, ".")
+	for i, seg := range segments {
+		switch node := v.(type) {
+		case map[string]any:
+			next, ok := node[seg]
+			if !ok {
+				return "", false
+			}
+			v = next
+		case []any:
+			if seg == "[]" {
+				rest := strings.Join(segments[i+1:], ".")
+				var sb strings.Builder
+				found := false
+				for _, el := range node {
+					if rest == "" {
+						if s, ok := jsonScalar(el); ok {
+							sb.WriteString(s)
+							found = true
+						}
+						continue
+					}
+					if s, ok := jsonPath(el, rest); ok {
+						sb.WriteString(s)
+						found = true
+					}
+				}
+				return sb.String(), found
+			}
+			idx, err := strconv.Atoi(seg)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return "", false
+			}
+			v = node[idx]
+		default:
+			return "", false
+		}
+	}
+	return jsonScalar(v)
+}
+
+// jsonScalar renders a decoded JSON scalar as a string, ok is false for objects, arrays and null.
+func jsonScalar(v any) (string, bool) {
+	switch val := v.(type) {
+	case string:
+		return val, true
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(val), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,344 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor/mocks"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+const codexLikeProvider = `{
+  "name": "codex",
+  "command": "codex",
+  "args": ["exec", "--json"],
+  "read_only_args": ["exec", "--json", "--sandbox", "read-only"],
+  "model_args": ["-m", "{{model}}"],
+  "effort_args": ["-c", "model_reasoning_effort={{effort}}"],
+  "help_command": "codex /status",
+  "text": [{"when": {"type": "item.completed", "item.type": "agent_message"}, "path": "item.text", "newline": true}],
+  "session_id": {"when": {"type": "thread.started"}, "path": "thread_id"},
+  "error": {"when": {"type": "turn.failed"}, "path": "error.message"}
+}`
+
+func writeProvider(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "provider.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func streamRunner(stream string, waitErr error) *mocks.CommandRunnerMock {
+	return &mocks.CommandRunnerMock{
+		RunFunc: func(_ context.Context, _ string, _ ...string) (io.Reader, func() error, error) {
+			return strings.NewReader(stream), func() error { return waitErr }, nil
+		},
+	}
+}
+
+func TestLoadProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "codex like", content: codexLikeProvider},
+		{name: "result only", content: `{"command": "agent", "result": {"path": "result"}}`},
+		{name: "no command", content: `{"text": [{"path": "text"}]}`, wantErr: "command is required"},
+		{name: "no rules", content: `{"command": "agent"}`, wantErr: "at least one text or result rule"},
+		{name: "rule without path", content: `{"command": "agent", "text": [{"when": {"type": "text"}}]}`,
+			wantErr: "text[0] rule has no path"},
+		{name: "bad prompt", content: `{"command": "agent", "prompt": "pipe", "text": [{"path": "text"}]}`,
+			wantErr: `invalid prompt "pipe"`},
+		{name: "unknown field", content: `{"command": "agent", "txt": [{"path": "text"}]}`, wantErr: "unknown field"},
+		{name: "not json", content: `command = agent`, wantErr: "parse provider file"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := LoadProvider(writeProvider(t, tc.content))
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, p.Name)
+			assert.Equal(t, PromptStdin, p.Prompt)
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadProvider(filepath.Join(t.TempDir(), "missing.json"))
+		require.ErrorContains(t, err, "read provider file")
+	})
+}
+
+func TestLoadProvider_ShippedProviders(t *testing.T) {
+	files, err := filepath.Glob("../../scripts/providers/*.json")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, f := range files {
+		_, err := LoadProvider(f)
+		require.NoError(t, err, f)
+	}
+}
+
+func TestGenericExecutor_Run(t *testing.T) {
+	stream := `{"type":"thread.started","thread_id":"th-1"}
+{"type":"item.completed","item":{"type":"reasoning","text":"thinking"}}
+{"type":"item.completed","item":{"type":"agent_message","text":"task done"}}
+plain stderr line
+{"type":"item.completed","item":{"type":"agent_message","text":"` + status.Completed + `"}}
+{"type":"turn.completed"}
+`
+	var gotCmd string
+	var gotArgs []string
+	mock := streamRunner(stream, nil)
+	mock.RunFunc = func(_ context.Context, name string, args ...string) (io.Reader, func() error, error) {
+		gotCmd, gotArgs = name, args
+		return strings.NewReader(stream), func() error { return nil }, nil
+	}
+	var handled strings.Builder
+	e := &GenericExecutor{ProviderFile: writeProvider(t, codexLikeProvider), Model: "gpt-5", Effort: "high",
+		OutputHandler: func(text string) { handled.WriteString(text) }, cmdRunner: mock}
+
+	result := e.Run(context.Background(), "do the task")
+
+	require.NoError(t, result.Error)
+	assert.Equal(t, "codex", gotCmd)
+	assert.Equal(t, []string{"exec", "--json", "-m", "gpt-5", "-c", "model_reasoning_effort=high"}, gotArgs)
+	assert.Equal(t, "task done\nplain stderr line\n"+status.Completed+"\n", result.Output)
+	assert.Equal(t, result.Output, handled.String())
+	assert.Equal(t, status.Completed, result.Signal)
+	assert.Equal(t, "th-1", result.SessionID)
+	assert.NotContains(t, result.Output, "thinking")
+}
+
+func TestGenericExecutor_Run_ReadOnly(t *testing.T) {
+	t.Run("uses read-only args", func(t *testing.T) {
+		mock := streamRunner("", nil)
+		e := &GenericExecutor{ProviderFile: writeProvider(t, codexLikeProvider), ReadOnly: true, cmdRunner: mock}
+		e.Run(context.Background(), "review")
+		require.Len(t, mock.RunCalls(), 1)
+		assert.Equal(t, []string{"exec", "--json", "--sandbox", "read-only"}, mock.RunCalls()[0].Args)
+	})
+
+	t.Run("provider without read-only args", func(t *testing.T) {
+		mock := streamRunner("", nil)
+		e := &GenericExecutor{ProviderFile: writeProvider(t, `{"command": "agent", "text": [{"path": "text"}]}`),
+			ReadOnly: true, cmdRunner: mock}
+		result := e.Run(context.Background(), "review")
+		require.ErrorContains(t, result.Error, "provider agent has no read_only_args")
+		assert.Empty(t, mock.RunCalls())
+	})
+}
+
+func TestGenericExecutor_Run_Errors(t *testing.T) {
+	t.Run("provider file error", func(t *testing.T) {
+		e := &GenericExecutor{ProviderFile: filepath.Join(t.TempDir(), "missing.json")}
+		result := e.Run(context.Background(), "prompt")
+		require.ErrorContains(t, result.Error, "read provider file")
+	})
+
+	t.Run("start error", func(t *testing.T) {
+		mock := &mocks.CommandRunnerMock{
+			RunFunc: func(_ context.Context, _ string, _ ...string) (io.Reader, func() error, error) {
+				return nil, nil, errors.New("command not found")
+			},
+		}
+		e := &GenericExecutor{ProviderFile: writeProvider(t, codexLikeProvider), cmdRunner: mock}
+		require.ErrorContains(t, e.Run(context.Background(), "prompt").Error, "command not found")
+	})
+
+	t.Run("exit error without output", func(t *testing.T) {
+		e := &GenericExecutor{ProviderFile: writeProvider(t, codexLikeProvider),
+			cmdRunner: streamRunner("", errors.New("exit status 2"))}
+		require.ErrorContains(t, e.Run(context.Background(), "prompt").Error, "codex exited with error: exit status 2")
+	})
+
+	t.Run("exit error ignored with signal", func(t *testing.T) {
+		stream := `{"type":"item.completed","item":{"type":"agent_message","text":"` + status.Completed + `"}}` + "\n"
+		e := &GenericExecutor{ProviderFile: writeProvider(t, codexLikeProvider),
+			cmdRunner: streamRunner(stream, errors.New("exit status 1"))}
+		result := e.Run(context.Background(), "prompt")
+		require.NoError(t, result.Error)
+		assert.Equal(t, status.Completed, result.Signal)
+	})
+
+	t.Run("error event", func(t *testing.T) {
+		stream := `{"type":"turn.failed","error":{"message":"model overloaded"}}` + "\n"
+		e := &GenericExecutor{ProviderFile: writeProvider(t, codexLikeProvider), cmdRunner: streamRunner(stream, nil)}
+		result := e.Run(context.Background(), "prompt")
+		require.ErrorContains(t, result.Error, "codex reported error: model overloaded")
+		assert.Equal(t, "model overloaded", result.Output)
+	})
+
+	t.Run("limit pattern in error event", func(t *testing.T) {
+		stream := `{"type":"turn.failed","error":{"message":"You've hit your usage limit"}}` + "\n"
+		e := &GenericExecutor{ProviderFile: writeProvider(t, codexLikeProvider), cmdRunner: streamRunner(stream, nil),
+			LimitPatterns: []string{"usage limit"}}
+		var limitErr *LimitPatternError
+		require.ErrorAs(t, e.Run(context.Background(), "prompt").Error, &limitErr)
+		assert.Equal(t, "usage limit", limitErr.Pattern)
+		assert.Equal(t, "codex /status", limitErr.HelpCmd)
+	})
+
+	t.Run("retry pattern", func(t *testing.T) {
+		e := &GenericExecutor{ProviderFile: writeProvider(t, codexLikeProvider),
+			cmdRunner: streamRunner("stream disconnected\n", nil), RetryPatterns: []string{"stream disconnected"}}
+		var retryErr *RetryPatternError
+		require.ErrorAs(t, e.Run(context.Background(), "prompt").Error, &retryErr)
+	})
+}
+
+func TestGenericExecutor_Run_IdleTimeout(t *testing.T) {
+	pr, pw := io.Pipe()
+	mock := &mocks.CommandRunnerMock{
+		RunFunc: func(ctx context.Context, _ string, _ ...string) (io.Reader, func() error, error) {
+			go func() {
+				_, _ = pw.Write([]byte(`{"type":"item.completed","item":{"type":"agent_message","text":"partial"}}` + "\n"))
+				<-ctx.Done()
+				_ = pw.CloseWithError(ctx.Err())
+			}()
+			return pr, func() error { return ctx.Err() }, nil
+		},
+	}
+	e := &GenericExecutor{ProviderFile: writeProvider(t, codexLikeProvider), IdleTimeout: 50 * time.Millisecond, cmdRunner: mock}
+
+	result := e.Run(context.Background(), "prompt")
+
+	require.NoError(t, result.Error)
+	assert.True(t, result.IdleTimedOut)
+	assert.Equal(t, "partial\n", result.Output)
+}
+
+func TestGenericExecutor_Run_ResultFallback(t *testing.T) {
+	provider := `{"command": "agent", "text": [{"when": {"type": "delta"}, "path": "text"}],
+		"result": {"when": {"type": "result"}, "path": "result"}}`
+
+	t.Run("result used when nothing streamed", func(t *testing.T) {
+		stream := `{"type":"result","result":"all done ` + status.ReviewDone + `"}` + "\n"
+		e := &GenericExecutor{ProviderFile: writeProvider(t, provider), cmdRunner: streamRunner(stream, nil)}
+		result := e.Run(context.Background(), "prompt")
+		require.NoError(t, result.Error)
+		assert.Equal(t, "all done "+status.ReviewDone, result.Output)
+		assert.Equal(t, status.ReviewDone, result.Signal)
+	})
+
+	t.Run("result ignored after streamed text", func(t *testing.T) {
+		stream := `{"type":"delta","text":"streamed"}` + "\n" + `{"type":"result","result":"streamed"}` + "\n"
+		e := &GenericExecutor{ProviderFile: writeProvider(t, provider), cmdRunner: streamRunner(stream, nil)}
+		assert.Equal(t, "streamed", e.Run(context.Background(), "prompt").Output)
+	})
+}
+
+func TestGenericExecutor_buildArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider Provider
+		model    string
+		want     []string
+	}{
+		{name: "stdin", provider: Provider{Prompt: PromptStdin, Args: []string{"run"}}, want: []string{"run"}},
+		{name: "arg placeholder", provider: Provider{Prompt: PromptArg, Args: []string{"run", "--prompt={{prompt}}", "-q"}},
+			want: []string{"run", "--prompt=fix {{model}}", "-q"}},
+		{name: "arg appended last", provider: Provider{Prompt: PromptArg, Args: []string{"run"},
+			ModelArgs: []string{"--model", "{{model}}"}}, model: "m1", want: []string{"run", "--model", "m1", "fix {{model}}"}},
+		{name: "model args skipped without model", provider: Provider{Prompt: PromptStdin, Args: []string{"run"},
+			ModelArgs: []string{"--model", "{{model}}"}}, want: []string{"run"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := &GenericExecutor{Model: tc.model}
+			args, cleanup, err := e.buildArgs(&tc.provider, "fix {{model}}")
+			require.NoError(t, err)
+			defer cleanup()
+			assert.Equal(t, tc.want, args)
+		})
+	}
+
+	t.Run("prompt file", func(t *testing.T) {
+		e := &GenericExecutor{}
+		p := &Provider{Prompt: PromptFile, Args: []string{"run", "--file", "{{prompt_file}}"}}
+		args, cleanup, err := e.buildArgs(p, "the prompt")
+		require.NoError(t, err)
+		require.Len(t, args, 3)
+		data, err := os.ReadFile(args[2])
+		require.NoError(t, err)
+		assert.Equal(t, "the prompt", string(data))
+		cleanup()
+		assert.NoFileExists(t, args[2])
+	})
+}
+
+func TestGenericExecutor_Run_RealCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	line := `{"type":"text","text":"from ` + status.Completed + `"}` + "\n"
+
+	t.Run("stdin", func(t *testing.T) {
+		provider := `{"command": "sh", "args": ["-c", "cat; echo \"$AGENT_MODE\" >&2"], "env": {"AGENT_MODE": "headless"},
+			"text": [{"when": {"type": "text"}, "path": "text", "newline": true}]}`
+		e := &GenericExecutor{ProviderFile: writeProvider(t, provider)}
+		result := e.Run(context.Background(), line)
+		require.NoError(t, result.Error)
+		assert.Equal(t, "from "+status.Completed+"\nheadless\n", result.Output)
+		assert.Equal(t, status.Completed, result.Signal)
+	})
+
+	t.Run("file", func(t *testing.T) {
+		provider := `{"command": "sh", "args": ["-c", "cat \"$0\"", "{{prompt_file}}"], "prompt": "file",
+			"text": [{"when": {"type": "text"}, "path": "text"}]}`
+		e := &GenericExecutor{ProviderFile: writeProvider(t, provider)}
+		result := e.Run(context.Background(), line)
+		require.NoError(t, result.Error)
+		assert.Equal(t, status.Completed, result.Signal)
+	})
+}
+
+func TestJSONPath(t *testing.T) {
+	event := map[string]any{
+		"type": "assistant",
+		"n":    float64(3),
+		"ok":   true,
+		"message": map[string]any{"content": []any{
+			map[string]any{"type": "text", "text": "a"},
+			map[string]any{"type": "tool_use"},
+			map[string]any{"type": "text", "text": "b"},
+		}},
+		"tags": []any{"x", "y"},
+	}
+	tests := []struct {
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{path: "type", want: "assistant", wantOK: true},
+		{path: "n", want: "3", wantOK: true},
+		{path: "ok", want: "true", wantOK: true},
+		{path: "message.content.0.text", want: "a", wantOK: true},
+		{path: "message.content.[].text", want: "ab", wantOK: true},
+		{path: "tags.[]", want: "xy", wantOK: true},
+		{path: "message.content.5.text"},
+		{path: "message"},
+		{path: "missing.path"},
+		{path: "type.sub"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			got, ok := jsonPath(event, tc.path)
+			assert.Equal(t, tc.wantOK, ok)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
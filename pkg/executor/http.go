@@ -0,0 +1,455 @@
+package executor
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultHTTPChunkTokens is the approximate diff size, in tokens, sent per http review request.
+const DefaultHTTPChunkTokens = 24000
+
+// DefaultHTTPIdleTimeout is how long an http review request may go without receiving data.
+const DefaultHTTPIdleTimeout = 5 * time.Minute
+
+const (
+	httpCharsPerToken = 4 // rough token estimate of source code and diffs
+	httpMaxRetries    = 3
+	httpRetryDelay    = 2 * time.Second
+	httpMaxErrorBody  = 64 << 10
+)
+
+// httpDiffNote tells the model it can't run the git commands of the review prompt.
+const httpDiffNote = "You can't run commands or read files. The diff to review is in the next message: " +
+	"review it directly and report the issues in the format described above."
+
+// errHTTPStalled cancels a request whose server sent no data for the idle timeout.
+var errHTTPStalled = errors.New("no data received")
+
+// HTTPExecutor reviews a diff through an OpenAI-compatible chat completions endpoint, e.g. OpenAI,
+// OpenRouter, vLLM or Ollama. the diff is split by file into chunks of about ChunkTokens, each chunk
+// is reviewed by a streaming request, and the findings of all chunks are joined.
+type HTTPExecutor struct {
+	BaseURL       string            // API base URL including the version, e.g. http://localhost:11434/v1
+	Model         string            // model name sent with each request
+	APIKeyEnv     string            // env var holding the API key, empty sends no Authorization header
+	ChunkTokens   int               // approximate diff tokens per request, 0 uses DefaultHTTPChunkTokens
+	IdleTimeout   time.Duration     // abort a request receiving no data for this long, 0 uses DefaultHTTPIdleTimeout
+	OutputHandler func(text string) // called for each output line, can be nil
+	ErrorPatterns []string          // patterns to detect in error responses
+	LimitPatterns []string          // patterns to detect rate limits in error responses (checked before error patterns)
+	client        *http.Client      // for testing, nil uses http.DefaultClient
+	retryDelay    time.Duration     // for testing, zero uses httpRetryDelay
+}
+
+// chatMessage is a message of the chat completions API.
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model         string        `json:"model"`
+	Messages      []chatMessage `json:"messages"`
+	Stream        bool          `json:"stream"`
+	StreamOptions struct {
+		IncludeUsage bool `json:"include_usage"`
+	} `json:"stream_options"`
+}
+
+type chatUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+}
+
+// chatResponse is a streamed chunk or, for servers ignoring stream, the whole response.
+type chatResponse struct {
+	Choices []struct {
+		Delta   chatMessage `json:"delta"`
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Usage *chatUsage `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// httpStatusError is a non-2xx response of the chat completions endpoint.
+type httpStatusError struct {
+	code int
+	body string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("http review request: %d %s: %s", e.code, http.StatusText(e.code), e.body)
+}
+
+// retryableError marks a failed request worth retrying: transport errors, broken streams, 429 and 5xx.
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+// Run reviews diff with the review prompt. an empty diff returns an empty result without calling
+// the endpoint. output is streamed line-by-line to OutputHandler.
+func (e *HTTPExecutor) Run(ctx context.Context, prompt, diff string) Result {
+	if e.BaseURL == "" || e.Model == "" {
+		return Result{Error: errors.New("http review endpoint not configured")}
+	}
+	var apiKey string
+	if e.APIKeyEnv != "" {
+		if apiKey = os.Getenv(e.APIKeyEnv); apiKey == "" {
+			return Result{Error: fmt.Errorf("http review API key env %s is not set", e.APIKeyEnv)}
+		}
+	}
+	if strings.TrimSpace(diff) == "" {
+		e.emit("no changes to review")
+		return Result{}
+	}
+
+	chunks := splitDiff(diff, cmp.Or(e.ChunkTokens, DefaultHTTPChunkTokens)*httpCharsPerToken)
+	var outputs []string
+	var usage Usage
+	for i, chunk := range chunks {
+		if len(chunks) > 1 {
+			e.emit(fmt.Sprintf("reviewing diff part %d of %d", i+1, len(chunks)))
+		}
+		messages := []chatMessage{
+			{Role: "system", Content: prompt + "\n\n" + httpDiffNote},
+			{Role: "user", Content: fmt.Sprintf("Diff to review (part %d of %d):\n\n```diff\n%s```", i+1, len(chunks), chunk)},
+		}
+		text, u, err := e.complete(ctx, apiKey, messages)
+		usage = usage.Add(u)
+		if errors.Is(err, errHTTPStalled) && ctx.Err() == nil {
+			// still stalled after the retries, a soft kill the caller retries like an idle timeout
+			return Result{Output: joinChunkReviews(outputs), Usage: usage, IdleTimedOut: true}
+		}
+		if err != nil {
+			return Result{Output: joinChunkReviews(outputs), Usage: usage, Error: e.classifyError(ctx, err)}
+		}
+		outputs = append(outputs, text)
+	}
+
+	output := joinChunkReviews(outputs)
+	return Result{Output: output, Signal: detectSignal(output), Usage: usage}
+}
+
+// complete sends one chat completions request, retrying retryable failures with a doubling delay.
+func (e *HTTPExecutor) complete(ctx context.Context, apiKey string, messages []chatMessage) (string, Usage, error) {
+	delay := cmp.Or(e.retryDelay, httpRetryDelay)
+	for attempt := 0; ; attempt++ {
+		text, usage, err := e.request(ctx, apiKey, messages)
+		if _, retryable := errors.AsType[*retryableError](err); !retryable || attempt >= httpMaxRetries || ctx.Err() != nil {
+			return text, usage, err
+		}
+		e.emit(fmt.Sprintf("%v, retrying in %s", err, delay))
+		select {
+		case <-ctx.Done():
+			return "", Usage{}, fmt.Errorf("context error: %w", ctx.Err())
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// request sends a streaming chat completions request and collects the answer. a server
+// answering with a plain JSON response instead of an event stream is handled too. the request
+// is canceled when no data arrives for the idle timeout, reported as a retryable stall.
+func (e *HTTPExecutor) request(ctx context.Context, apiKey string, messages []chatMessage) (string, Usage, error) {
+	body := chatRequest{Model: e.Model, Messages: messages, Stream: true}
+	body.StreamOptions.IncludeUsage = true
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("marshal request: %w", err)
+	}
+	idle := cmp.Or(e.IdleTimeout, DefaultHTTPIdleTimeout)
+	reqCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	timer := time.AfterFunc(idle, func() { cancel(errHTTPStalled) })
+	defer timer.Stop()
+	stalled := func(err error) error {
+		if err != nil && ctx.Err() == nil && errors.Is(context.Cause(reqCtx), errHTTPStalled) {
+			return &retryableError{err: fmt.Errorf("http review request: %w for %s", errHTTPStalled, idle)}
+		}
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, e.BaseURL+"/chat/completions", bytes.NewReader(data))
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	client := e.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", Usage{}, fmt.Errorf("context error: %w", ctx.Err())
+		}
+		if errors.Is(context.Cause(reqCtx), errHTTPStalled) {
+			return "", Usage{}, stalled(err)
+		}
+		return "", Usage{}, &retryableError{err: fmt.Errorf("http review request: %w", err)}
+	}
+	defer resp.Body.Close()
+	respBody := &idleReader{r: resp.Body, touch: func() { timer.Reset(idle) }}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		errBody, _ := io.ReadAll(io.LimitReader(respBody, httpMaxErrorBody))
+		statusErr := &httpStatusError{code: resp.StatusCode, body: strings.TrimSpace(string(errBody))}
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return "", Usage{}, &retryableError{err: statusErr}
+		}
+		return "", Usage{}, statusErr
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == "application/json" {
+		text, usage, err := e.readResponse(respBody)
+		return text, usage, stalled(err)
+	}
+	text, usage, err := e.readStream(reqCtx, respBody)
+	return text, usage, stalled(err)
+}
+
+// idleReader calls touch on every read returning data, which keeps the idle timer of a request from firing.
+type idleReader struct {
+	r     io.Reader
+	touch func()
+}
+
+func (r *idleReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.touch()
+	}
+	return n, err //nolint:wrapcheck // io.Reader contract, io.EOF must pass through unwrapped
+}
+
+// readStream collects the content deltas of a server-sent event stream.
+func (e *HTTPExecutor) readStream(ctx context.Context, r io.Reader) (string, Usage, error) {
+	var text strings.Builder
+	var usage Usage
+	var streamErr error
+	out := lineBuffer{emit: e.emit}
+	readErr := readLines(ctx, r, func(line string) {
+		payload, ok := strings.CutPrefix(line, "data:")
+		if payload = strings.TrimSpace(payload); !ok || payload == "" || payload == "[DONE]" || streamErr != nil {
+			return
+		}
+		var chunk chatResponse
+		if err := json.Unmarshal([]byte(payload), &chunk); err != nil {
+			streamErr = fmt.Errorf("decode stream event: %w", err)
+			return
+		}
+		if chunk.Error != nil {
+			streamErr = &httpStatusError{code: http.StatusInternalServerError, body: chunk.Error.Message}
+			return
+		}
+		if chunk.Usage != nil {
+			usage = Usage{InputTokens: chunk.Usage.PromptTokens, OutputTokens: chunk.Usage.CompletionTokens}
+		}
+		for _, choice := range chunk.Choices {
+			text.WriteString(choice.Delta.Content)
+			out.write(choice.Delta.Content)
+		}
+	})
+	out.flush()
+	if readErr != nil {
+		if ctx.Err() != nil {
+			return text.String(), usage, fmt.Errorf("context error: %w", ctx.Err())
+		}
+		return text.String(), usage, &retryableError{err: fmt.Errorf("read stream: %w", readErr)}
+	}
+	return text.String(), usage, streamErr
+}
+
+// readResponse decodes a non-streamed chat completions response.
+func (e *HTTPExecutor) readResponse(r io.Reader) (string, Usage, error) {
+	var resp chatResponse
+	if err := json.NewDecoder(r).Decode(&resp); err != nil {
+		return "", Usage{}, fmt.Errorf("decode response: %w", err)
+	}
+	var usage Usage
+	if resp.Usage != nil {
+		usage = Usage{InputTokens: resp.Usage.PromptTokens, OutputTokens: resp.Usage.CompletionTokens}
+	}
+	var text strings.Builder
+	for _, choice := range resp.Choices {
+		text.WriteString(choice.Message.Content)
+	}
+	out := lineBuffer{emit: e.emit}
+	out.write(text.String())
+	out.flush()
+	return text.String(), usage, nil
+}
+
+// classifyError maps a failed request to the limit and error pattern errors. patterns are matched
+// against error responses only, never against review output. a 429 response without a matching
+// limit pattern is still reported as a rate limit.
+func (e *HTTPExecutor) classifyError(ctx context.Context, err error) error {
+	statusErr, ok := errors.AsType[*httpStatusError](err)
+	if !ok || ctx.Err() != nil {
+		return err
+	}
+	helpCmd := "curl -s " + e.BaseURL + "/models"
+	if pattern := matchPattern(statusErr.body, e.LimitPatterns); pattern != "" {
+		return &LimitPatternError{Pattern: pattern, HelpCmd: helpCmd}
+	}
+	if pattern := matchPattern(statusErr.body, e.ErrorPatterns); pattern != "" {
+		return &PatternMatchError{Pattern: pattern, HelpCmd: helpCmd}
+	}
+	if statusErr.code == http.StatusTooManyRequests {
+		return &LimitPatternError{Pattern: "429 Too Many Requests", HelpCmd: helpCmd}
+	}
+	return err
+}
+
+func (e *HTTPExecutor) emit(line string) {
+	if e.OutputHandler != nil {
+		e.OutputHandler(line + "\n")
+	}
+}
+
+// lineBuffer turns streamed text fragments into whole lines.
+type lineBuffer struct {
+	emit func(line string)
+	buf  string
+}
+
+func (b *lineBuffer) write(s string) {
+	b.buf += s
+	for {
+		idx := strings.IndexByte(b.buf, '\n')
+		if idx < 0 {
+			return
+		}
+		b.emit(b.buf[:idx])
+		b.buf = b.buf[idx+1:]
+	}
+}
+
+func (b *lineBuffer) flush() {
+	if b.buf != "" {
+		b.emit(b.buf)
+		b.buf = ""
+	}
+}
+
+// joinChunkReviews joins the reviews of diff chunks. chunks without findings are left out,
+// unless no chunk has findings.
+func joinChunkReviews(outputs []string) string {
+	var findings []string
+	for _, out := range outputs {
+		if out = strings.TrimSpace(out); out != "" && !strings.EqualFold(out, "NO ISSUES FOUND") {
+			findings = append(findings, out)
+		}
+	}
+	switch {
+	case len(findings) > 0:
+		return strings.Join(findings, "\n\n") + "\n"
+	case len(outputs) > 0:
+		return "NO ISSUES FOUND\n"
+	default:
+		return ""
+	}
+}
+
+// splitDiff splits a unified diff into chunks of at most maxChars, keeping whole files together
+// when they fit. a larger file is split by hunks, each repeating the file header, and a huge hunk
+// by lines.
+func splitDiff(diff string, maxChars int) []string {
+	if !strings.HasSuffix(diff, "\n") {
+		diff += "\n"
+	}
+	var chunks []string
+	var cur strings.Builder
+	for _, piece := range diffPieces(diff, maxChars) {
+		if cur.Len() > 0 && cur.Len()+len(piece) > maxChars {
+			chunks = append(chunks, cur.String())
+			cur.Reset()
+		}
+		cur.WriteString(piece)
+	}
+	if cur.Len() > 0 {
+		chunks = append(chunks, cur.String())
+	}
+	return chunks
+}
+
+// diffPieces splits a diff into file sections no larger than maxChars where possible.
+func diffPieces(diff string, maxChars int) []string {
+	var pieces []string
+	for _, section := range splitBefore(diff, "diff --git ") {
+		if len(section) <= maxChars {
+			pieces = append(pieces, section)
+			continue
+		}
+		hunks := splitBefore(section, "@@ ")
+		var header string
+		if !strings.HasPrefix(hunks[0], "@@ ") {
+			header, hunks = hunks[0], hunks[1:]
+		}
+		for _, hunk := range hunks {
+			pieces = append(pieces, splitLines(header+hunk, maxChars)...)
+		}
+		if len(hunks) == 0 {
+			pieces = append(pieces, splitLines(header, maxChars)...)
+		}
+	}
+	return pieces
+}
+
+// splitBefore splits s before each line starting with prefix.
+func splitBefore(s, prefix string) []string {
+	var parts []string
+	var cur strings.Builder
+	for line := range strings.SplitAfterSeq(s, "\n") {
+		if strings.HasPrefix(line, prefix) && cur.Len() > 0 {
+			parts = append(parts, cur.String())
+			cur.Reset()
+		}
+		cur.WriteString(line)
+	}
+	if cur.Len() > 0 {
+		parts = append(parts, cur.String())
+	}
+	return parts
+}
+
+// splitLines splits s into parts of whole lines of at most maxChars, a longer line being a part of its own.
+func splitLines(s string, maxChars int) []string {
+	if len(s) <= maxChars {
+		return []string{s}
+	}
+	var parts []string
+	var cur strings.Builder
+	for line := range strings.SplitAfterSeq(s, "\n") {
+		if cur.Len() > 0 && cur.Len()+len(line) > maxChars {
+			parts = append(parts, cur.String())
+			cur.Reset()
+		}
+		cur.WriteString(line)
+	}
+	if cur.Len() > 0 {
+		parts = append(parts, cur.String())
+	}
+	return parts
+}
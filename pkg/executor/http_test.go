@@ -0,0 +1,317 @@
+package executor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chatStub is a stub OpenAI-compatible chat completions server.
+type chatStub struct {
+	mu       sync.Mutex
+	requests []chatRequest
+	auth     []string
+	handle   func(w http.ResponseWriter, call int, req chatRequest)
+}
+
+func (s *chatStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/chat/completions" || r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+	var req chatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	s.auth = append(s.auth, r.Header.Get("Authorization"))
+	call := len(s.requests)
+	s.mu.Unlock()
+	s.handle(w, call, req)
+}
+
+// streamAnswer writes text as server-sent content deltas followed by a usage event.
+func streamAnswer(w http.ResponseWriter, text string) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	for _, part := range strings.SplitAfter(text, " ") {
+		data, _ := json.Marshal(map[string]any{"choices": []any{map[string]any{"delta": map[string]string{"content": part}}}})
+		_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
+	}
+	_, _ = fmt.Fprint(w, "data: {\"choices\": [], \"usage\": {\"prompt_tokens\": 100, \"completion_tokens\": 20}}\n\n")
+	_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+}
+
+func newHTTPTestExecutor(t *testing.T, stub *chatStub) (*HTTPExecutor, *[]string) {
+	t.Helper()
+	srv := httptest.NewServer(stub)
+	t.Cleanup(srv.Close)
+	var lines []string
+	var mu sync.Mutex
+	return &HTTPExecutor{BaseURL: srv.URL + "/v1", Model: "qwen", retryDelay: time.Millisecond,
+		OutputHandler: func(text string) { mu.Lock(); lines = append(lines, text); mu.Unlock() }}, &lines
+}
+
+const testDiff = "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@\n-package a\n+package b\n"
+
+func TestHTTPExecutor_Run(t *testing.T) {
+	stub := &chatStub{handle: func(w http.ResponseWriter, _ int, _ chatRequest) {
+		streamAnswer(w, "- a.go:1 - wrong package name\n- a.go:1 - missing doc")
+	}}
+	e, lines := newHTTPTestExecutor(t, stub)
+	t.Setenv("TEST_REVIEW_KEY", "secret")
+	e.APIKeyEnv = "TEST_REVIEW_KEY"
+
+	result := e.Run(t.Context(), "review prompt", testDiff)
+
+	require.NoError(t, result.Error)
+	assert.Equal(t, "- a.go:1 - wrong package name\n- a.go:1 - missing doc\n", result.Output)
+	assert.Equal(t, Usage{InputTokens: 100, OutputTokens: 20}, result.Usage)
+	assert.Equal(t, []string{"- a.go:1 - wrong package name\n", "- a.go:1 - missing doc\n"}, *lines, "streamed by line")
+
+	require.Len(t, stub.requests, 1)
+	req := stub.requests[0]
+	assert.Equal(t, "qwen", req.Model)
+	assert.True(t, req.Stream)
+	assert.True(t, req.StreamOptions.IncludeUsage)
+	require.Len(t, req.Messages, 2)
+	assert.Equal(t, "system", req.Messages[0].Role)
+	assert.True(t, strings.HasPrefix(req.Messages[0].Content, "review prompt\n\n"))
+	assert.Contains(t, req.Messages[0].Content, "can't run commands")
+	assert.Equal(t, "Diff to review (part 1 of 1):\n\n```diff\n"+testDiff+"```", req.Messages[1].Content)
+	assert.Equal(t, "Bearer secret", stub.auth[0])
+}
+
+func TestHTTPExecutor_Run_Chunks(t *testing.T) {
+	stub := &chatStub{handle: func(w http.ResponseWriter, call int, _ chatRequest) {
+		if call == 2 {
+			streamAnswer(w, "NO ISSUES FOUND")
+			return
+		}
+		streamAnswer(w, fmt.Sprintf("- f%d.go:1 - issue", call))
+	}}
+	e, _ := newHTTPTestExecutor(t, stub)
+	e.ChunkTokens = 20 // 80 chars, one file per request
+	var diff strings.Builder
+	for i := 1; i <= 3; i++ {
+		fmt.Fprintf(&diff, "diff --git a/f%d.go b/f%d.go\n@@ -1 +1 @@\n-old line\n+new line\n", i, i)
+	}
+
+	result := e.Run(t.Context(), "prompt", diff.String())
+
+	require.NoError(t, result.Error)
+	require.Len(t, stub.requests, 3)
+	assert.Contains(t, stub.requests[2].Messages[1].Content, "(part 3 of 3)")
+	assert.Contains(t, stub.requests[2].Messages[1].Content, "f3.go")
+	assert.Equal(t, "- f1.go:1 - issue\n\n- f3.go:1 - issue\n", result.Output, "chunk without findings left out")
+	assert.Equal(t, Usage{InputTokens: 300, OutputTokens: 60}, result.Usage)
+}
+
+func TestHTTPExecutor_Run_EmptyDiff(t *testing.T) {
+	stub := &chatStub{handle: func(http.ResponseWriter, int, chatRequest) { t.Fatal("unexpected request") }}
+	e, lines := newHTTPTestExecutor(t, stub)
+
+	result := e.Run(t.Context(), "prompt", " \n")
+
+	require.NoError(t, result.Error)
+	assert.Empty(t, result.Output)
+	assert.Equal(t, []string{"no changes to review\n"}, *lines)
+}
+
+func TestHTTPExecutor_Run_Retries(t *testing.T) {
+	stub := &chatStub{handle: func(w http.ResponseWriter, call int, _ chatRequest) {
+		if call < 3 {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		streamAnswer(w, "NO ISSUES FOUND")
+	}}
+	e, lines := newHTTPTestExecutor(t, stub)
+
+	result := e.Run(t.Context(), "prompt", testDiff)
+
+	require.NoError(t, result.Error)
+	assert.Equal(t, "NO ISSUES FOUND\n", result.Output)
+	assert.Len(t, stub.requests, 3)
+	assert.Contains(t, (*lines)[0], "503 Service Unavailable: overloaded, retrying in 1ms")
+}
+
+func TestHTTPExecutor_Run_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      int
+		body      string
+		limit     []string
+		errs      []string
+		wantCalls int
+		check     func(t *testing.T, err error)
+	}{
+		{name: "client error is not retried", code: http.StatusBadRequest, body: "unknown model", wantCalls: 1,
+			check: func(t *testing.T, err error) { assert.ErrorContains(t, err, "400 Bad Request: unknown model") }},
+		{name: "error pattern", code: http.StatusUnauthorized, body: "invalid api key", errs: []string{"Invalid API key"}, wantCalls: 1,
+			check: func(t *testing.T, err error) {
+				patternErr, ok := errors.AsType[*PatternMatchError](err)
+				require.True(t, ok)
+				assert.Equal(t, "Invalid API key", patternErr.Pattern)
+			}},
+		{name: "rate limit after retries", code: http.StatusTooManyRequests, body: "slow down", wantCalls: httpMaxRetries + 1,
+			check: func(t *testing.T, err error) {
+				limitErr, ok := errors.AsType[*LimitPatternError](err)
+				require.True(t, ok)
+				assert.Equal(t, "429 Too Many Requests", limitErr.Pattern)
+			}},
+		{name: "limit pattern", code: http.StatusForbidden, body: "quota exceeded for today", limit: []string{"quota exceeded"}, wantCalls: 1,
+			check: func(t *testing.T, err error) {
+				limitErr, ok := errors.AsType[*LimitPatternError](err)
+				require.True(t, ok)
+				assert.Equal(t, "quota exceeded", limitErr.Pattern)
+				assert.Contains(t, limitErr.HelpCmd, "/v1/models")
+			}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := &chatStub{handle: func(w http.ResponseWriter, _ int, _ chatRequest) { http.Error(w, tc.body, tc.code) }}
+			e, _ := newHTTPTestExecutor(t, stub)
+			e.LimitPatterns, e.ErrorPatterns = tc.limit, tc.errs
+
+			result := e.Run(t.Context(), "prompt", testDiff)
+
+			require.Error(t, result.Error)
+			tc.check(t, result.Error)
+			assert.Len(t, stub.requests, tc.wantCalls)
+		})
+	}
+}
+
+func TestHTTPExecutor_Run_IdleTimeout(t *testing.T) {
+	stall := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, "data: {\"choices\": [{\"delta\": {\"content\": \"- a.go:1 - \"}}]}\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done() // the stream stalls until the client gives up
+	}
+
+	t.Run("stalled stream is retried", func(t *testing.T) {
+		var calls int
+		var mu sync.Mutex
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			calls++
+			call := calls
+			mu.Unlock()
+			if call == 1 {
+				stall(w, r)
+				return
+			}
+			streamAnswer(w, "NO ISSUES FOUND")
+		}))
+		t.Cleanup(srv.Close)
+		var lines []string
+		e := &HTTPExecutor{BaseURL: srv.URL + "/v1", Model: "qwen", IdleTimeout: 50 * time.Millisecond, retryDelay: time.Millisecond,
+			OutputHandler: func(text string) { lines = append(lines, text) }}
+
+		result := e.Run(t.Context(), "prompt", testDiff)
+
+		require.NoError(t, result.Error)
+		assert.False(t, result.IdleTimedOut)
+		assert.Equal(t, "NO ISSUES FOUND\n", result.Output)
+		assert.Equal(t, 2, calls)
+		assert.Contains(t, lines, "http review request: no data received for 50ms, retrying in 1ms\n")
+	})
+
+	t.Run("stream stalled on every retry is an idle timeout", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(stall))
+		t.Cleanup(srv.Close)
+		e := &HTTPExecutor{BaseURL: srv.URL + "/v1", Model: "qwen", IdleTimeout: 20 * time.Millisecond, retryDelay: time.Millisecond}
+
+		result := e.Run(t.Context(), "prompt", testDiff)
+
+		require.NoError(t, result.Error)
+		assert.True(t, result.IdleTimedOut)
+	})
+}
+
+func TestHTTPExecutor_Run_StreamError(t *testing.T) {
+	stub := &chatStub{handle: func(w http.ResponseWriter, _ int, _ chatRequest) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, "data: {\"error\": {\"message\": \"model crashed\"}}\n\n")
+	}}
+	e, _ := newHTTPTestExecutor(t, stub)
+
+	result := e.Run(t.Context(), "prompt", testDiff)
+
+	require.ErrorContains(t, result.Error, "model crashed")
+}
+
+func TestHTTPExecutor_Run_JSONResponse(t *testing.T) {
+	stub := &chatStub{handle: func(w http.ResponseWriter, _ int, _ chatRequest) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, _ = fmt.Fprint(w, `{"choices": [{"message": {"role": "assistant", "content": "- a.go:1 - bad"}}], "usage": {"prompt_tokens": 7, "completion_tokens": 3}}`)
+	}}
+	e, lines := newHTTPTestExecutor(t, stub)
+
+	result := e.Run(t.Context(), "prompt", testDiff)
+
+	require.NoError(t, result.Error)
+	assert.Equal(t, "- a.go:1 - bad\n", result.Output)
+	assert.Equal(t, Usage{InputTokens: 7, OutputTokens: 3}, result.Usage)
+	assert.Equal(t, []string{"- a.go:1 - bad\n"}, *lines)
+}
+
+func TestHTTPExecutor_Run_Config(t *testing.T) {
+	result := (&HTTPExecutor{Model: "qwen"}).Run(t.Context(), "prompt", testDiff)
+	require.ErrorContains(t, result.Error, "http review endpoint not configured")
+
+	t.Setenv("TEST_REVIEW_KEY", "")
+	result = (&HTTPExecutor{BaseURL: "http://localhost:1/v1", Model: "qwen", APIKeyEnv: "TEST_REVIEW_KEY"}).Run(t.Context(), "prompt", testDiff)
+	require.ErrorContains(t, result.Error, "http review API key env TEST_REVIEW_KEY is not set")
+}
+
+func TestSplitDiff(t *testing.T) {
+	fileA := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1,2 +1,2 @@\n-a1\n+a2\n@@ -10 +10 @@\n-a10\n+a11\n"
+	fileB := "diff --git a/b.go b/b.go\n@@ -1 +1 @@\n-b\n+c\n"
+
+	t.Run("fits in one chunk", func(t *testing.T) {
+		assert.Equal(t, []string{fileA + fileB}, splitDiff(fileA+fileB, 1000))
+	})
+
+	t.Run("whole files per chunk", func(t *testing.T) {
+		assert.Equal(t, []string{fileA, fileB}, splitDiff(fileA+fileB, len(fileA)+1))
+	})
+
+	t.Run("large file split by hunks with header", func(t *testing.T) {
+		chunks := splitDiff(fileA, 80)
+		header := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n"
+		assert.Equal(t, []string{header + "@@ -1,2 +1,2 @@\n-a1\n+a2\n", header + "@@ -10 +10 @@\n-a10\n+a11\n"}, chunks)
+	})
+
+	t.Run("huge hunk split by lines", func(t *testing.T) {
+		diff := "diff --git a/c.go b/c.go\n@@ -1 +1,40 @@\n" + strings.Repeat("+line\n", 40)
+		chunks := splitDiff(diff, 100)
+		assert.Greater(t, len(chunks), 2)
+		assert.Equal(t, diff, strings.Join(chunks, ""), "nothing lost")
+		for _, c := range chunks {
+			assert.LessOrEqual(t, len(c), 100)
+		}
+	})
+
+	t.Run("missing trailing newline", func(t *testing.T) {
+		assert.Equal(t, []string{fileB}, splitDiff(strings.TrimSuffix(fileB, "\n"), 1000))
+	})
+}
+
+func TestJoinChunkReviews(t *testing.T) {
+	assert.Empty(t, joinChunkReviews(nil))
+	assert.Equal(t, "NO ISSUES FOUND\n", joinChunkReviews([]string{"NO ISSUES FOUND", " no issues found\n"}))
+	assert.Equal(t, "- a.go:1 - x\n", joinChunkReviews([]string{"NO ISSUES FOUND", "- a.go:1 - x\n"}))
+}
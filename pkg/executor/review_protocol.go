@@ -0,0 +1,95 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// ReviewProtocolVersion is the version of the JSON protocol spoken with custom review scripts.
+const ReviewProtocolVersion = 1
+
+// ReviewRequest is the JSON request a custom review script receives on stdin. the script
+// still gets the prompt file path as its argument, so text-mode scripts can ignore stdin.
+type ReviewRequest struct {
+	Version            int      `json:"version"`
+	PromptFile         string   `json:"prompt_file"`                   // same file as the script argument
+	DiffCommand        string   `json:"diff_command"`                  // git diff command of this iteration
+	BaseRef            string   `json:"base_ref"`                      // branch or commit the changes are reviewed against
+	ChangedFiles       []string `json:"changed_files"`                 // files changed against base_ref, including uncommitted ones
+	PlanFile           string   `json:"plan_file,omitempty"`           // empty when reviewing without a plan
+	ProgressFile       string   `json:"progress_file,omitempty"`       // progress log with the previous iterations
+	Iteration          int      `json:"iteration"`                     // 1-based external review iteration
+	PreviousEvaluation string   `json:"previous_evaluation,omitempty"` // claude's answer to the previous findings
+}
+
+// ReviewResponse is the JSON answer of a custom review script. a script answering
+// in plain text instead is handled as before: the text is the findings.
+type ReviewResponse struct {
+	Version  int             `json:"version"`
+	Findings []ReviewFinding `json:"findings"`
+	Done     bool            `json:"done"`            // no more findings, same as the CODEX_REVIEW_DONE signal
+	Summary  string          `json:"summary"`         // optional free-form text shown before the findings
+	Usage    *Usage          `json:"usage,omitempty"` // optional tokens and cost of the review session
+}
+
+// ReviewFinding is a single finding of a JSON review response.
+type ReviewFinding struct {
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+}
+
+// parseReviewResponse finds a JSON review response in script output. lines printed before the
+// JSON object (e.g. stderr logging, merged into stdout) are ignored. ok is false when the
+// output holds no response, which means the script answered in plain text.
+func parseReviewResponse(output string) (resp ReviewResponse, ok bool, err error) {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var candidate ReviewResponse
+		if json.Unmarshal([]byte(strings.Join(lines[i:], "\n")), &candidate) != nil || candidate.Version == 0 {
+			continue
+		}
+		if candidate.Version > ReviewProtocolVersion {
+			return ReviewResponse{}, false, fmt.Errorf("unsupported review protocol version %d, expected %d",
+				candidate.Version, ReviewProtocolVersion)
+		}
+		return candidate, true, nil
+	}
+	return ReviewResponse{}, false, nil
+}
+
+// Text renders the response as the plain-text findings passed on to the evaluation prompt,
+// one "file:line - [severity] description" line per finding.
+func (r ReviewResponse) Text() string {
+	var sb strings.Builder
+	if r.Summary != "" {
+		sb.WriteString(strings.TrimSpace(r.Summary) + "\n\n")
+	}
+	for _, f := range r.Findings {
+		sb.WriteString("- ")
+		switch {
+		case f.File != "" && f.Line > 0:
+			fmt.Fprintf(&sb, "%s:%d - ", f.File, f.Line)
+		case f.File != "":
+			sb.WriteString(f.File + " - ")
+		}
+		if f.Severity != "" {
+			sb.WriteString("[" + f.Severity + "] ")
+		}
+		sb.WriteString(f.Description + "\n")
+	}
+	if len(r.Findings) == 0 {
+		sb.WriteString("NO ISSUES FOUND\n")
+	}
+	if r.Done {
+		sb.WriteString(status.CodexDone + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,119 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Usage holds token and cost accounting reported by an executor session.
+// CostUSD is only known for claude; codex reports tokens only.
+type Usage struct {
+	InputTokens         int64   `json:"input_tokens"`
+	OutputTokens        int64   `json:"output_tokens"`
+	CacheCreationTokens int64   `json:"cache_creation_tokens"`
+	CacheReadTokens     int64   `json:"cache_read_tokens"`
+	CostUSD             float64 `json:"cost_usd"`
+}
+
+// Add returns the sum of u and o.
+func (u Usage) Add(o Usage) Usage {
+	return Usage{
+		InputTokens:         u.InputTokens + o.InputTokens,
+		OutputTokens:        u.OutputTokens + o.OutputTokens,
+		CacheCreationTokens: u.CacheCreationTokens + o.CacheCreationTokens,
+		CacheReadTokens:     u.CacheReadTokens + o.CacheReadTokens,
+		CostUSD:             u.CostUSD + o.CostUSD,
+	}
+}
+
+// IsZero reports whether no usage was recorded.
+func (u Usage) IsZero() bool {
+	return u == Usage{}
+}
+
+// TotalTokens returns input, output, and cache tokens combined.
+func (u Usage) TotalTokens() int64 {
+	return u.InputTokens + u.OutputTokens + u.CacheCreationTokens + u.CacheReadTokens
+}
+
+// String formats usage for progress logs, e.g. "in 12.3k, out 4.5k, cache 1.2M/80k, $1.23".
+// cache is shown as read/write; cost is omitted when unknown.
+func (u Usage) String() string {
+	parts := []string{"in " + FormatTokens(u.InputTokens), "out " + FormatTokens(u.OutputTokens)}
+	if u.CacheReadTokens > 0 || u.CacheCreationTokens > 0 {
+		parts = append(parts, fmt.Sprintf("cache %s/%s", FormatTokens(u.CacheReadTokens), FormatTokens(u.CacheCreationTokens)))
+	}
+	if u.CostUSD > 0 {
+		parts = append(parts, fmt.Sprintf("$%.2f", u.CostUSD))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// FormatTokens renders a token count compactly: 950, 12.3k, 1.2M.
+func FormatTokens(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
+// claudeEventUsage is the token usage block of claude's stream-json "result" event.
+type claudeEventUsage struct {
+	InputTokens              int64 `json:"input_tokens"`
+	OutputTokens             int64 `json:"output_tokens"`
+	CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+}
+
+// resultUsage extracts session usage and cost from a claude "result" event.
+// ok is false for other events or when the event carries no accounting.
+func resultUsage(event *streamEvent) (Usage, bool) {
+	if event.Type != "result" {
+		return Usage{}, false
+	}
+	u := Usage{CostUSD: event.TotalCostUSD}
+	if event.Usage != nil {
+		u.InputTokens = event.Usage.InputTokens
+		u.OutputTokens = event.Usage.OutputTokens
+		u.CacheCreationTokens = event.Usage.CacheCreationInputTokens
+		u.CacheReadTokens = event.Usage.CacheReadInputTokens
+	}
+	return u, !u.IsZero()
+}
+
+// codexTokenCount is the payload of codex's rollout "event_msg" token_count record.
+// total_token_usage is cumulative for the session, so the last record wins.
+type codexTokenCount struct {
+	Type string `json:"type"`
+	Info *struct {
+		TotalTokenUsage struct {
+			InputTokens       int64 `json:"input_tokens"`
+			CachedInputTokens int64 `json:"cached_input_tokens"`
+			OutputTokens      int64 `json:"output_tokens"`
+		} `json:"total_token_usage"`
+	} `json:"info"`
+}
+
+// parseCodexTokenCount extracts cumulative session usage from a codex rollout line.
+// codex counts cached tokens inside input_tokens; they are split out as cache reads.
+func parseCodexTokenCount(line []byte) (Usage, bool) {
+	var ev rolloutEvent
+	if err := json.Unmarshal(line, &ev); err != nil || ev.Type != "event_msg" {
+		return Usage{}, false
+	}
+	var tc codexTokenCount
+	if err := json.Unmarshal(ev.Payload, &tc); err != nil || tc.Type != "token_count" || tc.Info == nil {
+		return Usage{}, false
+	}
+	total := tc.Info.TotalTokenUsage
+	return Usage{
+		InputTokens:     max(total.InputTokens-total.CachedInputTokens, 0),
+		OutputTokens:    total.OutputTokens,
+		CacheReadTokens: total.CachedInputTokens,
+	}, true
+}
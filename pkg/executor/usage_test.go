@@ -0,0 +1,82 @@
+package executor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsage_Add(t *testing.T) {
+	a := Usage{InputTokens: 10, OutputTokens: 5, CacheCreationTokens: 2, CacheReadTokens: 100, CostUSD: 0.5}
+	b := Usage{InputTokens: 1, OutputTokens: 2, CacheCreationTokens: 3, CacheReadTokens: 4, CostUSD: 0.25}
+	assert.Equal(t, Usage{InputTokens: 11, OutputTokens: 7, CacheCreationTokens: 5, CacheReadTokens: 104, CostUSD: 0.75}, a.Add(b))
+	assert.Equal(t, int64(127), a.Add(b).TotalTokens())
+	assert.True(t, Usage{}.IsZero())
+	assert.False(t, b.IsZero())
+}
+
+func TestUsage_String(t *testing.T) {
+	tests := []struct {
+		name  string
+		usage Usage
+		want  string
+	}{
+		{name: "tokens only", usage: Usage{InputTokens: 950, OutputTokens: 12345}, want: "in 950, out 12.3k"},
+		{name: "with cache and cost",
+			usage: Usage{InputTokens: 1200, OutputTokens: 300, CacheReadTokens: 1_500_000, CacheCreationTokens: 80_000, CostUSD: 1.234},
+			want:  "in 1.2k, out 300, cache 1.5M/80.0k, $1.23"},
+		{name: "zero", usage: Usage{}, want: "in 0, out 0"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.usage.String())
+		})
+	}
+}
+
+func TestClaudeExecutor_parseStream_usage(t *testing.T) {
+	input := `{"type":"content_block_delta","delta":{"type":"text_delta","text":"done"}}
+{"type":"result","total_cost_usd":0.0421,"usage":{"input_tokens":12,"output_tokens":340,"cache_creation_input_tokens":2000,"cache_read_input_tokens":45000}}`
+
+	e := &ClaudeExecutor{}
+	result := e.parseStream(context.Background(), strings.NewReader(input), func() {})
+
+	assert.Equal(t, Usage{InputTokens: 12, OutputTokens: 340, CacheCreationTokens: 2000, CacheReadTokens: 45000, CostUSD: 0.0421},
+		result.Usage)
+}
+
+func TestClaudeExecutor_parseStream_noUsage(t *testing.T) {
+	input := `{"type":"content_block_delta","delta":{"type":"text_delta","text":"done"}}
+{"type":"result","result":"done"}`
+
+	e := &ClaudeExecutor{}
+	result := e.parseStream(context.Background(), strings.NewReader(input), func() {})
+	assert.True(t, result.Usage.IsZero())
+}
+
+func TestParseCodexTokenCount(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		want   Usage
+		wantOK bool
+	}{
+		{name: "token count splits cached input",
+			line:   `{"type":"event_msg","payload":{"type":"token_count","info":{"total_token_usage":{"input_tokens":5000,"cached_input_tokens":3000,"output_tokens":700}}}}`,
+			want:   Usage{InputTokens: 2000, OutputTokens: 700, CacheReadTokens: 3000},
+			wantOK: true},
+		{name: "token count without info", line: `{"type":"event_msg","payload":{"type":"token_count","info":null}}`},
+		{name: "other event_msg", line: `{"type":"event_msg","payload":{"type":"agent_message","message":"hi"}}`},
+		{name: "response item", line: `{"type":"response_item","payload":{"type":"message","role":"assistant"}}`},
+		{name: "invalid json", line: `{not json`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := parseCodexTokenCount([]byte(tc.line))
+			assert.Equal(t, tc.wantOK, ok)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
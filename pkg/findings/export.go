@@ -0,0 +1,387 @@
+package findings
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format is an export format of the findings ledger.
+type Format string
+
+// supported export formats.
+const (
+	FormatSARIF       Format = "sarif"       // SARIF 2.1.0, for GitHub code scanning
+	FormatJUnit       Format = "junit"       // JUnit XML, one test case per finding
+	FormatMarkdown    Format = "markdown"    // markdown report
+	FormatCodeQuality Format = "codequality" // GitLab code quality report
+)
+
+// Formats lists the supported export formats.
+var Formats = []Format{FormatSARIF, FormatJUnit, FormatMarkdown, FormatCodeQuality}
+
+// ParseFormat returns the export format named s.
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if Format(strings.ToLower(strings.TrimSpace(s))) == f {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown findings format %q, expected one of sarif, junit, markdown, codequality", s)
+}
+
+// Ext returns the file extension of the format, appended to the ledger name for exported files.
+func (f Format) Ext() string {
+	switch f {
+	case FormatSARIF:
+		return ".sarif"
+	case FormatJUnit:
+		return ".junit.xml"
+	case FormatMarkdown:
+		return ".md"
+	case FormatCodeQuality:
+		return ".codequality.json"
+	}
+	return "." + string(f)
+}
+
+// Latest returns the last recorded state of every finding, in the order the findings were first reported.
+// findings are matched by file:line, or by file and description when the line is unknown, so a finding
+// confirmed by one iteration and fixed by a later one is exported once, as fixed.
+func (l *Ledger) Latest() []Finding {
+	index := map[string]int{}
+	var res []Finding
+	for _, f := range l.Findings {
+		key := f.Location()
+		if f.Line == 0 {
+			key = f.File + "\x00" + strings.ToLower(f.Description)
+		}
+		if i, ok := index[key]; ok {
+			res[i] = f
+			continue
+		}
+		index[key] = len(res)
+		res = append(res, f)
+	}
+	return res
+}
+
+// Export writes the latest state of the ledger findings to w in the given format.
+// SARIF keeps the unfixed findings, with false positives marked as suppressed; the code quality
+// report keeps the confirmed findings with a file; JUnit and markdown cover all findings.
+func (l *Ledger) Export(w io.Writer, format Format) error {
+	var data []byte
+	var err error
+	switch format {
+	case FormatSARIF:
+		data, err = l.sarif()
+	case FormatJUnit:
+		data, err = l.junit()
+	case FormatMarkdown:
+		data = l.markdown()
+	case FormatCodeQuality:
+		data, err = l.codeQuality()
+	default:
+		return fmt.Errorf("unknown findings format %q", format)
+	}
+	if err != nil {
+		return fmt.Errorf("export findings as %s: %w", format, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("write %s findings: %w", format, err)
+	}
+	return nil
+}
+
+// ruleID returns the rule (check) id of a finding: the agent that reported it.
+func ruleID(f Finding) string {
+	if f.Agent == "" {
+		return "review"
+	}
+	return f.Agent
+}
+
+// fingerprint returns a stable id of a finding, used by CI tools to track it across runs.
+func fingerprint(f Finding) string {
+	sum := sha256.Sum256([]byte(ruleID(f) + "\x00" + f.Location() + "\x00" + f.Description))
+	return hex.EncodeToString(sum[:16])
+}
+
+// sarif types cover the subset of SARIF 2.1.0 ralphex produces.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string             `json:"ruleId"`
+	RuleIndex           int                `json:"ruleIndex"`
+	Level               string             `json:"level"`
+	Message             sarifMessage       `json:"message"`
+	Locations           []sarifLocation    `json:"locations,omitempty"`
+	PartialFingerprints map[string]string  `json:"partialFingerprints"`
+	Suppressions        []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           *sarifRegion  `json:"region,omitempty"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+// sarifLevel maps a finding severity to a SARIF result level.
+func sarifLevel(severity string) string {
+	switch severity {
+	case "critical":
+		return "error"
+	case "major":
+		return "warning"
+	}
+	return "note"
+}
+
+// sarif renders the unfixed findings, one rule per agent.
+func (l *Ledger) sarif() ([]byte, error) {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{Name: "ralphex", InformationURI: "https://github.com/umputun/ralphex",
+			Rules: []sarifRule{}}},
+		Results: []sarifResult{},
+	}
+	rules := map[string]int{}
+	for _, f := range l.Latest() {
+		if f.Verdict == VerdictFixed {
+			continue
+		}
+		id := ruleID(f)
+		idx, ok := rules[id]
+		if !ok {
+			idx = len(run.Tool.Driver.Rules)
+			rules[id] = idx
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules,
+				sarifRule{ID: id, ShortDescription: sarifMessage{Text: "ralphex " + id + " review"}})
+		}
+		res := sarifResult{RuleID: id, RuleIndex: idx, Level: sarifLevel(f.Severity),
+			Message: sarifMessage{Text: f.Description}, PartialFingerprints: map[string]string{"ralphex/v1": fingerprint(f)}}
+		if f.File != "" {
+			loc := sarifPhysicalLocation{ArtifactLocation: sarifArtifact{URI: f.File}}
+			if f.Line > 0 {
+				loc.Region = &sarifRegion{StartLine: f.Line}
+			}
+			res.Locations = []sarifLocation{{PhysicalLocation: loc}}
+		}
+		if f.Verdict == VerdictFalsePositive {
+			res.Suppressions = []sarifSuppression{{Kind: "external", Justification: "dismissed as false positive by ralphex review"}}
+		}
+		run.Results = append(run.Results, res)
+	}
+	return marshalJSON(sarifLog{Schema: "https://json.schemastore.org/sarif-2.1.0.json", Version: "2.1.0",
+		Runs: []sarifRun{run}})
+}
+
+// junit types cover the JUnit XML accepted by common CI test report parsers.
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// junit renders one test suite per agent: fixed findings pass, confirmed findings fail
+// and false positives are skipped.
+func (l *Ledger) junit() ([]byte, error) {
+	report := junitSuites{Name: "ralphex review findings"}
+	suites := map[string]int{}
+	for _, f := range l.Latest() {
+		id := ruleID(f)
+		idx, ok := suites[id]
+		if !ok {
+			idx = len(report.Suites)
+			suites[id] = idx
+			report.Suites = append(report.Suites, junitSuite{Name: id})
+		}
+		name := f.Description
+		if loc := f.Location(); loc != "" {
+			name = loc + ": " + f.Description
+		}
+		tc := junitCase{ClassName: "ralphex." + id, Name: name}
+		suite := &report.Suites[idx]
+		switch f.Verdict {
+		case VerdictConfirmed:
+			tc.Failure = &junitFailure{Message: f.Description, Type: f.Severity, Text: f.Location()}
+			suite.Failures++
+		case VerdictFalsePositive:
+			tc.Skipped = &junitSkipped{Message: "false positive"}
+			suite.Skipped++
+		case VerdictFixed:
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, tc)
+	}
+	for _, s := range report.Suites {
+		report.Tests += s.Tests
+		report.Failures += s.Failures
+		report.Skipped += s.Skipped
+	}
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal junit report: %w", err)
+	}
+	return append(append([]byte(xml.Header), data...), '\n'), nil
+}
+
+// markdown renders a report with verdict counts and a table of all findings.
+func (l *Ledger) markdown() []byte {
+	latest := l.Latest()
+	counts := (&Ledger{Findings: latest}).Counts()
+	var b strings.Builder
+	b.WriteString("# Review findings\n\n")
+	if l.Plan != "" {
+		fmt.Fprintf(&b, "Plan: `%s`\n", l.Plan)
+	}
+	if l.Branch != "" {
+		fmt.Fprintf(&b, "Branch: `%s`\n", l.Branch)
+	}
+	fmt.Fprintf(&b, "\n%d findings: %d fixed, %d confirmed, %d false positives\n",
+		len(latest), counts[VerdictFixed], counts[VerdictConfirmed], counts[VerdictFalsePositive])
+	if len(latest) > 0 {
+		b.WriteString("\n| Verdict | Severity | Location | Agent | Description | Commit |\n")
+		b.WriteString("|---|---|---|---|---|---|\n")
+		for _, f := range latest {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n", f.Verdict, cell(f.Severity), cell(f.Location()),
+				cell(f.Agent), cell(f.Description), cell(f.Commit))
+		}
+	}
+	return []byte(b.String())
+}
+
+// cell escapes a markdown table cell.
+func cell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>").Replace(s)
+}
+
+// codeQualityIssue is an entry of the GitLab code quality report.
+type codeQualityIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    codeQualityLocation `json:"location"`
+}
+
+type codeQualityLocation struct {
+	Path  string           `json:"path"`
+	Lines codeQualityLines `json:"lines"`
+}
+
+type codeQualityLines struct {
+	Begin int `json:"begin"`
+}
+
+// codeQualitySeverity maps a finding severity to a GitLab code quality severity.
+func codeQualitySeverity(severity string) string {
+	switch severity {
+	case "critical", "major", "minor":
+		return severity
+	}
+	return "info"
+}
+
+// codeQuality renders the confirmed findings with a file; GitLab requires a location
+// and has no notion of dismissed issues.
+func (l *Ledger) codeQuality() ([]byte, error) {
+	issues := []codeQualityIssue{}
+	for _, f := range l.Latest() {
+		if f.Verdict != VerdictConfirmed || f.File == "" {
+			continue
+		}
+		issues = append(issues, codeQualityIssue{Description: f.Description, CheckName: ruleID(f),
+			Fingerprint: fingerprint(f), Severity: codeQualitySeverity(f.Severity),
+			Location: codeQualityLocation{Path: f.File, Lines: codeQualityLines{Begin: max(f.Line, 1)}}})
+	}
+	return marshalJSON(issues)
+}
+
+func marshalJSON(v any) ([]byte, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal report: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// ExportFile returns the path of the ledger at path exported in format, next to the ledger.
+func ExportFile(path string, format Format) string {
+	return strings.TrimSuffix(path, ".json") + format.Ext()
+}
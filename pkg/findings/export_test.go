@@ -0,0 +1,164 @@
+package findings
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLedger() *Ledger {
+	return &Ledger{Plan: "docs/plans/feature.md", Branch: "feature", Findings: []Finding{
+		{File: "a.go", Line: 3, Severity: "critical", Agent: "quality", Verdict: VerdictConfirmed, Description: "race"},
+		{File: "b.go", Line: 9, Severity: "minor", Agent: "codex", Verdict: VerdictFalsePositive, Description: "intended | by design"},
+		{File: "a.go", Line: 3, Severity: "critical", Agent: "quality", Verdict: VerdictFixed, Commit: "abc", Description: "race fixed"},
+		{File: "c.go", Line: 1, Severity: "major", Agent: "testing", Verdict: VerdictConfirmed, Description: "no test"},
+		{Agent: "documentation", Verdict: VerdictConfirmed, Description: "README is stale"},
+	}}
+}
+
+func TestParseFormat(t *testing.T) {
+	f, err := ParseFormat(" SARIF ")
+	require.NoError(t, err)
+	assert.Equal(t, FormatSARIF, f)
+	_, err = ParseFormat("pdf")
+	require.ErrorContains(t, err, `unknown findings format "pdf"`)
+}
+
+func TestExportFile(t *testing.T) {
+	assert.Equal(t, "/r/.ralphex/findings/feature.sarif", ExportFile("/r/.ralphex/findings/feature.json", FormatSARIF))
+	assert.Equal(t, "/r/feature.junit.xml", ExportFile("/r/feature.json", FormatJUnit))
+	assert.Equal(t, "/r/feature.codequality.json", ExportFile("/r/feature.json", FormatCodeQuality))
+}
+
+func TestLedger_Latest(t *testing.T) {
+	latest := testLedger().Latest()
+	require.Len(t, latest, 4)
+	assert.Equal(t, VerdictFixed, latest[0].Verdict, "later verdict at the same location wins")
+	assert.Equal(t, "abc", latest[0].Commit)
+	assert.Equal(t, "b.go", latest[1].File)
+	assert.Equal(t, "documentation", latest[3].Agent)
+}
+
+func TestLedger_Export(t *testing.T) {
+	t.Run("sarif", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, testLedger().Export(&buf, FormatSARIF))
+		var log sarifLog
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &log))
+		assert.Equal(t, "2.1.0", log.Version)
+		require.Len(t, log.Runs, 1)
+		run := log.Runs[0]
+		assert.Equal(t, "ralphex", run.Tool.Driver.Name)
+		ids := []string{}
+		for _, r := range run.Tool.Driver.Rules {
+			ids = append(ids, r.ID)
+		}
+		assert.Equal(t, []string{"codex", "testing", "documentation"}, ids, "fixed findings are not exported")
+
+		require.Len(t, run.Results, 3)
+		fp := run.Results[0]
+		assert.Equal(t, "codex", fp.RuleID)
+		assert.Equal(t, "note", fp.Level)
+		assert.Equal(t, "b.go", fp.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+		assert.Equal(t, 9, fp.Locations[0].PhysicalLocation.Region.StartLine)
+		require.Len(t, fp.Suppressions, 1)
+		assert.Equal(t, "external", fp.Suppressions[0].Kind)
+		assert.NotEmpty(t, fp.PartialFingerprints["ralphex/v1"])
+
+		confirmed := run.Results[1]
+		assert.Equal(t, 1, confirmed.RuleIndex)
+		assert.Equal(t, "warning", confirmed.Level)
+		assert.Empty(t, confirmed.Suppressions)
+		assert.Empty(t, run.Results[2].Locations, "finding without a file has no location")
+	})
+
+	t.Run("junit", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, testLedger().Export(&buf, FormatJUnit))
+		assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte(xml.Header)))
+		var report junitSuites
+		require.NoError(t, xml.Unmarshal(buf.Bytes(), &report))
+		assert.Equal(t, 4, report.Tests)
+		assert.Equal(t, 2, report.Failures)
+		assert.Equal(t, 1, report.Skipped)
+		require.Len(t, report.Suites, 4)
+		assert.Equal(t, "quality", report.Suites[0].Name)
+		assert.Equal(t, "a.go:3: race fixed", report.Suites[0].Cases[0].Name)
+		assert.Nil(t, report.Suites[0].Cases[0].Failure)
+		assert.NotNil(t, report.Suites[1].Cases[0].Skipped)
+		require.NotNil(t, report.Suites[2].Cases[0].Failure)
+		assert.Equal(t, "no test", report.Suites[2].Cases[0].Failure.Message)
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, testLedger().Export(&buf, FormatMarkdown))
+		out := buf.String()
+		assert.Contains(t, out, "# Review findings\n")
+		assert.Contains(t, out, "Branch: `feature`")
+		assert.Contains(t, out, "4 findings: 1 fixed, 2 confirmed, 1 false positives")
+		assert.Contains(t, out, "| fixed | critical | a.go:3 | quality | race fixed | abc |")
+		assert.Contains(t, out, `intended \| by design`)
+
+		buf.Reset()
+		require.NoError(t, (&Ledger{}).Export(&buf, FormatMarkdown))
+		assert.NotContains(t, buf.String(), "| Verdict |")
+	})
+
+	t.Run("codequality", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, testLedger().Export(&buf, FormatCodeQuality))
+		var issues []codeQualityIssue
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &issues))
+		require.Len(t, issues, 1, "only confirmed findings with a file")
+		assert.Equal(t, "testing", issues[0].CheckName)
+		assert.Equal(t, "major", issues[0].Severity)
+		assert.Equal(t, codeQualityLocation{Path: "c.go", Lines: codeQualityLines{Begin: 1}}, issues[0].Location)
+		assert.Len(t, issues[0].Fingerprint, 32)
+
+		buf.Reset()
+		require.NoError(t, (&Ledger{}).Export(&buf, FormatCodeQuality))
+		assert.Equal(t, "[]\n", buf.String())
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		require.ErrorContains(t, testLedger().Export(&bytes.Buffer{}, "pdf"), "unknown findings format")
+	})
+}
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, Dir)
+	older := &Ledger{Branch: "feature", Started: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)}
+	require.NoError(t, older.Save(filepath.Join(dir, "old-plan.json")))
+	newer := &Ledger{Branch: "feature", Started: time.Date(2026, 5, 2, 0, 0, 0, 0, time.UTC)}
+	require.NoError(t, newer.Save(filepath.Join(dir, "new-plan.json")))
+	require.NoError(t, (&Ledger{}).Save(filepath.Join(dir, "fix-login.json")))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new-plan.codequality.json"), []byte("[]"), 0o600))
+
+	path, err := Find(root, "feature")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "new-plan.json"), path, "most recent ledger of the branch")
+
+	path, err = Find(root, "fix/login")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "fix-login.json"), path, "ledger named after the branch")
+
+	path, err = Find(root, "docs/plans/old-plan.md")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "old-plan.json"), path)
+
+	path, err = Find(root, "/tmp/ledger.json")
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/ledger.json", path)
+
+	_, err = Find(root, "missing")
+	require.ErrorContains(t, err, `no findings ledger for branch "missing"`)
+}
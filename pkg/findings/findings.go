@@ -0,0 +1,179 @@
+// Package findings keeps the ledger of review findings of a run: every issue the review agents, the external
+// review tool and the evaluating agent reported, with its location, severity and what was done about it.
+package findings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Dir is the directory of findings ledgers within the project.
+const Dir = ".ralphex/findings"
+
+// Verdict is the outcome of a verified finding.
+type Verdict string
+
+// verdicts reported in FINDINGS payloads.
+const (
+	VerdictConfirmed     Verdict = "confirmed"      // real issue, left unfixed
+	VerdictFixed         Verdict = "fixed"          // real issue, fixed
+	VerdictFalsePositive Verdict = "false_positive" // dismissed after verification
+)
+
+// Finding is a single review finding.
+type Finding struct {
+	File        string    `json:"file,omitempty"`
+	Line        int       `json:"line,omitempty"`
+	Severity    string    `json:"severity,omitempty"` // critical, major or minor
+	Agent       string    `json:"agent,omitempty"`    // reviewer agent or external tool that reported it
+	Verdict     Verdict   `json:"verdict"`
+	Commit      string    `json:"commit,omitempty"` // commit of the fix, empty when not fixed or not committed yet
+	Description string    `json:"description"`
+	Stage       string    `json:"stage,omitempty"` // pipeline stage that recorded it, set by the ledger owner
+	Time        time.Time `json:"time,omitzero"`   // when it was recorded
+}
+
+// Normalize canonicalizes free-form verdict and severity spellings, e.g. "False Positive" to false_positive.
+func (f *Finding) Normalize() {
+	verdict := strings.ToLower(strings.TrimSpace(string(f.Verdict)))
+	f.Verdict = Verdict(strings.NewReplacer(" ", "_", "-", "_").Replace(verdict))
+	f.Severity = strings.ToLower(strings.TrimSpace(f.Severity))
+	f.File = strings.TrimSpace(f.File)
+	f.Description = strings.TrimSpace(f.Description)
+}
+
+// Validate checks that the finding has a description, a known verdict and a sane line.
+func (f Finding) Validate() error {
+	if f.Description == "" {
+		return errors.New("missing description")
+	}
+	switch f.Verdict {
+	case VerdictConfirmed, VerdictFixed, VerdictFalsePositive:
+	default:
+		return fmt.Errorf("unknown verdict %q", f.Verdict)
+	}
+	if f.Line < 0 {
+		return fmt.Errorf("invalid line %d", f.Line)
+	}
+	return nil
+}
+
+// Location returns file:line, the file alone without a line, or empty without a file.
+func (f Finding) Location() string {
+	if f.File == "" || f.Line == 0 {
+		return f.File
+	}
+	return fmt.Sprintf("%s:%d", f.File, f.Line)
+}
+
+// Ledger is the findings recorded during one run, in the order they were reported.
+// a finding reported again by a later review iteration is recorded again, so the ledger
+// shows how each issue evolved across the review pipeline.
+type Ledger struct {
+	Plan     string    `json:"plan,omitempty"`
+	Branch   string    `json:"branch,omitempty"`
+	Started  time.Time `json:"started,omitzero"`
+	Findings []Finding `json:"findings"`
+}
+
+// Counts returns the number of findings per verdict.
+func (l *Ledger) Counts() map[Verdict]int {
+	res := map[Verdict]int{}
+	for _, f := range l.Findings {
+		res[f.Verdict]++
+	}
+	return res
+}
+
+// Path returns the ledger path under root for a run named after the plan file, or after
+// the branch when there is no plan, e.g. review mode.
+func Path(root, planFile, branch string) string {
+	name := strings.TrimSuffix(filepath.Base(planFile), ".md")
+	if planFile == "" {
+		name = strings.NewReplacer("/", "-", "\\", "-").Replace(branch)
+	}
+	if name == "" {
+		name = "review"
+	}
+	return filepath.Join(root, Dir, name+".json")
+}
+
+// Find returns the ledger path for ref: a ledger file (.json), a plan file (.md) or a branch name.
+// a branch is matched against the branch recorded in the ledgers under root, the most recent one
+// wins, then against the ledger named after the branch.
+func Find(root, ref string) (string, error) {
+	switch {
+	case strings.HasSuffix(ref, ".json"):
+		return ref, nil
+	case strings.HasSuffix(ref, ".md"):
+		return Path(root, ref, ""), nil
+	}
+	paths, err := filepath.Glob(filepath.Join(root, Dir, "*.json"))
+	if err != nil {
+		return "", fmt.Errorf("list findings ledgers: %w", err)
+	}
+	var found string
+	var started time.Time
+	for _, p := range paths {
+		l, loadErr := Load(p)
+		if loadErr != nil || l.Branch != ref { // exported reports and foreign files don't parse as ledgers
+			continue
+		}
+		if found == "" || l.Started.After(started) {
+			found, started = p, l.Started
+		}
+	}
+	if found != "" {
+		return found, nil
+	}
+	if p := Path(root, "", ref); fileExists(p) {
+		return p, nil
+	}
+	return "", fmt.Errorf("no findings ledger for branch %q in %s", ref, filepath.Join(root, Dir))
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Load reads the ledger at path.
+func Load(path string) (*Ledger, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is the ralphex findings ledger
+	if err != nil {
+		return nil, fmt.Errorf("read findings ledger: %w", err)
+	}
+	var l Ledger
+	if err := json.Unmarshal(data, &l); err != nil {
+		return nil, fmt.Errorf("parse findings ledger %s: %w", path, err)
+	}
+	return &l, nil
+}
+
+// Save writes the ledger to path, creating its directory. the file is replaced atomically,
+// so readers never see a partial ledger.
+func (l *Ledger) Save(path string) error {
+	if l.Findings == nil {
+		l.Findings = []Finding{}
+	}
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal findings ledger: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("create findings dir: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write findings ledger: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replace findings ledger: %w", err)
+	}
+	return nil
+}
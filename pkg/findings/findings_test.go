@@ -0,0 +1,86 @@
+package findings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinding_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		f       Finding
+		wantErr string
+	}{
+		{name: "valid", f: Finding{File: "a.go", Line: 3, Verdict: VerdictFixed, Description: "bug"}},
+		{name: "no file", f: Finding{Verdict: VerdictConfirmed, Description: "docs are stale"}},
+		{name: "no description", f: Finding{Verdict: VerdictFixed}, wantErr: "missing description"},
+		{name: "unknown verdict", f: Finding{Verdict: "wontfix", Description: "x"}, wantErr: "unknown verdict"},
+		{name: "negative line", f: Finding{Line: -1, Verdict: VerdictFixed, Description: "x"}, wantErr: "invalid line"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.f.Validate()
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFinding_Normalize(t *testing.T) {
+	f := Finding{File: " a.go ", Severity: " Critical", Verdict: "False-Positive", Description: " x \n"}
+	f.Normalize()
+	assert.Equal(t, Finding{File: "a.go", Severity: "critical", Verdict: VerdictFalsePositive, Description: "x"}, f)
+}
+
+func TestFinding_Location(t *testing.T) {
+	assert.Equal(t, "a.go:3", Finding{File: "a.go", Line: 3}.Location())
+	assert.Equal(t, "a.go", Finding{File: "a.go"}.Location())
+	assert.Empty(t, Finding{Line: 3}.Location())
+}
+
+func TestPath(t *testing.T) {
+	assert.Equal(t, filepath.Join("/repo", Dir, "feature.json"), Path("/repo", "docs/plans/feature.md", "ignored"))
+	assert.Equal(t, filepath.Join("/repo", Dir, "fix-login.json"), Path("/repo", "", "fix/login"))
+	assert.Equal(t, filepath.Join(Dir, "review.json"), Path("", "", ""))
+}
+
+func TestLedger_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "findings", "feature.json")
+	started := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	empty := &Ledger{Plan: "docs/plans/feature.md", Started: started}
+	require.NoError(t, empty.Save(path))
+	data, err := os.ReadFile(path) //nolint:gosec // test file
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"findings": []`)
+
+	l := &Ledger{Plan: "docs/plans/feature.md", Started: started, Findings: []Finding{
+		{File: "a.go", Line: 3, Agent: "quality", Verdict: VerdictFixed, Commit: "abc", Description: "bug", Stage: "review_first",
+			Time: started.Add(time.Minute)},
+		{Agent: "codex", Verdict: VerdictFalsePositive, Description: "intended", Stage: "external"},
+		{Agent: "testing", Verdict: VerdictFixed, Description: "missing test"},
+	}}
+	require.NoError(t, l.Save(path))
+	_, err = os.Stat(path + ".tmp")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, l, loaded)
+	assert.Equal(t, map[Verdict]int{VerdictFixed: 2, VerdictFalsePositive: 1}, loaded.Counts())
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o600))
+	_, err = Load(path)
+	require.ErrorContains(t, err, "parse findings ledger")
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -435,6 +436,74 @@ func (e *externalBackend) diffStats(baseBranch string) (DiffStats, error) {
 	return result, nil
 }
 
+// changedFiles lists files changed since the merge base of baseRef and HEAD: committed,
+// uncommitted and untracked ones. returns nil if baseRef can't be resolved.
+func (e *externalBackend) changedFiles(baseRef string) ([]string, error) {
+	ref := e.resolveRef(baseRef)
+	if ref == "" {
+		return nil, nil
+	}
+	mergeBase, err := e.run("merge-base", ref, "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("merge base: %w", err)
+	}
+	tracked, err := e.run("diff", "--name-only", strings.TrimSpace(mergeBase))
+	if err != nil {
+		return nil, fmt.Errorf("diff names: %w", err)
+	}
+	untracked, err := e.run("ls-files", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, fmt.Errorf("untracked files: %w", err)
+	}
+
+	var files []string
+	for line := range strings.SplitSeq(tracked+"\n"+untracked, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	slices.Sort(files)
+	return slices.Compact(files), nil
+}
+
+// branchDiff returns the diff of tracked files since the merge base of baseRef and HEAD,
+// committed and uncommitted changes together. returns empty if baseRef can't be resolved.
+func (e *externalBackend) branchDiff(baseRef string) (string, error) {
+	ref := e.resolveRef(baseRef)
+	if ref == "" {
+		return "", nil
+	}
+	mergeBase, err := e.run("merge-base", ref, "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("merge base: %w", err)
+	}
+	diff, err := e.run("diff", "--no-color", "--no-ext-diff", strings.TrimSpace(mergeBase))
+	if err != nil {
+		return "", fmt.Errorf("diff: %w", err)
+	}
+	return diff, nil
+}
+
+// commitLog returns the one-line log of commits since the merge base of baseRef and HEAD, oldest first.
+// returns nil if baseRef can't be resolved.
+func (e *externalBackend) commitLog(baseRef string) ([]string, error) {
+	ref := e.resolveRef(baseRef)
+	if ref == "" {
+		return nil, nil
+	}
+	out, err := e.run("log", "--reverse", "--no-decorate", "--no-color", "--format=%h %s", ref+"..HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("log: %w", err)
+	}
+	var commits []string
+	for line := range strings.SplitSeq(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			commits = append(commits, line)
+		}
+	}
+	return commits, nil
+}
+
 // resolveRef tries to resolve a branch name to a valid git ref.
 // checks local branch, remote tracking (origin/<name>), "origin/" prefixed names,
 // and finally arbitrary refs like commit hashes or tags via rev-parse.
@@ -519,6 +588,41 @@ func (e *externalBackend) addWorktree(path, branch string, createBranch bool) er
 	return nil
 }
 
+// addDetachedWorktree creates a git worktree at the given path with HEAD detached at ref.
+func (e *externalBackend) addDetachedWorktree(path, ref string) error {
+	if _, err := e.run("worktree", "add", "--detach", path, ref); err != nil {
+		return fmt.Errorf("add worktree: %w", err)
+	}
+	return nil
+}
+
+// resolveCommit returns the full hash of the commit ref points to.
+func (e *externalBackend) resolveCommit(ref string) (string, error) {
+	out, err := e.run("rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil || out == "" {
+		return "", fmt.Errorf("unknown commit %q", ref)
+	}
+	return out, nil
+}
+
+// applyPatch applies a patch file to the working tree: a mailbox with git am (aborted on failure),
+// a plain diff with git apply --index, leaving the changes staged.
+func (e *externalBackend) applyPatch(path string, mailbox bool) error {
+	if !mailbox {
+		if _, err := e.run("apply", "--index", path); err != nil {
+			return fmt.Errorf("apply: %w", err)
+		}
+		return nil
+	}
+	if _, err := e.run("am", "--3way", path); err != nil {
+		if _, abortErr := e.run("am", "--abort"); abortErr != nil {
+			return fmt.Errorf("am: %w (abort: %w)", err, abortErr)
+		}
+		return fmt.Errorf("am: %w", err)
+	}
+	return nil
+}
+
 // removeWorktree removes a git worktree at the given path.
 func (e *externalBackend) removeWorktree(path string) error {
 	_, err := e.run("worktree", "remove", "--force", path)
@@ -537,6 +641,17 @@ func (e *externalBackend) pruneWorktrees() error {
 	return nil
 }
 
+// merge merges branch into the current branch, aborting the merge on failure.
+func (e *externalBackend) merge(branch string) error {
+	if _, err := e.run("merge", "--no-ff", "--no-edit", branch); err != nil {
+		if _, abortErr := e.run("merge", "--abort"); abortErr != nil {
+			return fmt.Errorf("merge: %w (abort: %w)", err, abortErr)
+		}
+		return fmt.Errorf("merge: %w", err)
+	}
+	return nil
+}
+
 // extractPathFromPorcelain extracts file path from git status --porcelain output.
 // format: "XY path" or "XY original -> renamed"
 func (e *externalBackend) extractPathFromPorcelain(line string) string {
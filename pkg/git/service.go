@@ -1,11 +1,14 @@
 package git
 
 import (
+	"bytes"
+	"cmp"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/umputun/ralphex/pkg/plan"
@@ -40,9 +43,16 @@ type backend interface {
 	commitFiles(msg string, paths ...string) error
 	createInitialCommit(msg string) error
 	diffStats(baseBranch string) (DiffStats, error)
+	changedFiles(baseRef string) ([]string, error)
+	branchDiff(baseRef string) (string, error)
+	commitLog(baseRef string) ([]string, error)
 	addWorktree(path, branch string, createBranch bool) error
+	addDetachedWorktree(path, ref string) error
+	resolveCommit(ref string) (string, error)
+	applyPatch(path string, mailbox bool) error
 	removeWorktree(path string) error
 	pruneWorktrees() error
+	merge(branch string) error
 }
 
 // DiffStats holds statistics about changes between two commits.
@@ -55,9 +65,10 @@ type DiffStats struct {
 // Service provides git operations for ralphex workflows.
 // It is the single public API for the git package.
 type Service struct {
-	repo    backend
-	log     Logger
-	trailer string // optional trailer line appended to all commits
+	repo       backend
+	log        Logger
+	trailer    string   // optional trailer line appended to all commits
+	allowDirty []string // repo-relative paths that may be uncommitted when a plan branch or worktree is created
 }
 
 // NewService opens a git repository and returns a Service.
@@ -82,6 +93,35 @@ func (s *Service) SetCommitTrailer(trailer string) {
 	s.trailer = trailer
 }
 
+// AllowDirtyPaths marks files that may have uncommitted changes when a plan branch or
+// worktree is created, e.g. the other pending plans of a queue. paths outside the repo are ignored.
+func (s *Service) AllowDirtyPaths(paths ...string) {
+	for _, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			continue
+		}
+		if resolved, evalErr := filepath.EvalSymlinks(abs); evalErr == nil {
+			abs = resolved
+		}
+		rel, err := filepath.Rel(s.repo.root(), abs)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		s.allowDirty = append(s.allowDirty, filepath.ToSlash(rel))
+	}
+}
+
+// MergeBranch merges branch into the current branch with a merge commit.
+// a conflicting merge is aborted, leaving the working tree as it was.
+func (s *Service) MergeBranch(branch string) error {
+	s.log.Printf("merging branch: %s\n", branch)
+	if err := s.repo.merge(branch); err != nil {
+		return fmt.Errorf("merge %s: %w", branch, err)
+	}
+	return nil
+}
+
 // appendTrailer appends the configured trailer to a commit message.
 // returns the message unchanged when no trailer is configured.
 func (s *Service) appendTrailer(msg string) string {
@@ -199,6 +239,9 @@ func (s *Service) preparePlanBranch(planFile string, requireDefault bool, defaul
 	if err != nil {
 		return "", false, fmt.Errorf("check uncommitted files: %w", err)
 	}
+	dirtyFiles = slices.DeleteFunc(dirtyFiles, func(f string) bool {
+		return slices.ContainsFunc(s.allowDirty, func(a string) bool { return strings.EqualFold(a, f) })
+	})
 	if len(dirtyFiles) > 0 {
 		fileList := s.formatDirtyFiles(dirtyFiles)
 		if requireDefault {
@@ -323,6 +366,81 @@ func (s *Service) CreateWorktreeForPlan(planFile, defaultBranch, branchOverride
 	return wtPath, planHasChanges, nil
 }
 
+// ResolveRange resolves a commit range "A..B" to the commit hashes of its ends.
+// an omitted end defaults to HEAD, as in git; symmetric "A...B" ranges are rejected.
+func (s *Service) ResolveRange(commitRange string) (base, head string, err error) {
+	from, to, ok := strings.Cut(commitRange, "..")
+	if !ok || strings.HasPrefix(to, ".") || (from == "" && to == "") {
+		return "", "", fmt.Errorf("invalid commit range %q, expected A..B", commitRange)
+	}
+	if base, err = s.repo.resolveCommit(cmp.Or(from, "HEAD")); err != nil {
+		return "", "", fmt.Errorf("resolve range start: %w", err)
+	}
+	if head, err = s.repo.resolveCommit(cmp.Or(to, "HEAD")); err != nil {
+		return "", "", fmt.Errorf("resolve range end: %w", err)
+	}
+	return base, head, nil
+}
+
+// ResolveCommit returns the commit hash of ref (branch, tag, hash or expression like HEAD~2).
+func (s *Service) ResolveCommit(ref string) (string, error) {
+	hash, err := s.repo.resolveCommit(ref)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", ref, err)
+	}
+	return hash, nil
+}
+
+// CreateReviewWorktree creates a scratch worktree at .ralphex/worktrees/<name> with HEAD detached at ref,
+// so changes outside the current branch can be reviewed without checking anything out in the main repo.
+// returns the worktree path. a review that keeps its fixes leaves the worktree behind, so reviewing
+// the same range or patch again fails with the command removing it.
+func (s *Service) CreateReviewWorktree(name, ref string) (string, error) {
+	wtPath := filepath.Join(s.repo.root(), ".ralphex", "worktrees", name)
+	if pruneErr := s.repo.pruneWorktrees(); pruneErr != nil {
+		s.log.Printf("warning: prune worktrees: %v\n", pruneErr)
+	}
+	if _, statErr := os.Stat(wtPath); statErr == nil {
+		return "", fmt.Errorf("review worktree %s is left over from a previous review of the same changes, "+
+			"check its commits and remove it with: git worktree remove %s", wtPath, wtPath)
+	}
+	s.log.Printf("creating review worktree at %s\n", ref)
+	if err := s.repo.addDetachedWorktree(wtPath, ref); err != nil {
+		return "", fmt.Errorf("add review worktree: %w", err)
+	}
+	return wtPath, nil
+}
+
+// ApplyPatch commits the patch file at path on top of HEAD. a mailbox (git format-patch output) is
+// applied with git am, keeping its commits, authors and messages; a plain diff is applied with
+// git apply and committed as a single commit.
+func (s *Service) ApplyPatch(path string) error {
+	mailbox, err := isMailbox(path)
+	if err != nil {
+		return err
+	}
+	s.log.Printf("applying patch: %s\n", path)
+	if err := s.repo.applyPatch(path, mailbox); err != nil {
+		return fmt.Errorf("apply patch %s: %w", filepath.Base(path), err)
+	}
+	if mailbox {
+		return nil
+	}
+	if err := s.repo.commit("apply patch " + filepath.Base(path)); err != nil {
+		return fmt.Errorf("commit patch %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// isMailbox reports whether the patch file is a mailbox, i.e. starts with the "From <hash>" line of git format-patch.
+func isMailbox(path string) (bool, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // user-provided patch file
+	if err != nil {
+		return false, fmt.Errorf("read patch: %w", err)
+	}
+	return bytes.HasPrefix(data, []byte("From ")), nil
+}
+
 // CommitPlanFile stages and commits a plan file on the current branch.
 // mainRepoRoot is the root of the main repository, used to compute the plan file's
 // relative path when the service operates inside a worktree.
@@ -593,8 +711,38 @@ func (s *Service) DiffStats(baseBranch string) (DiffStats, error) {
 	return s.repo.diffStats(baseBranch)
 }
 
+// ChangedFiles returns the files changed on the branch since it forked from baseRef, including
+// uncommitted and untracked files, sorted. returns nil if baseRef doesn't exist.
+func (s *Service) ChangedFiles(baseRef string) ([]string, error) {
+	files, err := s.repo.changedFiles(baseRef)
+	if err != nil {
+		return nil, fmt.Errorf("changed files against %q: %w", baseRef, err)
+	}
+	return files, nil
+}
+
+// BranchDiff returns the diff of the branch since it forked from baseRef, including uncommitted
+// changes of tracked files. returns empty if baseRef doesn't exist.
+func (s *Service) BranchDiff(baseRef string) (string, error) {
+	diff, err := s.repo.branchDiff(baseRef)
+	if err != nil {
+		return "", fmt.Errorf("branch diff against %q: %w", baseRef, err)
+	}
+	return diff, nil
+}
+
+// CommitLog returns the commits of the branch since it forked from baseRef as "<short hash> <subject>"
+// lines, oldest first. returns nil if baseRef doesn't exist.
+func (s *Service) CommitLog(baseRef string) ([]string, error) {
+	commits, err := s.repo.commitLog(baseRef)
+	if err != nil {
+		return nil, fmt.Errorf("commit log against %q: %w", baseRef, err)
+	}
+	return commits, nil
+}
+
 // EnsureLocalGitignore creates .ralphex/.gitignore with patterns for runtime artifacts
-// (progress/ and worktrees/). this keeps ignore rules self-contained inside .ralphex/
+// (progress/, worktrees/ and findings/). this keeps ignore rules self-contained inside .ralphex/
 // instead of modifying the project's root .gitignore.
 // idempotent: does nothing if the file already exists with the expected content.
 func (s *Service) EnsureLocalGitignore() error {
@@ -604,7 +752,7 @@ func (s *Service) EnsureLocalGitignore() error {
 	}
 
 	gitignorePath := filepath.Join(ralphexDir, ".gitignore")
-	const content = ".gitignore\nprogress/\nworktrees/\n"
+	const content = ".gitignore\nprogress/\nworktrees/\nfindings/\n"
 
 	if existing, err := os.ReadFile(gitignorePath); err == nil { //nolint:gosec // .gitignore is world-readable
 		if string(existing) == content {
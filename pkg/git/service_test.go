@@ -960,7 +960,7 @@ func TestService_EnsureLocalGitignore(t *testing.T) {
 		gitignorePath := filepath.Join(dir, ".ralphex", ".gitignore")
 		content, err := os.ReadFile(gitignorePath) //nolint:gosec // test file
 		require.NoError(t, err)
-		assert.Equal(t, ".gitignore\nprogress/\nworktrees/\n", string(content))
+		assert.Equal(t, ".gitignore\nprogress/\nworktrees/\nfindings/\n", string(content))
 	})
 
 	t.Run("idempotent when content matches", func(t *testing.T) {
@@ -972,7 +972,7 @@ func TestService_EnsureLocalGitignore(t *testing.T) {
 		require.NoError(t, os.MkdirAll(filepath.Join(dir, ".ralphex"), 0o750))
 		require.NoError(t, os.WriteFile(
 			filepath.Join(dir, ".ralphex", ".gitignore"),
-			[]byte(".gitignore\nprogress/\nworktrees/\n"), 0o600))
+			[]byte(".gitignore\nprogress/\nworktrees/\nfindings/\n"), 0o600))
 
 		err = svc.EnsureLocalGitignore()
 		require.NoError(t, err)
@@ -996,7 +996,7 @@ func TestService_EnsureLocalGitignore(t *testing.T) {
 
 		content, err := os.ReadFile(filepath.Join(dir, ".ralphex", ".gitignore")) //nolint:gosec // test file
 		require.NoError(t, err)
-		assert.Equal(t, ".gitignore\nprogress/\nworktrees/\n", string(content))
+		assert.Equal(t, ".gitignore\nprogress/\nworktrees/\nfindings/\n", string(content))
 	})
 
 	t.Run("creates .ralphex dir if missing", func(t *testing.T) {
@@ -1574,6 +1574,189 @@ func TestService_RemoveWorktree(t *testing.T) {
 	})
 }
 
+func TestService_AllowDirtyPaths(t *testing.T) {
+	setup := func(t *testing.T) (svc *Service, planA, planB string) {
+		t.Helper()
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		plansDir := filepath.Join(dir, "docs", "plans")
+		require.NoError(t, os.MkdirAll(plansDir, 0o750))
+		planA, planB = filepath.Join(plansDir, "queue-a.md"), filepath.Join(plansDir, "queue-b.md")
+		require.NoError(t, os.WriteFile(planA, []byte("# A"), 0o600))
+		require.NoError(t, os.WriteFile(planB, []byte("# B"), 0o600))
+		return svc, planA, planB
+	}
+
+	t.Run("other untracked plan blocks worktree by default", func(t *testing.T) {
+		svc, planA, _ := setup(t)
+		_, _, err := svc.CreateWorktreeForPlan(planA, "master", "")
+		require.ErrorContains(t, err, "queue-b.md")
+	})
+
+	t.Run("allowed plans do not block worktree", func(t *testing.T) {
+		svc, planA, planB := setup(t)
+		svc.AllowDirtyPaths(planA, planB, "/outside/repo.md")
+		assert.Equal(t, []string{"docs/plans/queue-a.md", "docs/plans/queue-b.md"}, svc.allowDirty)
+
+		wtPath, planNeedsCommit, err := svc.CreateWorktreeForPlan(planA, "master", "")
+		require.NoError(t, err)
+		assert.True(t, planNeedsCommit)
+		require.NoError(t, svc.RemoveWorktree(wtPath))
+	})
+}
+
+func TestService_MergeBranch(t *testing.T) {
+	t.Run("merges branch with merge commit", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		runGit(t, dir, "checkout", "-b", "feature")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "feature.txt"), []byte("feature\n"), 0o600))
+		runGit(t, dir, "add", "feature.txt")
+		runGit(t, dir, "commit", "-m", "add feature")
+		runGit(t, dir, "checkout", "master")
+
+		require.NoError(t, svc.MergeBranch("feature"))
+		assert.FileExists(t, filepath.Join(dir, "feature.txt"))
+		parents := strings.Fields(runGit(t, dir, "log", "-1", "--format=%P"))
+		assert.Len(t, parents, 2, "merge commit expected")
+	})
+
+	t.Run("conflict is aborted", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		runGit(t, dir, "checkout", "-b", "feature")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("feature\n"), 0o600))
+		runGit(t, dir, "commit", "-am", "feature readme")
+		runGit(t, dir, "checkout", "master")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("master\n"), 0o600))
+		runGit(t, dir, "commit", "-am", "master readme")
+
+		err = svc.MergeBranch("feature")
+		require.ErrorContains(t, err, "merge feature")
+		dirty, err := svc.repo.isDirty()
+		require.NoError(t, err)
+		assert.False(t, dirty, "aborted merge leaves a clean tree")
+	})
+
+	t.Run("unknown branch", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		require.Error(t, svc.MergeBranch("missing"))
+	})
+}
+
+func TestService_ResolveRange(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	svc, err := NewService(dir, noopServiceLogger())
+	require.NoError(t, err)
+	first := strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\n"), 0o600))
+	runGit(t, dir, "add", "a.txt")
+	runGit(t, dir, "commit", "-m", "add a")
+	second := strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD"))
+
+	base, head, err := svc.ResolveRange("HEAD~1..master")
+	require.NoError(t, err)
+	assert.Equal(t, first, base)
+	assert.Equal(t, second, head)
+
+	base, head, err = svc.ResolveRange(first[:7] + "..")
+	require.NoError(t, err)
+	assert.Equal(t, first, base)
+	assert.Equal(t, second, head, "omitted end defaults to HEAD")
+
+	for _, r := range []string{"master", "..", "HEAD~1...HEAD"} {
+		_, _, err = svc.ResolveRange(r)
+		require.ErrorContains(t, err, "invalid commit range", r)
+	}
+	_, _, err = svc.ResolveRange("missing..HEAD")
+	require.ErrorContains(t, err, `resolve range start: unknown commit "missing"`)
+}
+
+func TestService_CreateReviewWorktree(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	svc, err := NewService(dir, noopServiceLogger())
+	require.NoError(t, err)
+	head := strings.TrimSpace(runGit(t, dir, "rev-parse", "HEAD"))
+
+	wtPath, err := svc.CreateReviewWorktree("review-patch", "master")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = svc.RemoveWorktree(wtPath) })
+	assert.Equal(t, filepath.Join(dir, ".ralphex", "worktrees", "review-patch"), wtPath)
+	assert.Equal(t, head, strings.TrimSpace(runGit(t, wtPath, "rev-parse", "HEAD")))
+	branch, err := svc.CurrentBranch()
+	require.NoError(t, err)
+	assert.Equal(t, "master", branch, "main repo stays on its branch")
+
+	_, err = svc.CreateReviewWorktree("review-patch", "master")
+	require.ErrorContains(t, err, "left over from a previous review")
+	assert.ErrorContains(t, err, "git worktree remove "+wtPath)
+	assert.NotContains(t, err.Error(), "another instance")
+}
+
+func TestService_ApplyPatch(t *testing.T) {
+	// makePatch commits a change on a throwaway branch and returns its patch in the requested form
+	makePatch := func(t *testing.T, dir string, mailbox bool) string {
+		t.Helper()
+		runGit(t, dir, "checkout", "-b", "patch-src")
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Test\npatched\n"), 0o600))
+		runGit(t, dir, "commit", "-am", "patch readme")
+		var out string
+		if mailbox {
+			out = runGit(t, dir, "format-patch", "-1", "--stdout")
+		} else {
+			out = runGit(t, dir, "diff", "HEAD~1")
+		}
+		runGit(t, dir, "checkout", "master")
+		path := filepath.Join(t.TempDir(), "change.patch")
+		require.NoError(t, os.WriteFile(path, []byte(out+"\n"), 0o600))
+		return path
+	}
+
+	t.Run("mailbox keeps the commit", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		require.NoError(t, svc.ApplyPatch(makePatch(t, dir, true)))
+		assert.Equal(t, "patch readme", strings.TrimSpace(runGit(t, dir, "log", "-1", "--format=%s")))
+		assert.Contains(t, runGit(t, dir, "show", "HEAD:README.md"), "patched")
+	})
+
+	t.Run("plain diff is committed", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		require.NoError(t, svc.ApplyPatch(makePatch(t, dir, false)))
+		assert.Equal(t, "apply patch change.patch", strings.TrimSpace(runGit(t, dir, "log", "-1", "--format=%s")))
+		dirty, err := svc.repo.isDirty()
+		require.NoError(t, err)
+		assert.False(t, dirty)
+	})
+
+	t.Run("conflicting patch", func(t *testing.T) {
+		dir := setupExternalTestRepo(t)
+		svc, err := NewService(dir, noopServiceLogger())
+		require.NoError(t, err)
+		patch := makePatch(t, dir, true)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("other\n"), 0o600))
+		runGit(t, dir, "commit", "-am", "diverge")
+		require.ErrorContains(t, svc.ApplyPatch(patch), "apply patch change.patch")
+		dirty, err := svc.repo.isDirty()
+		require.NoError(t, err)
+		assert.False(t, dirty, "failed am is aborted")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		svc, err := NewService(setupExternalTestRepo(t), noopServiceLogger())
+		require.NoError(t, err)
+		require.ErrorContains(t, svc.ApplyPatch("/nonexistent/change.patch"), "read patch")
+	})
+}
+
 func TestService_FileHasChanges(t *testing.T) {
 	t.Run("returns true for dirty file", func(t *testing.T) {
 		dir := setupExternalTestRepo(t)
@@ -1817,3 +2000,83 @@ func TestService_resolveFilesystemCase(t *testing.T) {
 		})
 	}
 }
+
+func TestService_ChangedFiles(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	svc, err := NewService(dir, noopServiceLogger())
+	require.NoError(t, err)
+
+	files, err := svc.ChangedFiles("master")
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	runGit(t, dir, "checkout", "-b", "feature")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.go"), []byte("package b\n"), 0o600))
+	runGit(t, dir, "add", "b.go")
+	runGit(t, dir, "commit", "-m", "add b")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Changed\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.go"), []byte("package a\n"), 0o600))
+
+	files, err = svc.ChangedFiles("master")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"README.md", "a.go", "b.go"}, files, "committed, uncommitted and untracked files")
+
+	files, err = svc.ChangedFiles("nonexistent")
+	require.NoError(t, err)
+	assert.Nil(t, files)
+}
+
+func TestService_CommitLog(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	svc, err := NewService(dir, noopServiceLogger())
+	require.NoError(t, err)
+
+	commits, err := svc.CommitLog("master")
+	require.NoError(t, err)
+	assert.Empty(t, commits)
+
+	runGit(t, dir, "checkout", "-b", "feature")
+	for _, name := range []string{"a", "b"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name+".go"), []byte("package "+name+"\n"), 0o600))
+		runGit(t, dir, "add", name+".go")
+		runGit(t, dir, "commit", "-m", "add "+name)
+	}
+
+	commits, err = svc.CommitLog("master")
+	require.NoError(t, err)
+	require.Len(t, commits, 2)
+	assert.Regexp(t, `^[0-9a-f]{7,} add a$`, commits[0], "oldest first")
+	assert.Regexp(t, `^[0-9a-f]{7,} add b$`, commits[1])
+
+	commits, err = svc.CommitLog("nonexistent")
+	require.NoError(t, err)
+	assert.Nil(t, commits)
+}
+
+func TestService_BranchDiff(t *testing.T) {
+	dir := setupExternalTestRepo(t)
+	svc, err := NewService(dir, noopServiceLogger())
+	require.NoError(t, err)
+
+	diff, err := svc.BranchDiff("master")
+	require.NoError(t, err)
+	assert.Empty(t, diff)
+
+	runGit(t, dir, "checkout", "-b", "feature")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.go"), []byte("package b\n"), 0o600))
+	runGit(t, dir, "add", "b.go")
+	runGit(t, dir, "commit", "-m", "add b")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Changed\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "untracked.go"), []byte("package u\n"), 0o600))
+
+	diff, err = svc.BranchDiff("master")
+	require.NoError(t, err)
+	assert.Contains(t, diff, "diff --git a/b.go b/b.go")
+	assert.Contains(t, diff, "+package b")
+	assert.Contains(t, diff, "diff --git a/README.md b/README.md", "uncommitted changes included")
+	assert.NotContains(t, diff, "untracked.go")
+
+	diff, err = svc.BranchDiff("nonexistent")
+	require.NoError(t, err)
+	assert.Empty(t, diff)
+}
@@ -12,6 +12,7 @@ import (
 	"os/exec"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/charmbracelet/glamour"
 	"github.com/pmezard/go-difflib/difflib"
@@ -51,6 +52,45 @@ func ReadLineWithContext(ctx context.Context, reader *bufio.Reader) (string, err
 	}
 }
 
+// lineReader reads lines from one bufio.Reader for the lifetime of a collector. a read abandoned
+// by a canceled context leaves its line pending for the next read instead of losing it, so a
+// question answered elsewhere (e.g. from the dashboard) doesn't swallow the answer to the next one.
+type lineReader struct {
+	reader *bufio.Reader
+
+	mu      sync.Mutex
+	pending chan readLineResult // line being read, nil when no read is in flight
+}
+
+// readLine returns the next line (including newline), or the context error if ctx is canceled first.
+func (l *lineReader) readLine(ctx context.Context) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("read line: %w", err)
+	}
+
+	l.mu.Lock()
+	if l.pending == nil {
+		ch := make(chan readLineResult, 1)
+		go func() {
+			line, err := l.reader.ReadString('\n')
+			ch <- readLineResult{line: line, err: err}
+		}()
+		l.pending = ch
+	}
+	pending := l.pending
+	l.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return "", fmt.Errorf("read line: %w", ctx.Err())
+	case result := <-pending:
+		l.mu.Lock()
+		l.pending = nil
+		l.mu.Unlock()
+		return result.line, result.err
+	}
+}
+
 // TerminalCollector provides interactive input collection using fzf (if available) or numbered selection fallback.
 type TerminalCollector struct {
 	stdin      io.Reader                                                 // for testing, nil uses os.Stdin
@@ -58,6 +98,9 @@ type TerminalCollector struct {
 	editorFunc func(ctx context.Context, content string) (string, error) // for testing, nil uses real editor
 	noColor    bool                                                      // if true, skip glamour rendering
 	noFzf      bool                                                      // if true, skip fzf even if available (for testing)
+
+	linesOnce sync.Once
+	lines     *lineReader // shared by all prompts, see lineReader
 }
 
 // NewTerminalCollector creates a new TerminalCollector with specified options.
@@ -72,6 +115,12 @@ func (c *TerminalCollector) getStdin() io.Reader {
 	return os.Stdin
 }
 
+// readLine reads the next stdin line through the reader shared by all prompts of the collector.
+func (c *TerminalCollector) readLine(ctx context.Context) (string, error) {
+	c.linesOnce.Do(func() { c.lines = &lineReader{reader: bufio.NewReader(c.getStdin())} })
+	return c.lines.readLine(ctx)
+}
+
 func (c *TerminalCollector) getStdout() io.Writer {
 	if c.stdout != nil {
 		return c.stdout
@@ -105,7 +154,7 @@ func (c *TerminalCollector) AskQuestion(ctx context.Context, question string, op
 	}
 
 	// fallback to numbered selection
-	return c.selectWithNumbers(ctx, question, opts, nil)
+	return c.selectWithNumbers(ctx, question, opts)
 }
 
 // hasFzf checks if fzf is available in PATH.
@@ -132,7 +181,7 @@ func (c *TerminalCollector) selectWithFzf(ctx context.Context, question string,
 			case 130: // user pressed Escape
 				return "", errors.New("selection canceled")
 			case 1: // no match found — fall back to custom answer
-				return c.readCustomAnswer(ctx, nil)
+				return c.readCustomAnswer(ctx)
 			}
 		}
 		return "", fmt.Errorf("fzf selection failed: %w", err)
@@ -144,17 +193,14 @@ func (c *TerminalCollector) selectWithFzf(ctx context.Context, question string,
 	}
 
 	if selected == otherOption {
-		return c.readCustomAnswer(ctx, nil)
+		return c.readCustomAnswer(ctx)
 	}
 
 	return selected, nil
 }
 
 // selectWithNumbers presents numbered options for selection via stdin.
-// when reader is provided, it reuses the existing bufio.Reader to avoid data loss
-// with piped input (creating a second bufio.NewReader on the same io.Reader would
-// lose data already buffered by the first reader).
-func (c *TerminalCollector) selectWithNumbers(ctx context.Context, question string, options []string, reader *bufio.Reader) (string, error) {
+func (c *TerminalCollector) selectWithNumbers(ctx context.Context, question string, options []string) (string, error) {
 	stdout := c.getStdout()
 
 	// print question and options
@@ -165,12 +211,7 @@ func (c *TerminalCollector) selectWithNumbers(ctx context.Context, question stri
 	}
 	_, _ = fmt.Fprintf(stdout, "Enter number (1-%d): ", len(options))
 
-	// reuse provided reader or create a new one
-	r := reader
-	if r == nil {
-		r = bufio.NewReader(c.getStdin())
-	}
-	line, err := ReadLineWithContext(ctx, r)
+	line, err := c.readLine(ctx)
 	if err != nil {
 		return "", fmt.Errorf("read input: %w", err)
 	}
@@ -188,26 +229,19 @@ func (c *TerminalCollector) selectWithNumbers(ctx context.Context, question stri
 
 	selected := options[num-1]
 	if selected == otherOption {
-		return c.readCustomAnswer(ctx, r)
+		return c.readCustomAnswer(ctx)
 	}
 
 	return selected, nil
 }
 
 // readCustomAnswer prompts the user for free-text input and returns the answer.
-// when reader is provided, it reuses the existing bufio.Reader to avoid data loss
-// with piped input (creating a second bufio.NewReader on the same io.Reader would
-// lose data already buffered by the first reader).
-func (c *TerminalCollector) readCustomAnswer(ctx context.Context, reader *bufio.Reader) (string, error) {
+func (c *TerminalCollector) readCustomAnswer(ctx context.Context) (string, error) {
 	stdout := c.getStdout()
 
 	_, _ = fmt.Fprint(stdout, "Enter your answer: ")
 
-	r := reader
-	if r == nil {
-		r = bufio.NewReader(c.getStdin())
-	}
-	line, err := ReadLineWithContext(ctx, r)
+	line, err := c.readLine(ctx)
 	if err != nil {
 		return "", fmt.Errorf("read custom answer: %w", err)
 	}
@@ -267,13 +301,10 @@ func (c *TerminalCollector) AskDraftReview(ctx context.Context, question, planCo
 	_, _ = fmt.Fprintln(stdout, "━━━━━━━━━━━━━━━━━━")
 	_, _ = fmt.Fprintln(stdout)
 
-	// create reader once to avoid losing buffered data when looping
-	reader := bufio.NewReader(c.getStdin())
-
 	options := []string{"Accept", "Revise", "Interactive review", "Reject"}
 
 	for {
-		action, selectErr := c.selectWithNumbers(ctx, question, options, reader)
+		action, selectErr := c.selectWithNumbers(ctx, question, options)
 		if selectErr != nil {
 			// only validation errors (bad number, out of range) are retriable
 			if errors.Is(selectErr, errInvalidInput) {
@@ -294,7 +325,7 @@ func (c *TerminalCollector) AskDraftReview(ctx context.Context, question, planCo
 			_, _ = fmt.Fprintln(stdout)
 			_, _ = fmt.Fprint(stdout, "Enter revision feedback: ")
 
-			feedback, readErr := ReadLineWithContext(ctx, reader)
+			feedback, readErr := c.readLine(ctx)
 			if readErr != nil {
 				return "", "", fmt.Errorf("read feedback: %w", readErr)
 			}
@@ -41,7 +41,7 @@ func TestTerminalCollector_selectWithNumbers(t *testing.T) {
 			var stdout bytes.Buffer
 			c := &TerminalCollector{stdin: strings.NewReader(tc.input), stdout: &stdout}
 
-			got, err := c.selectWithNumbers(context.Background(), tc.question, tc.options, nil)
+			got, err := c.selectWithNumbers(context.Background(), tc.question, tc.options)
 
 			if tc.wantErr != "" {
 				require.Error(t, err)
@@ -72,7 +72,7 @@ func TestTerminalCollector_selectWithNumbers_otherOption(t *testing.T) {
 		var stdout bytes.Buffer
 		c := &TerminalCollector{stdin: strings.NewReader("1\n"), stdout: &stdout}
 
-		got, err := c.selectWithNumbers(context.Background(), "Pick one", opts, nil)
+		got, err := c.selectWithNumbers(context.Background(), "Pick one", opts)
 
 		require.NoError(t, err)
 		assert.Equal(t, "A", got)
@@ -86,7 +86,7 @@ func TestTerminalCollector_selectWithNumbers_otherOption(t *testing.T) {
 		reader := &sequentialLineReader{lines: []string{"3", "my custom answer"}}
 		c := &TerminalCollector{stdin: reader, stdout: &stdout}
 
-		got, err := c.selectWithNumbers(context.Background(), "Pick one", opts, nil)
+		got, err := c.selectWithNumbers(context.Background(), "Pick one", opts)
 
 		require.NoError(t, err)
 		assert.Equal(t, "my custom answer", got)
@@ -100,7 +100,7 @@ func TestTerminalCollector_selectWithNumbers_otherOption(t *testing.T) {
 		reader := &sequentialLineReader{lines: []string{"3", ""}}
 		c := &TerminalCollector{stdin: reader, stdout: &stdout}
 
-		_, err := c.selectWithNumbers(context.Background(), "Pick one", opts, nil)
+		_, err := c.selectWithNumbers(context.Background(), "Pick one", opts)
 
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "custom answer cannot be empty")
@@ -111,7 +111,7 @@ func TestTerminalCollector_selectWithNumbers_otherOption(t *testing.T) {
 		reader := &sequentialLineReader{lines: []string{"3", "   "}}
 		c := &TerminalCollector{stdin: reader, stdout: &stdout}
 
-		_, err := c.selectWithNumbers(context.Background(), "Pick one", opts, nil)
+		_, err := c.selectWithNumbers(context.Background(), "Pick one", opts)
 
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "custom answer cannot be empty")
@@ -123,7 +123,7 @@ func TestTerminalCollector_readCustomAnswer(t *testing.T) {
 		var stdout bytes.Buffer
 		c := &TerminalCollector{stdin: strings.NewReader("my answer\n"), stdout: &stdout}
 
-		got, err := c.readCustomAnswer(context.Background(), nil)
+		got, err := c.readCustomAnswer(context.Background())
 
 		require.NoError(t, err)
 		assert.Equal(t, "my answer", got)
@@ -134,7 +134,7 @@ func TestTerminalCollector_readCustomAnswer(t *testing.T) {
 		var stdout bytes.Buffer
 		c := &TerminalCollector{stdin: strings.NewReader("  trimmed  \n"), stdout: &stdout}
 
-		got, err := c.readCustomAnswer(context.Background(), nil)
+		got, err := c.readCustomAnswer(context.Background())
 
 		require.NoError(t, err)
 		assert.Equal(t, "trimmed", got)
@@ -144,7 +144,7 @@ func TestTerminalCollector_readCustomAnswer(t *testing.T) {
 		var stdout bytes.Buffer
 		c := &TerminalCollector{stdin: strings.NewReader("\n"), stdout: &stdout}
 
-		_, err := c.readCustomAnswer(context.Background(), nil)
+		_, err := c.readCustomAnswer(context.Background())
 
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "custom answer cannot be empty")
@@ -154,7 +154,7 @@ func TestTerminalCollector_readCustomAnswer(t *testing.T) {
 		var stdout bytes.Buffer
 		c := &TerminalCollector{stdin: strings.NewReader(""), stdout: &stdout}
 
-		_, err := c.readCustomAnswer(context.Background(), nil)
+		_, err := c.readCustomAnswer(context.Background())
 
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "read custom answer")
@@ -166,7 +166,7 @@ func TestTerminalCollector_readCustomAnswer(t *testing.T) {
 		var stdout bytes.Buffer
 		c := &TerminalCollector{stdin: strings.NewReader("answer\n"), stdout: &stdout}
 
-		_, err := c.readCustomAnswer(ctx, nil)
+		_, err := c.readCustomAnswer(ctx)
 
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "read custom answer")
@@ -198,6 +198,38 @@ func TestTerminalCollector_AskQuestion_appendsOther(t *testing.T) {
 	})
 }
 
+func TestTerminalCollector_AskQuestion_canceledReadKeepsLine(t *testing.T) {
+	// a question canceled while waiting for stdin (e.g. answered from the dashboard) leaves
+	// the next typed line to the next question
+	r, w := io.Pipe()
+	defer r.Close()
+	c := &TerminalCollector{stdin: r, stdout: io.Discard, noFzf: true}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	_, err := c.readLine(canceled) // sets up the shared reader without reading
+	require.ErrorIs(t, err, context.Canceled)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.AskQuestion(ctx, "First", []string{"A", "B"})
+		done <- err
+	}()
+	require.Eventually(t, func() bool {
+		c.lines.mu.Lock()
+		defer c.lines.mu.Unlock()
+		return c.lines.pending != nil
+	}, time.Second, 5*time.Millisecond)
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	go func() { _, _ = w.Write([]byte("2\n")) }()
+	got, err := c.AskQuestion(context.Background(), "Second", []string{"C", "D"})
+	require.NoError(t, err)
+	assert.Equal(t, "D", got)
+}
+
 func TestTerminalCollector_AskQuestion_sentinelCollision(t *testing.T) {
 	// if an incoming option matches otherOption exactly, it should be filtered out
 	// so the user sees only one "Other" entry at the end
@@ -252,7 +284,7 @@ func TestTerminalCollector_selectWithNumbers_outputFormat(t *testing.T) {
 	var stdout bytes.Buffer
 	c := &TerminalCollector{stdin: strings.NewReader("2\n"), stdout: &stdout}
 
-	_, err := c.selectWithNumbers(context.Background(), "Which database?", []string{"PostgreSQL", "MySQL", "SQLite"}, nil)
+	_, err := c.selectWithNumbers(context.Background(), "Which database?", []string{"PostgreSQL", "MySQL", "SQLite"})
 	require.NoError(t, err)
 
 	output := stdout.String()
@@ -267,7 +299,7 @@ func TestTerminalCollector_selectWithNumbers_readError(t *testing.T) {
 	// use an empty reader that will return EOF immediately
 	c := &TerminalCollector{stdin: strings.NewReader(""), stdout: &bytes.Buffer{}}
 
-	_, err := c.selectWithNumbers(context.Background(), "Pick one", []string{"A", "B"}, nil)
+	_, err := c.selectWithNumbers(context.Background(), "Pick one", []string{"A", "B"})
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "read input")
@@ -70,6 +70,25 @@ type Result struct {
 	Additions int    `json:"additions"`
 	Deletions int    `json:"deletions"`
 	Error     string `json:"error,omitempty"`
+
+	// token and cost accounting summed over all executor sessions; zero when not reported
+	InputTokens         int64   `json:"input_tokens,omitempty"`
+	OutputTokens        int64   `json:"output_tokens,omitempty"`
+	CacheCreationTokens int64   `json:"cache_creation_tokens,omitempty"`
+	CacheReadTokens     int64   `json:"cache_read_tokens,omitempty"`
+	CostUSD             float64 `json:"cost_usd,omitempty"`
+
+	// per-plan outcomes of a --queue run, empty for a single plan
+	Plans []PlanResult `json:"plans,omitempty"`
+}
+
+// PlanResult is the outcome of one plan of a --queue run.
+type PlanResult struct {
+	PlanFile string `json:"plan_file"`
+	Branch   string `json:"branch"`
+	Status   string `json:"status"` // "success", "failed" or "skipped"
+	Duration string `json:"duration,omitempty"`
+	Error    string `json:"error,omitempty"`
 }
 
 // New creates a notification Service from the given Params.
@@ -221,10 +240,32 @@ func (s *Service) formatMessage(r Result) string {
 		fmt.Fprintf(&b, "changes:  %d files (+%d/-%d lines)\n", r.Files, r.Additions, r.Deletions)
 	}
 
+	if r.InputTokens > 0 || r.OutputTokens > 0 {
+		fmt.Fprintf(&b, "tokens:   %d in, %d out, %d cache read, %d cache write\n",
+			r.InputTokens, r.OutputTokens, r.CacheReadTokens, r.CacheCreationTokens)
+	}
+	if r.CostUSD > 0 {
+		fmt.Fprintf(&b, "cost:     $%.2f\n", r.CostUSD)
+	}
+
 	if r.Error != "" {
 		fmt.Fprintf(&b, "error:    %s\n", r.Error)
 	}
 
+	if len(r.Plans) > 0 {
+		b.WriteString("\nplans:\n")
+	}
+	for _, p := range r.Plans {
+		fmt.Fprintf(&b, "  %-7s %s", p.Status, p.PlanFile)
+		if p.Duration != "" {
+			fmt.Fprintf(&b, " (%s)", p.Duration)
+		}
+		if p.Error != "" {
+			fmt.Fprintf(&b, ": %s", p.Error)
+		}
+		b.WriteString("\n")
+	}
+
 	return b.String()
 }
 
@@ -453,6 +453,33 @@ func TestService_FormatMessage(t *testing.T) {
 		assert.Contains(t, msg, "changes:  0 files (+0/-0 lines)")
 	})
 
+	t.Run("usage lines", func(t *testing.T) {
+		msg := svc.formatMessage(Result{
+			Status: "failure", Error: "boom", InputTokens: 1200, OutputTokens: 340,
+			CacheReadTokens: 50000, CacheCreationTokens: 800, CostUSD: 1.234,
+		})
+		assert.Contains(t, msg, "tokens:   1200 in, 340 out, 50000 cache read, 800 cache write")
+		assert.Contains(t, msg, "cost:     $1.23")
+
+		msg = svc.formatMessage(Result{Status: "success"})
+		assert.NotContains(t, msg, "tokens:")
+		assert.NotContains(t, msg, "cost:")
+	})
+
+	t.Run("queue plans", func(t *testing.T) {
+		msg := svc.formatMessage(Result{
+			Status: "failure", Mode: "queue", PlanFile: "docs/plans", Error: "1 of 2 plans did not complete",
+			Plans: []PlanResult{
+				{PlanFile: "auth.md", Branch: "auth", Status: "success", Duration: "12m 3s"},
+				{PlanFile: "api.md", Branch: "api", Status: "failed", Duration: "1m", Error: "exit status 1"},
+			},
+		})
+		assert.Contains(t, msg, "plans:\n  success auth.md (12m 3s)\n  failed  api.md (1m): exit status 1\n")
+
+		msg = svc.formatMessage(Result{Status: "success"})
+		assert.NotContains(t, msg, "plans:")
+	})
+
 	t.Run("message line count", func(t *testing.T) {
 		msg := svc.formatMessage(Result{
 			Status:    "success",
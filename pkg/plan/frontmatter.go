@@ -0,0 +1,85 @@
+package plan
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Settings are the per-plan run settings of a plan's YAML frontmatter. they override the config files
+// and are overridden by CLI flags. nil pointers and empty values are not set by the plan.
+type Settings struct {
+	Branch             string `yaml:"branch"`
+	TaskModel          string `yaml:"task_model"`
+	ReviewModel        string `yaml:"review_model"`
+	MaxIterations      int    `yaml:"max_iterations"`
+	ExternalReviewTool string `yaml:"external_review_tool"`
+	Finalize           *bool  `yaml:"finalize"`
+	Worktree           *bool  `yaml:"worktree"`
+	BaseRef            string `yaml:"base_ref"`
+}
+
+// frontmatterKeys are the keys accepted in plan frontmatter. depends_on is read by ParsePlan.
+var frontmatterKeys = []string{"branch", "task_model", "review_model", "max_iterations", "external_review_tool",
+	"finalize", "worktree", "base_ref", "depends_on"}
+
+// splitFrontmatter splits a "---" delimited frontmatter block off the start of content,
+// the same convention as agent files. returns false when content has no frontmatter.
+func splitFrontmatter(content string) (header, body string, ok bool) {
+	after, found := strings.CutPrefix(strings.ReplaceAll(content, "\r\n", "\n"), "---\n")
+	if !found {
+		return "", content, false
+	}
+	if rest, empty := strings.CutPrefix(after, "---\n"); empty {
+		return "", rest, true
+	}
+	header, body, found = strings.Cut(after, "\n---")
+	if !found {
+		return "", content, false
+	}
+	// closing delimiter must be on its own line
+	if body != "" && body[0] != '\n' {
+		return "", content, false
+	}
+	return header, strings.TrimPrefix(body, "\n"), true
+}
+
+// ParseSettings returns the settings of the plan content's frontmatter, zero settings when it has none.
+// unknown keys and malformed values are errors, so a typo doesn't silently run with the wrong settings.
+func ParseSettings(content string) (Settings, error) {
+	header, _, ok := splitFrontmatter(content)
+	if !ok || strings.TrimSpace(header) == "" {
+		return Settings{}, nil
+	}
+	var keys map[string]any
+	if err := yaml.Unmarshal([]byte(header), &keys); err != nil {
+		return Settings{}, fmt.Errorf("parse plan frontmatter: %w", err)
+	}
+	for _, key := range slices.Sorted(maps.Keys(keys)) {
+		if !slices.Contains(frontmatterKeys, key) {
+			return Settings{}, fmt.Errorf("unknown plan frontmatter key %q, expected one of %s", key,
+				strings.Join(frontmatterKeys, ", "))
+		}
+	}
+	var s Settings
+	if err := yaml.Unmarshal([]byte(header), &s); err != nil {
+		return Settings{}, fmt.Errorf("parse plan frontmatter: %w", err)
+	}
+	if s.MaxIterations < 0 {
+		return Settings{}, fmt.Errorf("plan frontmatter max_iterations must not be negative, got %d", s.MaxIterations)
+	}
+	return s, nil
+}
+
+// ReadSettings reads the frontmatter settings of the plan file at path.
+func ReadSettings(path string) (Settings, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path from user's plan selection
+	if err != nil {
+		return Settings{}, fmt.Errorf("read plan file: %w", err)
+	}
+	return ParseSettings(string(data))
+}
@@ -0,0 +1,68 @@
+package plan_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/plan"
+)
+
+func TestParseSettings(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name    string
+		content string
+		want    plan.Settings
+		wantErr string
+	}{
+		{name: "no frontmatter", content: "# Plan\n### Task 1: a\n- [ ] x\n"},
+		{name: "all keys", content: "---\nbranch: infra-vpc\ntask_model: opus:high\nreview_model: sonnet\n" +
+			"max_iterations: 80\nexternal_review_tool: codex,custom:gemini\nfinalize: false\nworktree: true\n" +
+			"base_ref: develop\ndepends_on: [base.md]\n---\n# Plan\n",
+			want: plan.Settings{Branch: "infra-vpc", TaskModel: "opus:high", ReviewModel: "sonnet", MaxIterations: 80,
+				ExternalReviewTool: "codex,custom:gemini", Finalize: &no, Worktree: &yes, BaseRef: "develop"}},
+		{name: "crlf", content: "---\r\nbranch: docs\r\n---\r\n# Plan\r\n", want: plan.Settings{Branch: "docs"}},
+		{name: "empty frontmatter", content: "---\n---\n# Plan\n"},
+		{name: "unclosed is not frontmatter", content: "---\nbranch: docs\n# Plan\n"},
+		{name: "unknown key", content: "---\nbranch: docs\nmax_iteration: 3\n---\n",
+			wantErr: `unknown plan frontmatter key "max_iteration"`},
+		{name: "bad value", content: "---\nmax_iterations: many\n---\n", wantErr: "parse plan frontmatter"},
+		{name: "negative iterations", content: "---\nmax_iterations: -1\n---\n", wantErr: "must not be negative"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := plan.ParseSettings(tc.content)
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestReadSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(path, []byte("---\nbranch: docs\n---\n# Plan\n"), 0o600))
+	s, err := plan.ReadSettings(path)
+	require.NoError(t, err)
+	assert.Equal(t, "docs", s.Branch)
+
+	_, err = plan.ReadSettings(filepath.Join(t.TempDir(), "missing.md"))
+	require.ErrorContains(t, err, "read plan file")
+}
+
+func TestParsePlan_Frontmatter(t *testing.T) {
+	content := "---\nbranch: docs\ndepends_on: [base.md, infra]\n---\n# Docs Plan\n\n### Task 1: write\n- [ ] write docs\n"
+	p, err := plan.ParsePlan(content)
+	require.NoError(t, err)
+	assert.Equal(t, "Docs Plan", p.Title)
+	assert.Equal(t, []string{"base.md", "infra"}, p.DependsOn)
+	require.Len(t, p.Tasks, 1)
+	assert.Equal(t, "### Task 1: write\n- [ ] write docs", p.Tasks[0].Body)
+}
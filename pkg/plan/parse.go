@@ -29,16 +29,21 @@ type Checkbox struct {
 
 // Task represents a task section in a plan.
 type Task struct {
-	Number     int        `json:"number"`
-	Title      string     `json:"title"`
-	Status     TaskStatus `json:"status"`
-	Checkboxes []Checkbox `json:"checkboxes"`
+	Number     int         `json:"number"`
+	Title      string      `json:"title"`
+	Status     TaskStatus  `json:"status"`
+	Checkboxes []Checkbox  `json:"checkboxes"`
+	Body       string      `json:"-"` // markdown of the task section, header included
+	Options    TaskOptions `json:"-"` // execution overrides of the header annotation or the line under it
+	OptionsErr string      `json:"-"` // invalid options annotation, dropped so the task runs with the run-wide settings
 }
 
 // Plan represents a parsed plan file.
 type Plan struct {
-	Title string `json:"title"`
-	Tasks []Task `json:"tasks"`
+	Title      string   `json:"title"`
+	Tasks      []Task   `json:"tasks"`
+	Validation []string `json:"validation,omitempty"` // commands from the ## Validation Commands section
+	DependsOn  []string `json:"depends_on,omitempty"` // plans that must complete first, from a "depends_on:" line
 }
 
 // patterns for parsing plan markdown.
@@ -47,6 +52,14 @@ var (
 	// allow leading whitespace for indented sub-items (e.g. "  - [ ] Unit tests")
 	checkboxPattern = regexp.MustCompile(`^\s*-\s+\[([ xX])\]\s*(.*)$`)
 	titlePattern    = regexp.MustCompile(`^#\s+(.*)$`)
+	// validationHeaderPattern matches the h2 header that opens the validation commands section.
+	validationHeaderPattern = regexp.MustCompile(`(?i)^##\s+validation(?:\s+commands)?\s*$`)
+	// dependsOnPattern matches a "depends_on: a.md, b" line listing prerequisite plans.
+	dependsOnPattern = regexp.MustCompile(`(?i)^\s*depends_on:\s*(.*)$`)
+	// listItemPattern matches a bulleted or numbered list item and captures its text.
+	listItemPattern = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+(.*)$`)
+	// codeSpanPattern matches a leading inline code span, e.g. "`go test ./...` - run tests".
+	codeSpanPattern = regexp.MustCompile("^`([^`]+)`")
 	// formatInText matches [ ] or [x] in checkbox text — description/example, not actionable for completion check.
 	formatInText = regexp.MustCompile(`\[\s*[ xX]?\s*\]`)
 	// fenceOpenPattern matches a CommonMark code-fence opener: optional indentation up to 3 spaces,
@@ -60,23 +73,67 @@ var (
 )
 
 // ParsePlan parses plan markdown content into a structured Plan.
+// a YAML frontmatter block is skipped, except for its depends_on line.
 func ParsePlan(content string) (*Plan, error) {
 	p := &Plan{
 		Tasks: make([]Task, 0),
 	}
+	if header, body, ok := splitFrontmatter(content); ok {
+		for line := range strings.SplitSeq(header, "\n") {
+			if matches := dependsOnPattern.FindStringSubmatch(line); matches != nil {
+				p.DependsOn = append(p.DependsOn, parseDependsOn(matches[1])...)
+			}
+		}
+		content = body
+	}
 
 	scanner := bufio.NewScanner(strings.NewReader(content))
 	var currentTask *Task
+	var body strings.Builder // lines of the current task section
+	optionsLine := false     // next non-blank line may hold the options annotation of the current task
 	var ft fenceTracker
+	inValidation := false
+	saveTask := func() {
+		currentTask.Status = DetermineTaskStatus(currentTask.Checkboxes)
+		currentTask.Body = strings.TrimRight(body.String(), "\n")
+		p.Tasks = append(p.Tasks, *currentTask)
+		body.Reset()
+	}
 
 	for scanner.Scan() {
 		line := scanner.Text()
 
-		// skip lines inside fenced code blocks so example checkboxes are not parsed as tasks
+		// skip lines inside fenced code blocks so example checkboxes are not parsed as tasks.
+		// fenced lines inside the validation section are commands, one per line.
+		wasInFence := ft.open != ""
 		if ft.skip(line) {
+			if inValidation && wasInFence && ft.open != "" {
+				p.Validation = appendValidationCommand(p.Validation, line)
+			}
+			if currentTask != nil {
+				body.WriteString(line + "\n")
+			}
 			continue
 		}
 
+		// any header ends the validation section; the validation header itself (re)opens it
+		if strings.HasPrefix(line, "#") {
+			inValidation = validationHeaderPattern.MatchString(line)
+		}
+		if inValidation {
+			if matches := listItemPattern.FindStringSubmatch(line); matches != nil && !checkboxPattern.MatchString(line) {
+				p.Validation = appendValidationCommand(p.Validation, validationItemCommand(matches[1]))
+			}
+		}
+
+		// prerequisite plans, only outside task sections so task text can't declare them
+		if currentTask == nil && !inValidation {
+			if matches := dependsOnPattern.FindStringSubmatch(line); matches != nil {
+				p.DependsOn = append(p.DependsOn, parseDependsOn(matches[1])...)
+				continue
+			}
+		}
+
 		// check for plan title (first h1)
 		if p.Title == "" {
 			if matches := titlePattern.FindStringSubmatch(line); matches != nil {
@@ -89,8 +146,7 @@ func ParsePlan(content string) (*Plan, error) {
 		if matches := taskHeaderPattern.FindStringSubmatch(line); matches != nil {
 			// save previous task if exists
 			if currentTask != nil {
-				currentTask.Status = DetermineTaskStatus(currentTask.Checkboxes)
-				p.Tasks = append(p.Tasks, *currentTask)
+				saveTask()
 			}
 
 			taskNum := parseTaskNum(matches[1])
@@ -101,6 +157,13 @@ func ParsePlan(content string) (*Plan, error) {
 				Status:     TaskStatusPending,
 				Checkboxes: make([]Checkbox, 0),
 			}
+			title, annotation, found := cutTaskOptions(currentTask.Title)
+			if found {
+				currentTask.Title = title
+				currentTask.setOptions(annotation)
+			}
+			optionsLine = !found
+			body.WriteString(line + "\n")
 			continue
 		}
 
@@ -111,14 +174,22 @@ func ParsePlan(content string) (*Plan, error) {
 		isH2 := strings.HasPrefix(line, "##") && !strings.HasPrefix(line, "###")
 		isH1AfterTitle := strings.HasPrefix(line, "#") && p.Title != "" && !strings.HasPrefix(line, "##")
 		if currentTask != nil && (isH2 || isH1AfterTitle) && !taskHeaderPattern.MatchString(line) {
-			currentTask.Status = DetermineTaskStatus(currentTask.Checkboxes)
-			p.Tasks = append(p.Tasks, *currentTask)
+			saveTask()
 			currentTask = nil
 			continue
 		}
 
+		// options annotation on its own line right under the task header
+		if currentTask != nil && optionsLine && strings.TrimSpace(line) != "" {
+			optionsLine = false
+			if rest, annotation, found := cutTaskOptions(line); found && rest == "" {
+				currentTask.setOptions(annotation)
+			}
+		}
+
 		// check for checkbox (only if inside a task)
 		if currentTask != nil {
+			body.WriteString(line + "\n")
 			if matches := checkboxPattern.FindStringSubmatch(line); matches != nil {
 				checked := matches[1] == "x" || matches[1] == "X"
 				currentTask.Checkboxes = append(currentTask.Checkboxes, Checkbox{
@@ -131,8 +202,7 @@ func ParsePlan(content string) (*Plan, error) {
 
 	// save last task
 	if currentTask != nil {
-		currentTask.Status = DetermineTaskStatus(currentTask.Checkboxes)
-		p.Tasks = append(p.Tasks, *currentTask)
+		saveTask()
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -142,6 +212,39 @@ func ParsePlan(content string) (*Plan, error) {
 	return p, nil
 }
 
+// parseDependsOn splits a depends_on value into plan references.
+// accepts a comma-separated list, optionally wrapped in brackets: "a.md, b" or "[a.md, b]".
+func parseDependsOn(value string) []string {
+	value = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(value), "["), "]")
+	var refs []string
+	for ref := range strings.SplitSeq(value, ",") {
+		if ref = strings.Trim(strings.TrimSpace(ref), "\"'`"); ref != "" {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// validationItemCommand extracts the command from a validation list item.
+// a leading code span wins ("`make test` - run tests" yields "make test"),
+// otherwise the whole item text is the command.
+func validationItemCommand(text string) string {
+	text = strings.TrimSpace(text)
+	if matches := codeSpanPattern.FindStringSubmatch(text); matches != nil {
+		return matches[1]
+	}
+	return text
+}
+
+// appendValidationCommand appends a trimmed command, skipping blank lines and shell comments.
+func appendValidationCommand(commands []string, cmd string) []string {
+	cmd = strings.TrimSpace(cmd)
+	if cmd == "" || strings.HasPrefix(cmd, "#") {
+		return commands
+	}
+	return append(commands, cmd)
+}
+
 // ParsePlanFile reads and parses a plan file from disk.
 func ParsePlanFile(path string) (*Plan, error) {
 	content, err := os.ReadFile(path) //nolint:gosec // path is internally resolved, not from user input
@@ -251,6 +354,16 @@ func (cb Checkbox) IsActionable() bool {
 	return !formatInText.MatchString(cb.Text)
 }
 
+// Label returns the task label of the section header, e.g. "2.5" of "### Task 2.5: title".
+// Number is zero for such non-integer labels.
+func (t *Task) Label() string {
+	header, _, _ := strings.Cut(t.Body, "\n")
+	if matches := taskHeaderPattern.FindStringSubmatch(header); matches != nil {
+		return strings.TrimSpace(matches[1])
+	}
+	return strconv.Itoa(t.Number)
+}
+
 // HasUncompletedActionableWork returns true if the task has any unchecked actionable checkbox.
 // checkboxes whose text contains [ ] or [x] (format description) are ignored.
 func (t *Task) HasUncompletedActionableWork() bool {
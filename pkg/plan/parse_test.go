@@ -257,6 +257,10 @@ Just some text, no checkboxes.
 
 		assert.Equal(t, 3, p.Tasks[3].Number)
 		assert.Equal(t, "Third Task", p.Tasks[3].Title)
+
+		assert.Equal(t, "2.5", p.Tasks[2].Label())
+		assert.Equal(t, "3", p.Tasks[3].Label())
+		assert.Equal(t, "7", (&plan.Task{Number: 7}).Label(), "task without body")
 	})
 
 	t.Run("parses alphanumeric task headers", func(t *testing.T) {
@@ -493,6 +497,99 @@ Just some text, no checkboxes.
 		assert.Equal(t, "real done", p.Tasks[0].Checkboxes[0].Text)
 		assert.False(t, p.Tasks[0].HasUncompletedActionableWork())
 	})
+
+	t.Run("keeps task section body", func(t *testing.T) {
+		content := "# Plan\n\n## Overview\n\ntext\n\n" +
+			"### Task 1: First\n\n- [x] done\n\n" +
+			"### Task 2: Second\nuse this:\n```go\n## not a header\n```\n#### Notes\n- [ ] item\n\n" +
+			"## Success criteria\n- [ ] works\n"
+
+		p, err := plan.ParsePlan(content)
+		require.NoError(t, err)
+
+		require.Len(t, p.Tasks, 2)
+		assert.Equal(t, "### Task 1: First\n\n- [x] done", p.Tasks[0].Body)
+		assert.Equal(t, "### Task 2: Second\nuse this:\n```go\n## not a header\n```\n#### Notes\n- [ ] item", p.Tasks[1].Body)
+	})
+}
+
+func TestParsePlan_Validation(t *testing.T) {
+	t.Run("extracts list items with code spans", func(t *testing.T) {
+		content := `# Plan
+
+## Validation Commands
+- ` + "`go test ./...`" + `
+- ` + "`golangci-lint run`" + ` - lint everything
+* make build
+
+### Task 1: First
+- [ ] item
+`
+		p, err := plan.ParsePlan(content)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"go test ./...", "golangci-lint run", "make build"}, p.Validation)
+		require.Len(t, p.Tasks, 1)
+		require.Len(t, p.Tasks[0].Checkboxes, 1)
+	})
+
+	t.Run("extracts fenced commands and skips comments", func(t *testing.T) {
+		content := "# Plan\n\n## Validation\n\n```bash\n# run tests\ngo test ./...\n\ngo vet ./...\n```\n\n## Context\n- not a command\n"
+		p, err := plan.ParsePlan(content)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"go test ./...", "go vet ./..."}, p.Validation)
+	})
+
+	t.Run("section ends at next header", func(t *testing.T) {
+		content := `# Plan
+
+## Validation Commands
+1. ` + "`make test`" + `
+
+### Task 1: First
+- [ ] item
+- plain list item in task
+`
+		p, err := plan.ParsePlan(content)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"make test"}, p.Validation)
+	})
+
+	t.Run("ignores checkboxes and fenced blocks outside the section", func(t *testing.T) {
+		content := "# Plan\n\n## Validation Commands\n- [ ] go test ./...\n\n## Overview\n```\nmake lint\n```\n"
+		p, err := plan.ParsePlan(content)
+		require.NoError(t, err)
+		assert.Empty(t, p.Validation)
+	})
+
+	t.Run("no validation section", func(t *testing.T) {
+		p, err := plan.ParsePlan("# Plan\n\n### Task 1: First\n- [ ] item\n")
+		require.NoError(t, err)
+		assert.Empty(t, p.Validation)
+	})
+}
+
+func TestParsePlan_DependsOn(t *testing.T) {
+	t.Run("comma-separated list", func(t *testing.T) {
+		content := "# Plan\n\ndepends_on: 20260101-auth.md, billing\n\n### Task 1: First\n- [ ] item\n"
+		p, err := plan.ParsePlan(content)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"20260101-auth.md", "billing"}, p.DependsOn)
+		require.Len(t, p.Tasks, 1)
+	})
+
+	t.Run("bracketed quoted list and repeated lines", func(t *testing.T) {
+		content := "# Plan\nDepends_On: [\"auth.md\", 'api']\ndepends_on: ui\n"
+		p, err := plan.ParsePlan(content)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"auth.md", "api", "ui"}, p.DependsOn)
+	})
+
+	t.Run("ignored inside tasks and code fences", func(t *testing.T) {
+		content := "# Plan\n\n```\ndepends_on: fenced\n```\n\n### Task 1: First\ndepends_on: in-task\n- [ ] item\n"
+		p, err := plan.ParsePlan(content)
+		require.NoError(t, err)
+		assert.Empty(t, p.DependsOn)
+	})
 }
 
 func TestParsePlanFile(t *testing.T) {
@@ -0,0 +1,112 @@
+package plan
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// taskOptionsPattern matches a trailing "{model=opus:high, retries=3, timeout=45m}" annotation
+// of a task header, or a line holding only the annotation directly under the header.
+// cutTaskOptions accepts it only when every key is a task option, so other braces stay in the title.
+var taskOptionsPattern = regexp.MustCompile(`\{([^{}]*=[^{}]*)\}\s*$`)
+
+// taskOptionKeys are the keys of a task options annotation.
+var taskOptionKeys = []string{"model", "retries", "timeout"}
+
+// TaskOptions are the per-task execution overrides of a task header annotation.
+// empty values keep the run-wide settings.
+type TaskOptions struct {
+	Model   string        // model[:effort] spec of the task executor
+	Retries *int          // retries of a failed task iteration, nil keeps task_retry_count
+	Timeout time.Duration // session timeout of the task iterations
+}
+
+// IsZero reports whether the options override nothing.
+func (o TaskOptions) IsZero() bool {
+	return o.Model == "" && o.Retries == nil && o.Timeout == 0
+}
+
+// String returns the set options as "key=value" pairs, e.g. "model=opus:high, retries=3".
+func (o TaskOptions) String() string {
+	var parts []string
+	if o.Model != "" {
+		parts = append(parts, "model="+o.Model)
+	}
+	if o.Retries != nil {
+		parts = append(parts, "retries="+strconv.Itoa(*o.Retries))
+	}
+	if o.Timeout > 0 {
+		parts = append(parts, "timeout="+o.Timeout.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// cutTaskOptions splits a trailing options annotation off text. returns text unchanged and false
+// when it has no annotation, or the braces hold anything but model, retries and timeout keys,
+// e.g. "Render {name=value} placeholders".
+func cutTaskOptions(text string) (rest, annotation string, found bool) {
+	loc := taskOptionsPattern.FindStringSubmatchIndex(text)
+	if loc == nil {
+		return text, "", false
+	}
+	annotation = text[loc[2]:loc[3]]
+	for pair := range strings.SplitSeq(annotation, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, _, ok := strings.Cut(pair, "=")
+		if !ok || !slices.Contains(taskOptionKeys, strings.TrimSpace(key)) {
+			return text, "", false
+		}
+	}
+	return strings.TrimSpace(text[:loc[0]]), annotation, true
+}
+
+// setOptions sets the options of an annotation found by cutTaskOptions. an invalid value drops
+// all options of the task and is kept in OptionsErr, so a bad annotation doesn't break the plan.
+func (t *Task) setOptions(annotation string) {
+	opts, err := parseTaskOptions(annotation)
+	if err != nil {
+		t.OptionsErr = err.Error()
+		return
+	}
+	t.Options = opts
+}
+
+// parseTaskOptions parses the comma-separated key=value list of a task options annotation.
+func parseTaskOptions(annotation string) (TaskOptions, error) {
+	var opts TaskOptions
+	for pair := range strings.SplitSeq(annotation, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if value == "" {
+			return TaskOptions{}, fmt.Errorf("invalid task option %s, expected a value", key)
+		}
+		switch key {
+		case "model":
+			opts.Model = value
+		case "retries":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return TaskOptions{}, fmt.Errorf("invalid task option retries=%q, expected a non-negative number", value)
+			}
+			opts.Retries = &n
+		case "timeout":
+			d, err := time.ParseDuration(value)
+			if err != nil || d <= 0 {
+				return TaskOptions{}, fmt.Errorf("invalid task option timeout=%q, expected a positive duration like 45m", value)
+			}
+			opts.Timeout = d
+		default:
+			return TaskOptions{}, fmt.Errorf("unknown task option %q, expected model, retries or timeout", key)
+		}
+	}
+	return opts, nil
+}
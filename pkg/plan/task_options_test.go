@@ -0,0 +1,73 @@
+package plan_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/plan"
+)
+
+func TestParsePlan_TaskOptions(t *testing.T) {
+	three, zero := 3, 0
+	tests := []struct {
+		name    string
+		content string
+		title   string
+		want    plan.TaskOptions
+	}{
+		{name: "header annotation", content: "### Task 3: Rework locking {model=opus:high, retries=3, timeout=45m}\n- [ ] a\n",
+			title: "Rework locking", want: plan.TaskOptions{Model: "opus:high", Retries: &three, Timeout: 45 * time.Minute}},
+		{name: "line under header", content: "### Task 1: Rename\n\n{model=haiku, retries=0}\n- [ ] a\n",
+			title: "Rename", want: plan.TaskOptions{Model: "haiku", Retries: &zero}},
+		{name: "plain braces stay in title", content: "### Task 1: Handle {braces} in templates\n- [ ] a\n",
+			title: "Handle {braces} in templates"},
+		{name: "annotation after first line ignored", content: "### Task 1: Rename\n- [ ] a\n{model=haiku}\n",
+			title: "Rename"},
+		{name: "no options", content: "### Task 1: Rename\n- [ ] a\n", title: "Rename"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := plan.ParsePlan(tc.content)
+			require.NoError(t, err)
+			require.Len(t, p.Tasks, 1)
+			assert.Equal(t, tc.title, p.Tasks[0].Title)
+			assert.Equal(t, tc.want, p.Tasks[0].Options)
+			require.Len(t, p.Tasks[0].Checkboxes, 1)
+		})
+	}
+
+	t.Run("other keys stay in title", func(t *testing.T) {
+		for _, title := range []string{"Render {name=value} placeholders", "a {model=opus, retry=3}",
+			"a {model=opus, retries}"} {
+			p, err := plan.ParsePlan("### Task 1: " + title + "\n- [ ] a\n")
+			require.NoError(t, err)
+			assert.Equal(t, title, p.Tasks[0].Title)
+			assert.True(t, p.Tasks[0].Options.IsZero())
+			assert.Empty(t, p.Tasks[0].OptionsErr)
+		}
+	})
+
+	t.Run("invalid values drop the options", func(t *testing.T) {
+		p, err := plan.ParsePlan("### Task 2.5: a {model=opus, retries=-1}\n- [ ] a\n### Task 3: b\n{timeout=soon}\n- [ ] b\n" +
+			"### Task 4: c {model=}\n- [ ] c\n")
+		require.NoError(t, err)
+		require.Len(t, p.Tasks, 3)
+		assert.Equal(t, "a", p.Tasks[0].Title)
+		assert.True(t, p.Tasks[0].Options.IsZero())
+		assert.Equal(t, `invalid task option retries="-1", expected a non-negative number`, p.Tasks[0].OptionsErr)
+		assert.Contains(t, p.Tasks[1].OptionsErr, `invalid task option timeout="soon"`)
+		assert.Equal(t, "invalid task option model, expected a value", p.Tasks[2].OptionsErr)
+	})
+}
+
+func TestTaskOptions_String(t *testing.T) {
+	two := 2
+	assert.Empty(t, plan.TaskOptions{}.String())
+	assert.True(t, plan.TaskOptions{}.IsZero())
+	opts := plan.TaskOptions{Model: "opus:high", Retries: &two, Timeout: 45 * time.Minute}
+	assert.Equal(t, "model=opus:high, retries=2, timeout=45m0s", opts.String())
+	assert.False(t, opts.IsZero())
+}
@@ -0,0 +1,127 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// budgetGuard enforces run budget limits after every executor session.
+// on a crossed limit it either records the run as stopped or, with budget_action = downgrade,
+// switches executors to the budget model once and re-arms the limits from the
+// spend at that point, so a second crossing stops the run. the session that crossed
+// a limit keeps its result; the runner and task phase stop at the next stage or
+// iteration boundary, see Exceeded.
+type budgetGuard struct {
+	maxRunCost   float64
+	maxRunTokens int64
+	maxPhaseCost float64
+	downgrade    *modelSwitch // nil when downgrade is not configured or not possible
+	log          Logger
+
+	mu         sync.Mutex
+	downgraded bool
+	base       UsageStats // spend at the time of downgrade, limits apply to spend past it
+	stopErr    error      // set once a crossed limit stops the run
+}
+
+// newBudgetGuard returns nil when no budget limit is configured.
+func newBudgetGuard(cfg Config, log Logger, sw *modelSwitch) *budgetGuard {
+	app := cfg.AppConfig
+	if app == nil || (app.MaxRunCost <= 0 && app.MaxRunTokens <= 0 && app.MaxPhaseCost <= 0) {
+		return nil
+	}
+	g := &budgetGuard{maxRunCost: app.MaxRunCost, maxRunTokens: app.MaxRunTokens, maxPhaseCost: app.MaxPhaseCost, log: log}
+	if app.BudgetAction == config.BudgetActionDowngrade {
+		if sw == nil {
+			log.Print("warning: budget_action = downgrade needs budget_model or review_model, budget limits will stop the run instead")
+		}
+		g.downgrade = sw
+	}
+	return g
+}
+
+// check compares accumulated usage against the limits and records a crossing that stops the run.
+func (g *budgetGuard) check(stats UsageStats, ph status.Phase) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.stopErr != nil {
+		return
+	}
+	reason := g.crossed(stats, ph)
+	if reason == "" {
+		return
+	}
+	if g.downgrade != nil && !g.downgraded {
+		g.downgraded = true
+		g.base = stats
+		g.downgrade.activate()
+		g.log.Print("budget limit reached: %s, switching to budget model %s", reason, g.downgrade.model)
+		return
+	}
+	g.log.Print("budget limit reached: %s, stopping run; re-run the plan to resume", reason)
+	g.stopErr = fmt.Errorf("%w: %s", ErrBudgetExceeded, reason)
+}
+
+// Exceeded returns an error wrapping ErrBudgetExceeded once a crossed limit stopped the run.
+// safe to call on a nil guard.
+func (g *budgetGuard) Exceeded() error {
+	if g == nil {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.stopErr
+}
+
+// crossed returns a description of the first crossed limit, empty when within budget.
+func (g *budgetGuard) crossed(stats UsageStats, ph status.Phase) string {
+	var reasons []string
+	if runCost := stats.Total.CostUSD - g.base.Total.CostUSD; g.maxRunCost > 0 && runCost >= g.maxRunCost {
+		reasons = append(reasons, fmt.Sprintf("run cost $%.2f of max $%.2f", runCost, g.maxRunCost))
+	}
+	if tokens := stats.Total.TotalTokens() - g.base.Total.TotalTokens(); g.maxRunTokens > 0 && tokens >= g.maxRunTokens {
+		reasons = append(reasons, fmt.Sprintf("run tokens %s of max %s",
+			executor.FormatTokens(tokens), executor.FormatTokens(g.maxRunTokens)))
+	}
+	if phaseCost := stats.Phases[ph].CostUSD - g.base.Phases[ph].CostUSD; g.maxPhaseCost > 0 && phaseCost >= g.maxPhaseCost {
+		reasons = append(reasons, fmt.Sprintf("%s phase cost $%.2f of max $%.2f", ph, phaseCost, g.maxPhaseCost))
+	}
+	return strings.Join(reasons, ", ")
+}
+
+// modelSwitch routes sessions of wrapped executors to a cheaper executor once activated.
+type modelSwitch struct {
+	fallback Executor
+	model    string // budget model spec, for logging
+	active   atomic.Bool
+}
+
+func (s *modelSwitch) activate() { s.active.Store(true) }
+
+// wrap returns an executor that delegates to primary until the switch is activated.
+func (s *modelSwitch) wrap(primary Executor) Executor {
+	if primary == nil {
+		return nil
+	}
+	return &switchedExecutor{primary: primary, sw: s}
+}
+
+type switchedExecutor struct {
+	primary Executor
+	sw      *modelSwitch
+}
+
+func (e *switchedExecutor) Run(ctx context.Context, prompt string) executor.Result {
+	if e.sw.active.Load() {
+		return e.sw.fallback.Run(ctx, prompt)
+	}
+	return e.primary.Run(ctx, prompt)
+}
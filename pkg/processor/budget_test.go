@@ -0,0 +1,185 @@
+package processor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestNewBudgetGuard(t *testing.T) {
+	t.Run("nil without limits", func(t *testing.T) {
+		assert.Nil(t, newBudgetGuard(Config{AppConfig: testAppConfig(t)}, newMockLogger(), nil))
+		assert.Nil(t, newBudgetGuard(Config{}, newMockLogger(), nil))
+	})
+
+	t.Run("downgrade without budget model falls back to stop", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.MaxRunCost, appCfg.BudgetAction = 1, config.BudgetActionDowngrade
+		log := newMockLogger()
+		g := newBudgetGuard(Config{AppConfig: appCfg}, log, nil)
+		require.NotNil(t, g)
+		assert.Nil(t, g.downgrade)
+		assertLogContains(t, log, "needs budget_model")
+	})
+
+	t.Run("stop action ignores switch", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.MaxRunTokens = 100
+		g := newBudgetGuard(Config{AppConfig: appCfg}, newMockLogger(), &modelSwitch{})
+		require.NotNil(t, g)
+		assert.Nil(t, g.downgrade)
+	})
+}
+
+func TestBudgetGuard_Check(t *testing.T) {
+	usage := func(cost float64, tokens int64) UsageStats {
+		u := executor.Usage{InputTokens: tokens, CostUSD: cost}
+		return UsageStats{Total: u, Phases: map[status.Phase]executor.Usage{status.PhaseReview: u}}
+	}
+
+	t.Run("within limits", func(t *testing.T) {
+		g := &budgetGuard{maxRunCost: 5, maxRunTokens: 1000, maxPhaseCost: 2, log: newMockLogger()}
+		g.check(usage(1.5, 999), status.PhaseReview)
+		require.NoError(t, g.Exceeded())
+	})
+
+	tests := []struct {
+		name                 string
+		maxRunCost, maxPhase float64
+		maxRunTokens         int64
+		stats                UsageStats
+		want                 string
+	}{
+		{name: "run cost", maxRunCost: 5, stats: usage(5.01, 10), want: "run cost $5.01 of max $5.00"},
+		{name: "run tokens", maxRunTokens: 1000, stats: usage(0, 1500), want: "run tokens 1.5k of max 1.0k"},
+		{name: "phase cost", maxPhase: 2, stats: usage(2.5, 10), want: "review phase cost $2.50 of max $2.00"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			log := newMockLogger()
+			g := &budgetGuard{maxRunCost: tc.maxRunCost, maxRunTokens: tc.maxRunTokens, maxPhaseCost: tc.maxPhase, log: log}
+			g.check(tc.stats, status.PhaseReview)
+			err := g.Exceeded()
+			require.ErrorIs(t, err, ErrBudgetExceeded)
+			assert.Contains(t, err.Error(), tc.want)
+			assertLogContains(t, log, "stopping run")
+		})
+	}
+
+	t.Run("phase cost only counts the current phase", func(t *testing.T) {
+		g := &budgetGuard{maxPhaseCost: 2, log: newMockLogger()}
+		g.check(usage(2.5, 10), status.PhaseTask)
+		require.NoError(t, g.Exceeded())
+	})
+
+	t.Run("downgrade once then stop", func(t *testing.T) {
+		sw := &modelSwitch{model: "haiku"}
+		log := newMockLogger()
+		g := &budgetGuard{maxRunCost: 5, downgrade: sw, log: log}
+
+		g.check(usage(6, 10), status.PhaseReview)
+		require.NoError(t, g.Exceeded(), "first crossing downgrades")
+		assert.True(t, sw.active.Load())
+		assertLogContains(t, log, "switching to budget model")
+
+		g.check(usage(10.9, 10), status.PhaseReview)
+		require.NoError(t, g.Exceeded(), "limit re-armed from spend at downgrade")
+		g.check(usage(11, 10), status.PhaseReview)
+		require.ErrorIs(t, g.Exceeded(), ErrBudgetExceeded)
+	})
+
+	t.Run("first stop is kept", func(t *testing.T) {
+		g := &budgetGuard{maxRunCost: 5, log: newMockLogger()}
+		g.check(usage(6, 10), status.PhaseReview)
+		g.check(usage(9, 10), status.PhaseReview)
+		err := g.Exceeded()
+		require.ErrorIs(t, err, ErrBudgetExceeded)
+		assert.Contains(t, err.Error(), "run cost $6.00")
+	})
+
+	t.Run("nil guard", func(t *testing.T) {
+		var g *budgetGuard
+		require.NoError(t, g.Exceeded())
+	})
+}
+
+func TestModelSwitch_Wrap(t *testing.T) {
+	primary := newMockExecutor([]executor.Result{{Output: "primary"}})
+	fallback := newMockExecutor([]executor.Result{{Output: "fallback"}})
+	sw := &modelSwitch{fallback: fallback}
+	e := sw.wrap(primary)
+
+	assert.Equal(t, "primary", e.Run(t.Context(), "p").Output)
+	sw.activate()
+	assert.Equal(t, "fallback", e.Run(t.Context(), "p").Output)
+	assert.Len(t, primary.RunCalls(), 1)
+	assert.Nil(t, sw.wrap(nil))
+}
+
+func TestRunner_Budget_StopsRun(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n### Task 1: first\n- [ ] open\n### Task 2: second\n- [ ] open"), 0o600))
+
+	claude := newMockExecutor([]executor.Result{
+		{Output: "step", Usage: executor.Usage{CostUSD: 0.6}},
+		{Output: "step", Usage: executor.Usage{CostUSD: 0.6}},
+		{Output: "step", Usage: executor.Usage{CostUSD: 0.6}},
+	})
+	appCfg := testAppConfig(t)
+	appCfg.MaxRunCost = 1
+	cfg := Config{Mode: ModeTasksOnly, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1, AppConfig: appCfg}
+	r := NewWithExecutors(cfg, newMockLogger(), Executors{Task: claude}, &status.PhaseHolder{})
+
+	err := r.Run(t.Context())
+	require.ErrorIs(t, err, ErrBudgetExceeded)
+	assert.Len(t, claude.RunCalls(), 2, "run stops right after the session that crossed the limit")
+	assert.InDelta(t, 1.2, r.Usage().Total.CostUSD, 1e-9)
+}
+
+func TestRunner_Budget_StopsBetweenStages(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n### Task 1: first\n- [x] done"), 0o600))
+
+	claude := newMockExecutor([]executor.Result{
+		{Output: "done", Signal: status.Completed, Usage: executor.Usage{CostUSD: 2}},
+		{Output: "review", Signal: status.ReviewDone, Usage: executor.Usage{CostUSD: 0.1}},
+	})
+	appCfg := testAppConfig(t)
+	appCfg.MaxRunCost = 1
+	cfg := Config{Mode: ModeFull, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1, AppConfig: appCfg}
+	r := NewWithExecutors(cfg, newMockLogger(), Executors{Task: claude}, &status.PhaseHolder{})
+
+	err := r.Run(t.Context())
+	require.ErrorIs(t, err, ErrBudgetExceeded)
+	assert.NotContains(t, err.Error(), "task phase", "the crossing session completed the task stage")
+	assert.Len(t, claude.RunCalls(), 1, "the review stage never starts")
+}
+
+func TestRunner_Budget_DowngradesExecutors(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n### Task 1: first\n- [x] done"), 0o600))
+
+	claude := newMockExecutor([]executor.Result{{Output: "expensive", Usage: executor.Usage{CostUSD: 2}}})
+	var budgetPrompts []string
+	cheap := newMockExecutor(nil)
+	cheap.RunFunc = func(_ context.Context, prompt string) executor.Result {
+		budgetPrompts = append(budgetPrompts, prompt)
+		return executor.Result{Output: "cheap", Signal: status.Completed, Usage: executor.Usage{CostUSD: 0.1}}
+	}
+	appCfg := testAppConfig(t)
+	appCfg.MaxRunCost, appCfg.BudgetAction, appCfg.BudgetModel = 1, config.BudgetActionDowngrade, "haiku"
+	cfg := Config{Mode: ModeTasksOnly, PlanFile: planFile, MaxIterations: 10, IterationDelayMs: 1, AppConfig: appCfg}
+	r := NewWithExecutors(cfg, newMockLogger(), Executors{Task: claude, Budget: cheap}, &status.PhaseHolder{})
+
+	require.NoError(t, r.Run(t.Context()))
+	assert.Len(t, claude.RunCalls(), 1)
+	assert.Len(t, budgetPrompts, 1, "sessions after the downgrade run on the budget executor")
+}
@@ -8,28 +8,50 @@ import (
 
 	"github.com/umputun/ralphex/pkg/executor"
 	"github.com/umputun/ralphex/pkg/processor/phase"
+	"github.com/umputun/ralphex/pkg/status"
 )
 
 type retryPolicy struct {
 	cfg         Config
 	log         Logger
 	waitOnLimit time.Duration
+	usage       *usageTracker       // optional, records session usage per phase
+	holder      *status.PhaseHolder // optional, current phase for usage attribution
+	budget      *budgetGuard        // optional, run budget limits checked after every session
+	findings    *findingsRecorder   // optional, records FINDINGS payloads of every session
+	tape        *sessionTape        // optional, records every session or replays recorded ones
 }
 
 type retryPolicyOpts struct {
 	cfg         Config
 	log         Logger
 	waitOnLimit time.Duration
+	usage       *usageTracker
+	holder      *status.PhaseHolder
+	budget      *budgetGuard
+	findings    *findingsRecorder
+	tape        *sessionTape
 }
 
 func newRetryPolicy(opts retryPolicyOpts) *retryPolicy {
-	return &retryPolicy{cfg: opts.cfg, log: opts.log, waitOnLimit: opts.waitOnLimit}
+	return &retryPolicy{cfg: opts.cfg, log: opts.log, waitOnLimit: opts.waitOnLimit, usage: opts.usage,
+		holder: opts.holder, budget: opts.budget, findings: opts.findings, tape: opts.tape}
 }
 
+// Run executes a session with timeout and limit-wait retries.
+// the returned result carries usage summed over all sessions started by this call.
 func (p *retryPolicy) Run(ctx context.Context, run func(context.Context, string) executor.Result,
 	prompt string, toolName string) phase.ExecutionResult {
+	var spent executor.Usage
 	for {
-		result := p.runWithSessionTimeout(ctx, run, prompt, toolName)
+		result := p.runSession(ctx, run, prompt, toolName)
+		p.recordUsage(result.Result.Usage, toolName)
+		if p.findings != nil {
+			p.findings.record(result.Result.Output, toolName)
+		}
+		spent = spent.Add(result.Result.Usage)
+		result.Result.Usage = spent
+		p.checkBudget(result.Result.Usage)
 		if result.Result.Error == nil {
 			return result
 		}
@@ -54,11 +76,40 @@ func (p *retryPolicy) Run(ctx context.Context, run func(context.Context, string)
 			limitErr.Pattern, toolName, p.waitOnLimit)
 
 		if err := p.Sleep(ctx, p.waitOnLimit); err != nil {
-			return phase.ExecutionResult{Result: executor.Result{Error: fmt.Errorf("interrupted during limit wait: %w", ctx.Err())}}
+			return phase.ExecutionResult{Result: executor.Result{
+				Error: fmt.Errorf("interrupted during limit wait: %w", ctx.Err()), Usage: spent}}
 		}
 	}
 }
 
+// recordUsage logs a session's usage and adds it to the run totals under the current phase.
+func (p *retryPolicy) recordUsage(u executor.Usage, toolName string) {
+	if u.IsZero() {
+		return
+	}
+	p.log.Print("%s session usage: %s", toolName, u)
+	if p.usage == nil {
+		return
+	}
+	p.usage.add(p.currentPhase(), u)
+}
+
+// checkBudget evaluates run budget limits once a session reported usage. a crossing is only
+// recorded, the session result stays as is and the run stops at the next stage or task iteration.
+func (p *retryPolicy) checkBudget(u executor.Usage) {
+	if p.budget == nil || p.usage == nil || u.IsZero() {
+		return
+	}
+	p.budget.check(p.usage.stats(), p.currentPhase())
+}
+
+func (p *retryPolicy) currentPhase() status.Phase {
+	if p.holder == nil {
+		return ""
+	}
+	return p.holder.Get()
+}
+
 func (p *retryPolicy) HandlePatternMatchError(err error, tool string) error {
 	if patternErr, ok := errors.AsType[*executor.PatternMatchError](err); ok {
 		p.log.Print("error: detected %q in %s output", patternErr.Pattern, tool)
@@ -84,9 +135,20 @@ func (p *retryPolicy) Sleep(ctx context.Context, d time.Duration) error {
 	}
 }
 
+// runSession runs a single session with the session timeout, through the session tape when recording or replaying.
+func (p *retryPolicy) runSession(ctx context.Context, run func(context.Context, string) executor.Result,
+	prompt string, toolName string) phase.ExecutionResult {
+	if p.tape == nil {
+		return p.runWithSessionTimeout(ctx, run, prompt, toolName)
+	}
+	return p.tape.session(ctx, toolName, string(p.currentPhase()), prompt, func() phase.ExecutionResult {
+		return p.runWithSessionTimeout(ctx, run, prompt, toolName)
+	})
+}
+
 func (p *retryPolicy) runWithSessionTimeout(ctx context.Context, run func(context.Context, string) executor.Result,
 	prompt string, toolName string) phase.ExecutionResult {
-	sessionTimeout := p.sessionTimeout()
+	sessionTimeout := p.sessionTimeout(ctx)
 	codexMode := p.cfg.isCodexExecutor()
 	useTimeout := sessionTimeout > 0 && (codexMode || toolName == "claude")
 
@@ -119,7 +181,11 @@ func (p *retryPolicy) handleIdleTimeout(result executor.Result, toolName string)
 	return false
 }
 
-func (p *retryPolicy) sessionTimeout() time.Duration {
+// sessionTimeout returns the session timeout of ctx, the task override or the configured one.
+func (p *retryPolicy) sessionTimeout(ctx context.Context) time.Duration {
+	if d, ok := phase.SessionTimeout(ctx); ok {
+		return d
+	}
 	if p.cfg.AppConfig == nil {
 		return 0
 	}
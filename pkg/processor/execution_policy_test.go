@@ -11,6 +11,7 @@ import (
 
 	"github.com/umputun/ralphex/pkg/config"
 	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/processor/phase"
 	"github.com/umputun/ralphex/pkg/status"
 )
 
@@ -235,6 +236,25 @@ func TestExecutionPolicy_SessionTimeoutZeroDoesNotAddDeadline(t *testing.T) {
 	}
 }
 
+func TestExecutionPolicy_SessionTimeoutTaskOverride(t *testing.T) {
+	log := newMockLogger()
+	appCfg := testAppConfig(t)
+	appCfg.SessionTimeout = time.Hour
+	policy := newRetryPolicy(retryPolicyOpts{cfg: Config{AppConfig: appCfg}, log: log})
+
+	run := func(ctx context.Context, _ string) executor.Result {
+		<-ctx.Done()
+		return executor.Result{Error: ctx.Err()}
+	}
+	ctx := phase.WithSessionTimeout(t.Context(), 50*time.Millisecond)
+	result := policy.runWithSessionTimeout(ctx, run, "test prompt", "claude")
+
+	require.NoError(t, result.Result.Error)
+	assert.True(t, result.TimedOut)
+	assert.Equal(t, 50*time.Millisecond, policy.sessionTimeout(ctx))
+	assert.Equal(t, time.Hour, policy.sessionTimeout(t.Context()))
+}
+
 func TestExecutionPolicy_SessionTimeoutParentCancelNotMisidentified(t *testing.T) {
 	log := newMockLogger()
 	appCfg := testAppConfig(t)
@@ -1,9 +1,11 @@
 package processor
 
 import (
+	"cmp"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 
@@ -15,19 +17,28 @@ type executorFactory struct{}
 
 func (f *executorFactory) Build(cfg Config, log Logger) (Config, Executors) {
 	customExec := cfg.buildCustomExecutor(log)
+	customExecs := cfg.buildNamedCustomExecutors(log)
+	httpExec := cfg.buildHTTPExecutor(log)
 
 	if cfg.isCodexExecutor() {
 		if cfg.AppConfig.PassClaudeMd {
 			maybeEmitClaudeMdSetupHint(log)
 		}
 		codexTask, codexReview := cfg.buildCodexExecutors(log)
-		return cfg, Executors{Task: codexTask, Review: codexReview, Custom: customExec}
+		return cfg, Executors{Task: codexTask, Review: codexReview, Custom: customExec, Customs: customExecs,
+			HTTP: httpExec, Budget: cfg.buildBudgetExecutor(log), ForModel: cfg.modelExecutorBuilder(log)}
 	}
 
-	claudeExec, reviewExec := cfg.buildClaudeExecutors(log)
+	var taskExec, reviewExec Executor
+	if cfg.AppConfig != nil && cfg.AppConfig.AgentProvider != "" {
+		taskExec, reviewExec = cfg.buildProviderExecutors(log)
+	} else {
+		taskExec, reviewExec = cfg.buildClaudeExecutors(log)
+	}
 	codexExec := cfg.buildExternalCodexExecutor(log)
 
-	if cfg.CodexEnabled && f.needsCodexBinary(cfg.AppConfig) {
+	// replayed runs don't start codex, its absence must not change the recorded review flow
+	if cfg.CodexEnabled && cfg.ReplayDir == "" && f.needsCodexBinary(cfg.AppConfig) {
 		codexCmd := codexExec.Command
 		if codexCmd == "" {
 			codexCmd = "codex"
@@ -38,7 +49,50 @@ func (f *executorFactory) Build(cfg Config, log Logger) (Config, Executors) {
 		}
 	}
 
-	return cfg, Executors{Task: claudeExec, Review: reviewExec, External: codexExec, Custom: customExec}
+	return cfg, Executors{Task: taskExec, Review: reviewExec, External: codexExec, Custom: customExec,
+		Customs: customExecs, HTTP: httpExec, Budget: cfg.buildBudgetExecutor(log), ForModel: cfg.modelExecutorBuilder(log)}
+}
+
+// buildBudgetExecutor builds the executor switched to by budget_action = downgrade,
+// running budget_model (or the review model when unset) on the configured executor.
+// returns nil when downgrade is not configured or no model to switch to is known.
+func (cfg Config) buildBudgetExecutor(log Logger) Executor {
+	if cfg.AppConfig == nil || cfg.AppConfig.BudgetAction != config.BudgetActionDowngrade {
+		return nil
+	}
+	spec := cmp.Or(cfg.AppConfig.BudgetModel, cfg.ReviewModel)
+	if spec == "" {
+		return nil
+	}
+	return cfg.buildModelExecutor(log, spec)
+}
+
+// modelExecutorBuilder returns the builder of task executors for the model overrides of plan tasks.
+func (cfg Config) modelExecutorBuilder(log Logger) func(spec string) Executor {
+	return func(spec string) Executor { return cfg.buildModelExecutor(log, spec) }
+}
+
+// buildModelExecutor builds an executor of the configured kind running the model[:effort] spec.
+func (cfg Config) buildModelExecutor(log Logger, spec string) Executor {
+	if cfg.isCodexExecutor() {
+		var defModel, defEffort string
+		if cfg.AppConfig != nil {
+			defModel, defEffort = cfg.AppConfig.CodexModel, cfg.AppConfig.CodexReasoningEffort
+		}
+		e := cfg.buildCodexExecutor(log)
+		e.Model, e.ReasoningEffort, _ = ResolveCodexModelEffort(spec, defModel, defEffort)
+		return e
+	}
+	if cfg.AppConfig != nil && cfg.AppConfig.AgentProvider != "" {
+		e := cfg.newProviderExecutor(log)
+		e.Model, e.Effort = parseModelEffort(spec)
+		return e
+	}
+	e := &executor.ClaudeExecutor{OutputHandler: func(text string) { log.PrintAligned(text) }, Debug: cfg.Debug,
+		ReadOnly: cfg.ReportOnly}
+	cfg.applyClaudeAppConfig(e)
+	e.Model, e.Effort = parseModelEffort(spec)
+	return e
 }
 
 // buildClaudeExecutors constructs the claude executors for task and review phases.
@@ -49,7 +103,8 @@ func (cfg Config) buildClaudeExecutors(log Logger) (*executor.ClaudeExecutor, Ex
 		OutputHandler: func(text string) {
 			log.PrintAligned(text)
 		},
-		Debug: cfg.Debug,
+		Debug:    cfg.Debug,
+		ReadOnly: cfg.ReportOnly, // report-only review must not edit or commit
 	}
 	cfg.applyClaudeAppConfig(claudeExec)
 
@@ -70,6 +125,7 @@ func (cfg Config) buildClaudeExecutors(log Logger) (*executor.ClaudeExecutor, Ex
 		Debug:         cfg.Debug,
 		Model:         reviewModel,
 		Effort:        reviewEffort,
+		ReadOnly:      cfg.ReportOnly,
 	}
 	cfg.applyClaudeAppConfig(reviewExec)
 	return claudeExec, reviewExec
@@ -91,6 +147,36 @@ func (cfg Config) applyClaudeAppConfig(e *executor.ClaudeExecutor) {
 	e.PreserveAPIKey = cfg.AppConfig.PreserveAnthropicAPIKey
 }
 
+// buildProviderExecutors constructs the executors of the agent_provider CLI for task and review
+// phases, with the same task/review model split as buildClaudeExecutors.
+func (cfg Config) buildProviderExecutors(log Logger) (*executor.GenericExecutor, Executor) {
+	taskExec := cfg.newProviderExecutor(log)
+	taskExec.Model, taskExec.Effort = parseModelEffort(cfg.TaskModel)
+
+	reviewModel, reviewEffort := parseModelEffort(cmp.Or(cfg.ReviewModel, cfg.TaskModel))
+	if reviewModel == taskExec.Model && reviewEffort == taskExec.Effort {
+		return taskExec, nil
+	}
+	reviewExec := cfg.newProviderExecutor(log)
+	reviewExec.Model, reviewExec.Effort = reviewModel, reviewEffort
+	return taskExec, reviewExec
+}
+
+// newProviderExecutor returns a GenericExecutor for the agent_provider file. the claude
+// error, limit and retry patterns apply, since the provider takes the claude slot.
+func (cfg Config) newProviderExecutor(log Logger) *executor.GenericExecutor {
+	return &executor.GenericExecutor{
+		ProviderFile:  cfg.AppConfig.AgentProvider,
+		OutputHandler: func(text string) { log.PrintAligned(text) },
+		Debug:         cfg.Debug,
+		ErrorPatterns: cfg.AppConfig.ClaudeErrorPatterns,
+		LimitPatterns: cfg.AppConfig.ClaudeLimitPatterns,
+		RetryPatterns: cfg.AppConfig.ClaudeRetryPatterns,
+		IdleTimeout:   cfg.AppConfig.IdleTimeout,
+		ReadOnly:      cfg.ReportOnly, // report-only review must not edit or commit
+	}
+}
+
 // buildExternalCodexExecutor builds the codex executor used for the external review
 // phase in claude mode. MultiAgent stays off (the external review prompt does not use
 // spawn_agent) and PassClaudeMd stays off (rejected for claude mode by applyCodexOverrides).
@@ -99,6 +185,7 @@ func (cfg Config) buildExternalCodexExecutor(log Logger) *executor.CodexExecutor
 	if cfg.AppConfig != nil {
 		e.Sandbox = cfg.AppConfig.CodexSandbox
 	}
+	e.OutputHandler = cfg.externalToolOutput(log, "codex")
 	return e
 }
 
@@ -115,6 +202,9 @@ func (cfg Config) buildCodexExecutor(log Logger) *executor.CodexExecutor {
 		e.PassClaudeMd = cfg.AppConfig.PassClaudeMd
 		e.IdleTimeout = cfg.AppConfig.IdleTimeout
 	}
+	if cfg.ReportOnly {
+		e.Sandbox = "read-only" // report-only review must not edit or commit
+	}
 	return e
 }
 
@@ -176,16 +266,59 @@ func (cfg Config) buildCustomExecutor(log Logger) *executor.CustomExecutor {
 	if cfg.AppConfig == nil || cfg.AppConfig.CustomReviewScript == "" {
 		return nil
 	}
+	return cfg.newCustomExecutor(log, "custom", cfg.AppConfig.CustomReviewScript)
+}
+
+// buildNamedCustomExecutors returns the executors of custom_review_scripts, keyed by name,
+// used by custom:<name> external review tools. returns nil when no named script is configured.
+func (cfg Config) buildNamedCustomExecutors(log Logger) map[string]*executor.CustomExecutor {
+	if cfg.AppConfig == nil || len(cfg.AppConfig.CustomReviewScripts) == 0 {
+		return nil
+	}
+	execs := make(map[string]*executor.CustomExecutor, len(cfg.AppConfig.CustomReviewScripts))
+	for _, entry := range cfg.AppConfig.CustomReviewScripts {
+		name, script, _ := strings.Cut(entry, "=")
+		execs[name] = cfg.newCustomExecutor(log, "custom:"+name, script)
+	}
+	return execs
+}
+
+func (cfg Config) newCustomExecutor(log Logger, tool, script string) *executor.CustomExecutor {
 	return &executor.CustomExecutor{
-		Script: cfg.AppConfig.CustomReviewScript,
-		OutputHandler: func(text string) {
-			log.PrintAligned(text)
-		},
+		Script:        script,
+		OutputHandler: cfg.externalToolOutput(log, tool),
+		ErrorPatterns: cfg.AppConfig.CodexErrorPatterns,
+		LimitPatterns: cfg.AppConfig.CodexLimitPatterns,
+	}
+}
+
+// buildHTTPExecutor returns the OpenAI-compatible http review executor.
+// returns nil when http_review_base_url is not configured.
+func (cfg Config) buildHTTPExecutor(log Logger) *executor.HTTPExecutor {
+	if cfg.AppConfig == nil || cfg.AppConfig.HTTPReviewBaseURL == "" {
+		return nil
+	}
+	return &executor.HTTPExecutor{
+		BaseURL:       cfg.AppConfig.HTTPReviewBaseURL,
+		Model:         cfg.AppConfig.HTTPReviewModel,
+		APIKeyEnv:     cfg.AppConfig.HTTPReviewAPIKeyEnv,
+		ChunkTokens:   cfg.AppConfig.HTTPReviewChunkTokens,
+		IdleTimeout:   cfg.AppConfig.HTTPReviewIdleTimeout,
+		OutputHandler: cfg.externalToolOutput(log, "http"),
 		ErrorPatterns: cfg.AppConfig.CodexErrorPatterns,
 		LimitPatterns: cfg.AppConfig.CodexLimitPatterns,
 	}
 }
 
+// externalToolOutput returns the output handler of an external review tool. when several tools
+// review in parallel, their streamed lines interleave and are prefixed with the tool name.
+func (cfg Config) externalToolOutput(log Logger, tool string) func(string) {
+	if cfg.AppConfig == nil || !strings.Contains(cfg.AppConfig.ExternalReviewTool, ",") {
+		return func(text string) { log.PrintAligned(text) }
+	}
+	return func(text string) { log.PrintAligned("[" + tool + "] " + text) }
+}
+
 // claudeMdHintOnce ensures the user-level CLAUDE.md setup hint emits at most once
 // per process, regardless of how many runners or phases are constructed.
 var claudeMdHintOnce sync.Once
@@ -225,12 +358,11 @@ func (*executorFactory) needsCodexBinary(appConfig *config.Config) bool {
 	if appConfig == nil {
 		return true
 	}
-	switch appConfig.ExternalReviewTool {
-	case "custom", "none":
-		return false
-	default:
+	tools, err := config.ParseExternalReviewTools(appConfig.ExternalReviewTool)
+	if err != nil || len(tools) == 0 {
 		return true
 	}
+	return slices.Contains(tools, "codex")
 }
 
 // parseModelEffort splits a "model[:effort]" spec into separate parts.
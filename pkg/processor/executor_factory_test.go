@@ -118,6 +118,86 @@ func TestRunner_New_CodexNotInstalled_CustomReviewStillWorks(t *testing.T) {
 	assert.NotNil(t, r, "runner should be created")
 }
 
+func TestExecutorFactory_NamedCustomExecutors(t *testing.T) {
+	log := newRunnerMockLogger("progress.txt")
+	appCfg := testAppConfig(t)
+	appCfg.CodexCommand = "/nonexistent/path/to/codex"
+	appCfg.ExternalReviewTool = "custom:gemini,custom:opencode"
+	appCfg.CustomReviewScripts = []string{"gemini=/s/gemini.sh", "opencode=/s/opencode.sh"}
+
+	cfg, execs := (&executorFactory{}).Build(Config{MaxIterations: 50, CodexEnabled: true, AppConfig: appCfg}, log)
+
+	assert.True(t, cfg.CodexEnabled, "custom tools only don't need the codex binary")
+	require.Len(t, execs.Customs, 2)
+	assert.Equal(t, "/s/gemini.sh", execs.Customs["gemini"].Script)
+	assert.Equal(t, "/s/opencode.sh", execs.Customs["opencode"].Script)
+	execs.Customs["gemini"].OutputHandler("a.go:3 race")
+	require.Len(t, log.PrintAlignedCalls(), 1)
+	assert.Equal(t, "[custom:gemini] a.go:3 race", log.PrintAlignedCalls()[0].Text, "parallel tool output is prefixed")
+
+	f := &executorFactory{}
+	assert.True(t, f.needsCodexBinary(&config.Config{ExternalReviewTool: "custom:gemini,codex"}))
+	assert.False(t, f.needsCodexBinary(appCfg))
+	assert.True(t, f.needsCodexBinary(&config.Config{}), "codex by default")
+}
+
+func TestExecutorFactory_HTTPExecutor(t *testing.T) {
+	appCfg := testAppConfig(t)
+	appCfg.CodexCommand = "/nonexistent/path/to/codex"
+	appCfg.ExternalReviewTool = "http"
+	appCfg.CodexLimitPatterns = []string{"quota"}
+
+	_, execs := (&executorFactory{}).Build(Config{MaxIterations: 50, CodexEnabled: true, AppConfig: appCfg}, newRunnerMockLogger(""))
+	assert.Nil(t, execs.HTTP, "no executor without base url")
+
+	appCfg.HTTPReviewBaseURL = "http://localhost:11434/v1"
+	appCfg.HTTPReviewModel = "qwen"
+	appCfg.HTTPReviewAPIKeyEnv = "REVIEW_KEY"
+	appCfg.HTTPReviewChunkTokens = 8000
+	cfg, execs := (&executorFactory{}).Build(Config{MaxIterations: 50, CodexEnabled: true, AppConfig: appCfg}, newRunnerMockLogger(""))
+
+	assert.True(t, cfg.CodexEnabled, "http tool doesn't need the codex binary")
+	require.NotNil(t, execs.HTTP)
+	assert.Equal(t, "http://localhost:11434/v1", execs.HTTP.BaseURL)
+	assert.Equal(t, "qwen", execs.HTTP.Model)
+	assert.Equal(t, "REVIEW_KEY", execs.HTTP.APIKeyEnv)
+	assert.Equal(t, 8000, execs.HTTP.ChunkTokens)
+	assert.Equal(t, []string{"quota"}, execs.HTTP.LimitPatterns)
+}
+
+func TestExecutorFactory_AgentProvider(t *testing.T) {
+	appCfg := testAppConfig(t)
+	appCfg.CodexCommand = "/nonexistent/path/to/codex"
+	appCfg.AgentProvider = "/providers/codex.json"
+	appCfg.ClaudeLimitPatterns = []string{"usage limit"}
+	appCfg.BudgetAction = config.BudgetActionDowngrade
+	appCfg.BudgetModel = "mini"
+
+	_, execs := (&executorFactory{}).Build(Config{MaxIterations: 50, TaskModel: "gpt-5:high", ReviewModel: "o3",
+		ReportOnly: true, AppConfig: appCfg}, newRunnerMockLogger(""))
+
+	task, ok := execs.Task.(*executor.GenericExecutor)
+	require.True(t, ok, "task executor is %T", execs.Task)
+	assert.Equal(t, "/providers/codex.json", task.ProviderFile)
+	assert.Equal(t, "gpt-5", task.Model)
+	assert.Equal(t, "high", task.Effort)
+	assert.Equal(t, []string{"usage limit"}, task.LimitPatterns)
+	assert.True(t, task.ReadOnly)
+
+	review, ok := execs.Review.(*executor.GenericExecutor)
+	require.True(t, ok, "review executor is %T", execs.Review)
+	assert.Equal(t, "o3", review.Model)
+	assert.Empty(t, review.Effort)
+
+	budget, ok := execs.Budget.(*executor.GenericExecutor)
+	require.True(t, ok, "budget executor is %T", execs.Budget)
+	assert.Equal(t, "mini", budget.Model)
+
+	_, execs = (&executorFactory{}).Build(Config{MaxIterations: 50, TaskModel: "gpt-5", AppConfig: appCfg},
+		newRunnerMockLogger(""))
+	assert.Nil(t, execs.Review, "same model for review reuses the task executor")
+}
+
 func TestRunner_New_CodexNotInstalled_NoneReviewStillWorks(t *testing.T) {
 	log := newRunnerMockLogger("progress.txt")
 
@@ -318,6 +398,112 @@ func TestRunner_New_CodexModelEffortWiring(t *testing.T) {
 	}
 }
 
+func TestRunner_New_BudgetExecutorWiring(t *testing.T) {
+	log := newRunnerMockLogger("progress.txt")
+
+	t.Run("nil unless downgrade with budget model", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.BudgetModel = "haiku"
+		_, execs := (&executorFactory{}).Build(Config{Mode: ModeReview, MaxIterations: 50, AppConfig: appCfg}, log)
+		assert.Nil(t, execs.Budget, "stop action needs no budget executor")
+
+		appCfg.BudgetAction, appCfg.BudgetModel = config.BudgetActionDowngrade, ""
+		_, execs = (&executorFactory{}).Build(Config{Mode: ModeReview, MaxIterations: 50, AppConfig: appCfg}, log)
+		assert.Nil(t, execs.Budget, "downgrade without budget or review model")
+	})
+
+	t.Run("falls back to review model", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.BudgetAction = config.BudgetActionDowngrade
+		_, execs := (&executorFactory{}).Build(Config{Mode: ModeReview, MaxIterations: 50, TaskModel: "opus", ReviewModel: "sonnet:medium", AppConfig: appCfg}, log)
+		budgetExec, ok := execs.Budget.(*executor.ClaudeExecutor)
+		require.True(t, ok, "budget executor should be *executor.ClaudeExecutor")
+		assert.Equal(t, "sonnet", budgetExec.Model)
+		assert.Equal(t, "medium", budgetExec.Effort)
+	})
+
+	t.Run("claude budget model", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.BudgetAction, appCfg.BudgetModel = config.BudgetActionDowngrade, "haiku:low"
+		_, execs := (&executorFactory{}).Build(Config{Mode: ModeReview, MaxIterations: 50, TaskModel: "opus", AppConfig: appCfg}, log)
+		budgetExec, ok := execs.Budget.(*executor.ClaudeExecutor)
+		require.True(t, ok, "budget executor should be *executor.ClaudeExecutor")
+		assert.Equal(t, "haiku", budgetExec.Model)
+		assert.Equal(t, "low", budgetExec.Effort)
+	})
+
+	t.Run("codex budget model keeps config effort", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.Executor = config.ExecutorCodex
+		appCfg.CodexModel, appCfg.CodexReasoningEffort = "gpt-5.5", "xhigh"
+		appCfg.BudgetAction, appCfg.BudgetModel = config.BudgetActionDowngrade, "gpt-5.5-mini"
+		_, execs := (&executorFactory{}).Build(Config{Mode: ModeReview, MaxIterations: 50, AppConfig: appCfg}, log)
+		budgetExec, ok := execs.Budget.(*executor.CodexExecutor)
+		require.True(t, ok, "budget executor should be *executor.CodexExecutor")
+		assert.Equal(t, "gpt-5.5-mini", budgetExec.Model)
+		assert.Equal(t, "xhigh", budgetExec.ReasoningEffort)
+	})
+}
+
+func TestRunner_New_ModelExecutorWiring(t *testing.T) {
+	log := newRunnerMockLogger("progress.txt")
+
+	t.Run("claude", func(t *testing.T) {
+		_, execs := (&executorFactory{}).Build(Config{Mode: ModeFull, MaxIterations: 50, TaskModel: "sonnet", AppConfig: testAppConfig(t)}, log)
+		require.NotNil(t, execs.ForModel)
+		e, ok := execs.ForModel("opus:high").(*executor.ClaudeExecutor)
+		require.True(t, ok, "model executor should be *executor.ClaudeExecutor")
+		assert.Equal(t, "opus", e.Model)
+		assert.Equal(t, "high", e.Effort)
+	})
+
+	t.Run("codex keeps config effort", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.Executor = config.ExecutorCodex
+		appCfg.CodexModel, appCfg.CodexReasoningEffort = "gpt-5.5", "xhigh"
+		_, execs := (&executorFactory{}).Build(Config{Mode: ModeFull, MaxIterations: 50, AppConfig: appCfg}, log)
+		e, ok := execs.ForModel("gpt-5.5-mini").(*executor.CodexExecutor)
+		require.True(t, ok, "model executor should be *executor.CodexExecutor")
+		assert.Equal(t, "gpt-5.5-mini", e.Model)
+		assert.Equal(t, "xhigh", e.ReasoningEffort)
+		assert.True(t, e.MultiAgent)
+	})
+}
+
+func TestRunner_New_ReportOnlyWiring(t *testing.T) {
+	log := newRunnerMockLogger("progress.txt")
+
+	t.Run("claude executors are read-only", func(t *testing.T) {
+		_, execs := (&executorFactory{}).Build(Config{Mode: ModeReview, ReportOnly: true, MaxIterations: 50, ReviewModel: "sonnet",
+			AppConfig: testAppConfig(t)}, log)
+		taskExec, ok := execs.Task.(*executor.ClaudeExecutor)
+		require.True(t, ok)
+		assert.True(t, taskExec.ReadOnly)
+		reviewExec, ok := effectiveReviewExecutor(execs).(*executor.ClaudeExecutor)
+		require.True(t, ok)
+		assert.True(t, reviewExec.ReadOnly)
+		codexExec, ok := execs.External.(*executor.CodexExecutor)
+		require.True(t, ok)
+		assert.Equal(t, "read-only", codexExec.Sandbox)
+	})
+
+	t.Run("regular review is not restricted", func(t *testing.T) {
+		_, execs := (&executorFactory{}).Build(Config{Mode: ModeReview, MaxIterations: 50, AppConfig: testAppConfig(t)}, log)
+		taskExec, ok := execs.Task.(*executor.ClaudeExecutor)
+		require.True(t, ok)
+		assert.False(t, taskExec.ReadOnly)
+	})
+
+	t.Run("codex executor uses read-only sandbox", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.Executor = config.ExecutorCodex
+		_, execs := (&executorFactory{}).Build(Config{Mode: ModeReview, ReportOnly: true, MaxIterations: 50, AppConfig: appCfg}, log)
+		taskExec, ok := execs.Task.(*executor.CodexExecutor)
+		require.True(t, ok)
+		assert.Equal(t, "read-only", taskExec.Sandbox)
+	})
+}
+
 func TestRunner_New_ExecutorRouting(t *testing.T) {
 	log := newRunnerMockLogger("progress.txt")
 	holder := &status.PhaseHolder{}
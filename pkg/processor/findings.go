@@ -0,0 +1,145 @@
+package processor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/findings"
+	"github.com/umputun/ralphex/pkg/processor/phase"
+)
+
+// findingsRecorder accumulates the FINDINGS payloads of review sessions into the findings ledger of the run.
+// the ledger is reset when the first review stage starts and saved after every payload, so an interrupted
+// run keeps what was reported so far. safe for concurrent use.
+type findingsRecorder struct {
+	path    string
+	plan    string
+	branch  string
+	formats []string // export formats written by export
+	log     Logger
+
+	mu     sync.Mutex
+	stage  string           // pipeline stage the recorded findings are attributed to
+	ledger *findings.Ledger // nil until the first review stage starts
+}
+
+// newFindingsRecorder returns a recorder writing to cfg.FindingsPath, or nil when the ledger is disabled.
+func newFindingsRecorder(cfg Config, log Logger) *findingsRecorder {
+	if cfg.FindingsPath == "" {
+		return nil
+	}
+	return &findingsRecorder{path: cfg.FindingsPath, plan: cfg.PlanFile, branch: cfg.Branch, formats: cfg.FindingsExport, log: log}
+}
+
+// startStage attributes the following findings to stage. the first stage other than task
+// starts a fresh ledger, replacing the one of a previous run.
+func (r *findingsRecorder) startStage(stage string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stage = stage
+	if r.ledger != nil || stage == StageTask {
+		return
+	}
+	r.ledger = r.newLedger()
+	r.save()
+}
+
+// record adds the findings reported in output by tool; findings without an agent are attributed to tool.
+// output without a FINDINGS signal is ignored, a malformed payload is logged and dropped.
+func (r *findingsRecorder) record(output, tool string) {
+	reported, err := phase.ParseFindingsPayload(output)
+	if errors.Is(err, phase.ErrNoFindingsSignal) {
+		return
+	}
+	if err != nil {
+		r.log.Print("warning: %s %v", tool, err)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ledger == nil {
+		r.ledger = r.newLedger()
+	}
+	now := time.Now()
+	for _, f := range reported {
+		if f.Agent == "" {
+			f.Agent = tool
+		}
+		f.Stage, f.Time = r.stage, now
+		r.ledger.Findings = append(r.ledger.Findings, f)
+	}
+	r.save()
+}
+
+// summary returns the findings count per verdict and the ledger path, empty when no review stage ran.
+func (r *findingsRecorder) summary() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ledger == nil {
+		return ""
+	}
+	counts := r.ledger.Counts()
+	return fmt.Sprintf("review findings: %d fixed, %d confirmed, %d false positives, ledger %s",
+		counts[findings.VerdictFixed], counts[findings.VerdictConfirmed], counts[findings.VerdictFalsePositive], r.path)
+}
+
+// export writes the ledger in every configured format next to it; nothing is written when no review stage ran.
+// a failed export is logged, as the run itself is not affected.
+func (r *findingsRecorder) export() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ledger == nil {
+		return
+	}
+	for _, name := range r.formats {
+		format, err := findings.ParseFormat(name)
+		if err == nil {
+			err = r.exportFile(format)
+		}
+		if err != nil {
+			r.log.Print("warning: %v", err)
+			continue
+		}
+		r.log.Print("findings exported as %s to %s", format, findings.ExportFile(r.path, format))
+	}
+}
+
+// report writes the markdown report of the ledger next to it and returns its path.
+func (r *findingsRecorder) report() (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ledger == nil {
+		r.ledger = r.newLedger()
+	}
+	if err := r.exportFile(findings.FormatMarkdown); err != nil {
+		return "", err
+	}
+	return findings.ExportFile(r.path, findings.FormatMarkdown), nil
+}
+
+func (r *findingsRecorder) exportFile(format findings.Format) error {
+	var buf bytes.Buffer
+	if err := r.ledger.Export(&buf, format); err != nil {
+		return fmt.Errorf("findings ledger %s: %w", r.path, err)
+	}
+	if err := os.WriteFile(findings.ExportFile(r.path, format), buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("write %s findings: %w", format, err)
+	}
+	return nil
+}
+
+func (r *findingsRecorder) newLedger() *findings.Ledger {
+	return &findings.Ledger{Plan: r.plan, Branch: r.branch, Started: time.Now()}
+}
+
+// save writes the ledger; a write failure is logged, as the run itself is not affected. caller holds mu.
+func (r *findingsRecorder) save() {
+	if err := r.ledger.Save(r.path); err != nil {
+		r.log.Print("warning: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/findings"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// findingsOutput wraps a findings JSON list in a FINDINGS signal.
+func findingsOutput(list string) string {
+	return fmt.Sprintf("summary of the review\n<<<RALPHEX:FINDINGS>>>\n{\"findings\": %s}\n<<<RALPHEX:END>>>\n", list)
+}
+
+func TestFindingsRecorder(t *testing.T) {
+	t.Run("disabled without path", func(t *testing.T) {
+		assert.Nil(t, newFindingsRecorder(Config{}, newRunnerMockLogger("")))
+	})
+
+	t.Run("ledger starts with the first review stage", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "findings", "feature.json")
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(`{"findings":[{"verdict":"fixed","description":"old run"}]}`), 0o600))
+		log := newRunnerMockLogger("")
+		r := newFindingsRecorder(Config{FindingsPath: path, PlanFile: "docs/plans/feature.md"}, log)
+
+		r.startStage(StageTask)
+		assert.Empty(t, r.summary(), "no review stage ran yet")
+		r.startStage(StageReviewFirst)
+		l, err := findings.Load(path)
+		require.NoError(t, err)
+		assert.Empty(t, l.Findings, "ledger of the previous run replaced")
+		assert.Equal(t, "docs/plans/feature.md", l.Plan)
+
+		r.record(findingsOutput(`[{"file": "a.go", "line": 3, "agent": "quality", "verdict": "fixed", "commit": "abc", "description": "bug"}]`), "claude")
+		r.startStage(StageExternal)
+		r.record(findingsOutput(`[{"file": "b.go", "verdict": "false_positive", "description": "intended"}]`), "codex")
+		r.record("no findings here", "claude")
+		r.record("<<<RALPHEX:FINDINGS>>>\n{bad}\n<<<RALPHEX:END>>>", "claude")
+
+		l, err = findings.Load(path)
+		require.NoError(t, err)
+		require.Len(t, l.Findings, 2)
+		assert.Equal(t, StageReviewFirst, l.Findings[0].Stage)
+		assert.Equal(t, "quality", l.Findings[0].Agent)
+		assert.False(t, l.Findings[0].Time.IsZero())
+		assert.Equal(t, StageExternal, l.Findings[1].Stage)
+		assert.Equal(t, "codex", l.Findings[1].Agent, "defaults to the reporting tool")
+		assertLogArg(t, log, "claude")
+		assert.Equal(t, "review findings: 1 fixed, 0 confirmed, 1 false positives, ledger "+path, r.summary())
+	})
+}
+
+func TestRunner_FindingsLedger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "findings", "feature.json")
+	log := newRunnerMockLogger("progress.txt")
+	claude := newMockExecutor([]executor.Result{
+		{Output: findingsOutput(`[{"file": "a.go", "line": 7, "severity": "major", "agent": "quality", "verdict": "fixed", "commit": "abc", "description": "race"}]`)},
+		{Output: "review done", Signal: status.ReviewDone}, // pre-codex review loop
+		{Output: findingsOutput(`[{"file": "b.go", "line": 1, "agent": "codex", "verdict": "confirmed", "description": "leak"}]`)},
+		{Output: "done", Signal: status.CodexDone},
+		{Output: findingsOutput(`[{"file": "b.go", "line": 1, "agent": "implementation", "verdict": "fixed", "commit": "def", "description": "leak"}]`)},
+		{Output: "review done", Signal: status.ReviewDone},
+	})
+	codex := newMockExecutor([]executor.Result{{Output: "found leak in b.go:1"}, {Output: "no issues found"}})
+
+	cfg := Config{Mode: ModeReview, MaxIterations: 50, IterationDelayMs: 1, CodexEnabled: true, FindingsPath: path,
+		FindingsExport: []string{"sarif", "markdown"}, Branch: "feature", AppConfig: testAppConfig(t)}
+	r := NewWithExecutors(cfg, log, Executors{Task: claude, External: codex}, &status.PhaseHolder{})
+	require.NoError(t, r.Run(t.Context()))
+
+	l, err := findings.Load(path)
+	require.NoError(t, err)
+	require.Len(t, l.Findings, 3)
+	assert.Equal(t, "feature", l.Branch)
+	stages := make([]string, 0, len(l.Findings))
+	for _, f := range l.Findings {
+		stages = append(stages, f.Stage)
+	}
+	assert.Equal(t, []string{StageReviewFirst, StageExternal, StageReviewLoop}, stages)
+	assert.Equal(t, "def", l.Findings[2].Commit)
+	assertLogArg(t, log, "review findings: 2 fixed, 1 confirmed, 0 false positives, ledger "+path)
+
+	for _, format := range []findings.Format{findings.FormatSARIF, findings.FormatMarkdown} {
+		exported := findings.ExportFile(path, format)
+		assert.FileExists(t, exported)
+		assertLogArg(t, log, exported)
+	}
+	assert.NoFileExists(t, findings.ExportFile(path, findings.FormatJUnit))
+}
+
+func TestFindingsRecorder_Export(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feature.json")
+	log := newRunnerMockLogger("")
+	r := newFindingsRecorder(Config{FindingsPath: path, FindingsExport: []string{"junit", "pdf"}}, log)
+
+	r.export()
+	assert.NoFileExists(t, findings.ExportFile(path, findings.FormatJUnit), "nothing exported without review stages")
+
+	r.startStage(StageReviewFirst)
+	r.record(findingsOutput(`[{"file": "a.go", "line": 3, "verdict": "confirmed", "description": "bug"}]`), "claude")
+	r.export()
+	data, err := os.ReadFile(findings.ExportFile(path, findings.FormatJUnit)) //nolint:gosec // test file
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `failures="1"`)
+	var warnings []string
+	for _, call := range log.PrintCalls() {
+		if strings.HasPrefix(call.Format, "warning:") {
+			warnings = append(warnings, fmt.Sprintf(call.Format, call.Args...))
+		}
+	}
+	assert.Equal(t, []string{`warning: unknown findings format "pdf", expected one of sarif, junit, markdown, codequality`}, warnings)
+}
@@ -0,0 +1,229 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/plan"
+	"github.com/umputun/ralphex/pkg/processor/phase"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// DefaultHookTimeout bounds a single lifecycle hook run, used when hook_timeout is not set.
+const DefaultHookTimeout = 5 * time.Minute
+
+// lifecycle hook events, passed to hooks as the "event" field of the JSON context.
+const (
+	hookPrePhase   = "pre_phase"
+	hookPostPhase  = "post_phase"
+	hookPreTask    = "pre_task"
+	hookPostTask   = "post_task"
+	hookPreReview  = "pre_review"
+	hookOnFailure  = "on_failure"
+	hookOnComplete = "on_complete"
+)
+
+// hookContext is the JSON document passed to lifecycle hooks on stdin.
+type hookContext struct {
+	Event        string         `json:"event"`
+	Mode         Mode           `json:"mode"`
+	Phase        status.Phase   `json:"phase,omitempty"`
+	TaskNum      int            `json:"task_num,omitempty"`
+	TaskTitle    string         `json:"task_title,omitempty"`
+	PlanFile     string         `json:"plan_file,omitempty"`
+	Head         string         `json:"head,omitempty"`
+	ProgressPath string         `json:"progress_path,omitempty"`
+	DiffStats    *hookDiffStats `json:"diff_stats,omitempty"`
+	Error        string         `json:"error,omitempty"`
+}
+
+type hookDiffStats struct {
+	Files     int `json:"files"`
+	Additions int `json:"additions"`
+	Deletions int `json:"deletions"`
+}
+
+// diffStatsReporter is implemented by git checkers able to report branch diff stats, e.g. git.Service.
+type diffStatsReporter interface {
+	DiffStats(baseBranch string) (git.DiffStats, error)
+}
+
+// hookRunner runs the configured lifecycle hooks. task hooks are called by the task phase,
+// phase hooks are driven by phase holder transitions and run hooks by Runner.Run.
+type hookRunner struct {
+	cfg      Config
+	log      Logger
+	deps     *phase.Deps // late-bound git checker for HEAD and diff stats
+	locator  *planLocator
+	commands map[string]string // event -> shell command
+	timeout  time.Duration
+
+	mu      sync.Mutex
+	current status.Phase // phase started by the last pre_phase, empty once its post_phase fired
+}
+
+// newHookRunner returns nil when no hook is configured.
+func newHookRunner(cfg Config, log Logger, deps *phase.Deps, locator *planLocator) *hookRunner {
+	app := cfg.AppConfig
+	if app == nil {
+		return nil
+	}
+	commands := map[string]string{
+		hookPrePhase: app.HookPrePhase, hookPostPhase: app.HookPostPhase,
+		hookPreTask: app.HookPreTask, hookPostTask: app.HookPostTask, hookPreReview: app.HookPreReview,
+		hookOnFailure: app.HookOnFailure, hookOnComplete: app.HookOnComplete,
+	}
+	configured := false
+	for _, c := range commands {
+		configured = configured || c != ""
+	}
+	if !configured {
+		return nil
+	}
+	timeout := DefaultHookTimeout
+	if app.HookTimeout > 0 {
+		timeout = app.HookTimeout
+	}
+	return &hookRunner{cfg: cfg, log: log, deps: deps, locator: locator, commands: commands, timeout: timeout}
+}
+
+// PreTask runs the pre_task hook, implements phase.TaskHooks.
+func (h *hookRunner) PreTask(ctx context.Context, taskNum int) error {
+	return h.run(ctx, hookContext{Event: hookPreTask, Phase: status.PhaseTask, TaskNum: taskNum, TaskTitle: h.taskTitle(taskNum)})
+}
+
+// PostTask runs the post_task hook, implements phase.TaskHooks.
+func (h *hookRunner) PostTask(ctx context.Context, taskNum int) error {
+	return h.run(ctx, hookContext{Event: hookPostTask, Phase: status.PhaseTask, TaskNum: taskNum, TaskTitle: h.taskTitle(taskNum)})
+}
+
+// watchPhases fires phase hooks on holder transitions until the returned func is called.
+// transitions can't return errors, so a failing hook cancels the run through fail instead.
+func (h *hookRunner) watchPhases(ctx context.Context, holder *status.PhaseHolder, fail context.CancelCauseFunc) func() {
+	return holder.OnChange(func(_, cur status.Phase) {
+		if ctx.Err() != nil {
+			return // run is already stopping
+		}
+		if err := h.phaseChanged(ctx, cur); err != nil {
+			fail(err)
+		}
+	})
+}
+
+// phaseChanged ends the previous phase and starts cur: post_phase, pre_phase and, for review, pre_review.
+func (h *hookRunner) phaseChanged(ctx context.Context, cur status.Phase) error {
+	if err := h.endPhase(ctx); err != nil {
+		return err
+	}
+	if cur == "" {
+		return nil
+	}
+	h.mu.Lock()
+	h.current = cur
+	h.mu.Unlock()
+	if err := h.run(ctx, hookContext{Event: hookPrePhase, Phase: cur}); err != nil {
+		return err
+	}
+	if cur == status.PhaseReview {
+		return h.run(ctx, hookContext{Event: hookPreReview, Phase: cur})
+	}
+	return nil
+}
+
+// endPhase runs post_phase for the phase started last, at most once per phase.
+func (h *hookRunner) endPhase(ctx context.Context) error {
+	h.mu.Lock()
+	ph := h.current
+	h.current = ""
+	h.mu.Unlock()
+	if ph == "" {
+		return nil
+	}
+	return h.run(ctx, hookContext{Event: hookPostPhase, Phase: ph})
+}
+
+// finish runs the closing hooks of a run: post_phase for the last phase and on_complete on
+// success, on_failure otherwise. returns the error the run ends with, which is runErr unless
+// a closing hook failed. hooks run even when ctx is canceled, bounded by the hook timeout.
+func (h *hookRunner) finish(ctx context.Context, ph status.Phase, runErr error) error {
+	ctx = context.WithoutCancel(ctx)
+	if runErr == nil {
+		if runErr = h.endPhase(ctx); runErr == nil {
+			runErr = h.run(ctx, hookContext{Event: hookOnComplete, Phase: ph})
+		}
+		if runErr == nil {
+			return nil
+		}
+	}
+	h.mu.Lock()
+	h.current = ""
+	h.mu.Unlock()
+	// the run has already failed, so a failing on_failure hook is only logged by run
+	_ = h.run(ctx, hookContext{Event: hookOnFailure, Phase: ph, Error: runErr.Error()})
+	return runErr
+}
+
+// run executes the hook for hc.Event with the completed context on stdin.
+// returns an error wrapping ErrHookFailed when the hook exits non-zero, nil when no hook is set.
+func (h *hookRunner) run(ctx context.Context, hc hookContext) error {
+	command := h.commands[hc.Event]
+	if command == "" {
+		return nil
+	}
+	hc.Mode, hc.PlanFile, hc.ProgressPath = h.cfg.Mode, h.locator.Path(), h.cfg.ProgressPath
+	if hc.ProgressPath == "" {
+		hc.ProgressPath = h.log.Path()
+	}
+	hc.Head, hc.DiffStats = h.gitState()
+	data, err := json.Marshal(hc)
+	if err != nil {
+		return fmt.Errorf("marshal hook context: %w", err)
+	}
+
+	res := executor.RunShellCommandWithInput(ctx, command, data, h.timeout)
+	elapsed := res.Duration.Round(time.Millisecond)
+	if output := strings.TrimRight(res.Output, "\n"); output != "" {
+		h.log.PrintRaw("%s\n", output)
+	}
+	if res.Error != nil {
+		h.log.Print("hook %s FAILED: %s (%s): %v", hc.Event, command, elapsed, res.Error)
+		return fmt.Errorf("%w: %s: %w", ErrHookFailed, hc.Event, res.Error)
+	}
+	h.log.Print("hook %s passed: %s (%s)", hc.Event, command, elapsed)
+	return nil
+}
+
+// gitState returns HEAD and diff stats against the default branch, empty when unavailable.
+func (h *hookRunner) gitState() (head string, stats *hookDiffStats) {
+	if h.deps == nil || h.deps.Git == nil {
+		return "", nil
+	}
+	if hash, err := h.deps.Git.HeadHash(); err == nil {
+		head = hash
+	}
+	if ds, ok := h.deps.Git.(diffStatsReporter); ok && h.cfg.DefaultBranch != "" {
+		if st, err := ds.DiffStats(h.cfg.DefaultBranch); err == nil {
+			stats = &hookDiffStats{Files: st.Files, Additions: st.Additions, Deletions: st.Deletions}
+		}
+	}
+	return head, stats
+}
+
+// taskTitle returns the title of the 1-indexed plan task, empty when unknown.
+func (h *hookRunner) taskTitle(taskNum int) string {
+	path := h.locator.Path()
+	if path == "" {
+		return ""
+	}
+	parsed, err := plan.ParsePlanFile(path)
+	if err != nil || taskNum < 1 || taskNum > len(parsed.Tasks) {
+		return ""
+	}
+	return parsed.Tasks[taskNum-1].Title
+}
@@ -0,0 +1,164 @@
+//go:build unix
+
+package processor
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/git"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// hookRecorder returns a hook command appending its stdin as one line to the events file.
+func hookRecorder(events string) string {
+	return "cat >> " + events + "; echo >> " + events
+}
+
+func readHookEvents(t *testing.T, path string) []hookContext {
+	t.Helper()
+	f, err := os.Open(path) //nolint:gosec // test file in temp dir
+	require.NoError(t, err)
+	defer f.Close()
+	var res []hookContext
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var hc hookContext
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &hc))
+		res = append(res, hc)
+	}
+	require.NoError(t, sc.Err())
+	return res
+}
+
+func hookEventNames(events []hookContext) []string {
+	names := make([]string, 0, len(events))
+	for _, e := range events {
+		names = append(names, e.Event+":"+string(e.Phase))
+	}
+	return names
+}
+
+type hookGitChecker struct{}
+
+func (hookGitChecker) HeadHash() (string, error)        { return "abc123", nil }
+func (hookGitChecker) DiffFingerprint() (string, error) { return "", nil }
+func (hookGitChecker) DiffStats(string) (git.DiffStats, error) {
+	return git.DiffStats{Files: 2, Additions: 10, Deletions: 3}, nil
+}
+
+func writeHookPlan(t *testing.T) string {
+	t.Helper()
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n### Task 1: add widget\n- [x] done"), 0o600))
+	return planFile
+}
+
+func TestNewHookRunner_NilWithoutHooks(t *testing.T) {
+	assert.Nil(t, newHookRunner(Config{AppConfig: testAppConfig(t)}, newMockLogger(), nil, nil))
+	assert.Nil(t, newHookRunner(Config{}, newMockLogger(), nil, nil))
+}
+
+func TestRunner_Hooks_Lifecycle(t *testing.T) {
+	events := filepath.Join(t.TempDir(), "events")
+	appCfg := testAppConfig(t)
+	rec := hookRecorder(events)
+	appCfg.HookPrePhase, appCfg.HookPostPhase, appCfg.HookPreTask, appCfg.HookPostTask = rec, rec, rec, rec
+	appCfg.HookOnComplete, appCfg.HookOnFailure = rec, rec
+
+	claude := newMockExecutor([]executor.Result{{Output: "done", Signal: status.Completed}})
+	cfg := Config{Mode: ModeTasksOnly, PlanFile: writeHookPlan(t), ProgressPath: "progress.txt", MaxIterations: 10,
+		IterationDelayMs: 1, DefaultBranch: "master", AppConfig: appCfg}
+	r := NewWithExecutors(cfg, newRunnerMockLogger("progress.txt"), Executors{Task: claude}, &status.PhaseHolder{})
+	r.SetGitChecker(hookGitChecker{})
+
+	require.NoError(t, r.Run(t.Context()))
+
+	got := readHookEvents(t, events)
+	assert.Equal(t, []string{"pre_phase:task", "pre_task:task", "post_task:task", "post_phase:task", "on_complete:task"},
+		hookEventNames(got))
+	preTask := got[1]
+	assert.Equal(t, ModeTasksOnly, preTask.Mode)
+	assert.Equal(t, 1, preTask.TaskNum)
+	assert.Equal(t, "add widget", preTask.TaskTitle)
+	assert.Equal(t, cfg.PlanFile, preTask.PlanFile)
+	assert.Equal(t, "abc123", preTask.Head)
+	assert.Equal(t, "progress.txt", preTask.ProgressPath)
+	require.NotNil(t, preTask.DiffStats)
+	assert.Equal(t, hookDiffStats{Files: 2, Additions: 10, Deletions: 3}, *preTask.DiffStats)
+}
+
+func TestRunner_Hooks_PreTaskVeto(t *testing.T) {
+	events := filepath.Join(t.TempDir(), "events")
+	appCfg := testAppConfig(t)
+	appCfg.HookPreTask = "echo license check failed; exit 1"
+	appCfg.HookOnFailure = hookRecorder(events)
+
+	claude := newMockExecutor(nil)
+	log := newRunnerMockLogger("progress.txt")
+	cfg := Config{Mode: ModeTasksOnly, PlanFile: writeHookPlan(t), MaxIterations: 10, IterationDelayMs: 1, AppConfig: appCfg}
+	r := NewWithExecutors(cfg, log, Executors{Task: claude}, &status.PhaseHolder{})
+
+	err := r.Run(t.Context())
+	require.ErrorIs(t, err, ErrHookFailed)
+	assert.Contains(t, err.Error(), "pre_task")
+	assert.Empty(t, claude.RunCalls(), "vetoed task must not run")
+	assertLogContains(t, log, "hook %s FAILED")
+
+	got := readHookEvents(t, events)
+	require.Len(t, got, 1)
+	assert.Equal(t, hookOnFailure, got[0].Event)
+	assert.Contains(t, got[0].Error, "pre_task")
+}
+
+func TestRunner_Hooks_PreReviewFailureStopsRun(t *testing.T) {
+	appCfg := testAppConfig(t)
+	appCfg.HookPreReview = "exit 3"
+
+	claude := newMockExecutor([]executor.Result{{Output: "review done", Signal: status.ReviewDone}})
+	cfg := Config{Mode: ModeReview, MaxIterations: 50, IterationDelayMs: 1, AppConfig: appCfg}
+	r := NewWithExecutors(cfg, newMockLogger(), Executors{Task: claude}, &status.PhaseHolder{})
+
+	err := r.Run(t.Context())
+	require.ErrorIs(t, err, ErrHookFailed)
+	assert.Contains(t, err.Error(), "pre_review")
+	assert.Contains(t, err.Error(), "exit code 3")
+}
+
+func TestRunner_Hooks_OnCompleteFailureFailsRun(t *testing.T) {
+	events := filepath.Join(t.TempDir(), "events")
+	appCfg := testAppConfig(t)
+	appCfg.HookOnComplete = "exit 1"
+	appCfg.HookOnFailure = hookRecorder(events)
+
+	claude := newMockExecutor([]executor.Result{{Output: "done", Signal: status.Completed}})
+	cfg := Config{Mode: ModeTasksOnly, PlanFile: writeHookPlan(t), MaxIterations: 10, IterationDelayMs: 1, AppConfig: appCfg}
+	r := NewWithExecutors(cfg, newMockLogger(), Executors{Task: claude}, &status.PhaseHolder{})
+
+	err := r.Run(t.Context())
+	require.ErrorIs(t, err, ErrHookFailed)
+	assert.Contains(t, err.Error(), "on_complete")
+	assert.Equal(t, []string{"on_failure:task"}, hookEventNames(readHookEvents(t, events)))
+}
+
+func TestRunner_Hooks_PhaseWatchRemovedAfterRun(t *testing.T) {
+	events := filepath.Join(t.TempDir(), "events")
+	appCfg := testAppConfig(t)
+	appCfg.HookPrePhase = hookRecorder(events)
+
+	holder := &status.PhaseHolder{}
+	claude := newMockExecutor([]executor.Result{{Output: "done", Signal: status.Completed}})
+	cfg := Config{Mode: ModeTasksOnly, PlanFile: writeHookPlan(t), MaxIterations: 10, IterationDelayMs: 1, AppConfig: appCfg}
+	r := NewWithExecutors(cfg, newMockLogger(), Executors{Task: claude}, holder)
+	require.NoError(t, r.Run(t.Context()))
+
+	holder.Set(status.PhaseReview)
+	assert.Equal(t, []string{"pre_phase:task"}, hookEventNames(readHookEvents(t, events)))
+}
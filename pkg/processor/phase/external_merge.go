@@ -0,0 +1,76 @@
+package phase
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// toolReview is the review session result of one external review tool.
+type toolReview struct {
+	tool   string
+	result ExecutionResult
+}
+
+var (
+	// findingLocationRe matches a file:line reference, which tells finding lines apart from prose and formatting.
+	findingLocationRe = regexp.MustCompile(`[\w./-]+\.\w+:\d+`)
+	// listMarkerRe matches a leading list marker, "-", "*" or "1.", ignored when comparing descriptions.
+	listMarkerRe = regexp.MustCompile(`^\s*(?:[-*•]|\d+[.)])\s+`)
+)
+
+// mergeReviewOutputs combines the outputs of several external review tools into one findings block,
+// each output under a "=== tool ===" header. a finding at a file:line already reported by another tool
+// is dropped, however it is worded: the first occurrence keeps the longer of the descriptions and names
+// the tools that reported it too. a single output is returned unchanged.
+func mergeReviewOutputs(reviews []toolReview) string {
+	switch len(reviews) {
+	case 0:
+		return ""
+	case 1:
+		return reviews[0].result.Result.Output
+	}
+
+	type firstSeen struct {
+		line int
+		tool string
+	}
+	var lines []string
+	seen := map[string]firstSeen{}
+	also := map[int][]string{}
+	for _, review := range reviews {
+		lines = append(lines, "=== "+review.tool+" ===")
+		for line := range strings.SplitSeq(strings.TrimRight(review.result.Result.Output, "\n"), "\n") {
+			key := findingKey(line)
+			if first, ok := seen[key]; ok && key != "" && first.tool != review.tool {
+				if len(findingDescription(line)) > len(findingDescription(lines[first.line])) {
+					lines[first.line] = line
+				}
+				if !slices.Contains(also[first.line], review.tool) {
+					also[first.line] = append(also[first.line], review.tool)
+				}
+				continue
+			}
+			if _, ok := seen[key]; !ok && key != "" {
+				seen[key] = firstSeen{line: len(lines), tool: review.tool}
+			}
+			lines = append(lines, line)
+		}
+		lines = append(lines, "")
+	}
+	for idx, tools := range also {
+		lines[idx] += " (also reported by " + strings.Join(tools, ", ") + ")"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// findingKey returns the file:line reference of a finding line for duplicate detection, lowercased.
+// returns empty for lines without a file:line reference, which are never merged.
+func findingKey(line string) string {
+	return strings.ToLower(findingLocationRe.FindString(line))
+}
+
+// findingDescription returns a finding line without its list marker and surrounding spaces.
+func findingDescription(line string) string {
+	return strings.TrimSpace(listMarkerRe.ReplaceAllString(line, ""))
+}
@@ -0,0 +1,40 @@
+package phase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/umputun/ralphex/pkg/executor"
+)
+
+func TestMergeReviewOutputs(t *testing.T) {
+	review := func(tool, output string) toolReview {
+		return toolReview{tool: tool, result: ExecutionResult{Result: executor.Result{Output: output}}}
+	}
+
+	assert.Empty(t, mergeReviewOutputs(nil))
+	assert.Equal(t, "single\n", mergeReviewOutputs([]toolReview{review("codex", "single\n")}), "single output unchanged")
+
+	got := mergeReviewOutputs([]toolReview{
+		review("codex", "Summary\n- pkg/a.go:3 nil map write\n1. b.go:9 unchecked  error\n"),
+		review("custom:gemini", "Summary\n* PKG/a.go:3 nil map write\n- c.go:1 missing test\n"),
+		review("custom:opencode", "2) b.go:9 unchecked error\n- pkg/a.go:3 nil map write\n- c.go:1 missing test"),
+	})
+	want := "=== codex ===\nSummary\n- pkg/a.go:3 nil map write (also reported by custom:gemini, custom:opencode)\n" +
+		"1. b.go:9 unchecked  error (also reported by custom:opencode)\n\n" +
+		"=== custom:gemini ===\nSummary\n- c.go:1 missing test (also reported by custom:opencode)\n\n" +
+		"=== custom:opencode ===\n"
+	assert.Equal(t, want, got, "duplicate findings merged, prose kept")
+
+	got = mergeReviewOutputs([]toolReview{
+		review("codex", "- pkg/a.go:10 nil pointer dereference\n- b.go:4 typo in comment\n"),
+		review("custom:gemini", "- pkg/a.go:10: possible nil pointer dereference when cfg is nil\n- b.go:4 typo\n"),
+	})
+	want = "=== codex ===\n- pkg/a.go:10: possible nil pointer dereference when cfg is nil (also reported by custom:gemini)\n" +
+		"- b.go:4 typo in comment (also reported by custom:gemini)\n\n=== custom:gemini ===\n"
+	assert.Equal(t, want, got, "differently worded findings at one location merged, longer description kept")
+
+	got = mergeReviewOutputs([]toolReview{review("codex", "a.go:1 x\na.go:1 x"), review("custom", "b.go:2 y")})
+	assert.Equal(t, "=== codex ===\na.go:1 x\na.go:1 x\n\n=== custom ===\nb.go:2 y\n", got, "repeats within one tool are kept")
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/umputun/ralphex/pkg/config"
 	"github.com/umputun/ralphex/pkg/executor"
 	"github.com/umputun/ralphex/pkg/status"
 )
@@ -16,18 +19,21 @@ type ExternalReviewOutcome struct {
 	HadFindings bool
 }
 
-// ExternalReviewPhase runs codex or custom external review loops.
+// ExternalReviewPhase runs the external review loop of one or several tools (codex, http, custom scripts).
 type ExternalReviewPhase struct {
 	cfg            Config
 	log            ExternalReviewLogger
 	external       Executor
 	custom         *executor.CustomExecutor
+	customs        map[string]*executor.CustomExecutor
+	http           *executor.HTTPExecutor
 	review         Executor
 	policy         Policy
 	prompts        ExternalReviewPrompts
 	breaks         *BreakController
 	git            *GitState
 	phaseHolder    *status.PhaseHolder
+	budget         Budget
 	iterationDelay time.Duration
 }
 
@@ -37,26 +43,50 @@ type ExternalReviewPhaseOpts struct {
 	Log            ExternalReviewLogger
 	External       Executor
 	Custom         *executor.CustomExecutor
+	Customs        map[string]*executor.CustomExecutor // custom:<name> tools, keyed by name
+	HTTP           *executor.HTTPExecutor
 	Review         Executor
 	Policy         Policy
 	Prompts        ExternalReviewPrompts
 	Breaks         *BreakController
 	Git            *GitState
 	PhaseHolder    *status.PhaseHolder
+	Budget         Budget // stops the loop before the next iteration once a limit is crossed; nil disables it
 	IterationDelay time.Duration
 }
 
 // NewExternalReviewPhase creates an external review phase engine.
 func NewExternalReviewPhase(opts ExternalReviewPhaseOpts) *ExternalReviewPhase {
 	return &ExternalReviewPhase{
-		cfg: opts.Cfg, log: opts.Log, external: opts.External, custom: opts.Custom,
-		review: opts.Review, policy: opts.Policy, prompts: opts.Prompts, breaks: opts.Breaks,
-		git: opts.Git, phaseHolder: opts.PhaseHolder, iterationDelay: opts.IterationDelay,
+		cfg: opts.Cfg, log: opts.Log, external: opts.External, custom: opts.Custom, customs: opts.Customs,
+		http: opts.HTTP, review: opts.Review, policy: opts.Policy, prompts: opts.Prompts, breaks: opts.Breaks,
+		git: opts.Git, phaseHolder: opts.PhaseHolder, budget: opts.Budget, iterationDelay: opts.IterationDelay,
 	}
 }
 
-// Tool returns the effective external review tool after config and back-compat rules.
+// Tool returns the effective external review tools joined with "+", or none when external review is disabled.
 func (p *ExternalReviewPhase) Tool() string {
+	tools := p.Tools()
+	if len(tools) == 0 {
+		return "none"
+	}
+	return strings.Join(tools, "+")
+}
+
+// Tools returns the effective external review tools after config and back-compat rules,
+// empty when external review is disabled. several tools run in parallel in each iteration.
+func (p *ExternalReviewPhase) Tools() []string {
+	var tools []string
+	for tool := range strings.SplitSeq(p.configuredTool(), ",") {
+		if tool = strings.TrimSpace(tool); tool != "" && tool != "none" {
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
+
+// configuredTool returns the external_review_tool value in effect, codex_enabled = false meaning none.
+func (p *ExternalReviewPhase) configuredTool() string {
 	if p.cfg.ExternalReviewToolSet && p.cfg.AppConfig != nil && p.cfg.AppConfig.ExternalReviewTool != "" {
 		return p.cfg.AppConfig.ExternalReviewTool
 	}
@@ -71,29 +101,65 @@ func (p *ExternalReviewPhase) Tool() string {
 
 // Run executes the configured external review loop and reports whether fixes need post-review.
 func (p *ExternalReviewPhase) Run(ctx context.Context) (ExternalReviewOutcome, error) {
-	switch p.Tool() {
-	case "none":
+	tools := p.Tools()
+	if len(tools) == 0 {
 		p.log.Print("external review disabled, skipping...")
 		return ExternalReviewOutcome{}, nil
-	case "custom":
-		return p.runCustom(ctx)
-	default:
-		return p.runCodex(ctx)
 	}
+	if err := p.checkTools(tools); err != nil {
+		return ExternalReviewOutcome{}, err
+	}
+	return p.runLoop(ctx, tools)
 }
 
-func (p *ExternalReviewPhase) runCodex(ctx context.Context) (ExternalReviewOutcome, error) {
-	if p.external == nil {
-		return ExternalReviewOutcome{}, errors.New("codex review executor not configured")
+// Collect runs a single external review, without the evaluation session that fixes findings,
+// and returns the merged tool output. returns empty output when external review is disabled or timed out.
+func (p *ExternalReviewPhase) Collect(ctx context.Context) (string, error) {
+	tools := p.Tools()
+	if len(tools) == 0 {
+		return "", nil
+	}
+	if err := p.checkTools(tools); err != nil {
+		return "", err
+	}
+	if p.phaseHolder != nil {
+		p.phaseHolder.Set(status.PhaseCodex)
+	}
+	p.log.PrintSection(p.section(tools, 1))
+
+	var reviews []toolReview
+	for _, review := range p.runReviewTools(ctx, tools, reviewRound{iteration: 1, isFirst: true}) {
+		if err := wrapExecutorError(p.policy, review.result.Result.Error, review.tool); err != nil {
+			return "", err
+		}
+		if review.result.TimedOut {
+			p.log.Print("%s review session timed out, reporting without its findings", review.tool)
+			continue
+		}
+		if review.tool == "codex" {
+			p.showSummary(review.tool, review.result.Result.Output)
+		}
+		reviews = append(reviews, review)
 	}
-	return p.runLoop(ctx, "codex")
+	return mergeReviewOutputs(reviews), nil
 }
 
-func (p *ExternalReviewPhase) runCustom(ctx context.Context) (ExternalReviewOutcome, error) {
-	if p.custom == nil {
-		return ExternalReviewOutcome{}, errors.New("custom review script not configured")
+// checkTools verifies every tool has an executor before the review starts.
+func (p *ExternalReviewPhase) checkTools(tools []string) error {
+	for _, tool := range tools {
+		name, named := strings.CutPrefix(tool, config.CustomToolPrefix)
+		switch {
+		case named && p.customs[name] == nil:
+			return fmt.Errorf("custom review script %q not configured", name)
+		case tool == "custom" && p.custom == nil:
+			return errors.New("custom review script not configured")
+		case tool == "http" && p.http == nil:
+			return errors.New("http review executor not configured, set http_review_base_url and http_review_model")
+		case tool == "codex" && p.external == nil:
+			return errors.New("codex review executor not configured")
+		}
 	}
-	return p.runLoop(ctx, "custom")
+	return nil
 }
 
 func (p *ExternalReviewPhase) showSummary(toolName, output string) {
@@ -119,7 +185,7 @@ func (p *ExternalReviewPhase) showSummary(toolName, output string) {
 	}
 }
 
-func (p *ExternalReviewPhase) runLoop(ctx context.Context, tool string) (ExternalReviewOutcome, error) {
+func (p *ExternalReviewPhase) runLoop(ctx context.Context, tools []string) (ExternalReviewOutcome, error) {
 	outcome := ExternalReviewOutcome{}
 	loopCtx, loopCancel := p.breaks.context(ctx)
 	defer loopCancel()
@@ -130,9 +196,14 @@ func (p *ExternalReviewPhase) runLoop(ctx context.Context, tool string) (Externa
 
 loop:
 	for i := 1; i <= p.maxIterations(); i++ {
+		if p.budget != nil {
+			if err := p.budget.Exceeded(); err != nil {
+				return outcome, fmt.Errorf("external review: %w", err)
+			}
+		}
 		result, err := p.runIteration(loopCtx, externalReviewIterationOpts{
 			parent:         ctx,
-			tool:           tool,
+			tools:          tools,
 			iteration:      i,
 			firstCompleted: firstCompleted,
 			claudeResponse: claudeResponse,
@@ -174,7 +245,7 @@ loop:
 		}
 	}
 
-	p.log.Print("max %s iterations reached, continuing to next phase...", tool)
+	p.log.Print("max %s iterations reached, continuing to next phase...", strings.Join(tools, "+"))
 	return outcome, nil
 }
 
@@ -189,7 +260,7 @@ const (
 
 type externalReviewIterationOpts struct {
 	parent         context.Context
-	tool           string
+	tools          []string
 	iteration      int
 	firstCompleted bool
 	claudeResponse string
@@ -204,51 +275,77 @@ type externalReviewIterationResult struct {
 }
 
 func (p *ExternalReviewPhase) runIteration(ctx context.Context, opts externalReviewIterationOpts) (externalReviewIterationResult, error) {
-	if err := p.checkLoopDone(ctx, opts.parent, opts.tool); err != nil {
+	label := strings.Join(opts.tools, "+")
+	if err := p.checkLoopDone(ctx, opts.parent, label); err != nil {
 		return externalReviewIterationResult{}, err
 	}
 
-	p.log.PrintSection(p.section(opts.tool, opts.iteration))
+	p.log.PrintSection(p.section(opts.tools, opts.iteration))
 
-	reviewExecResult := p.runReviewTool(ctx, opts.tool, p.reviewPrompt(opts.tool, !opts.firstCompleted, opts.claudeResponse))
-	reviewResult := reviewExecResult.Result
-	if reviewResult.Error != nil {
-		if err := p.handleExecutorError(ctx, opts.parent, opts.tool, reviewResult.Error); err != nil {
-			return externalReviewIterationResult{}, err
+	round := reviewRound{iteration: opts.iteration, isFirst: !opts.firstCompleted, claudeResponse: opts.claudeResponse}
+	var reviews []toolReview
+	var timedOut []string
+	var failed []error
+	for _, review := range p.runReviewTools(ctx, opts.tools, round) {
+		if review.result.Result.Error != nil {
+			// handleExecutorError always returns non-nil for a non-nil error. a failed tool of several
+			// is left out like a timed-out one, the iteration fails only when every tool failed
+			err := p.handleExecutorError(ctx, opts.parent, review.tool, review.result.Result.Error)
+			if len(opts.tools) == 1 || errors.Is(err, errExternalReviewBreak) {
+				return externalReviewIterationResult{}, err
+			}
+			failed = append(failed, err)
+			continue
+		}
+		switch {
+		case review.result.TimedOut:
+			timedOut = append(timedOut, review.tool)
+		case review.result.Result.Output == "":
+			p.log.Print("%s review returned no output, skipping...", review.tool)
+		default:
+			reviews = append(reviews, review)
 		}
-		// handleExecutorError always returns non-nil for a non-nil error, so this
-		// fall-through is intentionally unreachable; kept defensive against future changes.
 	}
 
-	if reviewExecResult.TimedOut {
-		p.log.Print("%s review session timed out, retrying on next iteration...", opts.tool)
-		return externalReviewIterationResult{action: externalReviewRetry}, nil
+	if len(failed) == len(opts.tools) {
+		return externalReviewIterationResult{}, errors.Join(failed...)
+	}
+	for _, err := range failed {
+		p.log.Print("review failed: %v, evaluating the other reviews without it", err)
 	}
 
-	if reviewResult.Output == "" {
-		p.log.Print("%s review returned no output, skipping...", opts.tool)
+	if len(reviews) == 0 && len(timedOut) > 0 {
+		p.log.Print("%s review session timed out, retrying on next iteration...", strings.Join(timedOut, "+"))
+		return externalReviewIterationResult{action: externalReviewRetry}, nil
+	}
+	if len(reviews) == 0 {
 		return externalReviewIterationResult{action: externalReviewBreakLoop}, nil
 	}
+	for _, tool := range timedOut {
+		p.log.Print("%s review session timed out, evaluating the other reviews without it...", tool)
+	}
 
-	if opts.tool == "codex" {
-		p.showSummary(opts.tool, reviewResult.Output)
+	for _, review := range reviews {
+		if review.tool == "codex" {
+			p.showSummary(review.tool, review.result.Result.Output)
+		}
 	}
 
 	before := p.snapshotBeforeEval()
-	claudeExecResult, err := p.runClaudeEvaluation(ctx, opts.parent, opts.tool, reviewResult.Output)
+	claudeExecResult, err := p.runClaudeEvaluation(ctx, opts.parent, reviews)
 	if err != nil {
 		return externalReviewIterationResult{}, err
 	}
 
 	if claudeExecResult.TimedOut {
-		p.log.Print("claude eval session timed out, retrying %s iteration...", opts.tool)
+		p.log.Print("claude eval session timed out, retrying %s iteration...", label)
 		return externalReviewIterationResult{action: externalReviewRetry}, nil
 	}
 
 	claudeResult := claudeExecResult.Result
 	result := externalReviewIterationResult{before: before, claudeResponse: claudeResult.Output, firstCompleted: true}
 	if IsCodexDone(claudeResult.Signal) {
-		p.log.Print("%s review complete - no more findings", opts.tool)
+		p.log.Print("%s review complete - no more findings", label)
 		result.action = externalReviewStop
 		return result, nil
 	}
@@ -290,12 +387,12 @@ func (p *ExternalReviewPhase) snapshotBeforeEval() gitSnapshot {
 	return p.git.snapshot()
 }
 
-func (p *ExternalReviewPhase) runClaudeEvaluation(loopCtx, parent context.Context, tool, output string) (ExecutionResult, error) {
+func (p *ExternalReviewPhase) runClaudeEvaluation(loopCtx, parent context.Context, reviews []toolReview) (ExecutionResult, error) {
 	if p.phaseHolder != nil {
 		p.phaseHolder.Set(status.PhaseClaudeEval)
 	}
 	p.log.PrintSection(status.NewClaudeEvalSection())
-	result := p.policy.Run(loopCtx, p.review.Run, p.evalPrompt(tool, output), "claude")
+	result := p.policy.Run(loopCtx, p.review.Run, p.evalPrompt(reviews), "claude")
 	if p.phaseHolder != nil {
 		p.phaseHolder.Set(status.PhaseCodex)
 	}
@@ -328,30 +425,90 @@ func (p *ExternalReviewPhase) maxIterations() int {
 	return maxIterations
 }
 
-func (p *ExternalReviewPhase) runReviewTool(ctx context.Context, tool, prompt string) ExecutionResult {
-	if tool == "custom" {
-		return p.policy.Run(ctx, p.custom.Run, prompt, tool)
+// reviewRound describes the external review iteration a review session belongs to.
+type reviewRound struct {
+	iteration      int
+	isFirst        bool
+	claudeResponse string
+	request        executor.ReviewRequest // JSON request of custom review scripts, its base ref is diffed by the http tool
+}
+
+// runReviewTools runs a review session of every tool, concurrently when there are several,
+// and returns the results in the order of tools.
+func (p *ExternalReviewPhase) runReviewTools(ctx context.Context, tools []string, round reviewRound) []toolReview {
+	if slices.ContainsFunc(tools, func(tool string) bool { return tool != "codex" }) {
+		round.request = p.prompts.CustomReviewRequest(round.isFirst, round.claudeResponse)
+		round.request.Iteration = round.iteration
+		round.request.ChangedFiles = p.git.changedFiles(round.request.BaseRef)
+	}
+	reviews := make([]toolReview, len(tools))
+	if len(tools) == 1 {
+		reviews[0] = toolReview{tool: tools[0], result: p.runReviewTool(ctx, tools[0], round)}
+		return reviews
+	}
+	var wg sync.WaitGroup
+	for i, tool := range tools {
+		wg.Go(func() {
+			reviews[i] = toolReview{tool: tool, result: p.runReviewTool(ctx, tool, round)}
+		})
+	}
+	wg.Wait()
+	return reviews
+}
+
+func (p *ExternalReviewPhase) runReviewTool(ctx context.Context, tool string, round reviewRound) ExecutionResult {
+	prompt := p.reviewPrompt(tool, round.isFirst, round.claudeResponse)
+	switch tool {
+	case "codex":
+		return p.policy.Run(ctx, p.external.Run, prompt, tool)
+	case "http":
+		diff, err := p.git.branchDiff(round.request.BaseRef)
+		if err != nil {
+			return ExecutionResult{Result: executor.Result{Error: err}}
+		}
+		run := func(ctx context.Context, prompt string) executor.Result { return p.http.Run(ctx, prompt, diff) }
+		return p.policy.Run(ctx, run, prompt, tool)
+	}
+	custom := p.custom
+	if name, ok := strings.CutPrefix(tool, config.CustomToolPrefix); ok {
+		custom = p.customs[name]
+	}
+	run := func(ctx context.Context, prompt string) executor.Result {
+		return custom.RunRequest(ctx, prompt, round.request)
 	}
-	return p.policy.Run(ctx, p.external.Run, prompt, tool)
+	return p.policy.Run(ctx, run, prompt, tool)
 }
 
+// reviewPrompt returns the review prompt of the tool: codex has its own, the http tool and custom scripts
+// share the custom review prompt.
 func (p *ExternalReviewPhase) reviewPrompt(tool string, isFirst bool, claudeResponse string) string {
-	if tool == "custom" {
+	if tool != "codex" {
 		return p.prompts.CustomReviewPrompt(isFirst, claudeResponse)
 	}
 	return p.prompts.CodexReviewPrompt(isFirst, claudeResponse)
 }
 
-func (p *ExternalReviewPhase) evalPrompt(tool, output string) string {
-	if tool == "custom" {
-		return p.prompts.CustomEvaluationPrompt(output)
+// evalPrompt returns the evaluation prompt of the merged findings, the codex one when codex reported.
+func (p *ExternalReviewPhase) evalPrompt(reviews []toolReview) string {
+	output := mergeReviewOutputs(reviews)
+	if slices.ContainsFunc(reviews, func(r toolReview) bool { return r.tool == "codex" }) {
+		return p.prompts.CodexEvaluationPrompt(output)
 	}
-	return p.prompts.CodexEvaluationPrompt(output)
+	return p.prompts.CustomEvaluationPrompt(output)
 }
 
-func (p *ExternalReviewPhase) section(tool string, iteration int) status.Section {
-	if tool == "custom" {
+// section returns the iteration header of a review round, named after the tool set when several tools run.
+func (p *ExternalReviewPhase) section(tools []string, iteration int) status.Section {
+	if len(tools) > 1 {
+		return status.NewExternalIterationSection(strings.Join(tools, "+"), iteration)
+	}
+	if tools[0] != "codex" {
 		return status.NewCustomIterationSection(iteration)
 	}
 	return status.NewCodexIterationSection(iteration)
 }
+
+// isCustomTool reports whether the tool is a custom review script, custom or custom:<name>.
+func isCustomTool(tool string) bool {
+	return tool == "custom" || strings.HasPrefix(tool, config.CustomToolPrefix)
+}
@@ -2,10 +2,15 @@ package phase
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -19,6 +24,8 @@ type externalReviewPhaseTestOpts struct {
 	review   Executor
 	external Executor
 	custom   *executor.CustomExecutor
+	customs  map[string]*executor.CustomExecutor
+	http     *executor.HTTPExecutor
 	log      *mockLogger
 }
 
@@ -36,7 +43,7 @@ func externalReviewPhaseFromRunner(t *testing.T, opts externalReviewPhaseTestOpt
 	if opts.external == nil {
 		opts.external = newTaskPhaseMockExecutor(nil)
 	}
-	r := newTestRunner(testRunnerOpts{cfg: opts.cfg, log: opts.log, execs: Executors{Task: opts.review, External: opts.external, Custom: opts.custom}, holder: &status.PhaseHolder{}})
+	r := newTestRunner(testRunnerOpts{cfg: opts.cfg, log: opts.log, execs: Executors{Task: opts.review, External: opts.external, Custom: opts.custom, Customs: opts.customs, HTTP: opts.http}, holder: &status.PhaseHolder{}})
 	phase, ok := r.phases.external.(*externalReviewPhase)
 	require.True(t, ok)
 	return phase, opts.log
@@ -52,6 +59,7 @@ func TestExternalReviewPhaseTool(t *testing.T) {
 		{name: "disabled backward compat", cfg: Config{CodexEnabled: false, AppConfig: testAppConfig(t)}, want: "none"},
 		{name: "explicit override", cfg: explicitExternalToolConfig(t, "codex", false), want: "codex"},
 		{name: "configured none", cfg: configuredExternalToolConfig(t, "none"), want: "none"},
+		{name: "several tools", cfg: configuredExternalToolConfig(t, "codex, custom:gemini"), want: "codex+custom:gemini"},
 	}
 
 	for _, tc := range tests {
@@ -243,7 +251,7 @@ type mockCustomRunnerImpl struct {
 	idx     int
 }
 
-func (m *mockCustomRunnerImpl) Run(_ context.Context, _, _ string) (io.Reader, func() error, error) {
+func (m *mockCustomRunnerImpl) Run(_ context.Context, _, _ string, _ io.Reader) (io.Reader, func() error, error) {
 	if m.idx >= len(m.results) {
 		return nil, nil, errors.New("no more mock results")
 	}
@@ -274,6 +282,26 @@ func TestExternalReviewPhaseStalemateBreaksAfterPatience(t *testing.T) {
 	assertLogContains(t, log, "stalemate detected")
 }
 
+func TestExternalReviewPhaseRunBudget(t *testing.T) {
+	budget := &budgetMock{}
+	review := &executorMock{RunFunc: func(context.Context, string) executor.Result {
+		budget.err = fmt.Errorf("%w: run cost $2.00 of max $1.00", ErrBudgetExceeded) // crossed by the evaluation session
+		return executor.Result{Output: "fixed"}
+	}}
+	external := newTaskPhaseMockExecutor([]executor.Result{{Output: "found issue"}, {Output: "found issue"}})
+	phase, _ := externalReviewPhaseFromRunner(t, externalReviewPhaseTestOpts{
+		cfg: Config{MaxIterations: 50, CodexEnabled: true, AppConfig: testAppConfig(t)}, review: review, external: external,
+	})
+	phase.budget = budget
+
+	outcome, err := phase.Run(t.Context())
+
+	require.ErrorIs(t, err, ErrBudgetExceeded)
+	assert.True(t, outcome.HadFindings)
+	assert.Len(t, external.RunCalls(), 1, "the loop stops before the next iteration")
+	assert.Len(t, review.RunCalls(), 1)
+}
+
 func TestExternalReviewPhaseTimeoutRetriesNextIteration(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -364,3 +392,303 @@ func TestExternalReviewPhaseKeepsBranchDiffAfterTimeout(t *testing.T) {
 	assert.Contains(t, prompts[1], "git diff")
 	assert.NotContains(t, prompts[1], "PREVIOUS REVIEW CONTEXT")
 }
+
+func TestExternalReviewPhase_Collect(t *testing.T) {
+	t.Run("codex output without evaluation", func(t *testing.T) {
+		review := newTaskPhaseMockExecutor(nil)
+		codex := newTaskPhaseMockExecutor([]executor.Result{{Output: "found bug in a.go:3"}})
+		phase, _ := externalReviewPhaseFromRunner(t, externalReviewPhaseTestOpts{
+			cfg: Config{CodexEnabled: true, MaxIterations: 50}, review: review, external: codex})
+		out, err := phase.Collect(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, "found bug in a.go:3", out)
+		assert.Len(t, codex.RunCalls(), 1)
+		assert.Empty(t, review.RunCalls(), "findings are not evaluated")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		codex := newTaskPhaseMockExecutor(nil)
+		phase, _ := externalReviewPhaseFromRunner(t, externalReviewPhaseTestOpts{cfg: configuredExternalToolConfig(t, "none"), external: codex})
+		out, err := phase.Collect(t.Context())
+		require.NoError(t, err)
+		assert.Empty(t, out)
+		assert.Empty(t, codex.RunCalls())
+	})
+
+	t.Run("executor error", func(t *testing.T) {
+		codex := newTaskPhaseMockExecutor([]executor.Result{{Error: errors.New("codex broke")}})
+		phase, _ := externalReviewPhaseFromRunner(t, externalReviewPhaseTestOpts{cfg: Config{CodexEnabled: true, MaxIterations: 50}, external: codex})
+		_, err := phase.Collect(t.Context())
+		require.ErrorContains(t, err, "codex broke")
+	})
+}
+
+// customRunnerFunc is a custom review script runner returning the result of the func.
+type customRunnerFunc func() executor.Result
+
+func (f customRunnerFunc) Run(_ context.Context, _, _ string, _ io.Reader) (io.Reader, func() error, error) {
+	result := f()
+	return strings.NewReader(result.Output), func() error { return result.Error }, nil
+}
+
+func namedCustomExecutor(run customRunnerFunc) *executor.CustomExecutor {
+	custom := &executor.CustomExecutor{Script: "/path/to/script.sh"}
+	custom.SetRunner(run)
+	return custom
+}
+
+// requestCapturingRunner is a custom review script runner keeping the JSON requests it was given.
+type requestCapturingRunner struct {
+	output   string
+	requests []executor.ReviewRequest
+}
+
+func (r *requestCapturingRunner) Run(_ context.Context, _, _ string, stdin io.Reader) (io.Reader, func() error, error) {
+	var req executor.ReviewRequest
+	if err := json.NewDecoder(stdin).Decode(&req); err != nil {
+		return nil, nil, err
+	}
+	r.requests = append(r.requests, req)
+	return strings.NewReader(r.output), func() error { return nil }, nil
+}
+
+func TestExternalReviewPhaseCustomReviewRequest(t *testing.T) {
+	runner := &requestCapturingRunner{output: `{"version": 1, "findings": [{"file": "a.go", "line": 3, "description": "race"}]}`}
+	custom := &executor.CustomExecutor{Script: "/path/to/script.sh"}
+	custom.SetRunner(runner)
+	review := newTaskPhaseMockExecutor([]executor.Result{{Output: "fixed the race"}, {Output: "done", Signal: status.CodexDone}})
+	phase, _ := externalReviewPhaseFromRunner(t, externalReviewPhaseTestOpts{
+		cfg: configuredExternalToolConfig(t, "custom"), review: review, custom: custom,
+	})
+	phase.git.deps.Git = &changedFilesGitChecker{files: []string{"a.go"}}
+
+	_, err := phase.Run(t.Context())
+
+	require.NoError(t, err)
+	require.Len(t, runner.requests, 2)
+	first := runner.requests[0]
+	assert.Equal(t, 1, first.Iteration)
+	assert.Equal(t, "git diff master...HEAD", first.DiffCommand)
+	assert.Equal(t, "master", first.BaseRef)
+	assert.Equal(t, []string{"a.go"}, first.ChangedFiles)
+	assert.Empty(t, first.PreviousEvaluation)
+	assert.Equal(t, 2, runner.requests[1].Iteration)
+	assert.Equal(t, "fixed the race", runner.requests[1].PreviousEvaluation)
+	assert.Equal(t, "custom eval: - a.go:3 - race\n", review.RunCalls()[0].Prompt, "json findings rendered as text")
+}
+
+// branchDiffGitChecker is a git checker also diffing the branch.
+type branchDiffGitChecker struct {
+	gitCheckerMock
+	diff    string
+	err     error
+	baseRef string
+}
+
+func (g *branchDiffGitChecker) BranchDiff(baseRef string) (string, error) {
+	g.baseRef = baseRef
+	return g.diff, g.err
+}
+
+func TestExternalReviewPhaseHTTPTool(t *testing.T) {
+	var prompts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct{ Content string } `json:"messages"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		prompts = append(prompts, req.Messages[0].Content, req.Messages[1].Content)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"choices": [{"message": {"content": "- a.go:1 - wrong package"}}]}`)
+	}))
+	defer srv.Close()
+
+	t.Run("branch diff reviewed with the custom prompt", func(t *testing.T) {
+		prompts = nil
+		review := newTaskPhaseMockExecutor([]executor.Result{{Output: "done", Signal: status.CodexDone}})
+		phase, log := externalReviewPhaseFromRunner(t, externalReviewPhaseTestOpts{
+			cfg: configuredExternalToolConfig(t, "http"), review: review,
+			http: &executor.HTTPExecutor{BaseURL: srv.URL + "/v1", Model: "qwen"},
+		})
+		git := &branchDiffGitChecker{diff: "diff --git a/a.go b/a.go\n+package b\n"}
+		phase.git.deps.Git = git
+
+		_, err := phase.Run(t.Context())
+
+		require.NoError(t, err)
+		assert.Equal(t, "master", git.baseRef)
+		require.Len(t, prompts, 2)
+		assert.True(t, strings.HasPrefix(prompts[0], "git diff\ncustom review prompt"))
+		assert.Contains(t, prompts[1], "+package b")
+		require.Len(t, review.RunCalls(), 1)
+		assert.Equal(t, "custom eval: - a.go:1 - wrong package\n", review.RunCalls()[0].Prompt)
+		assert.Equal(t, status.SectionCustomIteration, log.PrintSectionCalls()[0].Section.Type)
+	})
+
+	t.Run("diff error", func(t *testing.T) {
+		phase, _ := externalReviewPhaseFromRunner(t, externalReviewPhaseTestOpts{
+			cfg: configuredExternalToolConfig(t, "http"), http: &executor.HTTPExecutor{BaseURL: srv.URL + "/v1", Model: "qwen"},
+		})
+		phase.git.deps.Git = &branchDiffGitChecker{err: errors.New("bad object")}
+
+		_, err := phase.Run(t.Context())
+
+		require.ErrorContains(t, err, "http execution: branch diff: bad object")
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		phase, _ := externalReviewPhaseFromRunner(t, externalReviewPhaseTestOpts{cfg: configuredExternalToolConfig(t, "http")})
+
+		_, err := phase.Run(t.Context())
+
+		require.ErrorContains(t, err, "http review executor not configured")
+	})
+}
+
+// timeoutToolPolicy reports every session of one tool as timed out.
+type timeoutToolPolicy struct {
+	*testPolicy
+	tool string
+}
+
+func (p timeoutToolPolicy) Run(ctx context.Context, run func(context.Context, string) executor.Result, prompt, tool string) ExecutionResult {
+	result := p.testPolicy.Run(ctx, run, prompt, tool)
+	result.TimedOut = tool == p.tool
+	return result
+}
+
+func TestExternalReviewPhaseRunSeveralTools(t *testing.T) {
+	t.Run("tools run in parallel and findings are evaluated once", func(t *testing.T) {
+		geminiStarted := make(chan struct{})
+		codex := &executorMock{RunFunc: func(context.Context, string) executor.Result {
+			select {
+			case <-geminiStarted:
+				return executor.Result{Output: "- a.go:3 nil map write\n- b.go:9 unchecked error"}
+			case <-time.After(5 * time.Second):
+				return executor.Result{Error: errors.New("gemini review did not run in parallel")}
+			}
+		}}
+		gemini := namedCustomExecutor(func() executor.Result {
+			close(geminiStarted)
+			return executor.Result{Output: "* A.go:3 nil map write\n- c.go:1 missing test"}
+		})
+		review := newTaskPhaseMockExecutor([]executor.Result{{Output: "done", Signal: status.CodexDone}})
+		log := newMockLogger("progress.txt")
+		phase, _ := externalReviewPhaseFromRunner(t, externalReviewPhaseTestOpts{
+			cfg: configuredExternalToolConfig(t, "codex,custom:gemini"), review: review, external: codex,
+			customs: map[string]*executor.CustomExecutor{"gemini": gemini}, log: log,
+		})
+
+		outcome, err := phase.Run(t.Context())
+
+		require.NoError(t, err)
+		assert.False(t, outcome.HadFindings)
+		assert.Contains(t, log.PrintSectionCalls(), printSectionCall{Section: status.NewExternalIterationSection("codex+custom:gemini", 1)},
+			"iteration named after the tool set")
+		require.Len(t, review.RunCalls(), 1, "one evaluation of the merged findings")
+		prompt := review.RunCalls()[0].Prompt
+		assert.True(t, strings.HasPrefix(prompt, "codex eval: === codex ===\n"), "codex evaluation prompt when codex took part")
+		assert.Contains(t, prompt, "- a.go:3 nil map write (also reported by custom:gemini)")
+		assert.Contains(t, prompt, "=== custom:gemini ===\n- c.go:1 missing test")
+		assert.Equal(t, 1, strings.Count(prompt, "nil map write"))
+	})
+
+	t.Run("custom tools only use the custom evaluation", func(t *testing.T) {
+		review := newTaskPhaseMockExecutor([]executor.Result{{Output: "done", Signal: status.CodexDone}})
+		phase, _ := externalReviewPhaseFromRunner(t, externalReviewPhaseTestOpts{
+			cfg: configuredExternalToolConfig(t, "custom:gemini,custom:opencode"), review: review,
+			customs: map[string]*executor.CustomExecutor{
+				"gemini":   namedCustomExecutor(func() executor.Result { return executor.Result{Output: "a.go:3 race"} }),
+				"opencode": namedCustomExecutor(func() executor.Result { return executor.Result{Output: "b.go:4 leak"} }),
+			},
+		})
+
+		_, err := phase.Run(t.Context())
+
+		require.NoError(t, err)
+		require.Len(t, review.RunCalls(), 1)
+		assert.True(t, strings.HasPrefix(review.RunCalls()[0].Prompt, "custom eval: === custom:gemini ==="))
+		assert.Contains(t, review.RunCalls()[0].Prompt, "=== custom:opencode ===\nb.go:4 leak")
+	})
+
+	t.Run("timed out tool is left out of the iteration", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		review := newTaskPhaseMockExecutor([]executor.Result{{Output: "done", Signal: status.CodexDone}})
+		external := newTaskPhaseMockExecutor([]executor.Result{{Output: "partial"}})
+		phase, _ := externalReviewPhaseFromRunner(t, externalReviewPhaseTestOpts{
+			cfg: configuredExternalToolConfig(t, "codex,custom:gemini"), review: review, external: external, log: log,
+			customs: map[string]*executor.CustomExecutor{
+				"gemini": namedCustomExecutor(func() executor.Result { return executor.Result{Output: "a.go:3 race"} }),
+			},
+		})
+		phase.policy = timeoutToolPolicy{testPolicy: newTestPolicy(phase.cfg, log), tool: "codex"}
+
+		_, err := phase.Run(t.Context())
+
+		require.NoError(t, err)
+		require.Len(t, review.RunCalls(), 1)
+		assert.Equal(t, "custom eval: a.go:3 race\n", review.RunCalls()[0].Prompt, "only the reported findings")
+		assertLogContains(t, log, "review session timed out, evaluating the other reviews without it")
+	})
+
+	t.Run("failed tool is left out of the iteration", func(t *testing.T) {
+		log := newMockLogger("progress.txt")
+		review := newTaskPhaseMockExecutor([]executor.Result{{Output: "done", Signal: status.CodexDone}})
+		external := newTaskPhaseMockExecutor([]executor.Result{{Error: errors.New("codex crashed")}})
+		phase, _ := externalReviewPhaseFromRunner(t, externalReviewPhaseTestOpts{
+			cfg: configuredExternalToolConfig(t, "codex,custom:gemini"), review: review, external: external, log: log,
+			customs: map[string]*executor.CustomExecutor{
+				"gemini": namedCustomExecutor(func() executor.Result { return executor.Result{Output: "a.go:3 race"} }),
+			},
+		})
+
+		_, err := phase.Run(t.Context())
+
+		require.NoError(t, err)
+		require.Len(t, review.RunCalls(), 1)
+		assert.Equal(t, "custom eval: a.go:3 race\n", review.RunCalls()[0].Prompt, "only the reported findings")
+		assertLogContains(t, log, "review failed: %v, evaluating the other reviews without it")
+	})
+
+	t.Run("every tool failed", func(t *testing.T) {
+		review := newTaskPhaseMockExecutor(nil)
+		external := newTaskPhaseMockExecutor([]executor.Result{{Error: errors.New("codex crashed")}})
+		phase, _ := externalReviewPhaseFromRunner(t, externalReviewPhaseTestOpts{
+			cfg: configuredExternalToolConfig(t, "codex,custom:gemini"), review: review, external: external,
+			customs: map[string]*executor.CustomExecutor{
+				"gemini": namedCustomExecutor(func() executor.Result { return executor.Result{Error: errors.New("exit status 2")} }),
+			},
+		})
+
+		_, err := phase.Run(t.Context())
+
+		require.ErrorContains(t, err, "codex execution: codex crashed")
+		require.ErrorContains(t, err, "custom:gemini execution")
+		assert.Empty(t, review.RunCalls())
+	})
+
+	t.Run("named script not configured", func(t *testing.T) {
+		phase, _ := externalReviewPhaseFromRunner(t, externalReviewPhaseTestOpts{cfg: configuredExternalToolConfig(t, "codex,custom:gemini")})
+
+		_, err := phase.Run(t.Context())
+
+		require.ErrorContains(t, err, `custom review script "gemini" not configured`)
+	})
+
+	t.Run("collect merges outputs", func(t *testing.T) {
+		codex := newTaskPhaseMockExecutor([]executor.Result{{Output: "a.go:3 race"}})
+		review := newTaskPhaseMockExecutor(nil)
+		phase, _ := externalReviewPhaseFromRunner(t, externalReviewPhaseTestOpts{
+			cfg: configuredExternalToolConfig(t, "codex,custom:gemini"), review: review, external: codex,
+			customs: map[string]*executor.CustomExecutor{
+				"gemini": namedCustomExecutor(func() executor.Result { return executor.Result{Output: "a.go:3 race"} }),
+			},
+		})
+
+		out, err := phase.Collect(t.Context())
+
+		require.NoError(t, err)
+		assert.Equal(t, "=== codex ===\na.go:3 race (also reported by custom:gemini)\n\n=== custom:gemini ===\n", out)
+		assert.Empty(t, review.RunCalls())
+	})
+}
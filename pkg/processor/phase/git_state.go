@@ -1,5 +1,10 @@
 package phase
 
+import (
+	"errors"
+	"fmt"
+)
+
 // GitState reads git state for review loops.
 type GitState struct {
 	deps *Deps
@@ -46,6 +51,48 @@ func (g *GitState) diffFingerprint() string {
 	return fp
 }
 
+// changedFilesLister is implemented by git checkers able to list the files changed against a base ref, e.g. git.Service.
+type changedFilesLister interface {
+	ChangedFiles(baseRef string) ([]string, error)
+}
+
+// branchDiffer is implemented by git checkers able to diff the branch against a base ref, e.g. git.Service.
+type branchDiffer interface {
+	BranchDiff(baseRef string) (string, error)
+}
+
+// branchDiff returns the branch diff against baseRef reviewed by the http review tool.
+func (g *GitState) branchDiff(baseRef string) (string, error) {
+	if g == nil || g.deps == nil || g.deps.Git == nil {
+		return "", errors.New("branch diff: git not available")
+	}
+	differ, ok := g.deps.Git.(branchDiffer)
+	if !ok {
+		return "", errors.New("branch diff: not supported by git checker")
+	}
+	diff, err := differ.BranchDiff(baseRef)
+	if err != nil {
+		return "", fmt.Errorf("branch diff: %w", err)
+	}
+	return diff, nil
+}
+
+func (g *GitState) changedFiles(baseRef string) []string {
+	if g == nil || g.deps == nil || g.deps.Git == nil {
+		return nil
+	}
+	lister, ok := g.deps.Git.(changedFilesLister)
+	if !ok {
+		return nil
+	}
+	files, err := lister.ChangedFiles(baseRef)
+	if err != nil {
+		g.log.Print("warning: failed to list changed files: %v", err)
+		return nil
+	}
+	return files
+}
+
 func (g *GitState) snapshot() gitSnapshot {
 	return gitSnapshot{head: g.headHash(), diff: g.diffFingerprint()}
 }
@@ -32,6 +32,29 @@ func TestGitStateErrorsReturnEmptySnapshot(t *testing.T) {
 	assertLogContains(t, log, "failed to get diff fingerprint")
 }
 
+// changedFilesGitChecker is a git checker also listing changed files.
+type changedFilesGitChecker struct {
+	gitCheckerMock
+	files []string
+	err   error
+}
+
+func (g *changedFilesGitChecker) ChangedFiles(string) ([]string, error) { return g.files, g.err }
+
+func TestGitStateChangedFiles(t *testing.T) {
+	git := NewGitState(&Deps{Git: &changedFilesGitChecker{files: []string{"a.go", "b.go"}}}, newMockLogger(""))
+	assert.Equal(t, []string{"a.go", "b.go"}, git.changedFiles("master"))
+
+	log := newMockLogger("")
+	git = NewGitState(&Deps{Git: &changedFilesGitChecker{err: errors.New("bad ref")}}, log)
+	assert.Nil(t, git.changedFiles("master"))
+	assertLogContains(t, log, "failed to list changed files")
+
+	assert.Nil(t, NewGitState(&Deps{Git: &gitCheckerMock{}}, newMockLogger("")).changedFiles("master"),
+		"git checker without changed files support")
+	assert.Nil(t, NewGitState(&Deps{}, newMockLogger("")).changedFiles("master"))
+}
+
 func TestStalemateStateUpdate(t *testing.T) {
 	log := newMockLogger("")
 	state := newStalemateState(Config{ReviewPatience: 2}, log)
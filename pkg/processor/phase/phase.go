@@ -118,6 +118,28 @@ type GitChecker interface {
 	DiffFingerprint() (string, error)
 }
 
+// Validator runs a plan validation command on behalf of the task phase.
+type Validator interface {
+	Validate(ctx context.Context, command string) executor.CommandResult
+}
+
+// UsageRecorder attributes executor usage to plan tasks.
+type UsageRecorder interface {
+	RecordTaskUsage(taskNum int, u executor.Usage)
+}
+
+// TaskHooks runs lifecycle hooks around task iterations.
+// a returned error stops the task phase.
+type TaskHooks interface {
+	PreTask(ctx context.Context, taskNum int) error
+	PostTask(ctx context.Context, taskNum int) error
+}
+
+// Budget reports whether a run budget limit crossed during a session stopped the run.
+type Budget interface {
+	Exceeded() error
+}
+
 // Deps holds late-bound dependencies shared by phase engines.
 type Deps struct {
 	Git            GitChecker
@@ -126,6 +148,21 @@ type Deps struct {
 	PauseHandler   func(ctx context.Context) bool
 }
 
+// sessionTimeoutKey is the context key of a session timeout override.
+type sessionTimeoutKey struct{}
+
+// WithSessionTimeout returns a context overriding the session timeout of the sessions run with it,
+// used for the timeout of a task header annotation.
+func WithSessionTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, sessionTimeoutKey{}, d)
+}
+
+// SessionTimeout returns the session timeout override of ctx, false when it has none.
+func SessionTimeout(ctx context.Context) (time.Duration, bool) {
+	d, ok := ctx.Value(sessionTimeoutKey{}).(time.Duration)
+	return d, ok
+}
+
 // ExecutionResult is the execution output plus phase-level timeout metadata.
 type ExecutionResult struct {
 	Result   executor.Result
@@ -140,8 +177,9 @@ type Policy interface {
 }
 
 // TaskPrompts renders task phase prompts.
+// taskPos is the 1-indexed plan task the iteration works on, zero for the first task with open work.
 type TaskPrompts interface {
-	TaskPrompt() string
+	TaskPrompt(taskPos int) string
 }
 
 // ReviewPrompts renders internal review prompts.
@@ -156,6 +194,7 @@ type ExternalReviewPrompts interface {
 	CodexEvaluationPrompt(codexOutput string) string
 	CustomReviewPrompt(isFirst bool, claudeResponse string) string
 	CustomEvaluationPrompt(customOutput string) string
+	CustomReviewRequest(isFirst bool, claudeResponse string) executor.ReviewRequest
 }
 
 // PlanCreationPrompts renders interactive plan creation prompts.
@@ -176,5 +215,11 @@ type Locator interface {
 // ErrUserAborted is returned when a user aborts task execution after a break signal.
 var ErrUserAborted = errors.New("user aborted")
 
+// ErrBudgetExceeded is returned when a run budget limit stops the run.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// ErrHookFailed is returned when a lifecycle hook exits non-zero and vetoes or fails the run.
+var ErrHookFailed = errors.New("hook failed")
+
 // ErrUserRejectedPlan is returned when a user rejects a plan draft.
 var ErrUserRejectedPlan = errors.New("user rejected plan")
@@ -18,6 +18,7 @@ type ReviewPhase struct {
 	prompts        ReviewPrompts
 	git            *GitState
 	phaseHolder    *status.PhaseHolder
+	budget         Budget
 	iterationDelay time.Duration
 }
 
@@ -30,6 +31,7 @@ type ReviewPhaseOpts struct {
 	Prompts        ReviewPrompts
 	Git            *GitState
 	PhaseHolder    *status.PhaseHolder
+	Budget         Budget // stops the review loop before the next iteration once a limit is crossed; nil disables it
 	IterationDelay time.Duration
 }
 
@@ -37,7 +39,7 @@ type ReviewPhaseOpts struct {
 func NewReviewPhase(opts ReviewPhaseOpts) *ReviewPhase {
 	return &ReviewPhase{
 		cfg: opts.Cfg, log: opts.Log, exec: opts.Exec, policy: opts.Policy,
-		prompts: opts.Prompts, git: opts.Git, phaseHolder: opts.PhaseHolder,
+		prompts: opts.Prompts, git: opts.Git, phaseHolder: opts.PhaseHolder, budget: opts.Budget,
 		iterationDelay: opts.IterationDelay,
 	}
 }
@@ -65,6 +67,11 @@ func (p *ReviewPhase) Loop(ctx context.Context, prefix string) error {
 			return fmt.Errorf("review: %w", ctx.Err())
 		default:
 		}
+		if p.budget != nil {
+			if err := p.budget.Exceeded(); err != nil {
+				return fmt.Errorf("review: %w", err)
+			}
+		}
 
 		p.log.PrintSection(p.section(i, ": critical/major"))
 		headBefore := p.headHash()
@@ -109,6 +116,38 @@ func (p *ReviewPhase) Loop(ctx context.Context, prefix string) error {
 	return nil
 }
 
+// Custom runs a single session of a custom pipeline stage with the given rendered prompt.
+func (p *ReviewPhase) Custom(ctx context.Context, name, prompt string) error {
+	if p.phaseHolder != nil {
+		p.phaseHolder.Set(status.PhaseReview)
+	}
+	p.log.PrintSection(status.NewGenericSection("stage: " + name))
+	return p.run(ctx, prompt, name+" stage")
+}
+
+// Report runs the single session of a report-only review with the given rendered prompt and
+// returns its output. there is no loop, as nothing is fixed between iterations.
+func (p *ReviewPhase) Report(ctx context.Context, prompt string) (string, error) {
+	if p.phaseHolder != nil {
+		p.phaseHolder.Set(status.PhaseReview)
+	}
+	p.log.PrintSection(status.NewGenericSection("report-only review"))
+
+	execName := p.cfg.executorName()
+	execResult := p.policy.Run(ctx, p.exec.Run, prompt, execName)
+	result := execResult.Result
+	if err := wrapExecutorError(p.policy, result.Error, execName); err != nil {
+		return "", err
+	}
+	if result.Signal == SignalFailed {
+		return "", errors.New("report-only review failed (FAILED signal received)")
+	}
+	if execResult.TimedOut {
+		return "", errors.New("report-only review timed out")
+	}
+	return result.Output, nil
+}
+
 func (p *ReviewPhase) headHash() string {
 	return p.git.headHash()
 }
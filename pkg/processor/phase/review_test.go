@@ -1,7 +1,9 @@
 package phase
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -140,6 +142,21 @@ func TestReviewPhase_Loop_GitCheckerNilSkipsNoCommitCheck(t *testing.T) {
 	assert.Len(t, exec.RunCalls(), 3)
 }
 
+func TestReviewPhase_Loop_Budget(t *testing.T) {
+	budget := &budgetMock{}
+	exec := &executorMock{RunFunc: func(context.Context, string) executor.Result {
+		budget.err = fmt.Errorf("%w: run cost $2.00 of max $1.00", ErrBudgetExceeded) // crossed by this session
+		return executor.Result{Output: "looking"}
+	}}
+	phase, _ := reviewPhaseFromRunner(t, reviewPhaseTestOpts{cfg: Config{MaxIterations: 30}, exec: exec})
+	phase.budget = budget
+
+	err := phase.Loop(t.Context(), "")
+
+	require.ErrorIs(t, err, ErrBudgetExceeded)
+	assert.Len(t, exec.RunCalls(), 1, "the loop stops before the next iteration")
+}
+
 func TestReviewPhase_Loop_GitCheckerErrorSkipsNoCommitCheck(t *testing.T) {
 	exec := newTaskPhaseMockExecutor([]executor.Result{{Output: "looking"}, {Output: "looking"}, {Output: "looking"}})
 	phase, _ := reviewPhaseFromRunner(t, reviewPhaseTestOpts{cfg: Config{MaxIterations: 30}, exec: exec})
@@ -211,6 +228,26 @@ func TestReviewPhase_Loop_DoneSignalStopsBeforeTimeoutHandling(t *testing.T) {
 	assertLogNotContains(t, log, "retrying review iteration")
 }
 
+func TestReviewPhase_Custom(t *testing.T) {
+	exec := newTaskPhaseMockExecutor([]executor.Result{{Output: "done", Signal: SignalReviewDone}})
+	phase, log := reviewPhaseFromRunner(t, reviewPhaseTestOpts{cfg: Config{MaxIterations: 50}, exec: exec})
+
+	require.NoError(t, phase.Custom(t.Context(), "docs", "update the docs"))
+	require.Len(t, exec.RunCalls(), 1)
+	assert.Equal(t, "update the docs", exec.RunCalls()[0].Prompt)
+	assert.Equal(t, status.PhaseReview, phase.phaseHolder.Get())
+	require.Len(t, log.PrintSectionCalls(), 1)
+	assert.Equal(t, "stage: docs", log.PrintSectionCalls()[0].Section.Label)
+}
+
+func TestReviewPhase_Custom_FailedSignal(t *testing.T) {
+	exec := newTaskPhaseMockExecutor([]executor.Result{{Output: "error", Signal: status.Failed}})
+	phase, _ := reviewPhaseFromRunner(t, reviewPhaseTestOpts{cfg: Config{MaxIterations: 50}, exec: exec})
+
+	err := phase.Custom(t.Context(), "docs", "update the docs")
+	require.ErrorContains(t, err, "FAILED signal")
+}
+
 func assertLogContains(t *testing.T, log *mockLogger, text string) {
 	t.Helper()
 	for _, call := range log.PrintCalls() {
@@ -229,3 +266,29 @@ func assertLogNotContains(t *testing.T, log *mockLogger, text string) {
 		}
 	}
 }
+
+func TestReviewPhase_Report(t *testing.T) {
+	t.Run("returns output", func(t *testing.T) {
+		exec := newTaskPhaseMockExecutor([]executor.Result{{Output: "report"}})
+		phase, _ := reviewPhaseFromRunner(t, reviewPhaseTestOpts{cfg: Config{MaxIterations: 50}, exec: exec})
+		out, err := phase.Report(t.Context(), "review prompt")
+		require.NoError(t, err)
+		assert.Equal(t, "report", out)
+		require.Len(t, exec.RunCalls(), 1)
+		assert.Equal(t, "review prompt", exec.RunCalls()[0].Prompt)
+	})
+
+	t.Run("failed signal", func(t *testing.T) {
+		exec := newTaskPhaseMockExecutor([]executor.Result{{Output: "error", Signal: status.Failed}})
+		phase, _ := reviewPhaseFromRunner(t, reviewPhaseTestOpts{cfg: Config{MaxIterations: 50}, exec: exec})
+		_, err := phase.Report(t.Context(), "review prompt")
+		require.ErrorContains(t, err, "FAILED signal")
+	})
+
+	t.Run("executor error", func(t *testing.T) {
+		exec := newTaskPhaseMockExecutor([]executor.Result{{Error: errors.New("boom")}})
+		phase, _ := reviewPhaseFromRunner(t, reviewPhaseTestOpts{cfg: Config{MaxIterations: 50}, exec: exec})
+		_, err := phase.Report(t.Context(), "review prompt")
+		require.ErrorContains(t, err, "boom")
+	})
+}
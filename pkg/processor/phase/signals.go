@@ -7,6 +7,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/umputun/ralphex/pkg/findings"
 	"github.com/umputun/ralphex/pkg/status"
 )
 
@@ -19,12 +20,15 @@ const (
 	SignalQuestion   = status.Question
 	SignalPlanReady  = status.PlanReady
 	SignalPlanDraft  = status.PlanDraft
+	SignalFindings   = status.Findings
 )
 
 var questionSignalRe = regexp.MustCompile(`<<<RALPHEX:QUESTION>>>\s*([\s\S]*?)\s*<<<RALPHEX:END>>>`)
 
 var planDraftSignalRe = regexp.MustCompile(`<<<RALPHEX:PLAN_DRAFT>>>\s*([\s\S]*?)\s*<<<RALPHEX:END>>>`)
 
+var findingsSignalRe = regexp.MustCompile(`<<<RALPHEX:FINDINGS>>>\s*([\s\S]*?)\s*<<<RALPHEX:END>>>`)
+
 // QuestionPayload represents a question signal from the plan creation phase.
 type QuestionPayload struct {
 	Question string   `json:"question"`
@@ -32,6 +36,11 @@ type QuestionPayload struct {
 	Context  string   `json:"context,omitempty"`
 }
 
+// FindingsPayload represents a findings signal from a review or evaluation session.
+type FindingsPayload struct {
+	Findings []findings.Finding `json:"findings"`
+}
+
 // IsReviewDone reports whether signal marks internal review completion.
 func IsReviewDone(signal string) bool {
 	return signal == SignalReviewDone
@@ -53,6 +62,9 @@ var ErrNoQuestionSignal = errors.New("no question signal found")
 // ErrNoPlanDraftSignal indicates no plan draft signal was found in output.
 var ErrNoPlanDraftSignal = errors.New("no plan draft signal found")
 
+// ErrNoFindingsSignal indicates no findings signal was found in output.
+var ErrNoFindingsSignal = errors.New("no findings signal found")
+
 // ParseQuestionPayload extracts a question payload from output containing a QUESTION signal.
 func ParseQuestionPayload(output string) (*QuestionPayload, error) {
 	if !strings.Contains(output, SignalQuestion) {
@@ -102,3 +114,36 @@ func ParsePlanDraftPayload(output string) (string, error) {
 
 	return content, nil
 }
+
+// ParseFindingsPayload extracts the findings of every FINDINGS signal in output, normalized.
+// a session may report findings in several blocks; an empty findings list is valid.
+func ParseFindingsPayload(output string) ([]findings.Finding, error) {
+	if !strings.Contains(output, SignalFindings) {
+		return nil, ErrNoFindingsSignal
+	}
+
+	matches := findingsSignalRe.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return nil, errors.New("malformed findings signal: missing END marker")
+	}
+
+	res := []findings.Finding{}
+	for _, m := range matches {
+		jsonStr := strings.TrimSpace(m[1])
+		if jsonStr == "" {
+			return nil, errors.New("malformed findings signal: empty JSON payload")
+		}
+		var payload FindingsPayload
+		if err := json.Unmarshal([]byte(jsonStr), &payload); err != nil {
+			return nil, fmt.Errorf("malformed findings signal: invalid JSON: %w", err)
+		}
+		for i, f := range payload.Findings {
+			f.Normalize()
+			if err := f.Validate(); err != nil {
+				return nil, fmt.Errorf("malformed findings signal: finding %d: %w", i+1, err)
+			}
+			res = append(res, f)
+		}
+	}
+	return res, nil
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/findings"
 )
 
 func Test_IsReviewDone(t *testing.T) {
@@ -583,3 +585,59 @@ The project uses Go modules and a layered package layout.
 		})
 	}
 }
+
+func Test_ParseFindingsPayload(t *testing.T) {
+	t.Run("several blocks, normalized", func(t *testing.T) {
+		output := `fixed the nil map write
+<<<RALPHEX:FINDINGS>>>
+{"findings": [
+  {"file": "pkg/api/handler.go", "line": 42, "severity": "Major", "agent": "quality", "verdict": "fixed", "commit": "a1b2c3d", "description": "nil map write"},
+  {"file": "pkg/api/handler_test.go", "severity": "minor", "agent": "testing", "verdict": "False Positive", "description": " covered by TestHandler "}
+]}
+<<<RALPHEX:END>>>
+<<<RALPHEX:FINDINGS>>>
+{"findings": [{"verdict": "confirmed", "description": "README is out of date"}]}
+<<<RALPHEX:END>>>`
+
+		res, err := ParseFindingsPayload(output)
+		require.NoError(t, err)
+		require.Len(t, res, 3)
+		assert.Equal(t, findings.Finding{File: "pkg/api/handler.go", Line: 42, Severity: "major", Agent: "quality",
+			Verdict: findings.VerdictFixed, Commit: "a1b2c3d", Description: "nil map write"}, res[0])
+		assert.Equal(t, findings.VerdictFalsePositive, res[1].Verdict)
+		assert.Equal(t, "covered by TestHandler", res[1].Description)
+		assert.Equal(t, findings.VerdictConfirmed, res[2].Verdict)
+	})
+
+	t.Run("empty findings list", func(t *testing.T) {
+		res, err := ParseFindingsPayload("<<<RALPHEX:FINDINGS>>>\n{\"findings\": []}\n<<<RALPHEX:END>>>\n<<<RALPHEX:REVIEW_DONE>>>")
+		require.NoError(t, err)
+		assert.Empty(t, res)
+	})
+
+	t.Run("no signal", func(t *testing.T) {
+		_, err := ParseFindingsPayload("all good\n<<<RALPHEX:REVIEW_DONE>>>")
+		require.ErrorIs(t, err, ErrNoFindingsSignal)
+	})
+
+	tests := []struct {
+		name    string
+		output  string
+		wantErr string
+	}{
+		{name: "missing end marker", output: "<<<RALPHEX:FINDINGS>>>\n{\"findings\": []}", wantErr: "missing END marker"},
+		{name: "empty payload", output: "<<<RALPHEX:FINDINGS>>>\n<<<RALPHEX:END>>>", wantErr: "empty JSON payload"},
+		{name: "invalid json", output: "<<<RALPHEX:FINDINGS>>>\n{\"findings\": [\n<<<RALPHEX:END>>>", wantErr: "invalid JSON"},
+		{name: "unknown verdict", output: `<<<RALPHEX:FINDINGS>>>{"findings": [{"verdict": "maybe", "description": "x"}]}<<<RALPHEX:END>>>`,
+			wantErr: `finding 1: unknown verdict "maybe"`},
+		{name: "missing description", output: `<<<RALPHEX:FINDINGS>>>{"findings": [{"verdict": "fixed"}]}<<<RALPHEX:END>>>`,
+			wantErr: "missing description"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseFindingsPayload(tc.output)
+			require.ErrorContains(t, err, tc.wantErr)
+			require.NotErrorIs(t, err, ErrNoFindingsSignal)
+		})
+	}
+}
@@ -5,38 +5,63 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"strings"
 	"time"
 
 	"github.com/umputun/ralphex/pkg/plan"
 	"github.com/umputun/ralphex/pkg/status"
 )
 
+// maxValidationOutputLen caps the tail of each failing validation command's output injected into the fix prompt.
+const maxValidationOutputLen = 4000
+
+// validationFixPrefix is prepended to the task prompt after the plan's validation commands failed.
+const validationFixPrefix = "IMPORTANT: after the previous task iteration the harness ran the plan's validation commands " +
+	"and they FAILED. The failures below are authoritative, regardless of what the previous iteration reported.\n" +
+	"In this iteration, fix these failures first: stay on the task you just worked on, " +
+	"re-run the validation commands until they pass, and commit the fix with message: fix: <brief description>.\n" +
+	"Do NOT start a new Task section in this iteration. Only output <<<RALPHEX:ALL_TASKS_DONE>>> " +
+	"if validation passes and no [ ] items remain in the plan.\n\n" +
+	"Validation failures:\n%s\n\n"
+
 // TaskPhase executes plan tasks until completion.
 type TaskPhase struct {
-	cfg            Config
-	log            TaskLogger
-	exec           Executor
-	policy         Policy
-	prompts        TaskPrompts
-	locator        Locator
-	deps           *Deps
-	breaks         *BreakController
-	iterationDelay time.Duration
-	retryCount     int
+	cfg               Config
+	log               TaskLogger
+	exec              Executor
+	modelExec         func(spec string) Executor
+	policy            Policy
+	prompts           TaskPrompts
+	locator           Locator
+	deps              *Deps
+	breaks            *BreakController
+	validator         Validator
+	usage             UsageRecorder
+	hooks             TaskHooks
+	budget            Budget
+	iterationDelay    time.Duration
+	retryCount        int
+	validationRetries int
 }
 
 // TaskPhaseOpts contains dependencies for TaskPhase.
 type TaskPhaseOpts struct {
-	Cfg            Config
-	Log            TaskLogger
-	Exec           Executor
-	Policy         Policy
-	Prompts        TaskPrompts
-	Locator        Locator
-	Deps           *Deps
-	Breaks         *BreakController
-	IterationDelay time.Duration
-	RetryCount     int
+	Cfg               Config
+	Log               TaskLogger
+	Exec              Executor
+	ModelExec         func(spec string) Executor // returns the executor of a task model override; nil ignores overrides
+	Policy            Policy
+	Prompts           TaskPrompts
+	Locator           Locator
+	Deps              *Deps
+	Breaks            *BreakController
+	Validator         Validator     // runs plan validation commands after each iteration; nil disables the gate
+	Usage             UsageRecorder // receives per-task executor usage; nil disables attribution
+	Hooks             TaskHooks     // runs pre/post task hooks; nil disables them
+	Budget            Budget        // stops the phase before the next iteration once a limit is crossed; nil disables it
+	IterationDelay    time.Duration
+	RetryCount        int
+	ValidationRetries int // fix iterations allowed per validation failure streak before the phase fails
 }
 
 // NewTaskPhase creates a task phase engine.
@@ -46,16 +71,22 @@ func NewTaskPhase(opts TaskPhaseOpts) *TaskPhase {
 		breaks = NewBreakController(opts.Deps)
 	}
 	return &TaskPhase{
-		cfg: opts.Cfg, log: opts.Log, exec: opts.Exec, policy: opts.Policy,
-		prompts: opts.Prompts, locator: opts.Locator, deps: opts.Deps, breaks: breaks,
-		iterationDelay: opts.IterationDelay, retryCount: opts.RetryCount,
+		cfg: opts.Cfg, log: opts.Log, exec: opts.Exec, modelExec: opts.ModelExec, policy: opts.Policy,
+		prompts: opts.Prompts, locator: opts.Locator, deps: opts.Deps, breaks: breaks, validator: opts.Validator,
+		usage: opts.Usage, hooks: opts.Hooks, budget: opts.Budget, iterationDelay: opts.IterationDelay, retryCount: opts.RetryCount,
+		validationRetries: opts.ValidationRetries,
 	}
 }
 
 // Run executes one plan task per iteration until all actionable task checkboxes are complete.
 func (p *TaskPhase) Run(ctx context.Context) error {
-	prompt := p.prompts.TaskPrompt()
 	retryCount := 0
+	escalation := 0 // failed or timed-out attempts of the current task, each steps up task_escalation
+	prevPos := -1   // task position of the previous iteration; a new task starts with fresh retries
+	validationFailures := 0
+	fixTaskNum := 0 // task whose validation failed; the fix iteration stays on it
+	fixTaskPos := 0 // plan position of that task, zero when the failing iteration ran outside a task section
+	fixPrefix := "" // validation failures prepended to the prompt of fix iterations
 
 	for i := 1; i <= p.cfg.MaxIterations; i++ {
 		select {
@@ -63,18 +94,44 @@ func (p *TaskPhase) Run(ctx context.Context) error {
 			return fmt.Errorf("task phase: %w", ctx.Err())
 		default:
 		}
+		if p.budget != nil {
+			if err := p.budget.Exceeded(); err != nil {
+				return fmt.Errorf("task phase: %w", err)
+			}
+		}
 
-		taskNum := i
-		if pos := p.NextPlanTaskPosition(); pos > 0 {
-			taskNum = pos
+		taskNum, taskPos := i, p.NextPlanTaskPosition()
+		if taskPos > 0 {
+			taskNum = taskPos
+		}
+		if fixTaskNum > 0 {
+			taskNum, taskPos = fixTaskNum, fixTaskPos
+		}
+		if taskPos != prevPos {
+			retryCount, escalation, prevPos = 0, 0, taskPos
 		}
 		p.log.PrintSection(status.NewTaskIterationSection(taskNum))
+		prompt := fixPrefix + p.prompts.TaskPrompt(taskPos)
+		exec, maxRetries, timeout := p.taskSettings(taskPos, escalation)
+
+		if p.hooks != nil {
+			if err := p.hooks.PreTask(ctx, taskNum); err != nil {
+				return fmt.Errorf("task %d: %w", taskNum, err)
+			}
+		}
 
 		loopCtx, loopCancel := p.breaks.context(ctx)
 
 		execName := p.cfg.executorName()
-		execResult := p.policy.Run(loopCtx, p.exec.Run, prompt, execName)
+		runCtx := loopCtx
+		if timeout > 0 {
+			runCtx = WithSessionTimeout(loopCtx, timeout)
+		}
+		execResult := p.policy.Run(runCtx, exec.Run, prompt, execName)
 		result := execResult.Result
+		if p.usage != nil {
+			p.usage.RecordTaskUsage(taskNum, result.Usage)
+		}
 
 		manualBreak := p.breaks.isBreak(loopCtx, ctx)
 		loopCancel()
@@ -87,7 +144,7 @@ func (p *TaskPhase) Run(ctx context.Context) error {
 			}
 			p.breaks.drain()
 			i--
-			retryCount = 0
+			retryCount, escalation = 0, 0
 			continue
 		}
 
@@ -97,12 +154,40 @@ func (p *TaskPhase) Run(ctx context.Context) error {
 
 		if execResult.TimedOut {
 			p.log.Print("%s session timed out, retrying task iteration after %s...", execName, retryBackoff)
+			escalation++
 			if err := p.policy.Sleep(ctx, retryBackoff); err != nil {
 				return fmt.Errorf("interrupted: %w", err)
 			}
 			continue
 		}
 
+		if result.Signal != SignalFailed {
+			failures, err := p.runValidation(ctx, taskNum)
+			if err != nil {
+				return err
+			}
+			if failures != "" {
+				if validationFailures >= p.validationRetries {
+					return fmt.Errorf("validation commands still failing after %d fix attempts", validationFailures)
+				}
+				validationFailures++
+				fixTaskNum, fixTaskPos = taskNum, taskPos
+				fixPrefix = fmt.Sprintf(validationFixPrefix, failures)
+				p.log.Print("validation failed, re-running task %d with failure output (attempt %d/%d)...",
+					taskNum, validationFailures, p.validationRetries)
+				if err := p.policy.Sleep(ctx, p.iterationDelay); err != nil {
+					return fmt.Errorf("interrupted: %w", err)
+				}
+				continue
+			}
+			validationFailures, fixTaskNum, fixTaskPos, fixPrefix = 0, 0, 0, ""
+			if p.hooks != nil {
+				if err := p.hooks.PostTask(ctx, taskNum); err != nil {
+					return fmt.Errorf("task %d: %w", taskNum, err)
+				}
+			}
+		}
+
 		if result.Signal == SignalCompleted {
 			if p.HasUncompletedTasks() {
 				p.log.Print("warning: completion signal received but plan still has [ ] items, continuing...")
@@ -113,9 +198,10 @@ func (p *TaskPhase) Run(ctx context.Context) error {
 		}
 
 		if result.Signal == SignalFailed {
-			if retryCount < p.retryCount {
+			if retryCount < maxRetries {
 				p.log.Print("task failed, retrying...")
 				retryCount++
+				escalation++
 				if err := p.policy.Sleep(ctx, p.iterationDelay); err != nil {
 					return fmt.Errorf("interrupted: %w", err)
 				}
@@ -124,7 +210,7 @@ func (p *TaskPhase) Run(ctx context.Context) error {
 			return errors.New("task execution failed after retry (FAILED signal received)")
 		}
 
-		retryCount = 0
+		retryCount, escalation = 0, 0
 		if err := p.policy.Sleep(ctx, p.iterationDelay); err != nil {
 			return fmt.Errorf("interrupted: %w", err)
 		}
@@ -133,6 +219,103 @@ func (p *TaskPhase) Run(ctx context.Context) error {
 	return fmt.Errorf("max iterations (%d) reached without completion", p.cfg.MaxIterations)
 }
 
+// taskSettings returns the executor, retry count and session timeout of an iteration on the task
+// at taskPos, applying the options of the task header annotation over the run-wide settings.
+// escalation is the number of failed attempts of the task so far; from the first retry on, the
+// executor runs the matching task_escalation spec. a zero timeout keeps the configured session timeout.
+func (p *TaskPhase) taskSettings(taskPos, escalation int) (exec Executor, retries int, timeout time.Duration) {
+	exec, retries = p.exec, p.retryCount
+	var specs []string
+	if p.cfg.AppConfig != nil {
+		specs = p.cfg.AppConfig.TaskEscalation
+	}
+	// every escalation step gets its attempt
+	retries = max(retries, len(specs))
+
+	opts := p.planTaskOptions(taskPos)
+	if !opts.IsZero() {
+		p.log.Print("task options: %s", opts)
+	}
+	if opts.Model != "" {
+		exec = p.executorFor(opts.Model, exec)
+	}
+	if opts.Retries != nil {
+		retries = *opts.Retries
+	}
+	if escalation > 0 && len(specs) > 0 {
+		step := min(escalation, len(specs))
+		p.log.Print("task model escalated to %s (step %d/%d)", specs[step-1], step, len(specs))
+		exec = p.executorFor(specs[step-1], exec)
+	}
+	return exec, retries, opts.Timeout
+}
+
+// executorFor returns the task executor of a model spec, def when the runner can't build one.
+func (p *TaskPhase) executorFor(spec string, def Executor) Executor {
+	if p.modelExec == nil {
+		p.log.Print("[WARN] task model %q ignored, using the default task executor", spec)
+		return def
+	}
+	return p.modelExec(spec)
+}
+
+// planTaskOptions returns the options of the 1-indexed plan task, zero options when unavailable.
+func (p *TaskPhase) planTaskOptions(taskPos int) plan.TaskOptions {
+	if taskPos <= 0 {
+		return plan.TaskOptions{}
+	}
+	parsed, err := plan.ParsePlanFile(p.locator.Path())
+	if err != nil || taskPos > len(parsed.Tasks) {
+		return plan.TaskOptions{}
+	}
+	t := parsed.Tasks[taskPos-1]
+	if t.OptionsErr != "" {
+		p.log.Print("[WARN] task %s options ignored: %s", t.Label(), t.OptionsErr)
+	}
+	return t.Options
+}
+
+// runValidation runs the plan's validation commands and returns a report of the failing ones,
+// or an empty string when all pass, the gate is disabled, or the plan declares no commands.
+// every command runs even after a failure so the fix prompt sees the whole picture.
+func (p *TaskPhase) runValidation(ctx context.Context, taskNum int) (string, error) {
+	if p.validator == nil {
+		return "", nil
+	}
+	parsed, err := plan.ParsePlanFile(p.locator.Path())
+	if err != nil {
+		p.log.Print("[WARN] failed to parse plan file for validation commands: %v", err)
+		return "", nil
+	}
+	if len(parsed.Validation) == 0 {
+		return "", nil
+	}
+
+	p.log.PrintSection(status.NewGenericSection(fmt.Sprintf("validation: task %d", taskNum)))
+	var failures strings.Builder
+	for _, command := range parsed.Validation {
+		res := p.validator.Validate(ctx, command)
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("validation: %w", ctx.Err())
+		}
+		elapsed := res.Duration.Round(time.Millisecond)
+		if res.Error == nil {
+			p.log.Print("validation passed: %s (%s)", command, elapsed)
+			continue
+		}
+		p.log.Print("validation FAILED: %s (%s): %v", command, elapsed, res.Error)
+		output := strings.TrimRight(res.Output, "\n")
+		if output != "" {
+			p.log.PrintRaw("%s\n", output)
+		}
+		if len(output) > maxValidationOutputLen {
+			output = "...\n" + output[len(output)-maxValidationOutputLen:]
+		}
+		fmt.Fprintf(&failures, "$ %s\n%v\n%s\n\n", command, res.Error, output)
+	}
+	return strings.TrimRight(failures.String(), "\n"), nil
+}
+
 // ValidatePlanHasTasks rejects plan files without executable task sections.
 func (p *TaskPhase) ValidatePlanHasTasks() error {
 	path := p.locator.Path()
@@ -215,6 +215,176 @@ func TestTaskPhase_Run_UsesPlanTaskPosition(t *testing.T) {
 	assertTaskSectionPrinted(t, log, 2)
 }
 
+func TestTaskPhase_Run_TaskPromptPosition(t *testing.T) {
+	planContent := "# Plan\n## Validation Commands\n- `make test`\n### Task 1: setup\n- [x] done\n### Task 2: build\n- [ ] build it"
+	planFile := writeTaskPhasePlan(t, planContent)
+	exec := &executorMock{RunFunc: func(_ context.Context, _ string) executor.Result {
+		updated := strings.ReplaceAll(planContent, "- [ ] build it", "- [x] build it")
+		require.NoError(t, os.WriteFile(planFile, []byte(updated), 0o600))
+		return executor.Result{Signal: status.Completed}
+	}}
+	validator := &validatorMock{results: map[string][]executor.CommandResult{
+		"make test": {{ExitCode: 1, Error: errors.New("exit code 1")}, {}},
+	}}
+	phase := taskPhaseFromRunner(t, taskPhaseTestOpts{cfg: Config{MaxIterations: 10}, planFile: planFile, exec: exec,
+		log: newMockLogger("")})
+	prompts := &positionPrompts{}
+	phase.prompts, phase.validator, phase.validationRetries = prompts, validator, 3
+
+	require.NoError(t, phase.Run(t.Context()))
+	assert.Equal(t, []int{2, 2}, prompts.positions, "fix iteration stays on the task completed by the failed one")
+	assert.True(t, strings.HasPrefix(exec.RunCalls()[1].Prompt, "IMPORTANT: after the previous task iteration"))
+}
+
+func TestTaskPhase_Run_TaskOptions(t *testing.T) {
+	planFile := writeTaskPhasePlan(t, "# Plan\n### Task 1: locking {model=opus:high, retries=0, timeout=45m}\n- [ ] rework")
+	failed := []executor.Result{{Signal: status.Failed}, {Signal: status.Failed}, {Signal: status.Failed}}
+
+	t.Run("overrides applied", func(t *testing.T) {
+		def := newTaskPhaseMockExecutor(failed)
+		var timeouts []time.Duration
+		opus := &executorMock{RunFunc: func(ctx context.Context, _ string) executor.Result {
+			d, _ := SessionTimeout(ctx)
+			timeouts = append(timeouts, d)
+			return executor.Result{Signal: status.Failed}
+		}}
+		var specs []string
+		phase := taskPhaseFromRunner(t, taskPhaseTestOpts{cfg: Config{MaxIterations: 10}, exec: def,
+			log: newMockLogger(""), planFile: planFile, retryCount: 2})
+		phase.modelExec = func(spec string) Executor { specs = append(specs, spec); return opus }
+
+		require.ErrorContains(t, phase.Run(t.Context()), "FAILED signal")
+		assert.Empty(t, def.RunCalls(), "default executor not used for the task")
+		assert.Equal(t, []string{"opus:high"}, specs)
+		assert.Equal(t, []time.Duration{45 * time.Minute}, timeouts, "retries=0 stops after the first failure")
+	})
+
+	t.Run("model ignored without builder", func(t *testing.T) {
+		def := newTaskPhaseMockExecutor(failed)
+		log := newMockLogger("")
+		phase := taskPhaseFromRunner(t, taskPhaseTestOpts{cfg: Config{MaxIterations: 10}, exec: def, log: log,
+			planFile: planFile, retryCount: 2})
+
+		require.ErrorContains(t, phase.Run(t.Context()), "FAILED signal")
+		assert.Len(t, def.RunCalls(), 1)
+		var formats []string
+		for _, c := range log.PrintCalls() {
+			formats = append(formats, c.Format)
+		}
+		assert.Contains(t, formats, "task options: %s")
+		assert.Contains(t, formats, "[WARN] task model %q ignored, using the default task executor")
+	})
+	t.Run("invalid options warned and dropped", func(t *testing.T) {
+		planFile := writeTaskPhasePlan(t, "# Plan\n### Task 1: locking {model=opus, retries=many}\n- [ ] rework")
+		def := newTaskPhaseMockExecutor(failed)
+		log := newMockLogger("")
+		phase := taskPhaseFromRunner(t, taskPhaseTestOpts{cfg: Config{MaxIterations: 10}, exec: def, log: log,
+			planFile: planFile, retryCount: 1})
+		phase.modelExec = func(string) Executor { t.Fatal("model override of invalid options applied"); return nil }
+
+		require.ErrorContains(t, phase.Run(t.Context()), "FAILED signal")
+		assert.Len(t, def.RunCalls(), 2)
+		var warned bool
+		for _, c := range log.PrintCalls() {
+			warned = warned || c.Format == "[WARN] task %s options ignored: %s"
+		}
+		assert.True(t, warned)
+	})
+}
+
+func TestTaskPhase_Run_TaskEscalation(t *testing.T) {
+	newPhase := func(t *testing.T, planFile string, def Executor, log *mockLogger) (*taskPhase, *[]string) {
+		t.Helper()
+		appCfg := testAppConfig(t)
+		appCfg.TaskEscalation = []string{"sonnet", "opus:max"}
+		phase := taskPhaseFromRunner(t, taskPhaseTestOpts{cfg: Config{MaxIterations: 10, AppConfig: appCfg}, exec: def,
+			log: log, planFile: planFile})
+		var used []string
+		phase.modelExec = func(spec string) Executor {
+			return &executorMock{RunFunc: func(ctx context.Context, prompt string) executor.Result {
+				used = append(used, spec)
+				return def.Run(ctx, prompt)
+			}}
+		}
+		return phase, &used
+	}
+
+	t.Run("steps up on every failure", func(t *testing.T) {
+		planFile := writeTaskPhasePlan(t, "# Plan\n### Task 1: first\n- [ ] todo")
+		def := newTaskPhaseMockExecutor([]executor.Result{{Signal: status.Failed}, {Signal: status.Failed},
+			{Signal: status.Failed}, {Signal: status.Failed}})
+		log := newMockLogger("")
+		phase, used := newPhase(t, planFile, def, log)
+
+		require.ErrorContains(t, phase.Run(t.Context()), "FAILED signal")
+		assert.Len(t, def.RunCalls(), 3, "each escalation step gets a retry even with task_retry_count 1")
+		assert.Equal(t, []string{"sonnet", "opus:max"}, *used)
+		var steps []string
+		for _, c := range log.PrintCalls() {
+			if c.Format == "task model escalated to %s (step %d/%d)" {
+				steps = append(steps, fmt.Sprintf(c.Format, c.Args...))
+			}
+		}
+		assert.Equal(t, []string{"task model escalated to sonnet (step 1/2)", "task model escalated to opus:max (step 2/2)"}, steps)
+	})
+
+	t.Run("next task drops back to the task model", func(t *testing.T) {
+		planContent := "# Plan\n### Task 1: first\n- [ ] one\n### Task 2: second\n- [ ] two"
+		planFile := writeTaskPhasePlan(t, planContent)
+		calls := 0
+		def := &executorMock{RunFunc: func(_ context.Context, _ string) executor.Result {
+			calls++
+			switch calls {
+			case 1:
+				return executor.Result{Signal: status.Failed}
+			case 2:
+				require.NoError(t, os.WriteFile(planFile, []byte(strings.Replace(planContent, "- [ ] one", "- [x] one", 1)), 0o600))
+				return executor.Result{}
+			default:
+				require.NoError(t, os.WriteFile(planFile, []byte(strings.ReplaceAll(planContent, "- [ ]", "- [x]")), 0o600))
+				return executor.Result{Signal: status.Completed}
+			}
+		}}
+		phase, used := newPhase(t, planFile, def, newMockLogger(""))
+
+		require.NoError(t, phase.Run(t.Context()))
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, []string{"sonnet"}, *used, "only the retry of task 1 escalates")
+	})
+
+	t.Run("task finished by a timed-out session drops back", func(t *testing.T) {
+		planContent := "# Plan\n### Task 1: first\n- [ ] one\n### Task 2: second\n- [ ] two"
+		planFile := writeTaskPhasePlan(t, planContent)
+		calls := 0
+		def := &executorMock{RunFunc: func(_ context.Context, _ string) executor.Result {
+			calls++
+			if calls == 1 {
+				require.NoError(t, os.WriteFile(planFile, []byte(strings.Replace(planContent, "- [ ] one", "- [x] one", 1)), 0o600))
+				return executor.Result{}
+			}
+			require.NoError(t, os.WriteFile(planFile, []byte(strings.ReplaceAll(planContent, "- [ ]", "- [x]")), 0o600))
+			return executor.Result{Signal: status.Completed}
+		}}
+		phase, used := newPhase(t, planFile, def, newMockLogger(""))
+		phase.policy = newScriptedTestPolicy(phase.log, ExecutionResult{TimedOut: true},
+			ExecutionResult{Result: executor.Result{Signal: status.Completed}})
+
+		require.NoError(t, phase.Run(t.Context()))
+		assert.Equal(t, 2, calls)
+		assert.Empty(t, *used, "task 2 runs on the task model")
+	})
+}
+
+// positionPrompts records the task positions of the rendered task prompts.
+type positionPrompts struct {
+	positions []int
+}
+
+func (p *positionPrompts) TaskPrompt(taskPos int) string {
+	p.positions = append(p.positions, taskPos)
+	return fmt.Sprintf("task %d prompt", taskPos)
+}
+
 func TestTaskPhase_Run_BreakWithPauseResume(t *testing.T) {
 	planFile := writeTaskPhasePlan(t, "# Plan\n### Task 1:\n- [x] something")
 	breakCh := make(chan struct{}, 1)
@@ -315,6 +485,104 @@ func TestTaskPhase_Run_TimeoutBacksOffThenContinues(t *testing.T) {
 	assert.Equal(t, []time.Duration{retryBackoff}, policy.sleepCalls, "timeout retry waits the backoff once")
 }
 
+func TestTaskPhase_Run_ValidationGate(t *testing.T) {
+	const planContent = "# Plan\n## Validation Commands\n- `make test`\n- `make lint`\n### Task 1: first\n- [x] done"
+
+	t.Run("failure re-runs task with output until validation passes", func(t *testing.T) {
+		planFile := writeTaskPhasePlan(t, planContent)
+		log := newMockLogger("progress.txt")
+		exec := newTaskPhaseMockExecutor([]executor.Result{
+			{Output: "done", Signal: status.Completed},
+			{Output: "fixed", Signal: status.Completed},
+		})
+		validator := &validatorMock{results: map[string][]executor.CommandResult{
+			"make test": {{Output: "FAIL: TestFoo\n", ExitCode: 1, Error: errors.New("exit code 1")}, {}},
+		}}
+		phase := taskPhaseFromRunner(t, taskPhaseTestOpts{cfg: Config{MaxIterations: 10}, planFile: planFile, exec: exec, log: log})
+		phase.validator, phase.validationRetries = validator, 3
+
+		err := phase.Run(t.Context())
+
+		require.NoError(t, err)
+		calls := exec.RunCalls()
+		require.Len(t, calls, 2)
+		assert.Equal(t, "task prompt", calls[0].Prompt)
+		assert.Contains(t, calls[1].Prompt, "validation commands and they FAILED")
+		assert.Contains(t, calls[1].Prompt, "$ make test\nexit code 1\nFAIL: TestFoo")
+		assert.NotContains(t, calls[1].Prompt, "make lint", "passing commands are not reported")
+		assert.True(t, strings.HasSuffix(calls[1].Prompt, "task prompt"))
+		assert.Equal(t, []string{"make test", "make lint", "make test", "make lint"}, validator.commands())
+		assertTaskSectionPrinted(t, log, 1)
+	})
+
+	t.Run("fix outside a task section keeps the task position", func(t *testing.T) {
+		content := "# Plan\n## Validation Commands\n- `make test`\n### Task 1: first\n- [x] one\n### Task 2: second\n- [x] two\n" +
+			"## Success criteria\n- [ ] all green"
+		planFile := writeTaskPhasePlan(t, content)
+		log := newMockLogger("progress.txt")
+		calls := 0
+		exec := &executorMock{RunFunc: func(context.Context, string) executor.Result {
+			calls++
+			if calls < 3 {
+				return executor.Result{Output: "working"}
+			}
+			require.NoError(t, os.WriteFile(planFile, []byte(strings.ReplaceAll(content, "- [ ]", "- [x]")), 0o600))
+			return executor.Result{Signal: status.Completed}
+		}}
+		validator := &validatorMock{results: map[string][]executor.CommandResult{
+			"make test": {{}, {Output: "FAIL: TestFoo\n", ExitCode: 1, Error: errors.New("exit code 1")}, {}},
+		}}
+		prompts := &positionPrompts{}
+		phase := taskPhaseFromRunner(t, taskPhaseTestOpts{cfg: Config{MaxIterations: 10}, planFile: planFile, exec: exec, log: log})
+		phase.validator, phase.validationRetries, phase.prompts = validator, 3, prompts
+
+		require.NoError(t, phase.Run(t.Context()))
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, []int{0, 0, 0}, prompts.positions, "the fix of iteration 2 is not task 2 of the plan")
+		assertLogContains(t, log, "validation failed, re-running task %d with failure output (attempt %d/%d)...")
+	})
+
+	t.Run("fails after retries exhausted", func(t *testing.T) {
+		planFile := writeTaskPhasePlan(t, planContent)
+		exec := newTaskPhaseMockExecutor(nil)
+		fail := executor.CommandResult{ExitCode: 2, Error: errors.New("exit code 2")}
+		validator := &validatorMock{results: map[string][]executor.CommandResult{"make lint": {fail, fail, fail}}}
+		phase := taskPhaseFromRunner(t, taskPhaseTestOpts{cfg: Config{MaxIterations: 10}, planFile: planFile, exec: exec, log: newMockLogger("")})
+		phase.validator, phase.validationRetries = validator, 2
+
+		err := phase.Run(t.Context())
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "validation commands still failing after 2 fix attempts")
+		assert.Len(t, exec.RunCalls(), 3)
+	})
+
+	t.Run("failed signal skips validation", func(t *testing.T) {
+		planFile := writeTaskPhasePlan(t, planContent)
+		exec := newTaskPhaseMockExecutor([]executor.Result{{Signal: status.Failed}, {Signal: status.Failed}})
+		validator := &validatorMock{}
+		phase := taskPhaseFromRunner(t, taskPhaseTestOpts{cfg: Config{MaxIterations: 10}, planFile: planFile, exec: exec, log: newMockLogger("")})
+		phase.validator, phase.validationRetries = validator, 3
+
+		err := phase.Run(t.Context())
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "FAILED signal")
+		assert.Empty(t, validator.commands())
+	})
+
+	t.Run("plan without validation commands", func(t *testing.T) {
+		planFile := writeTaskPhasePlan(t, "# Plan\n### Task 1: first\n- [x] done")
+		exec := newTaskPhaseMockExecutor([]executor.Result{{Signal: status.Completed}})
+		validator := &validatorMock{}
+		phase := taskPhaseFromRunner(t, taskPhaseTestOpts{cfg: Config{MaxIterations: 10}, planFile: planFile, exec: exec, log: newMockLogger("")})
+		phase.validator, phase.validationRetries = validator, 3
+
+		require.NoError(t, phase.Run(t.Context()))
+		assert.Empty(t, validator.commands())
+	})
+}
+
 func writeTaskPhasePlan(t *testing.T, content string) string {
 	t.Helper()
 	planFile := filepath.Join(t.TempDir(), "plan.md")
@@ -343,6 +611,8 @@ type Executors struct {
 	Review   Executor
 	External Executor
 	Custom   *executor.CustomExecutor
+	Customs  map[string]*executor.CustomExecutor
+	HTTP     *executor.HTTPExecutor
 }
 
 type Runner struct {
@@ -394,7 +664,7 @@ func newTestRunner(opts testRunnerOpts) *Runner {
 		Git: git, PhaseHolder: opts.holder, IterationDelay: iterDelay,
 	})
 	external := NewExternalReviewPhase(ExternalReviewPhaseOpts{
-		Cfg: opts.cfg, Log: opts.log, External: opts.execs.External, Custom: opts.execs.Custom, Review: review,
+		Cfg: opts.cfg, Log: opts.log, External: opts.execs.External, Custom: opts.execs.Custom, Customs: opts.execs.Customs, HTTP: opts.execs.HTTP, Review: review,
 		Policy: policy, Prompts: prompts, Breaks: breaks, Git: git, PhaseHolder: opts.holder, IterationDelay: iterDelay,
 	})
 	finalize := NewFinalizePhase(FinalizePhaseOpts{Cfg: opts.cfg, Log: opts.log, Exec: review, Policy: policy, Prompts: prompts, PhaseHolder: opts.holder})
@@ -529,6 +799,146 @@ func (m *executorMock) RunCalls() []executorRunCall {
 	return append([]executorRunCall(nil), m.runCalls...)
 }
 
+func TestTaskPhase_Run_RecordsTaskUsage(t *testing.T) {
+	planFile := writeTaskPhasePlan(t, "# Plan\n## Validation Commands\n- `make test`\n### Task 1: first\n- [x] done")
+	first := executor.Usage{InputTokens: 100, OutputTokens: 10, CostUSD: 0.01}
+	second := executor.Usage{InputTokens: 50, OutputTokens: 5}
+	exec := newTaskPhaseMockExecutor([]executor.Result{
+		{Output: "done", Signal: status.Completed, Usage: first},
+		{Output: "fixed", Signal: status.Completed, Usage: second},
+	})
+	validator := &validatorMock{results: map[string][]executor.CommandResult{
+		"make test": {{ExitCode: 1, Error: errors.New("exit code 1")}},
+	}}
+	recorder := &usageRecorderMock{}
+	phase := taskPhaseFromRunner(t, taskPhaseTestOpts{cfg: Config{MaxIterations: 10}, planFile: planFile, exec: exec,
+		log: newMockLogger("")})
+	phase.validator, phase.validationRetries, phase.usage = validator, 3, recorder
+
+	require.NoError(t, phase.Run(t.Context()))
+	assert.Equal(t, []taskUsage{{taskNum: 1, usage: first}, {taskNum: 1, usage: second}}, recorder.calls,
+		"validation fix iteration is attributed to the task it fixes")
+}
+
+func TestTaskPhase_Run_Hooks(t *testing.T) {
+	t.Run("pre and post task around successful iterations", func(t *testing.T) {
+		planFile := writeTaskPhasePlan(t, "# Plan\n### Task 1: first\n- [ ] open")
+		exec := newTaskPhaseMockExecutor([]executor.Result{
+			{Output: "failed", Signal: status.Failed},
+			{Output: "progress"},
+		})
+		hooks := &taskHooksMock{}
+		phase := taskPhaseFromRunner(t, taskPhaseTestOpts{cfg: Config{MaxIterations: 2}, planFile: planFile, exec: exec,
+			log: newMockLogger(""), retryCount: 1})
+		phase.hooks = hooks
+
+		require.ErrorContains(t, phase.Run(t.Context()), "max iterations")
+		assert.Equal(t, []string{"pre 1", "pre 1", "post 1"}, hooks.calls, "failed iteration skips post_task")
+	})
+
+	t.Run("pre task veto stops before the executor", func(t *testing.T) {
+		planFile := writeTaskPhasePlan(t, "# Plan\n### Task 1: first\n- [ ] open")
+		exec := newTaskPhaseMockExecutor(nil)
+		hooks := &taskHooksMock{preErr: ErrHookFailed}
+		phase := taskPhaseFromRunner(t, taskPhaseTestOpts{cfg: Config{MaxIterations: 10}, planFile: planFile, exec: exec,
+			log: newMockLogger("")})
+		phase.hooks = hooks
+
+		err := phase.Run(t.Context())
+		require.ErrorIs(t, err, ErrHookFailed)
+		assert.Contains(t, err.Error(), "task 1")
+		assert.Empty(t, exec.RunCalls())
+	})
+
+	t.Run("post task failure stops the phase", func(t *testing.T) {
+		planFile := writeTaskPhasePlan(t, "# Plan\n### Task 1: first\n- [ ] open\n### Task 2: second\n- [ ] open")
+		exec := newTaskPhaseMockExecutor([]executor.Result{{Output: "progress"}, {Output: "progress"}})
+		hooks := &taskHooksMock{postErr: ErrHookFailed}
+		phase := taskPhaseFromRunner(t, taskPhaseTestOpts{cfg: Config{MaxIterations: 10}, planFile: planFile, exec: exec,
+			log: newMockLogger("")})
+		phase.hooks = hooks
+
+		require.ErrorIs(t, phase.Run(t.Context()), ErrHookFailed)
+		assert.Len(t, exec.RunCalls(), 1)
+	})
+}
+
+func TestTaskPhase_Run_Budget(t *testing.T) {
+	planFile := writeTaskPhasePlan(t, "# Plan\n### Task 1: first\n- [ ] open\n### Task 2: second\n- [ ] open")
+	budget := &budgetMock{}
+	exec := &executorMock{RunFunc: func(context.Context, string) executor.Result {
+		budget.err = fmt.Errorf("%w: run cost $2.00 of max $1.00", ErrBudgetExceeded) // crossed by this session
+		return executor.Result{Output: "progress"}
+	}}
+	log := newMockLogger("")
+	phase := taskPhaseFromRunner(t, taskPhaseTestOpts{cfg: Config{MaxIterations: 10}, planFile: planFile, exec: exec, log: log})
+	phase.budget = budget
+
+	err := phase.Run(t.Context())
+	require.ErrorIs(t, err, ErrBudgetExceeded)
+	assert.Len(t, exec.RunCalls(), 1, "the phase stops before the next iteration")
+	assertTaskSectionPrinted(t, log, 1)
+}
+
+type budgetMock struct {
+	err error
+}
+
+func (m *budgetMock) Exceeded() error { return m.err }
+
+type taskHooksMock struct {
+	preErr, postErr error
+	calls           []string
+}
+
+func (m *taskHooksMock) PreTask(_ context.Context, taskNum int) error {
+	m.calls = append(m.calls, fmt.Sprintf("pre %d", taskNum))
+	return m.preErr
+}
+
+func (m *taskHooksMock) PostTask(_ context.Context, taskNum int) error {
+	m.calls = append(m.calls, fmt.Sprintf("post %d", taskNum))
+	return m.postErr
+}
+
+type taskUsage struct {
+	taskNum int
+	usage   executor.Usage
+}
+
+type usageRecorderMock struct {
+	calls []taskUsage
+}
+
+func (m *usageRecorderMock) RecordTaskUsage(taskNum int, u executor.Usage) {
+	m.calls = append(m.calls, taskUsage{taskNum: taskNum, usage: u})
+}
+
+type validatorMock struct {
+	mu      sync.Mutex
+	results map[string][]executor.CommandResult // per-command results consumed in order; exhausted = pass
+	calls   []string
+}
+
+func (m *validatorMock) Validate(_ context.Context, command string) executor.CommandResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, command)
+	res := executor.CommandResult{Command: command}
+	if queue := m.results[command]; len(queue) > 0 {
+		res = queue[0]
+		res.Command = command
+		m.results[command] = queue[1:]
+	}
+	return res
+}
+
+func (m *validatorMock) commands() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.calls...)
+}
+
 type askQuestionCall struct {
 	Ctx      context.Context
 	Question string
@@ -672,7 +1082,7 @@ func (p *testPolicy) Sleep(ctx context.Context, d time.Duration) error {
 
 type testPrompts struct{}
 
-func (testPrompts) TaskPrompt() string                         { return "task prompt" }
+func (testPrompts) TaskPrompt(int) string                      { return "task prompt" }
 func (testPrompts) FirstReviewPrompt() string                  { return "first review prompt" }
 func (testPrompts) SecondReviewPrompt(prefix string) string    { return prefix + "second review prompt" }
 func (testPrompts) CodexReviewPrompt(_ bool, _ string) string  { return "git diff\ncodex review prompt" }
@@ -684,6 +1094,14 @@ func (testPrompts) CustomEvaluationPrompt(output string) string { return "custom
 func (testPrompts) PlanPrompt() string                          { return "plan prompt" }
 func (testPrompts) FinalizePrompt() string                      { return "finalize prompt" }
 
+func (testPrompts) CustomReviewRequest(isFirst bool, claudeResponse string) executor.ReviewRequest {
+	diff := "git diff"
+	if isFirst {
+		diff = "git diff master...HEAD"
+	}
+	return executor.ReviewRequest{DiffCommand: diff, BaseRef: "master", PreviousEvaluation: claudeResponse}
+}
+
 type testLocator struct {
 	path string
 }
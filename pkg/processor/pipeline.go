@@ -0,0 +1,199 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// built-in pipeline stages. any other stage name is a custom stage backed by prompts/<name>.txt.
+const (
+	StageTask        = "task"         // task execution
+	StageReviewFirst = "review_first" // comprehensive first review pass
+	StageReviewLoop  = "review_loop"  // critical/major review loop
+	StageExternal    = "external"     // external review (codex or custom tool)
+	StageFinalize    = "finalize"     // finalize step
+)
+
+// builtinStages lists stages handled by the runner itself, in the order of the full preset.
+var builtinStages = []string{StageTask, StageReviewFirst, StageReviewLoop, StageExternal, StageFinalize}
+
+// modePipelines are the presets each execution mode runs when no pipeline is configured.
+var modePipelines = map[Mode][]string{
+	ModeFull:      {StageTask, StageReviewFirst, StageReviewLoop, StageExternal, StageReviewLoop, StageFinalize},
+	ModeReview:    {StageReviewFirst, StageReviewLoop, StageExternal, StageReviewLoop, StageFinalize},
+	ModeCodexOnly: {StageExternal, StageReviewLoop, StageFinalize},
+	ModeTasksOnly: {StageTask},
+}
+
+// modeCompletion is the message logged when a mode preset completes.
+var modeCompletion = map[Mode]string{
+	ModeFull:      "all phases completed successfully",
+	ModeReview:    "review phases completed successfully",
+	ModeCodexOnly: "codex phases completed successfully",
+	ModeTasksOnly: "task execution completed successfully",
+}
+
+// postExternalCommitPrefix asks the review loop following external review to commit leftovers first.
+const postExternalCommitPrefix = "IMPORTANT: Before starting the review, run `git status`. " +
+	"If there are uncommitted changes from previous review phases, " +
+	"stage and commit them with message: " +
+	"`fix: address code review findings`\n" +
+	"Then continue with the sequence below.\n\n"
+
+// pipeline returns the stages to run and the completion message.
+// a configured pipeline replaces the preset of the current mode.
+func (r *Runner) pipeline() (stages []string, done string) {
+	if len(r.cfg.Pipeline) > 0 {
+		return r.cfg.Pipeline, "pipeline completed successfully"
+	}
+	return modePipelines[r.cfg.Mode], modeCompletion[r.cfg.Mode]
+}
+
+// runPipeline runs stages in order. a review_loop directly after external review is the
+// post-external loop: it commits leftovers first and is skipped when external review is
+// disabled or found nothing.
+func (r *Runner) runPipeline(ctx context.Context, stages []string, done string) error {
+	prompts, err := r.preparePipeline(stages)
+	if err != nil {
+		return err
+	}
+
+	externalFindings := false // result of the external stage right before the current one
+	for i, stage := range stages {
+		if err := r.budget.Exceeded(); err != nil {
+			return err
+		}
+		afterExternal := i > 0 && stages[i-1] == StageExternal
+		if r.findings != nil {
+			r.findings.startStage(stage)
+		}
+		switch stage {
+		case StageTask:
+			err = r.runTaskStage(ctx)
+		case StageReviewFirst:
+			if err = r.phases.review.First(ctx); err != nil {
+				err = fmt.Errorf("first review: %w", err)
+			}
+		case StageReviewLoop:
+			err = r.runReviewLoopStage(ctx, stages[i+1:], afterExternal, externalFindings)
+		case StageExternal:
+			externalFindings, err = r.runExternalStage(ctx, i+1 < len(stages) && stages[i+1] == StageReviewLoop)
+		case StageFinalize:
+			if err = r.phases.finalize.Run(ctx); err != nil {
+				err = fmt.Errorf("finalize phase: %w", err)
+			}
+		default:
+			if err = r.phases.review.Custom(ctx, stage, r.prompts.CustomStagePrompt(prompts[stage])); err != nil {
+				err = fmt.Errorf("%s stage: %w", stage, err)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	r.log.Print("%s", done)
+	return nil
+}
+
+// preparePipeline checks stage prerequisites before anything runs and loads custom stage prompts.
+func (r *Runner) preparePipeline(stages []string) (map[string]string, error) {
+	if len(stages) == 0 {
+		return nil, fmt.Errorf("unknown mode: %s", r.cfg.Mode)
+	}
+	prompts := map[string]string{}
+	hasTask := false
+	for _, stage := range stages {
+		if stage == StageTask {
+			hasTask = true
+			continue
+		}
+		if slices.Contains(builtinStages, stage) || prompts[stage] != "" {
+			continue
+		}
+		if r.cfg.AppConfig == nil {
+			return nil, fmt.Errorf("custom stage %q needs application config", stage)
+		}
+		prompt, err := r.cfg.AppConfig.StagePrompt(stage)
+		if err != nil {
+			return nil, fmt.Errorf("load pipeline stage: %w", err)
+		}
+		if err := r.prompts.checkTemplate(stage+".txt", prompt); err != nil {
+			return nil, fmt.Errorf("validate pipeline stage %q: %w", stage, err)
+		}
+		prompts[stage] = prompt
+	}
+	if !hasTask {
+		return prompts, nil
+	}
+	if r.cfg.PlanFile == "" {
+		return nil, fmt.Errorf("plan file required for %s mode", r.cfg.Mode)
+	}
+	if err := r.phases.taskValidator.ValidatePlanHasTasks(); err != nil {
+		return nil, fmt.Errorf("validate task plan: %w", err)
+	}
+	return prompts, nil
+}
+
+func (r *Runner) runTaskStage(ctx context.Context) error {
+	r.phaseHolder.Set(status.PhaseTask)
+	r.log.PrintRaw("starting task execution phase\n")
+
+	if err := r.phases.task.Run(ctx); err != nil {
+		if errors.Is(err, ErrUserAborted) {
+			r.log.Print("task phase aborted by user")
+			return ErrUserAborted
+		}
+		return fmt.Errorf("task phase: %w", err)
+	}
+	return nil
+}
+
+// runExternalStage runs external review and reports whether it had findings.
+// the "no issues" note is only logged when a post-external review loop is about to be skipped.
+func (r *Runner) runExternalStage(ctx context.Context, reviewNext bool) (bool, error) {
+	tool := r.phases.external.Tool()
+	if tool == "none" {
+		r.log.Print("external review disabled, skipping...")
+		return false, nil
+	}
+
+	r.phaseHolder.Set(status.PhaseCodex)
+	r.log.PrintSection(status.NewGenericSection(tool + " external review"))
+
+	outcome, err := r.phases.external.Run(ctx)
+	if err != nil {
+		return false, fmt.Errorf("%s loop: %w", tool, err)
+	}
+	if !outcome.HadFindings && reviewNext {
+		r.log.Print("external review found no issues, skipping post-%s claude review", tool)
+	}
+	return outcome.HadFindings, nil
+}
+
+// runReviewLoopStage runs a review loop, labeled by its position relative to external review.
+func (r *Runner) runReviewLoopStage(ctx context.Context, rest []string, afterExternal, externalFindings bool) error {
+	if afterExternal {
+		if !externalFindings {
+			return nil
+		}
+		r.phaseHolder.Set(status.PhaseReview)
+		if err := r.phases.review.Loop(ctx, postExternalCommitPrefix); err != nil {
+			return fmt.Errorf("post-external review loop: %w", err)
+		}
+		return nil
+	}
+
+	label := "review loop"
+	if slices.Contains(rest, StageExternal) {
+		label = "pre-external review loop"
+	}
+	if err := r.phases.review.Loop(ctx, ""); err != nil {
+		return fmt.Errorf("%s: %w", label, err)
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// pipelineRecorder wires fake phases into r and records the stages they run.
+func pipelineRecorder(r *Runner, hadFindings bool) *[]string {
+	var calls []string
+	r.phases.task = testTaskPhase{runFunc: func(context.Context) error {
+		calls = append(calls, "task")
+		return nil
+	}}
+	r.phases.taskValidator = testTaskPhase{}
+	r.phases.review = testReviewPhase{
+		firstFunc: func(context.Context) error {
+			calls = append(calls, "first")
+			return nil
+		},
+		loopFunc: func(_ context.Context, prefix string) error {
+			if prefix != "" {
+				calls = append(calls, "post-loop")
+				return nil
+			}
+			calls = append(calls, "loop")
+			return nil
+		},
+		customFunc: func(_ context.Context, name, _ string) error {
+			calls = append(calls, "custom:"+name)
+			return nil
+		},
+	}
+	r.phases.external = testExternalReviewPhase{toolValue: "codex", hadFindings: hadFindings, runFunc: func(context.Context) error {
+		calls = append(calls, "external")
+		return nil
+	}}
+	r.phases.finalize = testFinalizePhase{runFunc: func(context.Context) error {
+		calls = append(calls, "finalize")
+		return nil
+	}}
+	return &calls
+}
+
+func assertLogArg(t *testing.T, log *mocks.LoggerMock, arg string) {
+	t.Helper()
+	for _, call := range log.PrintCalls() {
+		for _, a := range call.Args {
+			if a == arg {
+				return
+			}
+		}
+	}
+	t.Fatalf("expected log with arg %q, got %#v", arg, log.PrintCalls())
+}
+
+func TestRunner_Pipeline_ModePresets(t *testing.T) {
+	tests := []struct {
+		mode        Mode
+		hadFindings bool
+		want        []string
+		done        string
+	}{
+		{mode: ModeFull, hadFindings: true, want: []string{"task", "first", "loop", "external", "post-loop", "finalize"},
+			done: "all phases completed successfully"},
+		{mode: ModeFull, want: []string{"task", "first", "loop", "external", "finalize"},
+			done: "all phases completed successfully"},
+		{mode: ModeReview, hadFindings: true, want: []string{"first", "loop", "external", "post-loop", "finalize"},
+			done: "review phases completed successfully"},
+		{mode: ModeCodexOnly, hadFindings: true, want: []string{"external", "post-loop", "finalize"},
+			done: "codex phases completed successfully"},
+		{mode: ModeTasksOnly, want: []string{"task"}, done: "task execution completed successfully"},
+	}
+	for _, tc := range tests {
+		t.Run(string(tc.mode), func(t *testing.T) {
+			log := newRunnerMockLogger("progress.txt")
+			cfg := Config{Mode: tc.mode, PlanFile: "plan.md", MaxIterations: 50, AppConfig: testAppConfig(t)}
+			r := NewWithExecutors(cfg, log, Executors{Task: newMockExecutor(nil)}, &status.PhaseHolder{})
+			calls := pipelineRecorder(r, tc.hadFindings)
+
+			require.NoError(t, r.Run(t.Context()))
+			assert.Equal(t, tc.want, *calls)
+			assertLogArg(t, log, tc.done)
+		})
+	}
+}
+
+func TestRunner_Pipeline_Custom(t *testing.T) {
+	configDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(configDir, "prompts"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "prompts", "security_audit.txt"),
+		[]byte("audit plan {{PLAN_FILE}}"), 0o600))
+	appCfg, err := config.Load(configDir)
+	require.NoError(t, err)
+
+	log := newRunnerMockLogger("progress.txt")
+	cfg := Config{Mode: ModeFull, PlanFile: "docs/plans/feature.md", MaxIterations: 50, AppConfig: appCfg,
+		Pipeline: []string{"review_first", "task", "security_audit", "review_first", "external", "review_loop"}}
+	r := NewWithExecutors(cfg, log, Executors{Task: newMockExecutor(nil)}, &status.PhaseHolder{})
+	calls := pipelineRecorder(r, true)
+	var prompt string
+	r.phases.review = testReviewPhase{
+		firstFunc: func(context.Context) error {
+			*calls = append(*calls, "first")
+			return nil
+		},
+		loopFunc: func(context.Context, string) error {
+			*calls = append(*calls, "post-loop")
+			return nil
+		},
+		customFunc: func(_ context.Context, name, p string) error {
+			*calls = append(*calls, "custom:"+name)
+			prompt = p
+			return nil
+		},
+	}
+
+	require.NoError(t, r.Run(t.Context()))
+	assert.Equal(t, []string{"first", "task", "custom:security_audit", "first", "external", "post-loop"}, *calls)
+	assert.Contains(t, prompt, "audit plan docs/plans/feature.md", "custom stage prompt has variables replaced")
+	assertLogArg(t, log, "pipeline completed successfully")
+}
+
+func TestRunner_Pipeline_ExternalDisabledSkipsPostLoop(t *testing.T) {
+	log := newRunnerMockLogger("progress.txt")
+	cfg := Config{Mode: ModeReview, MaxIterations: 50, AppConfig: testAppConfig(t)}
+	r := NewWithExecutors(cfg, log, Executors{Task: newMockExecutor(nil)}, &status.PhaseHolder{})
+	calls := pipelineRecorder(r, true)
+	r.phases.external = testExternalReviewPhase{toolValue: "none"}
+
+	require.NoError(t, r.Run(t.Context()))
+	assert.Equal(t, []string{"first", "loop", "finalize"}, *calls)
+	assertLogContains(t, log, "external review disabled")
+}
+
+func TestRunner_Pipeline_Errors(t *testing.T) {
+	t.Run("missing custom stage prompt fails before any stage", func(t *testing.T) {
+		log := newRunnerMockLogger("progress.txt")
+		cfg := Config{Mode: ModeFull, PlanFile: "plan.md", AppConfig: testAppConfig(t),
+			Pipeline: []string{"task", "no_such_stage"}}
+		r := NewWithExecutors(cfg, log, Executors{Task: newMockExecutor(nil)}, &status.PhaseHolder{})
+		calls := pipelineRecorder(r, false)
+
+		err := r.Run(t.Context())
+		require.ErrorContains(t, err, "no prompts/no_such_stage.txt")
+		assert.Empty(t, *calls)
+	})
+
+	t.Run("task stage requires plan", func(t *testing.T) {
+		cfg := Config{Mode: ModeFull, AppConfig: testAppConfig(t), Pipeline: []string{"review_first", "task"}}
+		r := NewWithExecutors(cfg, newMockLogger(), Executors{Task: newMockExecutor(nil)}, &status.PhaseHolder{})
+		calls := pipelineRecorder(r, false)
+
+		err := r.Run(t.Context())
+		require.ErrorContains(t, err, "plan file required for full mode")
+		assert.Empty(t, *calls)
+	})
+
+	t.Run("pipeline without task needs no plan", func(t *testing.T) {
+		cfg := Config{Mode: ModeFull, AppConfig: testAppConfig(t), Pipeline: []string{"review_loop", "finalize"}}
+		r := NewWithExecutors(cfg, newMockLogger(), Executors{Task: newMockExecutor(nil)}, &status.PhaseHolder{})
+		calls := pipelineRecorder(r, false)
+
+		require.NoError(t, r.Run(t.Context()))
+		assert.Equal(t, []string{"loop", "finalize"}, *calls)
+	})
+
+	t.Run("stage error names the stage", func(t *testing.T) {
+		configDir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(configDir, "prompts"), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, "prompts", "docs.txt"), []byte("update docs"), 0o600))
+		appCfg, err := config.Load(configDir)
+		require.NoError(t, err)
+
+		cfg := Config{Mode: ModeFull, AppConfig: appCfg, Pipeline: []string{"docs", "finalize"}}
+		r := NewWithExecutors(cfg, newMockLogger(), Executors{Task: newMockExecutor(nil)}, &status.PhaseHolder{})
+		calls := pipelineRecorder(r, false)
+		r.phases.review = testReviewPhase{customFunc: func(context.Context, string, string) error {
+			return errors.New("boom")
+		}}
+
+		err = r.Run(t.Context())
+		require.EqualError(t, err, "docs stage: boom")
+		assert.Empty(t, *calls)
+	})
+}
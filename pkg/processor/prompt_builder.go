@@ -1,15 +1,20 @@
 package processor
 
 import (
+	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/processor/phase"
 )
 
 type promptBuilder struct {
 	cfg                    Config
 	log                    Logger
 	locator                *planLocator
+	deps                   *phase.Deps // git checker of the task-scoped tokens, set late by Runner.SetGitChecker
 	codexFrontmatterWarned map[string]bool
 }
 
@@ -17,6 +22,7 @@ type promptBuilderOpts struct {
 	cfg     Config
 	log     Logger
 	locator *planLocator
+	deps    *phase.Deps
 }
 
 func newPromptBuilder(opts promptBuilderOpts) *promptBuilder {
@@ -28,11 +34,13 @@ func newPromptBuilder(opts promptBuilderOpts) *promptBuilder {
 	if locator == nil {
 		locator = newPlanLocator(cfg)
 	}
-	return &promptBuilder{cfg: cfg, log: opts.log, locator: locator}
+	return &promptBuilder{cfg: cfg, log: opts.log, locator: locator, deps: opts.deps}
 }
 
-func (b *promptBuilder) TaskPrompt() string {
-	return b.prependCodexTaskGuidance(b.replacePromptVariables(b.cfg.AppConfig.TaskPrompt))
+// TaskPrompt renders the task prompt for the 1-indexed plan task taskPos, zero for the first task with open work.
+func (b *promptBuilder) TaskPrompt(taskPos int) string {
+	prompt := b.render(b.cfg.AppConfig.TaskPrompt, renderOpts{taskPos: taskPos})
+	return b.prependCodexTaskGuidance(b.appendCommitTrailerInstruction(prompt))
 }
 
 func (b *promptBuilder) FirstReviewPrompt() string {
@@ -43,29 +51,75 @@ func (b *promptBuilder) SecondReviewPrompt(prefix string) string {
 	return prefix + b.prependCodexReviewGuidance(b.replacePromptVariables(b.cfg.AppConfig.ReviewSecondPrompt))
 }
 
+// CustomStagePrompt renders the prompt of a custom pipeline stage loaded from prompts/<name>.txt.
+func (b *promptBuilder) CustomStagePrompt(prompt string) string {
+	return b.prependCodexReviewGuidance(b.replacePromptVariables(prompt))
+}
+
 func (b *promptBuilder) CodexReviewPrompt(isFirst bool, claudeResponse string) string {
 	return b.replaceVariablesWithIteration(b.cfg.AppConfig.CodexReviewPrompt, isFirst, claudeResponse)
 }
 
 func (b *promptBuilder) CodexEvaluationPrompt(codexOutput string) string {
-	prompt := b.replacePromptVariables(b.cfg.AppConfig.CodexPrompt)
-	return strings.ReplaceAll(prompt, "{{CODEX_OUTPUT}}", codexOutput)
+	return b.replacePromptVariablesWith(b.cfg.AppConfig.CodexPrompt, map[string]string{"CODEX_OUTPUT": codexOutput})
 }
 
 func (b *promptBuilder) CustomReviewPrompt(isFirst bool, claudeResponse string) string {
 	return b.replaceVariablesWithIteration(b.cfg.AppConfig.CustomReviewPrompt, isFirst, claudeResponse)
 }
 
+// CustomReviewRequest returns the JSON request of custom review scripts, without the iteration
+// and changed files, which the external review phase fills in.
+func (b *promptBuilder) CustomReviewRequest(isFirst bool, claudeResponse string) executor.ReviewRequest {
+	return executor.ReviewRequest{
+		DiffCommand:        b.getDiffInstruction(isFirst),
+		BaseRef:            b.getDefaultBranch(),
+		PlanFile:           b.locator.Path(),
+		ProgressFile:       b.cfg.ProgressPath,
+		PreviousEvaluation: claudeResponse,
+	}
+}
+
 func (b *promptBuilder) CustomEvaluationPrompt(customOutput string) string {
-	prompt := b.replacePromptVariables(b.cfg.AppConfig.CustomEvalPrompt)
-	return strings.ReplaceAll(prompt, "{{CUSTOM_OUTPUT}}", customOutput)
+	return b.replacePromptVariablesWith(b.cfg.AppConfig.CustomEvalPrompt, map[string]string{"CUSTOM_OUTPUT": customOutput})
+}
+
+// ReviewReportPrompt renders the report-only review prompt. {{REVIEW_AGENTS}} expands to the agent
+// references of review_first.txt and {{EXTERNAL_REVIEW}} to the output of the external review tool.
+// no commit trailer instruction is appended, as nothing is committed.
+func (b *promptBuilder) ReviewReportPrompt(tool, externalOutput string) string {
+	var agents []string
+	for _, m := range agentCallPattern.FindAllStringSubmatch(rewriteAgentRefs(b.cfg.AppConfig.ReviewFirstPrompt), -1) {
+		expanded, ok := b.expandAgent(m[1])
+		if !ok {
+			expanded = "{{agent:" + m[1] + "}}"
+		}
+		agents = append(agents, expanded)
+	}
+	prompt := b.render(b.cfg.AppConfig.ReviewReportPrompt, renderOpts{values: map[string]string{
+		"REVIEW_AGENTS":   strings.Join(agents, "\n"),
+		"EXTERNAL_REVIEW": b.externalReviewBlock(tool, externalOutput),
+	}})
+	return b.prependCodexReviewGuidance(prompt)
+}
+
+// agentCallPattern matches the {{agent "name"}} template call, the rewritten form of {{agent:name}}
+var agentCallPattern = regexp.MustCompile(`\{\{\s*agent\s+"([a-zA-Z0-9_-]+)"\s*\}\}`)
+
+// externalReviewBlock returns the external review findings section of the report-only review prompt,
+// empty when external review was not run or found nothing.
+func (b *promptBuilder) externalReviewBlock(tool, output string) string {
+	if strings.TrimSpace(output) == "" {
+		return ""
+	}
+	return fmt.Sprintf("\nThe external review tool (%s) reported the findings below. Include them in Step 3,\n"+
+		"verify each like the agent findings and report them with agent %q.\n\n---\n%s\n---\n",
+		tool, tool, strings.TrimSpace(output))
 }
 
 func (b *promptBuilder) PlanPrompt() string {
-	prompt := b.cfg.AppConfig.MakePlanPrompt
-	prompt = strings.ReplaceAll(prompt, "{{PLAN_DESCRIPTION}}", b.cfg.PlanDescription)
-	result := b.replaceBaseVariables(prompt)
-	return b.appendCommitTrailerInstruction(result)
+	return b.replacePromptVariablesWith(b.cfg.AppConfig.MakePlanPrompt,
+		map[string]string{"PLAN_DESCRIPTION": b.cfg.PlanDescription})
 }
 
 func (b *promptBuilder) FinalizePrompt() string {
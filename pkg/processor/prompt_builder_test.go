@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/executor"
 )
 
 func TestPromptBuilder_FinalPrompts(t *testing.T) {
@@ -28,7 +29,7 @@ func TestPromptBuilder_FinalPrompts(t *testing.T) {
 	}
 	builder := newPromptBuilder(promptBuilderOpts{cfg: cfg, log: newMockLogger(), locator: newPlanLocator(cfg)})
 
-	assert.Equal(t, "task docs/plans/test.md progress.txt", builder.TaskPrompt())
+	assert.Equal(t, "task docs/plans/test.md progress.txt", builder.TaskPrompt(0))
 	assert.Equal(t, "first implementation of plan at docs/plans/test.md", builder.FirstReviewPrompt())
 	assert.Equal(t, "prefix: second main", builder.SecondReviewPrompt("prefix: "))
 	assert.Contains(t, builder.CodexReviewPrompt(true, ""), "git diff main...HEAD")
@@ -39,11 +40,51 @@ func TestPromptBuilder_FinalPrompts(t *testing.T) {
 	assert.Equal(t, "finalize implementation of plan at docs/plans/test.md", builder.FinalizePrompt())
 }
 
+func TestPromptBuilder_CustomReviewRequest(t *testing.T) {
+	cfg := Config{PlanFile: "docs/plans/test.md", ProgressPath: "progress.txt", DefaultBranch: "main",
+		AppConfig: &config.Config{}}
+	builder := newPromptBuilder(promptBuilderOpts{cfg: cfg, log: newMockLogger(), locator: newPlanLocator(cfg)})
+
+	assert.Equal(t, executor.ReviewRequest{DiffCommand: "git diff main...HEAD", BaseRef: "main",
+		PlanFile: "docs/plans/test.md", ProgressFile: "progress.txt"}, builder.CustomReviewRequest(true, ""))
+
+	req := builder.CustomReviewRequest(false, "fixed the race")
+	assert.Equal(t, "git diff", req.DiffCommand)
+	assert.Equal(t, "fixed the race", req.PreviousEvaluation)
+
+	builder = newPromptBuilder(promptBuilderOpts{cfg: Config{AppConfig: &config.Config{}}, log: newMockLogger()})
+	req = builder.CustomReviewRequest(true, "")
+	assert.Equal(t, "master", req.BaseRef)
+	assert.Empty(t, req.PlanFile, "no plan file when reviewing a branch")
+}
+
+func TestPromptBuilder_ReviewReportPrompt(t *testing.T) {
+	appCfg := &config.Config{
+		ReviewFirstPrompt:  "step 1\n{{agent:quality}}\nsome text\n{{agent:testing}}",
+		ReviewReportPrompt: "report {{GOAL}}\n{{REVIEW_AGENTS}}\n{{EXTERNAL_REVIEW}}end",
+		CustomAgents:       []config.CustomAgent{{Name: "quality", Prompt: "check quality"}, {Name: "testing", Prompt: "check tests"}},
+	}
+	cfg := Config{DefaultBranch: "main", AppConfig: appCfg}
+	builder := newPromptBuilder(promptBuilderOpts{cfg: cfg, log: newMockLogger(), locator: newPlanLocator(cfg)})
+
+	prompt := builder.ReviewReportPrompt("codex", "")
+	assert.True(t, strings.HasPrefix(prompt, "report current branch vs main\n"))
+	assert.Contains(t, prompt, "check quality")
+	assert.Contains(t, prompt, "check tests")
+	assert.NotContains(t, prompt, "some text", "only agent references of review_first are used")
+	assert.NotContains(t, prompt, "{{")
+	assert.NotContains(t, prompt, "external review tool")
+
+	prompt = builder.ReviewReportPrompt("custom", "  leak in b.go:1\n")
+	assert.Contains(t, prompt, "The external review tool (custom) reported")
+	assert.Contains(t, prompt, "---\nleak in b.go:1\n---")
+}
+
 func TestPromptBuilder_NilConfigDependencies(t *testing.T) {
 	builder := newPromptBuilder(promptBuilderOpts{cfg: Config{}, log: newMockLogger()})
 
 	assert.NotPanics(t, func() {
-		assert.Empty(t, builder.TaskPrompt())
+		assert.Empty(t, builder.TaskPrompt(0))
 		assert.Empty(t, builder.FirstReviewPrompt())
 		assert.Empty(t, builder.CodexEvaluationPrompt("findings"))
 		assert.Empty(t, builder.FinalizePrompt())
@@ -55,7 +96,7 @@ func TestPromptBuilder_CodexTaskGuidance(t *testing.T) {
 	cfg := Config{AppConfig: appCfg}
 	builder := newPromptBuilder(promptBuilderOpts{cfg: cfg, log: newMockLogger(), locator: newPlanLocator(cfg)})
 
-	prompt := builder.TaskPrompt()
+	prompt := builder.TaskPrompt(0)
 	assert.True(t, strings.HasPrefix(prompt, codexTaskGuidance))
 	assert.Contains(t, prompt, "do work")
 }
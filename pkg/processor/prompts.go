@@ -3,6 +3,7 @@ package processor
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/umputun/ralphex/pkg/config"
@@ -12,8 +13,11 @@ import (
 // agentRefPattern matches {{agent:name}} template syntax
 var agentRefPattern = regexp.MustCompile(`\{\{agent:([a-zA-Z0-9_-]+)\}\}`)
 
-// getGoal returns the goal string based on whether a plan file is configured.
+// getGoal returns the goal string based on the review target and whether a plan file is configured.
 func (b *promptBuilder) getGoal() string {
+	if b.cfg.ReviewTarget != "" {
+		return b.cfg.ReviewTarget
+	}
 	if b.cfg.PlanFile == "" {
 		return "current branch vs " + b.getDefaultBranch()
 	}
@@ -22,6 +26,9 @@ func (b *promptBuilder) getGoal() string {
 
 // getPlanFileRef returns plan file reference or fallback text for prompts.
 func (b *promptBuilder) getPlanFileRef() string {
+	if b.cfg.PlanFile == "" && b.cfg.ReviewTarget != "" {
+		return "(no plan file - reviewing " + b.cfg.ReviewTarget + ")"
+	}
 	if b.cfg.PlanFile == "" {
 		return "(no plan file - reviewing current branch)"
 	}
@@ -36,20 +43,13 @@ func (b *promptBuilder) getProgressFileRef() string {
 	return b.cfg.ProgressPath
 }
 
-// replaceBaseVariables replaces common template variables in prompts.
-// supported: {{PLAN_FILE}}, {{PROGRESS_FILE}}, {{GOAL}}, {{DEFAULT_BRANCH}}, {{PLANS_DIR}}
-// this is the core replacement function used by all prompt builders.
-// replaces common template variables shared across all prompt types.
+// replaceBaseVariables renders the prompt template with the variables shared by all prompt types:
+// {{PLAN_FILE}}, {{PROGRESS_FILE}}, {{GOAL}}, {{DEFAULT_BRANCH}}, {{PLANS_DIR}} and the template data.
+// {{agent:name}} references and phase-specific tokens are kept as-is.
 // does not append trailer instruction — callers are responsible for calling appendCommitTrailerInstruction
 // once on the final assembled prompt, to avoid duplication when expanding agent references.
 func (b *promptBuilder) replaceBaseVariables(prompt string) string {
-	result := prompt
-	result = strings.ReplaceAll(result, "{{PLAN_FILE}}", b.getPlanFileRef())
-	result = strings.ReplaceAll(result, "{{PROGRESS_FILE}}", b.getProgressFileRef())
-	result = strings.ReplaceAll(result, "{{GOAL}}", b.getGoal())
-	result = strings.ReplaceAll(result, "{{DEFAULT_BRANCH}}", b.getDefaultBranch())
-	result = strings.ReplaceAll(result, "{{PLANS_DIR}}", b.getPlansDir())
-	return result
+	return b.render(prompt, renderOpts{noAgents: true})
 }
 
 // appendCommitTrailerInstruction appends trailer instruction to prompt when commit_trailer is configured.
@@ -93,12 +93,12 @@ If Claude's arguments are invalid, explain why the issues still exist.`, claudeR
 // supported: {{PLAN_FILE}}, {{PROGRESS_FILE}}, {{GOAL}}, {{DEFAULT_BRANCH}}, {{PLANS_DIR}},
 // {{DIFF_INSTRUCTION}}, {{PREVIOUS_REVIEW_CONTEXT}}, {{agent:name}}
 // this variant is used when iteration context is needed (e.g., external review prompts).
+// the previous review context is inserted as a value, so agent references inside it are not expanded.
 func (b *promptBuilder) replaceVariablesWithIteration(prompt string, isFirstIteration bool, claudeResponse string) string {
-	result := b.replaceBaseVariables(prompt)
-	result = strings.ReplaceAll(result, "{{DIFF_INSTRUCTION}}", b.getDiffInstruction(isFirstIteration))
-	result = b.expandAgentReferences(result) // expand agents before inserting external content
-	result = strings.ReplaceAll(result, "{{PREVIOUS_REVIEW_CONTEXT}}", b.buildPreviousContext(claudeResponse))
-	return b.appendCommitTrailerInstruction(result)
+	return b.appendCommitTrailerInstruction(b.render(prompt, renderOpts{values: map[string]string{
+		"DIFF_INSTRUCTION":        b.getDiffInstruction(isFirstIteration),
+		"PREVIOUS_REVIEW_CONTEXT": b.buildPreviousContext(claudeResponse),
+	}}))
 }
 
 // reviewContextInstruction returns the lead-in prepended to every review agent
@@ -271,44 +271,41 @@ func (b *promptBuilder) escapeCodexSingleQuoted(s string) string {
 // returns prompt unchanged if AppConfig is nil or no agents are configured.
 // missing agents log a warning and leave the reference as-is for visibility.
 func (b *promptBuilder) expandAgentReferences(prompt string) string {
-	if b.cfg.AppConfig == nil {
+	if b.cfg.AppConfig == nil || len(b.cfg.AppConfig.CustomAgents) == 0 {
 		return prompt
 	}
-	agents := b.cfg.AppConfig.CustomAgents
-	if len(agents) == 0 {
-		return prompt
-	}
-
-	// build agent lookup map
-	agentMap := make(map[string]config.CustomAgent, len(agents))
-	for _, agent := range agents {
-		agentMap[agent.Name] = agent
-	}
-
 	return agentRefPattern.ReplaceAllStringFunc(prompt, func(match string) string {
 		// extract name directly from match: {{agent:NAME}} -> NAME
-		name := match[8 : len(match)-2] // skip "{{agent:" and "}}"
-
-		agent, ok := agentMap[name]
-		if !ok {
-			b.log.Print("[WARN] agent %q not found, leaving reference unexpanded", name)
-			return match
-		}
-
-		b.log.Print("agent %q: %s", name, agent.Options)
-
-		// under codex syntax, formatAgentExpansionCodex collapses every {{agent:name}} into
-		// the same spawn_agent(agent='reviewer', task=...) call — frontmatter Model/AgentType
-		// are intentionally discarded. warn so users do not silently lose per-agent overrides.
-		if b.cfg.isCodexExecutor() && (agent.Model != "" || agent.AgentType != "") {
-			b.warnCodexFrontmatterDiscarded(name, agent.Options)
+		if expanded, ok := b.expandAgent(match[8 : len(match)-2]); ok { // skip "{{agent:" and "}}"
+			return expanded
 		}
+		return match
+	})
+}
 
-		// expand variables in agent content (no agent expansion to avoid recursion)
-		agentPrompt := b.replaceBaseVariables(agent.Prompt)
+// expandAgent returns the agent invocation block of the named agent, false when no such agent is configured.
+// the agent body is rendered as a template without agent expansion, to avoid recursion.
+func (b *promptBuilder) expandAgent(name string) (string, bool) {
+	if b.cfg.AppConfig == nil || len(b.cfg.AppConfig.CustomAgents) == 0 {
+		return "", false
+	}
+	idx := slices.IndexFunc(b.cfg.AppConfig.CustomAgents, func(a config.CustomAgent) bool { return a.Name == name })
+	if idx < 0 {
+		b.log.Print("[WARN] agent %q not found, leaving reference unexpanded", name)
+		return "", false
+	}
+	agent := b.cfg.AppConfig.CustomAgents[idx]
+	b.log.Print("agent %q: %s", name, agent.Options)
+
+	// under codex syntax, formatAgentExpansionCodex collapses every {{agent:name}} into
+	// the same spawn_agent(agent='reviewer', task=...) call — frontmatter Model/AgentType
+	// are intentionally discarded. warn so users do not silently lose per-agent overrides.
+	if b.cfg.isCodexExecutor() && (agent.Model != "" || agent.AgentType != "") {
+		b.warnCodexFrontmatterDiscarded(name, agent.Options)
+	}
 
-		return b.formatAgentExpansion(agentPrompt, agent.Options)
-	})
+	agentPrompt := b.render(agent.Prompt, renderOpts{name: "agents/" + name + ".txt", noAgents: true})
+	return b.formatAgentExpansion(agentPrompt, agent.Options), true
 }
 
 // warnCodexFrontmatterDiscarded logs a one-time-per-agent warning when codex
@@ -328,9 +325,12 @@ func (b *promptBuilder) warnCodexFrontmatterDiscarded(name string, opts config.O
 // supported: {{PLAN_FILE}}, {{PROGRESS_FILE}}, {{GOAL}}, {{DEFAULT_BRANCH}}, {{PLANS_DIR}}, {{agent:name}}
 // note: {{CODEX_OUTPUT}} and {{PLAN_DESCRIPTION}} are handled by specific build functions.
 func (b *promptBuilder) replacePromptVariables(prompt string) string {
-	result := b.replaceBaseVariables(prompt)
-	result = b.expandAgentReferences(result)
-	return b.appendCommitTrailerInstruction(result)
+	return b.replacePromptVariablesWith(prompt, nil)
+}
+
+// replacePromptVariablesWith is replacePromptVariables with values of phase-specific tokens.
+func (b *promptBuilder) replacePromptVariablesWith(prompt string, values map[string]string) string {
+	return b.appendCommitTrailerInstruction(b.render(prompt, renderOpts{values: values}))
 }
 
 // getDefaultBranch returns the default branch name or "master" as fallback.
@@ -236,6 +236,12 @@ func TestRunner_replacePromptVariables_NoGoal(t *testing.T) {
 		result := newPromptBuilderForTest(r).replacePromptVariables("Goal: {{GOAL}}")
 		assert.Equal(t, "Goal: current branch vs trunk", result)
 	})
+
+	t.Run("review target overrides plan and branch", func(t *testing.T) {
+		r := &Runner{cfg: Config{PlanFile: "docs/plans/test.md", DefaultBranch: "abc123", ReviewTarget: "commit range v1..v2"}}
+		result := newPromptBuilderForTest(r).replaceVariablesWithIteration("Goal: {{GOAL}}, run {{DIFF_INSTRUCTION}}", true, "")
+		assert.Equal(t, "Goal: commit range v1..v2, run git diff abc123...HEAD", result)
+	})
 }
 
 func TestRunner_replacePromptVariables_DefaultBranch(t *testing.T) {
@@ -262,6 +268,11 @@ func TestRunner_getPlanFileRef(t *testing.T) {
 		r := &Runner{cfg: Config{PlanFile: ""}}
 		assert.Equal(t, "(no plan file - reviewing current branch)", newPromptBuilderForTest(r).getPlanFileRef())
 	})
+
+	t.Run("without plan file, with review target", func(t *testing.T) {
+		r := &Runner{cfg: Config{ReviewTarget: "patch fix.diff on master"}}
+		assert.Equal(t, "(no plan file - reviewing patch fix.diff on master)", newPromptBuilderForTest(r).getPlanFileRef())
+	})
 }
 
 func TestRunner_resolvePlanFilePath(t *testing.T) {
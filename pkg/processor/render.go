@@ -0,0 +1,133 @@
+package processor
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// PromptPhases lists the prompts rendered by RenderPrompts, named after their prompts/<phase>.txt files.
+var PromptPhases = []string{"make_plan", "task", "review_first", "codex_review", "codex", "custom_review",
+	"custom_eval", "review_second", "review_report", "finalize"}
+
+// placeholders of the rendered prompts for the runtime values of a session
+const (
+	renderPlanDescription = "<plan description>"
+	renderExternalOutput  = "<external review output>"
+)
+
+// templateVarPattern matches any {{...}} left in a rendered prompt
+var templateVarPattern = regexp.MustCompile(`\{\{[^{}\n]+\}\}`)
+
+// RenderedPrompt is the prompt of a phase as sent to the executor.
+type RenderedPrompt struct {
+	Phase   string
+	Syntax  string   // agent syntax of the prompt, claude or codex
+	Text    string   // prompt after variable substitution and agent expansion
+	Unknown []string // template variables left unreplaced
+	Missing []string // agents referenced by {{agent:name}} but not configured
+	Error   string   // template error of the prompt, Text is the unrendered prompt then
+}
+
+// RenderPrompts renders the prompts of phases, all of PromptPhases when empty, in claude and codex syntax.
+// external review prompts are rendered for their first iteration and runtime output is shown as a placeholder.
+func RenderPrompts(cfg Config, phases []string) ([]RenderedPrompt, error) {
+	if len(phases) == 0 {
+		phases = PromptPhases
+	}
+	for _, ph := range phases {
+		if !slices.Contains(PromptPhases, ph) {
+			return nil, fmt.Errorf("unknown prompt phase %q, expected one of %s", ph, strings.Join(PromptPhases, ", "))
+		}
+	}
+	if cfg.PlanDescription == "" {
+		cfg.PlanDescription = renderPlanDescription
+	}
+
+	var res []RenderedPrompt
+	for _, syntax := range []string{"claude", "codex"} {
+		appCfg := config.Config{}
+		if cfg.AppConfig != nil {
+			appCfg = *cfg.AppConfig
+		}
+		appCfg.Executor = config.ExecutorClaude
+		if syntax == "codex" {
+			appCfg.Executor = config.ExecutorCodex
+		}
+		syntaxCfg := cfg
+		syntaxCfg.AppConfig = &appCfg
+		b := newPromptBuilder(promptBuilderOpts{cfg: syntaxCfg, log: nopLogger{}})
+		sources := b.promptSources()
+		for _, ph := range phases {
+			text := renderPhasePrompt(b, ph)
+			rp := RenderedPrompt{Phase: ph, Syntax: syntax, Text: text}
+			idx := slices.IndexFunc(sources, func(src promptSource) bool { return src.phase == ph })
+			if err := b.checkTemplate(ph+".txt", sources[idx].text); err != nil {
+				rp.Error = err.Error()
+			} else {
+				rp.Unknown, rp.Missing = unresolvedRefs(text)
+			}
+			res = append(res, rp)
+		}
+	}
+	return res, nil
+}
+
+// renderPhasePrompt builds the prompt of a phase the way the runner does.
+func renderPhasePrompt(b *promptBuilder, ph string) string {
+	switch ph {
+	case "make_plan":
+		return b.PlanPrompt()
+	case "task":
+		return b.TaskPrompt(0)
+	case "review_first":
+		return b.FirstReviewPrompt()
+	case "codex_review":
+		return b.CodexReviewPrompt(true, "")
+	case "codex":
+		return b.CodexEvaluationPrompt(renderExternalOutput)
+	case "custom_review":
+		return b.CustomReviewPrompt(true, "")
+	case "custom_eval":
+		return b.CustomEvaluationPrompt(renderExternalOutput)
+	case "review_second":
+		return b.SecondReviewPrompt("")
+	case "review_report":
+		return b.ReviewReportPrompt("codex", renderExternalOutput)
+	default:
+		return b.FinalizePrompt()
+	}
+}
+
+// unresolvedRefs returns the template variables and agent references left in a rendered prompt.
+// expandAgentReferences keeps references to unknown agents as-is, so a leftover {{agent:name}} is a missing agent.
+func unresolvedRefs(text string) (unknown, missing []string) {
+	for _, ref := range templateVarPattern.FindAllString(text, -1) {
+		if m := agentRefPattern.FindStringSubmatch(ref); m != nil {
+			if !slices.Contains(missing, m[1]) {
+				missing = append(missing, m[1])
+			}
+			continue
+		}
+		if !slices.Contains(unknown, ref) {
+			unknown = append(unknown, ref)
+		}
+	}
+	return unknown, missing
+}
+
+// nopLogger discards the builder's log lines, RenderPrompts reports unresolved references in its result.
+type nopLogger struct{}
+
+func (nopLogger) Print(string, ...any)          {}
+func (nopLogger) PrintRaw(string, ...any)       {}
+func (nopLogger) PrintSection(status.Section)   {}
+func (nopLogger) PrintAligned(string)           {}
+func (nopLogger) LogQuestion(string, []string)  {}
+func (nopLogger) LogAnswer(string)              {}
+func (nopLogger) LogDraftReview(string, string) {}
+func (nopLogger) Path() string                  { return "" }
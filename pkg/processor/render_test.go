@@ -0,0 +1,75 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+)
+
+func TestRenderPrompts(t *testing.T) {
+	t.Run("default prompts of every phase", func(t *testing.T) {
+		cfg := Config{PlanFile: "docs/plans/feature.md", ProgressPath: "progress-feature.txt", DefaultBranch: "main",
+			AppConfig: testAppConfig(t)}
+		rendered, err := RenderPrompts(cfg, nil)
+		require.NoError(t, err)
+		require.Len(t, rendered, 2*len(PromptPhases))
+
+		for _, p := range rendered {
+			assert.Empty(t, p.Unknown, "%s (%s)", p.Phase, p.Syntax)
+			assert.Empty(t, p.Missing, "%s (%s)", p.Phase, p.Syntax)
+			assert.NotEmpty(t, p.Text, "%s (%s)", p.Phase, p.Syntax)
+		}
+		assert.Equal(t, "make_plan", rendered[0].Phase)
+		assert.Equal(t, "claude", rendered[0].Syntax)
+		assert.Contains(t, rendered[0].Text, renderPlanDescription)
+		assert.Equal(t, "codex", rendered[len(PromptPhases)].Syntax)
+
+		first := rendered[2]
+		assert.Equal(t, "review_first", first.Phase)
+		assert.Contains(t, first.Text, "Use the Task tool")
+		assert.Contains(t, first.Text, "git diff main...HEAD")
+		codexFirst := rendered[len(PromptPhases)+2]
+		assert.Contains(t, codexFirst.Text, "spawn_agent(")
+		assert.Contains(t, codexFirst.Text, codexReviewGuidance)
+		assert.NotContains(t, first.Text, codexReviewGuidance)
+	})
+
+	t.Run("unknown variables and missing agents", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.Executor = config.ExecutorCodex
+		appCfg.TaskPrompt = "do {{PLAN_FILE}} with {{CODEX_OUTPUT}} and {{agent:ghost}}, {{CODEX_OUTPUT}} again, {{agent:quality}}"
+		rendered, err := RenderPrompts(Config{PlanFile: "plan.md", AppConfig: appCfg}, []string{"task"})
+		require.NoError(t, err)
+		require.Len(t, rendered, 2)
+		for _, p := range rendered {
+			assert.Equal(t, "task", p.Phase)
+			assert.Empty(t, p.Error)
+			assert.Equal(t, []string{"{{CODEX_OUTPUT}}"}, p.Unknown)
+			assert.Equal(t, []string{"ghost"}, p.Missing)
+			assert.Contains(t, p.Text, "do plan.md with")
+		}
+		assert.Contains(t, rendered[0].Text, "Use the Task tool")
+		assert.Contains(t, rendered[1].Text, "spawn_agent(")
+		assert.Equal(t, config.ExecutorCodex, appCfg.Executor, "caller config not modified")
+	})
+
+	t.Run("template error", func(t *testing.T) {
+		appCfg := testAppConfig(t)
+		appCfg.TaskPrompt = "do {{PLAN_FILE}} with {{TYPO}}"
+		rendered, err := RenderPrompts(Config{PlanFile: "plan.md", AppConfig: appCfg}, []string{"task"})
+		require.NoError(t, err)
+		require.Len(t, rendered, 2)
+		for _, p := range rendered {
+			assert.Contains(t, p.Error, `task.txt:1: function "TYPO" not defined`)
+			assert.Contains(t, p.Text, appCfg.TaskPrompt, "unrendered prompt")
+		}
+	})
+
+	t.Run("unknown phase", func(t *testing.T) {
+		_, err := RenderPrompts(Config{AppConfig: testAppConfig(t)}, []string{"review"})
+		require.ErrorContains(t, err, `unknown prompt phase "review"`)
+	})
+}
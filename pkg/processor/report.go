@@ -0,0 +1,44 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/umputun/ralphex/pkg/processor/phase"
+)
+
+// stageReport is the ledger stage of findings reported by a report-only review.
+const stageReport = "report"
+
+// runReport runs a report-only review: the external review tool once, when enabled, then a single
+// session fanning out the review_first agents. the verified findings are the JSON report (the findings
+// ledger) and a markdown report next to it; nothing is fixed or committed and there is no review loop.
+func (r *Runner) runReport(ctx context.Context) error {
+	if r.findings == nil {
+		return errors.New("report-only review requires a findings report path")
+	}
+	r.findings.startStage(stageReport)
+
+	tool := r.phases.external.Tool()
+	external, err := r.phases.external.Collect(ctx)
+	if err != nil {
+		return fmt.Errorf("external review: %w", err)
+	}
+
+	output, err := r.phases.review.Report(ctx, r.prompts.ReviewReportPrompt(tool, external))
+	if err != nil {
+		return fmt.Errorf("report-only review: %w", err)
+	}
+	if _, parseErr := phase.ParseFindingsPayload(output); errors.Is(parseErr, phase.ErrNoFindingsSignal) {
+		r.log.Print("warning: review reported no FINDINGS block, the report lists no findings")
+	}
+
+	mdPath, err := r.findings.report()
+	if err != nil {
+		return fmt.Errorf("write review report: %w", err)
+	}
+	r.log.Print("review report: %s, findings: %s", mdPath, r.findings.path)
+	r.log.Print("report-only review completed, no changes made")
+	return nil
+}
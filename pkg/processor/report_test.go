@@ -0,0 +1,85 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/findings"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestRunner_ReportOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "findings", "feature.json")
+	log := newRunnerMockLogger("progress.txt")
+	claude := newMockExecutor([]executor.Result{{Output: "one issue\n" + findingsOutput(
+		`[{"file": "b.go", "line": 1, "severity": "major", "agent": "codex", "verdict": "confirmed", "description": "leak"}]`)}})
+	codex := newMockExecutor([]executor.Result{{Output: "found leak in b.go:1"}})
+
+	cfg := Config{Mode: ModeReview, ReportOnly: true, MaxIterations: 50, IterationDelayMs: 1, CodexEnabled: true,
+		FindingsPath: path, Branch: "feature", AppConfig: testAppConfig(t)}
+	r := NewWithExecutors(cfg, log, Executors{Task: claude, External: codex}, &status.PhaseHolder{})
+	require.NoError(t, r.Run(t.Context()))
+
+	require.Len(t, claude.RunCalls(), 1, "single report session, no review loop")
+	require.Len(t, codex.RunCalls(), 1, "external review runs once, without evaluation")
+	prompt := claude.RunCalls()[0].Prompt
+	assert.Contains(t, prompt, "REPORT-ONLY review")
+	assert.Contains(t, prompt, "found leak in b.go:1")
+	assert.NotContains(t, prompt, "{{")
+
+	l, err := findings.Load(path)
+	require.NoError(t, err)
+	require.Len(t, l.Findings, 1)
+	assert.Equal(t, stageReport, l.Findings[0].Stage)
+	md := findings.ExportFile(path, findings.FormatMarkdown)
+	data, err := os.ReadFile(md) //nolint:gosec // test file
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "| confirmed | major | b.go:1 | codex | leak |  |")
+	assertLogArg(t, log, md)
+}
+
+func TestRunner_runReport(t *testing.T) {
+	newRunner := func(t *testing.T, review testReviewPhase, external testExternalReviewPhase) (*Runner, string) {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "feature.json")
+		cfg := Config{Mode: ModeReview, ReportOnly: true, FindingsPath: path, AppConfig: testAppConfig(t)}
+		r := NewWithExecutors(cfg, newRunnerMockLogger(""), Executors{Task: newMockExecutor(nil)}, &status.PhaseHolder{})
+		r.phases.review, r.phases.external = review, external
+		return r, path
+	}
+
+	t.Run("loop and fixes are skipped", func(t *testing.T) {
+		review := testReviewPhase{
+			firstFunc: func(context.Context) error { return errors.New("first called") },
+			loopFunc:  func(context.Context, string) error { return errors.New("loop called") },
+			reportFunc: func(_ context.Context, prompt string) (string, error) {
+				assert.NotContains(t, prompt, "external review tool")
+				return "no issues", nil
+			},
+		}
+		r, path := newRunner(t, review, testExternalReviewPhase{toolValue: "none"})
+		require.NoError(t, r.Run(t.Context()))
+		assert.FileExists(t, path)
+		assert.FileExists(t, findings.ExportFile(path, findings.FormatMarkdown))
+	})
+
+	t.Run("external review error", func(t *testing.T) {
+		external := testExternalReviewPhase{collectFunc: func(context.Context) (string, error) { return "", errors.New("boom") }}
+		r, _ := newRunner(t, testReviewPhase{}, external)
+		require.ErrorContains(t, r.Run(t.Context()), "external review: boom")
+	})
+
+	t.Run("review error", func(t *testing.T) {
+		review := testReviewPhase{reportFunc: func(context.Context, string) (string, error) { return "", errors.New("failed") }}
+		r, path := newRunner(t, review, testExternalReviewPhase{})
+		require.ErrorContains(t, r.Run(t.Context()), "report-only review: failed")
+		assert.NoFileExists(t, findings.ExportFile(path, findings.FormatMarkdown))
+	})
+}
@@ -2,6 +2,7 @@
 package processor
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
@@ -16,6 +17,12 @@ import (
 // DefaultIterationDelay is the pause between iterations to allow system to settle.
 const DefaultIterationDelay = 2 * time.Second
 
+// default validation gate settings, used when not set in config.
+const (
+	DefaultValidationTimeout    = 10 * time.Minute
+	DefaultValidationRetryCount = 3
+)
+
 // Mode represents the execution mode.
 type Mode string
 
@@ -46,6 +53,14 @@ type Config struct {
 	ExternalReviewToolSet bool           // when true, AppConfig.ExternalReviewTool is an explicit choice that overrides codex_enabled=false back-compat
 	FinalizeEnabled       bool           // whether finalize step is enabled
 	DefaultBranch         string         // default branch name (detected from repo)
+	ReviewTarget          string         // reviewed changes other than the current branch, e.g. "commit range a..b"; used as {{GOAL}}
+	Pipeline              []string       // stage order replacing the mode preset (empty = preset)
+	ReportOnly            bool           // review mode: report findings in a single read-only session, no fixes or commits
+	FindingsPath          string         // findings ledger of the review stages (empty = not recorded)
+	FindingsExport        []string       // formats the findings ledger is exported to when the run ends
+	Branch                string         // branch of the run, recorded in the findings ledger
+	RecordDir             string         // directory every executor session is recorded to (empty = not recorded)
+	ReplayDir             string         // directory of recorded sessions replayed instead of running executors
 	AppConfig             *config.Config // full application config (for executors and prompts)
 }
 
@@ -106,12 +121,17 @@ type GitChecker interface {
 // Executors groups the executor dependencies for the Runner.
 // Role-named: Task is used for the task phase, Review for review phases (nil = use Task),
 // External for the external review phase (nil = no external review), Custom is the
-// custom external review script executor.
+// custom external review script executor and Customs the custom_review_scripts ones, keyed by name.
+// HTTP is the OpenAI-compatible http review executor (nil when not configured).
 type Executors struct {
 	Task     Executor
 	Review   Executor // optional: separate executor for review phases (nil = use Task)
 	External Executor // external review executor (codex or wrapper); nil when Executor=codex or external review disabled
 	Custom   *executor.CustomExecutor
+	Customs  map[string]*executor.CustomExecutor
+	HTTP     *executor.HTTPExecutor
+	Budget   Executor                   // optional: cheaper executor switched to by budget_action = downgrade
+	ForModel func(spec string) Executor // optional: builds a task executor of a model[:effort] spec, for per-task overrides
 }
 
 // Runner orchestrates the execution loop.
@@ -121,6 +141,11 @@ type Runner struct {
 	phaseHolder *status.PhaseHolder
 	deps        *phase.Deps
 	phases      runnerPhases
+	usage       *usageTracker
+	findings    *findingsRecorder // nil when the findings ledger is disabled
+	prompts     *promptBuilder
+	hooks       *hookRunner  // nil when no lifecycle hook is configured
+	budget      *budgetGuard // nil when no budget limit is configured
 }
 
 type taskPhaseRunner interface {
@@ -134,11 +159,14 @@ type taskPlanValidator interface {
 type reviewPhaseRunner interface {
 	First(ctx context.Context) error
 	Loop(ctx context.Context, prefix string) error
+	Custom(ctx context.Context, name, prompt string) error
+	Report(ctx context.Context, prompt string) (string, error)
 }
 
 type externalReviewPhaseRunner interface {
 	Tool() string
 	Run(ctx context.Context) (phase.ExternalReviewOutcome, error)
+	Collect(ctx context.Context) (string, error)
 }
 
 type finalizePhaseRunner interface {
@@ -199,30 +227,73 @@ func NewWithExecutors(cfg Config, log Logger, execs Executors, holder *status.Ph
 		review = execs.Task
 	}
 
+	validator, validationRetries := newValidationGate(cfg)
+
+	task := execs.Task
+	var sw *modelSwitch
+	if execs.Budget != nil && cfg.AppConfig != nil {
+		sw = &modelSwitch{fallback: execs.Budget, model: cmp.Or(cfg.AppConfig.BudgetModel, cfg.ReviewModel)}
+	}
+	budget := newBudgetGuard(cfg, log, sw)
+	if budget != nil && budget.downgrade != nil {
+		task, review = sw.wrap(task), sw.wrap(review)
+	}
+	var modelExec func(spec string) phase.Executor
+	if execs.ForModel != nil {
+		models := map[string]Executor{}
+		modelExec = func(spec string) phase.Executor {
+			if e, ok := models[spec]; ok {
+				return e
+			}
+			e := execs.ForModel(spec)
+			if budget != nil && budget.downgrade != nil {
+				e = sw.wrap(e)
+			}
+			models[spec] = e
+			return e
+		}
+	}
+
 	locator := newPlanLocator(cfg)
-	policy := newRetryPolicy(retryPolicyOpts{cfg: cfg, log: log, waitOnLimit: waitOnLimit})
-	prompts := newPromptBuilder(promptBuilderOpts{cfg: cfg, log: log, locator: locator})
-	phaseCfg := toPhaseConfig(cfg)
+	usage := newUsageTracker()
+	findingsRec := newFindingsRecorder(cfg, log)
+	policy := newRetryPolicy(retryPolicyOpts{
+		cfg: cfg, log: log, waitOnLimit: waitOnLimit, usage: usage, holder: holder, budget: budget, findings: findingsRec,
+		tape: newSessionTape(cfg, log),
+	})
 	deps := &phase.Deps{}
+	prompts := newPromptBuilder(promptBuilderOpts{cfg: cfg, log: log, locator: locator, deps: deps})
+	phaseCfg := toPhaseConfig(cfg)
+	hooks := newHookRunner(cfg, log, deps, locator)
+	var taskHooks phase.TaskHooks
+	if hooks != nil {
+		taskHooks = hooks
+	}
+	var phaseBudget phase.Budget
+	if budget != nil {
+		phaseBudget = budget
+	}
 	breaks := phase.NewBreakController(deps)
 	git := phase.NewGitState(deps, log)
 	taskPhase := phase.NewTaskPhase(phase.TaskPhaseOpts{
-		Cfg: phaseCfg, Log: log, Exec: execs.Task, Policy: policy, Prompts: prompts,
-		Locator: locator, Deps: deps, Breaks: breaks, IterationDelay: iterDelay, RetryCount: retryCount,
+		Cfg: phaseCfg, Log: log, Exec: task, ModelExec: modelExec, Policy: policy, Prompts: prompts,
+		Locator: locator, Deps: deps, Breaks: breaks, Validator: validator, Usage: usage, Hooks: taskHooks, Budget: phaseBudget,
+		IterationDelay: iterDelay, RetryCount: retryCount, ValidationRetries: validationRetries,
 	})
 	reviewPhase := phase.NewReviewPhase(phase.ReviewPhaseOpts{
 		Cfg: phaseCfg, Log: log, Exec: review, Policy: policy, Prompts: prompts,
-		Git: git, PhaseHolder: holder, IterationDelay: iterDelay,
+		Git: git, PhaseHolder: holder, Budget: phaseBudget, IterationDelay: iterDelay,
 	})
 	externalPhase := phase.NewExternalReviewPhase(phase.ExternalReviewPhaseOpts{
-		Cfg: phaseCfg, Log: log, External: execs.External, Custom: execs.Custom, Review: review,
-		Policy: policy, Prompts: prompts, Breaks: breaks, Git: git, PhaseHolder: holder, IterationDelay: iterDelay,
+		Cfg: phaseCfg, Log: log, External: execs.External, Custom: execs.Custom, Customs: execs.Customs, HTTP: execs.HTTP, Review: review,
+		Policy: policy, Prompts: prompts, Breaks: breaks, Git: git, PhaseHolder: holder, Budget: phaseBudget,
+		IterationDelay: iterDelay,
 	})
 	finalizePhase := phase.NewFinalizePhase(phase.FinalizePhaseOpts{
 		Cfg: phaseCfg, Log: log, Exec: review, Policy: policy, Prompts: prompts, PhaseHolder: holder,
 	})
 	planCreationPhase := phase.NewPlanCreationPhase(phase.PlanCreationPhaseOpts{
-		Cfg: phaseCfg, Log: log, Exec: execs.Task, Policy: policy, Prompts: prompts,
+		Cfg: phaseCfg, Log: log, Exec: task, Policy: policy, Prompts: prompts,
 		Deps: deps, PhaseHolder: holder, IterationDelay: iterDelay,
 	})
 	phases := runnerPhases{
@@ -236,6 +307,11 @@ func NewWithExecutors(cfg Config, log Logger, execs Executors, holder *status.Ph
 		phaseHolder: holder,
 		deps:        deps,
 		phases:      phases,
+		usage:       usage,
+		findings:    findingsRec,
+		prompts:     prompts,
+		hooks:       hooks,
+		budget:      budget,
 	}
 }
 
@@ -274,169 +350,78 @@ func (r *Runner) SetPauseHandler(fn func(ctx context.Context) bool) {
 	r.deps.PauseHandler = fn
 }
 
-// Run executes the main loop based on configured mode.
-func (r *Runner) Run(ctx context.Context) error {
-	switch r.cfg.Mode {
-	case ModeFull:
-		return r.runFull(ctx)
-	case ModeReview:
-		return r.runReviewOnly(ctx)
-	case ModeCodexOnly:
-		return r.runCodexOnly(ctx)
-	case ModeTasksOnly:
-		return r.runTasksOnly(ctx)
-	case ModePlan:
-		if err := r.phases.planCreation.Run(ctx); err != nil {
-			if errors.Is(err, ErrUserRejectedPlan) {
-				return ErrUserRejectedPlan
-			}
-			return fmt.Errorf("plan creation phase: %w", err)
-		}
-		return nil
-	default:
-		return fmt.Errorf("unknown mode: %s", r.cfg.Mode)
+// Usage returns token and cost accounting accumulated so far, summed per phase and per task.
+func (r *Runner) Usage() UsageStats {
+	if r.usage == nil {
+		return UsageStats{}
 	}
+	return r.usage.stats()
 }
 
-// runFull executes the complete pipeline: tasks → review → codex → review.
-func (r *Runner) runFull(ctx context.Context) error {
-	if r.cfg.PlanFile == "" {
-		return errors.New("plan file required for full mode")
-	}
-	if err := r.phases.taskValidator.ValidatePlanHasTasks(); err != nil {
-		return fmt.Errorf("validate task plan: %w", err)
-	}
-
-	// phase 1: task execution
-	r.phaseHolder.Set(status.PhaseTask)
-	r.log.PrintRaw("starting task execution phase\n")
-
-	if err := r.phases.task.Run(ctx); err != nil {
-		if errors.Is(err, ErrUserAborted) {
-			r.log.Print("task phase aborted by user")
-			return ErrUserAborted
-		}
-		return fmt.Errorf("task phase: %w", err)
-	}
-
-	// phase 2: first review pass - address ALL findings
-	if err := r.phases.review.First(ctx); err != nil {
-		return fmt.Errorf("first review: %w", err)
-	}
-
-	// phase 2.1: review loop (critical/major) before external review
-	if err := r.phases.review.Loop(ctx, ""); err != nil {
-		return fmt.Errorf("pre-external review loop: %w", err)
-	}
-
-	// phase 2.5+3: external review → post-external review → finalize
-	if err := r.runExternalAndPostReview(ctx); err != nil {
-		return err
-	}
-
-	r.log.Print("all phases completed successfully")
-	return nil
-}
-
-// runReviewOnly executes only the review pipeline: review → external review → review.
-func (r *Runner) runReviewOnly(ctx context.Context) error {
-	// phase 1: first review
-	if err := r.phases.review.First(ctx); err != nil {
-		return fmt.Errorf("first review: %w", err)
+// Run executes the main loop based on configured mode.
+// usage summary is logged on return, regardless of the outcome.
+func (r *Runner) Run(ctx context.Context) error {
+	defer r.logUsage()
+	if r.findings != nil {
+		defer r.findings.export()
 	}
-
-	// phase 1.1: review loop (critical/major) before external review
-	if err := r.phases.review.Loop(ctx, ""); err != nil {
-		return fmt.Errorf("pre-external review loop: %w", err)
+	if r.hooks == nil {
+		return r.runMode(ctx)
 	}
 
-	// phase 2+3: external review → post-external review → finalize
-	if err := r.runExternalAndPostReview(ctx); err != nil {
-		return err
+	// phase hooks fire from phase transitions and stop the run by canceling its context
+	runCtx, fail := context.WithCancelCause(ctx)
+	defer fail(nil)
+	stopWatch := r.hooks.watchPhases(runCtx, r.phaseHolder, fail)
+	err := r.runMode(runCtx)
+	stopWatch()
+	if cause := context.Cause(runCtx); err != nil && errors.Is(cause, ErrHookFailed) {
+		err = cause
 	}
-
-	r.log.Print("review phases completed successfully")
-	return nil
+	return r.hooks.finish(ctx, r.phaseHolder.Get(), err)
 }
 
-// runCodexOnly executes only the external-review pipeline: external review → review → finalize.
-func (r *Runner) runCodexOnly(ctx context.Context) error {
-	if err := r.runExternalAndPostReview(ctx); err != nil {
-		return err
+// runMode runs plan creation or the stage pipeline of the configured mode.
+func (r *Runner) runMode(ctx context.Context) error {
+	if err := r.prompts.validatePrompts(); err != nil {
+		return fmt.Errorf("validate prompts: %w", err)
 	}
-
-	r.log.Print("codex phases completed successfully")
-	return nil
-}
-
-// runExternalAndPostReview runs the shared external-review → post-review → finalize pipeline.
-// used by runFull, runReviewOnly, and runCodexOnly to avoid duplicating this sequence.
-func (r *Runner) runExternalAndPostReview(ctx context.Context) error {
-	tool := r.phases.external.Tool()
-	if tool == "none" {
-		r.log.Print("external review disabled, skipping...")
-		if err := r.phases.finalize.Run(ctx); err != nil {
-			return fmt.Errorf("finalize phase: %w", err)
+	if r.cfg.Mode == ModePlan {
+		if err := r.phases.planCreation.Run(ctx); err != nil {
+			if errors.Is(err, ErrUserRejectedPlan) {
+				return ErrUserRejectedPlan
+			}
+			return fmt.Errorf("plan creation phase: %w", err)
 		}
 		return nil
 	}
-
-	r.phaseHolder.Set(status.PhaseCodex)
-	r.log.PrintSection(status.NewGenericSection(tool + " external review"))
-
-	outcome, err := r.phases.external.Run(ctx)
-	if err != nil {
-		return fmt.Errorf("%s loop: %w", tool, err)
+	if r.cfg.ReportOnly {
+		return r.runReport(ctx)
 	}
+	stages, done := r.pipeline()
+	return r.runPipeline(ctx, stages, done)
+}
 
-	if !outcome.HadFindings {
-		r.log.Print("external review found no issues, skipping post-%s claude review", tool)
-		if err := r.phases.finalize.Run(ctx); err != nil {
-			return fmt.Errorf("finalize phase: %w", err)
-		}
-		return nil
+// logUsage writes the per-phase and per-task usage summary to the progress log,
+// followed by the findings ledger summary when review stages ran.
+func (r *Runner) logUsage() {
+	for _, line := range r.Usage().summaryLines() {
+		r.log.Print("%s", line)
 	}
-
-	r.phaseHolder.Set(status.PhaseReview)
-
-	commitPrefix := "IMPORTANT: Before starting the review, run `git status`. " +
-		"If there are uncommitted changes from previous review phases, " +
-		"stage and commit them with message: " +
-		"`fix: address code review findings`\n" +
-		"Then continue with the sequence below.\n\n"
-	if err := r.phases.review.Loop(ctx, commitPrefix); err != nil {
-		return fmt.Errorf("post-external review loop: %w", err)
+	if r.findings == nil {
+		return
 	}
-
-	if err := r.phases.finalize.Run(ctx); err != nil {
-		return fmt.Errorf("finalize phase: %w", err)
+	if summary := r.findings.summary(); summary != "" {
+		r.log.Print("%s", summary)
 	}
-	return nil
 }
 
-// runTasksOnly executes only task phase, skipping all reviews.
-func (r *Runner) runTasksOnly(ctx context.Context) error {
-	if r.cfg.PlanFile == "" {
-		return errors.New("plan file required for tasks-only mode")
-	}
-	if err := r.phases.taskValidator.ValidatePlanHasTasks(); err != nil {
-		return fmt.Errorf("validate task plan: %w", err)
-	}
-
-	r.phaseHolder.Set(status.PhaseTask)
-	r.log.PrintRaw("starting task execution phase\n")
+// ErrBudgetExceeded is returned when a run budget limit stops the run.
+// the plan is left as is, so re-running it resumes from the first open task.
+var ErrBudgetExceeded = phase.ErrBudgetExceeded
 
-	if err := r.phases.task.Run(ctx); err != nil {
-		if errors.Is(err, ErrUserAborted) {
-			r.log.Print("task phase aborted by user")
-			return ErrUserAborted
-		}
-		return fmt.Errorf("task phase: %w", err)
-	}
-
-	r.log.Print("task execution completed successfully")
-	return nil
-}
+// ErrHookFailed is returned when a lifecycle hook exits non-zero and vetoes or fails the run.
+var ErrHookFailed = phase.ErrHookFailed
 
 // ErrUserAborted is a sentinel error returned when the user aborts or declines to resume after a break
 // signal (Ctrl+\). it is propagated as a non-nil error so that callers (including mode entrypoints) can
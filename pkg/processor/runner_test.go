@@ -3,6 +3,7 @@ package processor
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -68,8 +69,10 @@ func (p testTaskPhase) ValidatePlanHasTasks() error {
 }
 
 type testReviewPhase struct {
-	firstFunc func(ctx context.Context) error
-	loopFunc  func(ctx context.Context, prefix string) error
+	firstFunc  func(ctx context.Context) error
+	loopFunc   func(ctx context.Context, prefix string) error
+	customFunc func(ctx context.Context, name, prompt string) error
+	reportFunc func(ctx context.Context, prompt string) (string, error)
 }
 
 func (p testReviewPhase) First(ctx context.Context) error {
@@ -86,11 +89,26 @@ func (p testReviewPhase) Loop(ctx context.Context, prefix string) error {
 	return p.loopFunc(ctx, prefix)
 }
 
+func (p testReviewPhase) Custom(ctx context.Context, name, prompt string) error {
+	if p.customFunc == nil {
+		return nil
+	}
+	return p.customFunc(ctx, name, prompt)
+}
+
+func (p testReviewPhase) Report(ctx context.Context, prompt string) (string, error) {
+	if p.reportFunc == nil {
+		return "", nil
+	}
+	return p.reportFunc(ctx, prompt)
+}
+
 type testExternalReviewPhase struct {
 	toolValue   string
 	hadFindings bool
 	runErr      error
 	runFunc     func(ctx context.Context) error
+	collectFunc func(ctx context.Context) (string, error)
 }
 
 func (p testExternalReviewPhase) Tool() string {
@@ -109,6 +127,13 @@ func (p testExternalReviewPhase) Run(ctx context.Context) (phase.ExternalReviewO
 	return phase.ExternalReviewOutcome{HadFindings: p.hadFindings}, p.runErr
 }
 
+func (p testExternalReviewPhase) Collect(ctx context.Context) (string, error) {
+	if p.collectFunc == nil {
+		return "", nil
+	}
+	return p.collectFunc(ctx)
+}
+
 type testFinalizePhase struct {
 	runFunc func(ctx context.Context) error
 }
@@ -402,6 +427,35 @@ func TestRunner_RunTasksOnly_Success(t *testing.T) {
 	assert.Len(t, claude.RunCalls(), 1)
 }
 
+func TestRunner_Usage_AggregatesPerPhaseAndTask(t *testing.T) {
+	tmpDir := t.TempDir()
+	planFile := filepath.Join(tmpDir, "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n### Task 1: first\n- [x] done"), 0o600))
+
+	var lines []string
+	log := newRunnerMockLogger("progress.txt")
+	log.PrintFunc = func(format string, args ...any) { lines = append(lines, fmt.Sprintf(format, args...)) }
+	usage := executor.Usage{InputTokens: 100, OutputTokens: 20, CacheReadTokens: 3000, CostUSD: 0.12}
+	claude := newMockExecutor([]executor.Result{
+		{Error: &executor.LimitPatternError{Pattern: "limit", HelpCmd: "usage"}, Usage: usage},
+		{Output: "task done", Signal: status.Completed, Usage: usage},
+	})
+
+	appCfg := testAppConfig(t)
+	appCfg.WaitOnLimit = time.Millisecond
+	cfg := Config{Mode: ModeTasksOnly, PlanFile: planFile, MaxIterations: 5, AppConfig: appCfg}
+	r := NewWithExecutors(cfg, log, Executors{Task: claude}, &status.PhaseHolder{})
+	require.NoError(t, r.Run(t.Context()))
+
+	want := usage.Add(usage)
+	stats := r.Usage()
+	assert.Equal(t, want, stats.Total, "usage of the limit-retried session counts too")
+	assert.Equal(t, map[status.Phase]executor.Usage{status.PhaseTask: want}, stats.Phases)
+	assert.Equal(t, map[int]executor.Usage{1: want}, stats.Tasks)
+	assert.Contains(t, lines, "usage total: "+want.String())
+	assert.Contains(t, lines, "usage by task: task 1 ("+want.String()+")")
+}
+
 func TestRunner_RunTasksOnly_NoPlanFile(t *testing.T) {
 	log := newRunnerMockLogger("")
 	claude := newMockExecutor(nil)
@@ -0,0 +1,251 @@
+package processor
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/processor/phase"
+)
+
+// sessionRecord is one executor session saved by --record, a JSON file in the record directory.
+type sessionRecord struct {
+	Seq      int            `json:"seq"`
+	Tool     string         `json:"tool"` // executor of the session: claude, codex, http, custom or custom:<name>
+	Phase    string         `json:"phase"`
+	Prompt   string         `json:"prompt"`
+	TimedOut bool           `json:"timed_out"` // session or idle timeout, retried like a failed session
+	Result   recordedResult `json:"result"`
+}
+
+// recordedResult is the executor.Result of a recorded session. Output is the text parsed from the
+// session's stream, the raw executor stream is not recorded.
+type recordedResult struct {
+	Output       string         `json:"output"`
+	RecentText   string         `json:"recent_text,omitempty"`
+	Signal       string         `json:"signal,omitempty"`
+	Error        *recordedError `json:"error,omitempty"`
+	IdleTimedOut bool           `json:"idle_timed_out,omitempty"`
+	Usage        executor.Usage `json:"usage"`
+	SessionID    string         `json:"session_id,omitempty"`
+}
+
+// recordedError keeps the type of a session error, the retry policy and phases act on the pattern
+// error types and context errors.
+type recordedError struct {
+	Kind    string `json:"kind"` // pattern, limit, retry, canceled, deadline or error
+	Message string `json:"message"`
+	Pattern string `json:"pattern,omitempty"`
+	HelpCmd string `json:"help_cmd,omitempty"`
+}
+
+// sessionTape records the executor sessions of a run to a directory, or replays recorded sessions
+// in place of the executors. replayed sessions of a tool are served in recorded order, so the
+// parallel external review tools get their own sessions back. safe for concurrent use.
+type sessionTape struct {
+	dir    string
+	replay bool
+	log    Logger
+
+	mu      sync.Mutex
+	seq     int                        // last recorded session
+	loaded  bool                       // replay recordings read from dir
+	loadErr error                      // error reading the recordings, returned by every replayed session
+	queues  map[string][]sessionRecord // recordings not replayed yet, per tool
+}
+
+// newSessionTape returns a tape replaying cfg.ReplayDir or recording to cfg.RecordDir,
+// nil when neither is set.
+func newSessionTape(cfg Config, log Logger) *sessionTape {
+	switch {
+	case cfg.ReplayDir != "":
+		return &sessionTape{dir: cfg.ReplayDir, replay: true, log: log}
+	case cfg.RecordDir != "":
+		return &sessionTape{dir: cfg.RecordDir, log: log}
+	}
+	return nil
+}
+
+// session runs a session through run and records it, or returns the next recorded session of tool when replaying.
+func (t *sessionTape) session(ctx context.Context, tool, phaseName, prompt string,
+	run func() phase.ExecutionResult) phase.ExecutionResult {
+	if t.replay {
+		return t.next(ctx, tool, prompt)
+	}
+	result := run()
+	t.record(sessionRecord{Tool: tool, Phase: phaseName, Prompt: prompt, TimedOut: result.TimedOut,
+		Result: recordResult(result.Result)})
+	return result
+}
+
+func (t *sessionTape) record(rec sessionRecord) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.seq++
+	rec.Seq = t.seq
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err == nil {
+		name := fmt.Sprintf("%04d-%s.json", rec.Seq, strings.ReplaceAll(rec.Tool, ":", "-"))
+		err = os.WriteFile(filepath.Join(t.dir, name), data, 0o600)
+	}
+	if err != nil {
+		t.log.Print("warning: failed to record %s session %d: %v", rec.Tool, rec.Seq, err)
+	}
+}
+
+// next returns the next recorded session of tool. a prompt different from the recorded one is
+// logged and replayed anyway, which allows re-running recordings against modified prompts.
+func (t *sessionTape) next(ctx context.Context, tool, prompt string) phase.ExecutionResult {
+	if err := ctx.Err(); err != nil {
+		return phase.ExecutionResult{Result: executor.Result{Error: err}}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.loaded {
+		t.queues, t.loadErr = loadSessionRecords(t.dir)
+		t.loaded = true
+	}
+	if t.loadErr != nil {
+		return phase.ExecutionResult{Result: executor.Result{Error: t.loadErr}}
+	}
+	queue := t.queues[tool]
+	if len(queue) == 0 {
+		return phase.ExecutionResult{Result: executor.Result{
+			Error: fmt.Errorf("replay: no recorded %s session left in %s", tool, t.dir)}}
+	}
+	rec := queue[0]
+	t.queues[tool] = queue[1:]
+	t.log.Print("replaying %s session %d from %s", tool, rec.Seq, t.dir)
+	if rec.Prompt != prompt {
+		t.log.Print("replay: %s session %d prompt differs from the recorded one", tool, rec.Seq)
+	}
+	t.log.PrintAligned(rec.Result.Output)
+	return phase.ExecutionResult{Result: rec.Result.result(), TimedOut: rec.TimedOut}
+}
+
+// PrepareRecordDir creates the --record directory and checks that it holds no recordings of another run.
+func PrepareRecordDir(dir string) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("create record directory: %w", err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return fmt.Errorf("list record directory: %w", err)
+	}
+	if len(files) > 0 {
+		return fmt.Errorf("record directory %s already has recorded sessions, use an empty directory", dir)
+	}
+	return nil
+}
+
+// CheckReplayDir checks that the replay directory holds readable recorded sessions.
+func CheckReplayDir(dir string) error {
+	queues, err := loadSessionRecords(dir)
+	if err != nil {
+		return err
+	}
+	if len(queues) == 0 {
+		return fmt.Errorf("replay directory %s has no recorded sessions", dir)
+	}
+	return nil
+}
+
+// loadSessionRecords reads the recorded sessions of dir, grouped per tool in recorded order.
+func loadSessionRecords(dir string) (map[string][]sessionRecord, error) {
+	if _, err := os.Stat(dir); err != nil {
+		return nil, fmt.Errorf("replay directory: %w", err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("list replay directory: %w", err)
+	}
+	records := make([]sessionRecord, 0, len(files))
+	for _, f := range files {
+		data, readErr := os.ReadFile(f) //nolint:gosec // recordings of the user's replay directory
+		if readErr != nil {
+			return nil, fmt.Errorf("read recorded session: %w", readErr)
+		}
+		var rec sessionRecord
+		if jsonErr := json.Unmarshal(data, &rec); jsonErr != nil {
+			return nil, fmt.Errorf("parse recorded session %s: %w", f, jsonErr)
+		}
+		if rec.Tool == "" {
+			return nil, fmt.Errorf("recorded session %s has no tool", f)
+		}
+		records = append(records, rec)
+	}
+	slices.SortStableFunc(records, func(a, b sessionRecord) int { return cmp.Compare(a.Seq, b.Seq) })
+	queues := map[string][]sessionRecord{}
+	for _, rec := range records {
+		queues[rec.Tool] = append(queues[rec.Tool], rec)
+	}
+	return queues, nil
+}
+
+func recordResult(r executor.Result) recordedResult {
+	return recordedResult{Output: r.Output, RecentText: r.RecentText, Signal: r.Signal, Error: recordError(r.Error),
+		IdleTimedOut: r.IdleTimedOut, Usage: r.Usage, SessionID: r.SessionID}
+}
+
+func recordError(err error) *recordedError {
+	if err == nil {
+		return nil
+	}
+	rec := &recordedError{Kind: "error", Message: err.Error()}
+	if e, ok := errors.AsType[*executor.PatternMatchError](err); ok {
+		rec.Kind, rec.Pattern, rec.HelpCmd = "pattern", e.Pattern, e.HelpCmd
+	}
+	if e, ok := errors.AsType[*executor.LimitPatternError](err); ok {
+		rec.Kind, rec.Pattern, rec.HelpCmd = "limit", e.Pattern, e.HelpCmd
+	}
+	if e, ok := errors.AsType[*executor.RetryPatternError](err); ok {
+		rec.Kind, rec.Pattern = "retry", e.Pattern
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		rec.Kind = "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		rec.Kind = "deadline"
+	}
+	return rec
+}
+
+// result rebuilds the recorded executor.Result with an error of the recorded type.
+func (r recordedResult) result() executor.Result {
+	res := executor.Result{Output: r.Output, RecentText: r.RecentText, Signal: r.Signal, IdleTimedOut: r.IdleTimedOut,
+		Usage: r.Usage, SessionID: r.SessionID}
+	if r.Error == nil {
+		return res
+	}
+	switch r.Error.Kind {
+	case "pattern":
+		res.Error = &executor.PatternMatchError{Pattern: r.Error.Pattern, HelpCmd: r.Error.HelpCmd}
+	case "limit":
+		res.Error = &executor.LimitPatternError{Pattern: r.Error.Pattern, HelpCmd: r.Error.HelpCmd}
+	case "retry":
+		res.Error = &executor.RetryPatternError{Pattern: r.Error.Pattern}
+	case "canceled":
+		res.Error = wrapRecorded(r.Error.Message, context.Canceled)
+	case "deadline":
+		res.Error = wrapRecorded(r.Error.Message, context.DeadlineExceeded)
+	default:
+		res.Error = errors.New(r.Error.Message)
+	}
+	return res
+}
+
+// wrapRecorded returns target annotated with the recorded message, target itself when the message is its own.
+func wrapRecorded(msg string, target error) error {
+	if msg == target.Error() {
+		return target
+	}
+	return fmt.Errorf("%s: %w", strings.TrimSuffix(msg, ": "+target.Error()), target)
+}
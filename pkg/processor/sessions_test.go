@@ -0,0 +1,208 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
+	"github.com/umputun/ralphex/pkg/processor/phase"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestRunner_RecordAndReplay(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n### Task 1: first\n- [x] done"), 0o600))
+	recordDir := filepath.Join(t.TempDir(), "sessions")
+	require.NoError(t, PrepareRecordDir(recordDir))
+
+	claude := newMockExecutor([]executor.Result{
+		{Output: "task done", Signal: status.Completed, Usage: executor.Usage{InputTokens: 100, CostUSD: 0.5}},
+		{Output: "review done", Signal: status.ReviewDone},
+		{Output: "review done", Signal: status.ReviewDone},
+		{Output: "fixed issues"},
+		{Output: "done", Signal: status.CodexDone},
+		{Output: "review done", Signal: status.ReviewDone},
+	})
+	codex := newMockExecutor([]executor.Result{{Output: "found issue in foo.go"}, {Output: "no issues found"}})
+	cfg := Config{Mode: ModeFull, PlanFile: planFile, MaxIterations: 50, IterationDelayMs: 1, CodexEnabled: true,
+		AppConfig: testAppConfig(t), RecordDir: recordDir}
+	r := NewWithExecutors(cfg, newRunnerMockLogger("progress.txt"), Executors{Task: claude, External: codex},
+		&status.PhaseHolder{})
+	require.NoError(t, r.Run(t.Context()))
+
+	files, err := filepath.Glob(filepath.Join(recordDir, "*.json"))
+	require.NoError(t, err)
+	assert.Len(t, files, 8, "every claude and codex session recorded")
+	assert.FileExists(t, filepath.Join(recordDir, "0001-claude.json"))
+	require.NoError(t, CheckReplayDir(recordDir))
+
+	// replay runs the same state machine without calling the executors
+	failing := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+		t.Fatal("executor called during replay")
+		return executor.Result{}
+	}}
+	log := newRunnerMockLogger("progress.txt")
+	cfg.RecordDir, cfg.ReplayDir = "", recordDir
+	r = NewWithExecutors(cfg, log, Executors{Task: failing, External: failing}, &status.PhaseHolder{})
+	require.NoError(t, r.Run(t.Context()))
+	assert.InDelta(t, 0.5, r.Usage().Total.CostUSD, 0.001, "recorded usage replayed")
+
+	var replayed int
+	for _, call := range log.PrintCalls() {
+		if strings.HasPrefix(call.Format, "replaying %s session") {
+			replayed++
+		}
+		assert.NotContains(t, call.Format, "prompt differs")
+	}
+	assert.Equal(t, 8, replayed)
+
+	var aligned []string
+	for _, call := range log.PrintAlignedCalls() {
+		aligned = append(aligned, call.Text)
+	}
+	assert.Contains(t, aligned, "found issue in foo.go", "recorded output shown while replaying")
+}
+
+func TestSessionTape_ReplayPerTool(t *testing.T) {
+	dir := t.TempDir()
+	rec := &sessionTape{dir: dir, log: newMockLogger()}
+	runOutput := func(output string) func() phase.ExecutionResult {
+		return func() phase.ExecutionResult { return phase.ExecutionResult{Result: executor.Result{Output: output}} }
+	}
+	rec.session(t.Context(), "claude", "task", "p1", runOutput("claude 1"))
+	rec.session(t.Context(), "custom:gemini", "codex", "p2", runOutput("gemini 1"))
+	rec.session(t.Context(), "codex", "codex", "p3", runOutput("codex 1"))
+	rec.session(t.Context(), "claude", "codex", "p4", runOutput("claude 2"))
+	assert.FileExists(t, filepath.Join(dir, "0002-custom-gemini.json"))
+
+	log := newMockLogger()
+	replay := &sessionTape{dir: dir, replay: true, log: log}
+	notCalled := func() phase.ExecutionResult {
+		t.Fatal("run called during replay")
+		return phase.ExecutionResult{}
+	}
+	// parallel external tools may ask in any order, each gets its own sessions back
+	assert.Equal(t, "codex 1", replay.session(t.Context(), "codex", "codex", "p3", notCalled).Result.Output)
+	assert.Equal(t, "claude 1", replay.session(t.Context(), "claude", "task", "p1", notCalled).Result.Output)
+	assert.Equal(t, "gemini 1", replay.session(t.Context(), "custom:gemini", "codex", "p2", notCalled).Result.Output)
+	assert.Equal(t, "claude 2", replay.session(t.Context(), "claude", "codex", "changed", notCalled).Result.Output)
+	assertLogContains(t, log, "prompt differs from the recorded one")
+
+	res := replay.session(t.Context(), "claude", "codex", "p5", notCalled)
+	require.ErrorContains(t, res.Result.Error, "replay: no recorded claude session left")
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	res = replay.session(ctx, "codex", "codex", "p3", notCalled)
+	require.ErrorIs(t, res.Result.Error, context.Canceled)
+}
+
+func TestSessionTape_ResultRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		result phase.ExecutionResult
+		check  func(t *testing.T, got phase.ExecutionResult)
+	}{
+		{name: "signal and usage", result: phase.ExecutionResult{Result: executor.Result{Output: "out", RecentText: "recent",
+			Signal: status.Completed, Usage: executor.Usage{InputTokens: 10, OutputTokens: 5, CostUSD: 0.1}, SessionID: "s1"}},
+			check: func(t *testing.T, got phase.ExecutionResult) {
+				t.Helper()
+				assert.Equal(t, executor.Result{Output: "out", RecentText: "recent", Signal: status.Completed,
+					Usage: executor.Usage{InputTokens: 10, OutputTokens: 5, CostUSD: 0.1}, SessionID: "s1"}, got.Result)
+			}},
+		{name: "timed out", result: phase.ExecutionResult{Result: executor.Result{Output: "partial", IdleTimedOut: true},
+			TimedOut: true},
+			check: func(t *testing.T, got phase.ExecutionResult) {
+				t.Helper()
+				assert.True(t, got.TimedOut)
+				assert.True(t, got.Result.IdleTimedOut)
+			}},
+		{name: "pattern error", result: phase.ExecutionResult{Result: executor.Result{
+			Error: &executor.PatternMatchError{Pattern: "hit your limit", HelpCmd: "claude /usage"}}},
+			check: func(t *testing.T, got phase.ExecutionResult) {
+				t.Helper()
+				var e *executor.PatternMatchError
+				require.ErrorAs(t, got.Result.Error, &e)
+				assert.Equal(t, executor.PatternMatchError{Pattern: "hit your limit", HelpCmd: "claude /usage"}, *e)
+			}},
+		{name: "limit error", result: phase.ExecutionResult{Result: executor.Result{
+			Error: fmt.Errorf("wrapped: %w", &executor.LimitPatternError{Pattern: "rate limit", HelpCmd: "codex /status"})}},
+			check: func(t *testing.T, got phase.ExecutionResult) {
+				t.Helper()
+				var e *executor.LimitPatternError
+				require.ErrorAs(t, got.Result.Error, &e)
+				assert.Equal(t, "rate limit", e.Pattern)
+			}},
+		{name: "retry error", result: phase.ExecutionResult{Result: executor.Result{
+			Error: &executor.RetryPatternError{Pattern: "stream disconnected"}}},
+			check: func(t *testing.T, got phase.ExecutionResult) {
+				t.Helper()
+				var e *executor.RetryPatternError
+				require.ErrorAs(t, got.Result.Error, &e)
+			}},
+		{name: "canceled", result: phase.ExecutionResult{Result: executor.Result{Error: context.Canceled}},
+			check: func(t *testing.T, got phase.ExecutionResult) {
+				t.Helper()
+				require.ErrorIs(t, got.Result.Error, context.Canceled)
+				assert.Equal(t, "context canceled", got.Result.Error.Error())
+			}},
+		{name: "deadline", result: phase.ExecutionResult{Result: executor.Result{
+			Error: fmt.Errorf("claude exited with error: %w", context.DeadlineExceeded)}},
+			check: func(t *testing.T, got phase.ExecutionResult) {
+				t.Helper()
+				require.ErrorIs(t, got.Result.Error, context.DeadlineExceeded)
+				assert.Equal(t, "claude exited with error: context deadline exceeded", got.Result.Error.Error())
+			}},
+		{name: "plain error", result: phase.ExecutionResult{Result: executor.Result{Error: errors.New("exit status 1")}},
+			check: func(t *testing.T, got phase.ExecutionResult) {
+				t.Helper()
+				require.EqualError(t, got.Result.Error, "exit status 1")
+			}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			(&sessionTape{dir: dir, log: newMockLogger()}).session(t.Context(), "claude", "task", "prompt",
+				func() phase.ExecutionResult { return tc.result })
+			replay := &sessionTape{dir: dir, replay: true, log: newMockLogger()}
+			tc.check(t, replay.session(t.Context(), "claude", "task", "prompt", nil))
+		})
+	}
+}
+
+func TestPrepareRecordDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "a", "b")
+	require.NoError(t, PrepareRecordDir(dir))
+	assert.DirExists(t, dir)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0001-claude.json"), []byte("{}"), 0o600))
+	require.ErrorContains(t, PrepareRecordDir(dir), "already has recorded sessions")
+}
+
+func TestCheckReplayDir(t *testing.T) {
+	t.Run("missing", func(t *testing.T) {
+		require.ErrorContains(t, CheckReplayDir(filepath.Join(t.TempDir(), "missing")), "replay directory")
+	})
+	t.Run("empty", func(t *testing.T) {
+		require.ErrorContains(t, CheckReplayDir(t.TempDir()), "has no recorded sessions")
+	})
+	t.Run("corrupt", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "0001-claude.json"), []byte("not json"), 0o600))
+		require.ErrorContains(t, CheckReplayDir(dir), "parse recorded session")
+	})
+	t.Run("no tool", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "0001.json"), []byte(`{"seq": 1}`), 0o600))
+		require.ErrorContains(t, CheckReplayDir(dir), "has no tool")
+	})
+}
@@ -0,0 +1,138 @@
+package processor
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/plan"
+)
+
+// gitHistory is implemented by git checkers able to list the branch changes, e.g. git.Service.
+type gitHistory interface {
+	ChangedFiles(baseRef string) ([]string, error)
+	CommitLog(baseRef string) ([]string, error)
+}
+
+// taskContext resolves the task-scoped prompt tokens: {{TASK_NUMBER}}, {{TASK_TITLE}}, {{TASK_BODY}},
+// {{COMPLETED_TASKS}}, {{CHANGED_FILES}} and {{COMMIT_LOG}}. the plan is parsed on first use and
+// git is only asked for the tokens a prompt actually uses.
+type taskContext struct {
+	b      *promptBuilder
+	pos    int // 1-indexed plan task, zero for the first task with open work
+	parsed bool
+	tasks  []plan.Task
+}
+
+// task returns the task of the context, false when the plan has no task with open work.
+func (c *taskContext) task() (plan.Task, bool) {
+	tasks := c.planTasks()
+	if c.pos > 0 && c.pos <= len(tasks) {
+		return tasks[c.pos-1], true
+	}
+	for _, t := range tasks {
+		if t.HasUncompletedActionableWork() {
+			return t, true
+		}
+	}
+	return plan.Task{}, false
+}
+
+func (c *taskContext) number() string {
+	if t, ok := c.task(); ok {
+		return t.Label()
+	}
+	return ""
+}
+
+func (c *taskContext) title() string {
+	if t, ok := c.task(); ok {
+		return t.Title
+	}
+	return ""
+}
+
+func (c *taskContext) body() string {
+	if t, ok := c.task(); ok {
+		return t.Body
+	}
+	return ""
+}
+
+// completedTasks lists the tasks without open work as "Task N: title" lines.
+func (c *taskContext) completedTasks() string {
+	var lines []string
+	for _, t := range c.planTasks() {
+		if len(t.Checkboxes) > 0 && !t.HasUncompletedActionableWork() {
+			lines = append(lines, "Task "+t.Label()+": "+t.Title)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// changedFiles lists the files changed on the branch since the default branch, one per line.
+func (c *taskContext) changedFiles() string {
+	git := c.b.gitHistory()
+	if git == nil {
+		return ""
+	}
+	files, err := git.ChangedFiles(c.b.getDefaultBranch())
+	if err != nil {
+		c.b.warn("failed to list changed files for prompt: %v", err)
+		return ""
+	}
+	return strings.Join(files, "\n")
+}
+
+// commitLog lists the commits on the branch since the default branch, oldest first.
+func (c *taskContext) commitLog() string {
+	git := c.b.gitHistory()
+	if git == nil {
+		return ""
+	}
+	commits, err := git.CommitLog(c.b.getDefaultBranch())
+	if err != nil {
+		c.b.warn("failed to read commit log for prompt: %v", err)
+		return ""
+	}
+	return strings.Join(commits, "\n")
+}
+
+func (c *taskContext) planTasks() []plan.Task {
+	if c.parsed {
+		return c.tasks
+	}
+	c.parsed = true
+	path := c.b.locator.Path()
+	if path == "" {
+		return nil
+	}
+	parsed, err := plan.ParsePlanFile(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			c.b.warn("failed to parse plan file for task variables: %v", err)
+		}
+		return nil
+	}
+	c.tasks = parsed.Tasks
+	return c.tasks
+}
+
+// gitHistory returns the git checker of the runner when it can list branch changes.
+func (b *promptBuilder) gitHistory() gitHistory {
+	if b.deps == nil || b.deps.Git == nil {
+		return nil
+	}
+	git, ok := b.deps.Git.(gitHistory)
+	if !ok {
+		return nil
+	}
+	return git
+}
+
+// warn logs a warning when the builder has a logger.
+func (b *promptBuilder) warn(format string, args ...any) {
+	if b.log != nil {
+		b.log.Print("[WARN] "+format, args...)
+	}
+}
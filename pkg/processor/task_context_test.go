@@ -0,0 +1,94 @@
+package processor
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/processor/phase"
+)
+
+// historyGit is a git checker listing branch changes, counting the calls.
+type historyGit struct {
+	files, commits []string
+	err            error
+	calls          int
+}
+
+func (g *historyGit) HeadHash() (string, error)        { return "abc", nil }
+func (g *historyGit) DiffFingerprint() (string, error) { return "fp", nil }
+func (g *historyGit) ChangedFiles(string) ([]string, error) {
+	g.calls++
+	return g.files, g.err
+}
+func (g *historyGit) CommitLog(string) ([]string, error) {
+	g.calls++
+	return g.commits, g.err
+}
+
+func TestPromptBuilder_TaskTokens(t *testing.T) {
+	planFile := filepath.Join(t.TempDir(), "plan.md")
+	require.NoError(t, os.WriteFile(planFile, []byte("# Plan\n## Overview\ntext\n"+
+		"### Task 1: setup\n- [x] init\n\n### Task 2: build\n- [ ] build it\n- [ ] test it\n\n"+
+		"### Task 2.5: polish\n- [ ] polish\n\n## Success criteria\n- [ ] works\n"), 0o600))
+	git := &historyGit{files: []string{"a.go", "b.go"}, commits: []string{"1234567 feat: setup"}}
+	newBuilder := func(appCfg *config.Config, planFile string, git phase.GitChecker) *promptBuilder {
+		cfg := Config{PlanFile: planFile, DefaultBranch: "main", AppConfig: appCfg}
+		return newPromptBuilder(promptBuilderOpts{cfg: cfg, log: newMockLogger(), deps: &phase.Deps{Git: git}})
+	}
+	const all = "{{TASK_NUMBER}}|{{TASK_TITLE}}|{{TASK_BODY}}|{{COMPLETED_TASKS}}|{{CHANGED_FILES}}|{{COMMIT_LOG}}"
+
+	t.Run("first task with open work", func(t *testing.T) {
+		got, err := newBuilder(&config.Config{}, planFile, git).renderTemplate(all, renderOpts{})
+		require.NoError(t, err)
+		assert.Equal(t, "2|build|### Task 2: build\n- [ ] build it\n- [ ] test it|Task 1: setup|a.go\nb.go|1234567 feat: setup", got)
+	})
+
+	t.Run("task position", func(t *testing.T) {
+		got, err := newBuilder(&config.Config{}, planFile, git).renderTemplate(all, renderOpts{taskPos: 3})
+		require.NoError(t, err)
+		assert.Equal(t, "2.5|polish|### Task 2.5: polish\n- [ ] polish|Task 1: setup|a.go\nb.go|1234567 feat: setup", got)
+	})
+
+	t.Run("no plan and no git", func(t *testing.T) {
+		got, err := newBuilder(&config.Config{}, "", nil).renderTemplate(all, renderOpts{})
+		require.NoError(t, err)
+		assert.Equal(t, "|||||", got)
+	})
+
+	t.Run("git error is logged", func(t *testing.T) {
+		b := newBuilder(&config.Config{}, planFile, &historyGit{err: errors.New("bad ref")})
+		log := newMockLogger()
+		b.log = log
+		got, err := b.renderTemplate("[{{CHANGED_FILES}}]", renderOpts{})
+		require.NoError(t, err)
+		assert.Equal(t, "[]", got)
+		assertLogContains(t, log, "failed to list changed files")
+	})
+
+	t.Run("git is asked only for used tokens", func(t *testing.T) {
+		git := &historyGit{}
+		_, err := newBuilder(&config.Config{}, planFile, git).renderTemplate("{{TASK_TITLE}} {{PLAN_FILE}}", renderOpts{})
+		require.NoError(t, err)
+		assert.Zero(t, git.calls)
+	})
+
+	t.Run("default task prompt", func(t *testing.T) {
+		prompt := newBuilder(testAppConfig(t), planFile, git).TaskPrompt(0)
+		assert.Contains(t, prompt, "Your task for this iteration is Task 2 (build) of the plan file at "+planFile)
+		assert.Contains(t, prompt, "### Task 2: build\n- [ ] build it\n- [ ] test it\n")
+		assert.Contains(t, prompt, "Already completed task sections (do not redo them):\nTask 1: setup\n")
+		assert.Contains(t, prompt, "Files changed on this branch so far:\na.go\nb.go\n")
+		assert.NotContains(t, prompt, "Find the FIRST Task section")
+		assert.NotContains(t, prompt, "{{")
+
+		prompt = newBuilder(testAppConfig(t), "", nil).TaskPrompt(0)
+		assert.Contains(t, prompt, "Find the FIRST Task section")
+		assert.NotContains(t, prompt, "Your task for this iteration")
+	})
+}
@@ -0,0 +1,195 @@
+package processor
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// promptTokens are the backward-compatible {{NAME}} variables of prompt templates. each is a template
+// function; a token without a value in the rendered prompt is kept as-is, like before the template engine.
+var promptTokens = []string{"PLAN_FILE", "PROGRESS_FILE", "GOAL", "DEFAULT_BRANCH", "PLANS_DIR",
+	"DIFF_INSTRUCTION", "PREVIOUS_REVIEW_CONTEXT", "CODEX_OUTPUT", "CUSTOM_OUTPUT", "PLAN_DESCRIPTION",
+	"REVIEW_AGENTS", "EXTERNAL_REVIEW", "TASK_NUMBER", "TASK_TITLE", "TASK_BODY", "COMPLETED_TASKS",
+	"CHANGED_FILES", "COMMIT_LOG"}
+
+// promptData is the data of prompt templates, e.g. {{if .IsCodex}}, {{range .Agents}}, {{.Vars.name}}.
+type promptData struct {
+	IsCodex       bool // codex executor, agents expand to spawn_agent calls
+	HasPlan       bool // run has a plan file
+	PlanFile      string
+	ProgressFile  string
+	Goal          string
+	DefaultBranch string
+	PlansDir      string
+	Agents        []promptAgent     // configured review agents, {{agent .Name}} expands one
+	Vars          map[string]string // [prompt_vars] of the config, an undefined name fails the render
+}
+
+// promptAgent is a review agent as seen by prompt templates.
+type promptAgent struct {
+	Name      string
+	Model     string
+	AgentType string
+}
+
+// renderOpts are the per-prompt inputs of renderTemplate.
+type renderOpts struct {
+	name     string            // template name used in errors, e.g. task.txt
+	values   map[string]string // values of the phase-specific tokens, e.g. CODEX_OUTPUT
+	noAgents bool              // keep {{agent:name}} references as-is, for agent bodies and base-only rendering
+	taskPos  int               // 1-indexed plan task of the task-scoped tokens, zero for the first task with open work
+}
+
+// renderTemplate renders prompt as a text/template. the legacy {{agent:name}} syntax is rewritten to
+// {{agent "name"}} first, and prompts/partials/ snippets are available as {{template "name" .}}.
+// only the token functions, agent and the template builtins are callable, so a prompt can't reach
+// anything beyond the prompt data. values inserted by functions are not parsed again.
+func (b *promptBuilder) renderTemplate(prompt string, opts renderOpts) (string, error) {
+	funcs := template.FuncMap{"agent": func(name string) string {
+		if opts.noAgents {
+			return "{{agent:" + name + "}}"
+		}
+		if expanded, ok := b.expandAgent(name); ok {
+			return expanded
+		}
+		return "{{agent:" + name + "}}"
+	}}
+	base := b.baseTokenValues(opts.taskPos)
+	for _, token := range promptTokens {
+		funcs[token] = func() string {
+			if v, ok := opts.values[token]; ok {
+				return v
+			}
+			if value, ok := base[token]; ok {
+				return value()
+			}
+			return "{{" + token + "}}"
+		}
+	}
+
+	name := opts.name
+	if name == "" {
+		name = "prompt"
+	}
+	tmpl := template.New(name).Funcs(funcs).Option("missingkey=error")
+	if _, err := tmpl.Parse(rewriteAgentRefs(prompt)); err != nil {
+		return "", fmt.Errorf("parse prompt template: %w", err)
+	}
+	var partials map[string]string
+	if b.cfg.AppConfig != nil {
+		partials = b.cfg.AppConfig.PromptPartials
+	}
+	for _, partial := range slices.Sorted(maps.Keys(partials)) {
+		if _, err := tmpl.New(partial).Parse(rewriteAgentRefs(partials[partial])); err != nil {
+			return "", fmt.Errorf("parse prompt partial %s: %w", partial, err)
+		}
+	}
+
+	var buf strings.Builder
+	if err := tmpl.ExecuteTemplate(&buf, name, b.templateData()); err != nil {
+		return "", fmt.Errorf("render prompt template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// render renders prompt with renderTemplate. a template error is logged and the prompt is returned
+// unrendered; prompts are validated before the run, so this only happens for prompts changed mid-run.
+func (b *promptBuilder) render(prompt string, opts renderOpts) string {
+	res, err := b.renderTemplate(prompt, opts)
+	if err != nil {
+		b.warn("%v", err)
+		return prompt
+	}
+	return res
+}
+
+// rewriteAgentRefs rewrites {{agent:name}} references to the {{agent "name"}} template call.
+func rewriteAgentRefs(prompt string) string {
+	return agentRefPattern.ReplaceAllString(prompt, `{{agent "$1"}}`)
+}
+
+// baseTokenValues returns the value functions of the tokens shared by all prompts. values are computed
+// only for the tokens a prompt uses, so the plan and git are not read for prompts without task tokens.
+func (b *promptBuilder) baseTokenValues(taskPos int) map[string]func() string {
+	task := &taskContext{b: b, pos: taskPos}
+	return map[string]func() string{
+		"PLAN_FILE":       b.getPlanFileRef,
+		"PROGRESS_FILE":   b.getProgressFileRef,
+		"GOAL":            b.getGoal,
+		"DEFAULT_BRANCH":  b.getDefaultBranch,
+		"PLANS_DIR":       b.getPlansDir,
+		"TASK_NUMBER":     task.number,
+		"TASK_TITLE":      task.title,
+		"TASK_BODY":       task.body,
+		"COMPLETED_TASKS": task.completedTasks,
+		"CHANGED_FILES":   task.changedFiles,
+		"COMMIT_LOG":      task.commitLog,
+	}
+}
+
+// templateData returns the data of prompt templates.
+func (b *promptBuilder) templateData() promptData {
+	data := promptData{
+		IsCodex:       b.cfg.isCodexExecutor(),
+		HasPlan:       b.cfg.PlanFile != "",
+		PlanFile:      b.getPlanFileRef(),
+		ProgressFile:  b.getProgressFileRef(),
+		Goal:          b.getGoal(),
+		DefaultBranch: b.getDefaultBranch(),
+		PlansDir:      b.getPlansDir(),
+		Vars:          map[string]string{},
+	}
+	if b.cfg.AppConfig == nil {
+		return data
+	}
+	if b.cfg.AppConfig.PromptVars != nil {
+		data.Vars = b.cfg.AppConfig.PromptVars
+	}
+	for _, a := range b.cfg.AppConfig.CustomAgents {
+		data.Agents = append(data.Agents, promptAgent{Name: a.Name, Model: a.Model, AgentType: a.AgentType})
+	}
+	return data
+}
+
+// promptSource is a configured prompt template, checked by validatePrompts.
+type promptSource struct {
+	phase string // prompt name as in PromptPhases
+	text  string
+}
+
+// promptSources returns the prompt templates of the config in PromptPhases order.
+func (b *promptBuilder) promptSources() []promptSource {
+	c := b.cfg.AppConfig
+	return []promptSource{{"make_plan", c.MakePlanPrompt}, {"task", c.TaskPrompt}, {"review_first", c.ReviewFirstPrompt},
+		{"codex_review", c.CodexReviewPrompt}, {"codex", c.CodexPrompt}, {"custom_review", c.CustomReviewPrompt},
+		{"custom_eval", c.CustomEvalPrompt}, {"review_second", c.ReviewSecondPrompt},
+		{"review_report", c.ReviewReportPrompt}, {"finalize", c.FinalizePrompt}}
+}
+
+// validatePrompts renders every prompt template and agent body of the config, failing on syntax errors,
+// unknown {{NAME}} functions and undefined fields or [prompt_vars] variables.
+func (b *promptBuilder) validatePrompts() error {
+	if b.cfg.AppConfig == nil {
+		return nil
+	}
+	for _, src := range b.promptSources() {
+		if err := b.checkTemplate(src.phase+".txt", src.text); err != nil {
+			return err
+		}
+	}
+	for _, a := range b.cfg.AppConfig.CustomAgents {
+		if err := b.checkTemplate("agents/"+a.Name+".txt", a.Prompt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkTemplate renders a prompt template without agent expansion, which has its own check of the agent bodies.
+func (b *promptBuilder) checkTemplate(name, prompt string) error {
+	_, err := b.renderTemplate(prompt, renderOpts{name: name, noAgents: true})
+	return err
+}
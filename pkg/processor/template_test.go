@@ -0,0 +1,126 @@
+package processor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/processor/mocks"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestPromptBuilder_renderTemplate(t *testing.T) {
+	agents := []config.CustomAgent{{Name: "quality", Prompt: "check quality of {{PLAN_FILE}}"},
+		{Name: "security", Prompt: "check security", Options: config.Options{Model: "opus"}}}
+	newBuilder := func(appCfg *config.Config, planFile string) *promptBuilder {
+		cfg := Config{PlanFile: planFile, DefaultBranch: "main", AppConfig: appCfg}
+		return newPromptBuilder(promptBuilderOpts{cfg: cfg, log: newMockLogger(), locator: newPlanLocator(cfg)})
+	}
+
+	tests := []struct {
+		name     string
+		prompt   string
+		appCfg   *config.Config
+		planFile string
+		opts     renderOpts
+		want     string
+		contains []string
+	}{
+		{name: "legacy tokens", prompt: "plan {{PLAN_FILE}} on {{DEFAULT_BRANCH}}", planFile: "docs/plans/a.md",
+			want: "plan docs/plans/a.md on main"},
+		{name: "token without value kept", prompt: "output: {{CODEX_OUTPUT}}", want: "output: {{CODEX_OUTPUT}}"},
+		{name: "token value", prompt: "output: {{CODEX_OUTPUT}}", opts: renderOpts{values: map[string]string{
+			"CODEX_OUTPUT": "found {{agent:quality}} {{TYPO}}"}}, want: "output: found {{agent:quality}} {{TYPO}}"},
+		{name: "has plan", prompt: "{{if .HasPlan}}plan {{.PlanFile}}{{else}}no plan{{end}}", planFile: "a.md",
+			want: "plan a.md"},
+		{name: "no plan", prompt: "{{if .HasPlan}}plan{{else}}no plan{{end}}", want: "no plan"},
+		{name: "claude syntax", prompt: "{{if .IsCodex}}codex{{else}}claude{{end}}", want: "claude"},
+		{name: "codex syntax", prompt: "{{if .IsCodex}}codex{{else}}claude{{end}}",
+			appCfg: &config.Config{Executor: config.ExecutorCodex}, want: "codex"},
+		{name: "range over agents", prompt: "{{range .Agents}}- {{.Name}} {{.Model}}\n{{end}}",
+			appCfg: &config.Config{CustomAgents: agents}, want: "- quality \n- security opus\n"},
+		{name: "agent call and legacy reference", prompt: `{{agent "security"}} and {{agent:quality}}`,
+			appCfg: &config.Config{CustomAgents: agents}, planFile: "a.md",
+			contains: []string{"check security", "check quality of a.md", "Use the Task tool"}},
+		{name: "agents kept without expansion", prompt: "{{agent:quality}}", appCfg: &config.Config{CustomAgents: agents},
+			opts: renderOpts{noAgents: true}, want: "{{agent:quality}}"},
+		{name: "prompt vars", prompt: "run {{.Vars.lint_cmd}} before commit",
+			appCfg: &config.Config{PromptVars: map[string]string{"lint_cmd": "make lint"}}, want: "run make lint before commit"},
+		{name: "partial", prompt: `start {{template "rules" .}} end`, planFile: "a.md",
+			appCfg: &config.Config{PromptPartials: map[string]string{"rules": "rules of {{PLAN_FILE}}"}},
+			want:   "start rules of a.md end"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			appCfg := tc.appCfg
+			if appCfg == nil {
+				appCfg = &config.Config{}
+			}
+			got, err := newBuilder(appCfg, tc.planFile).renderTemplate(tc.prompt, tc.opts)
+			require.NoError(t, err)
+			if tc.contains == nil {
+				assert.Equal(t, tc.want, got)
+			}
+			for _, c := range tc.contains {
+				assert.Contains(t, got, c)
+			}
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		b := newBuilder(&config.Config{PromptVars: map[string]string{"lint_cmd": "make lint"}}, "")
+		_, err := b.renderTemplate("{{TYPO}}", renderOpts{name: "task.txt"})
+		require.ErrorContains(t, err, `template: task.txt:1: function "TYPO" not defined`)
+		_, err = b.renderTemplate("{{.Vars.test_cmd}}", renderOpts{})
+		require.ErrorContains(t, err, `map has no entry for key "test_cmd"`)
+		_, err = b.renderTemplate("{{.Unknown}}", renderOpts{})
+		require.ErrorContains(t, err, "can't evaluate field Unknown")
+		_, err = b.renderTemplate(`{{template "missing" .}}`, renderOpts{})
+		require.ErrorContains(t, err, `template "missing" not defined`)
+		_, err = b.renderTemplate("{{if .HasPlan}}unclosed", renderOpts{})
+		require.ErrorContains(t, err, "parse prompt template")
+	})
+
+	t.Run("render keeps prompt on error", func(t *testing.T) {
+		b := newBuilder(&config.Config{}, "a.md")
+		log := newMockLogger()
+		b.log = log
+		assert.Equal(t, "{{PLAN_FILE}} {{TYPO}}", b.render("{{PLAN_FILE}} {{TYPO}}", renderOpts{}))
+		require.Len(t, log.PrintCalls(), 1)
+		assert.Equal(t, "[WARN] %v", log.PrintCalls()[0].Format)
+		assert.ErrorContains(t, log.PrintCalls()[0].Args[0].(error), `function "TYPO" not defined`)
+	})
+}
+
+func TestPromptBuilder_validatePrompts(t *testing.T) {
+	newBuilder := func(appCfg *config.Config) *promptBuilder {
+		return newPromptBuilder(promptBuilderOpts{cfg: Config{AppConfig: appCfg}, log: newMockLogger()})
+	}
+	require.NoError(t, newBuilder(testAppConfig(t)).validatePrompts(), "embedded prompts are valid")
+	require.NoError(t, newBuilder(nil).validatePrompts())
+
+	appCfg := testAppConfig(t)
+	appCfg.ReviewSecondPrompt = "review {{GOAL}} with {{.Vars.style}}"
+	require.ErrorContains(t, newBuilder(appCfg).validatePrompts(), `review_second.txt:1:28: executing "review_second.txt" at <.Vars.style>: map has no entry for key "style"`)
+
+	appCfg = testAppConfig(t)
+	appCfg.CustomAgents = append(appCfg.CustomAgents, config.CustomAgent{Name: "broken", Prompt: "check {{PLANFILE}}"})
+	require.ErrorContains(t, newBuilder(appCfg).validatePrompts(), `agents/broken.txt:1: function "PLANFILE" not defined`)
+}
+
+func TestRunner_Run_InvalidPromptFailsStartup(t *testing.T) {
+	appCfg := testAppConfig(t)
+	appCfg.TaskPrompt = "implement {{PLAN_FILE}} and {{PROGRES_FILE}}"
+	failing := &mocks.ExecutorMock{RunFunc: func(context.Context, string) executor.Result {
+		t.Fatal("executor called with an invalid prompt")
+		return executor.Result{}
+	}}
+	r := NewWithExecutors(Config{Mode: ModeFull, PlanFile: "plan.md", MaxIterations: 1, AppConfig: appCfg},
+		newRunnerMockLogger("progress.txt"), Executors{Task: failing, External: failing}, &status.PhaseHolder{})
+	err := r.Run(t.Context())
+	require.ErrorContains(t, err, `validate prompts: parse prompt template: template: task.txt:1: function "PROGRES_FILE" not defined`)
+}
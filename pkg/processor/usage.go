@@ -0,0 +1,91 @@
+package processor
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// UsageStats is the token and cost accounting of a run, summed per phase and per plan task.
+type UsageStats struct {
+	Total  executor.Usage
+	Phases map[status.Phase]executor.Usage
+	Tasks  map[int]executor.Usage
+}
+
+// usageTracker accumulates executor usage across all phases of a run.
+// safe for concurrent use; the zero value is not usable, use newUsageTracker.
+type usageTracker struct {
+	mu     sync.Mutex
+	total  executor.Usage
+	phases map[status.Phase]executor.Usage
+	tasks  map[int]executor.Usage
+}
+
+func newUsageTracker() *usageTracker {
+	return &usageTracker{phases: map[status.Phase]executor.Usage{}, tasks: map[int]executor.Usage{}}
+}
+
+// add records usage of one executor session under the given phase.
+func (t *usageTracker) add(ph status.Phase, u executor.Usage) {
+	if u.IsZero() {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.total = t.total.Add(u)
+	t.phases[ph] = t.phases[ph].Add(u)
+}
+
+// RecordTaskUsage attributes usage to a plan task, implements phase.UsageRecorder.
+// phase totals are recorded separately by the retry policy, so this does not touch them.
+func (t *usageTracker) RecordTaskUsage(taskNum int, u executor.Usage) {
+	if u.IsZero() {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tasks[taskNum] = t.tasks[taskNum].Add(u)
+}
+
+// stats returns a snapshot of the accumulated usage.
+func (t *usageTracker) stats() UsageStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return UsageStats{Total: t.total, Phases: maps.Clone(t.phases), Tasks: maps.Clone(t.tasks)}
+}
+
+// usagePhaseOrder is the display order of phases in usage summaries.
+var usagePhaseOrder = []status.Phase{status.PhasePlan, status.PhaseTask, status.PhaseReview,
+	status.PhaseCodex, status.PhaseClaudeEval, status.PhaseFinalize}
+
+// summaryLines formats per-phase and per-task usage for the progress log, empty when nothing was recorded.
+func (s UsageStats) summaryLines() []string {
+	if s.Total.IsZero() {
+		return nil
+	}
+	var phases []string
+	for _, ph := range usagePhaseOrder {
+		if u, ok := s.Phases[ph]; ok {
+			phases = append(phases, fmt.Sprintf("%s (%s)", ph, u))
+		}
+	}
+	lines := []string{"usage total: " + s.Total.String()}
+	if len(phases) > 0 {
+		lines = append(lines, "usage by phase: "+strings.Join(phases, "; "))
+	}
+	if len(s.Tasks) > 0 {
+		nums := slices.Sorted(maps.Keys(s.Tasks))
+		tasks := make([]string, 0, len(nums))
+		for _, n := range nums {
+			tasks = append(tasks, fmt.Sprintf("task %d (%s)", n, s.Tasks[n]))
+		}
+		lines = append(lines, "usage by task: "+strings.Join(tasks, "; "))
+	}
+	return lines
+}
@@ -0,0 +1,53 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+func TestUsageTracker(t *testing.T) {
+	tr := newUsageTracker()
+	a := executor.Usage{InputTokens: 10, OutputTokens: 5, CostUSD: 0.1}
+	b := executor.Usage{InputTokens: 1, CacheReadTokens: 500}
+
+	tr.add(status.PhaseTask, a)
+	tr.add(status.PhaseTask, b)
+	tr.add(status.PhaseReview, b)
+	tr.add(status.PhaseCodex, executor.Usage{}) // zero usage is not recorded
+	tr.RecordTaskUsage(2, a)
+	tr.RecordTaskUsage(1, b)
+	tr.RecordTaskUsage(3, executor.Usage{})
+
+	stats := tr.stats()
+	assert.Equal(t, a.Add(b).Add(b), stats.Total)
+	assert.Equal(t, map[status.Phase]executor.Usage{status.PhaseTask: a.Add(b), status.PhaseReview: b}, stats.Phases)
+	assert.Equal(t, map[int]executor.Usage{1: b, 2: a}, stats.Tasks)
+
+	// snapshot is detached from the tracker
+	stats.Phases[status.PhaseTask] = executor.Usage{}
+	assert.Equal(t, a.Add(b), tr.stats().Phases[status.PhaseTask])
+}
+
+func TestUsageStats_summaryLines(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		assert.Empty(t, UsageStats{}.summaryLines())
+	})
+
+	t.Run("phases in pipeline order and tasks sorted", func(t *testing.T) {
+		u := executor.Usage{InputTokens: 1500, OutputTokens: 200}
+		stats := UsageStats{
+			Total:  u.Add(u).Add(u),
+			Phases: map[status.Phase]executor.Usage{status.PhaseReview: u, status.PhaseTask: u.Add(u)},
+			Tasks:  map[int]executor.Usage{3: u, 1: u},
+		}
+		assert.Equal(t, []string{
+			"usage total: in 4.5k, out 600",
+			"usage by phase: task (in 3.0k, out 400); review (in 1.5k, out 200)",
+			"usage by task: task 1 (in 1.5k, out 200); task 3 (in 1.5k, out 200)",
+		}, stats.summaryLines())
+	})
+}
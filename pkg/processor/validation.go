@@ -0,0 +1,36 @@
+package processor
+
+import (
+	"context"
+	"time"
+
+	"github.com/umputun/ralphex/pkg/executor"
+	"github.com/umputun/ralphex/pkg/processor/phase"
+)
+
+// shellValidator runs plan validation commands through the shell with a per-command timeout.
+type shellValidator struct {
+	timeout time.Duration
+}
+
+// Validate runs a single validation command and returns its captured result.
+func (v shellValidator) Validate(ctx context.Context, command string) executor.CommandResult {
+	return executor.RunShellCommand(ctx, command, v.timeout)
+}
+
+// newValidationGate returns the validator and fix-attempt limit for the task phase.
+// the validator is nil (gate disabled) unless validation_enabled is set in config or via --validate.
+func newValidationGate(cfg Config) (phase.Validator, int) {
+	if cfg.AppConfig == nil || !cfg.AppConfig.ValidationEnabled {
+		return nil, 0
+	}
+	timeout := DefaultValidationTimeout
+	if cfg.AppConfig.ValidationTimeout > 0 {
+		timeout = cfg.AppConfig.ValidationTimeout
+	}
+	retries := DefaultValidationRetryCount
+	if cfg.AppConfig.ValidationRetryCountSet {
+		retries = cfg.AppConfig.ValidationRetryCount
+	}
+	return shellValidator{timeout: timeout}, retries
+}
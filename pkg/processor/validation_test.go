@@ -0,0 +1,46 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/config"
+)
+
+func TestNewValidationGate(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		v, retries := newValidationGate(Config{AppConfig: &config.Config{}})
+		assert.Nil(t, v)
+		assert.Zero(t, retries)
+	})
+
+	t.Run("nil app config", func(t *testing.T) {
+		v, _ := newValidationGate(Config{})
+		assert.Nil(t, v)
+	})
+
+	t.Run("enabled with defaults", func(t *testing.T) {
+		v, retries := newValidationGate(Config{AppConfig: &config.Config{ValidationEnabled: true}})
+		require.NotNil(t, v)
+		assert.Equal(t, shellValidator{timeout: DefaultValidationTimeout}, v)
+		assert.Equal(t, DefaultValidationRetryCount, retries)
+	})
+
+	t.Run("enabled with explicit settings", func(t *testing.T) {
+		v, retries := newValidationGate(Config{AppConfig: &config.Config{
+			ValidationEnabled: true, ValidationTimeout: time.Minute, ValidationRetryCount: 0, ValidationRetryCountSet: true,
+		}})
+		assert.Equal(t, shellValidator{timeout: time.Minute}, v)
+		assert.Zero(t, retries)
+	})
+}
+
+func TestShellValidator_Validate(t *testing.T) {
+	res := shellValidator{timeout: time.Minute}.Validate(t.Context(), "echo validated && exit 1")
+	require.Error(t, res.Error)
+	assert.Equal(t, 1, res.ExitCode)
+	assert.Contains(t, res.Output, "validated")
+}
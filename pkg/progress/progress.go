@@ -17,6 +17,7 @@ import (
 	"golang.org/x/term"
 
 	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/executor"
 	"github.com/umputun/ralphex/pkg/status"
 )
 
@@ -135,7 +136,8 @@ type Logger struct {
 	startTime time.Time
 	holder    *status.PhaseHolder
 	colors    *Colors
-	runErr    error // set via SetFailed to record non-success outcome for the footer
+	runErr    error          // set via SetFailed to record non-success outcome for the footer
+	usage     executor.Usage // set via SetUsage, written to the footer when non-zero
 }
 
 // Config holds logger configuration.
@@ -624,6 +626,13 @@ func (l *Logger) SetFailed(reason error) {
 	l.runErr = reason
 }
 
+// SetUsage records the run's token and cost accounting. On Close, a "Usage:" line
+// with raw counts follows the Completed/Failed line. Safe to call multiple times;
+// the most recent call wins.
+func (l *Logger) SetUsage(u executor.Usage) {
+	l.usage = u
+}
+
 // Close writes footer, releases the file lock, and closes the progress file.
 // Writes "Completed:" footer on success, or "Failed: ... - <reason>" if SetFailed
 // was called with a non-nil error.
@@ -643,6 +652,10 @@ func (l *Logger) Close() error {
 		reason := sanitizeFailureReason(l.runErr.Error())
 		l.writeFileLocked("Failed: %s (%s) - %s\n", ts, l.Elapsed(), reason)
 	}
+	if !l.usage.IsZero() {
+		l.writeFileLocked("Usage: input=%d output=%d cache_creation=%d cache_read=%d cost=%.4f\n",
+			l.usage.InputTokens, l.usage.OutputTokens, l.usage.CacheCreationTokens, l.usage.CacheReadTokens, l.usage.CostUSD)
+	}
 	l.writeMu.Unlock()
 
 	// release file lock before closing
@@ -738,6 +751,11 @@ func filenameWithStem(stem, mode string) string {
 	return filepath.Join(progressDir, fmt.Sprintf("progress-%s%s.txt", stem, modeSuffix(mode)))
 }
 
+// FilePath returns the progress file path, relative to the project root, of a run of planFile in mode.
+func FilePath(planFile, mode string) string {
+	return progressFilename(planFile, "", mode, "")
+}
+
 // progressFilename returns progress file path based on plan and mode.
 func progressFilename(planFile, planDescription, mode, branchOverride string) string {
 	// plan mode uses sanitized plan description
@@ -16,6 +16,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/umputun/ralphex/pkg/config"
+	"github.com/umputun/ralphex/pkg/executor"
 	"github.com/umputun/ralphex/pkg/status"
 )
 
@@ -630,6 +631,45 @@ func TestLogger_Close_AfterSetFailed_WritesFailedFooter(t *testing.T) {
 	assert.Contains(t, s, strings.Repeat("-", 60))
 }
 
+func TestLogger_Close_WithUsage_WritesUsageFooter(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	require.NoError(t, os.Chdir(tmpDir))
+	defer func() { _ = os.Chdir(origDir) }()
+
+	l, err := NewLogger(Config{Mode: "full", Branch: "test"}, testColors(), &status.PhaseHolder{})
+	require.NoError(t, err)
+	l.Print("ok")
+	l.SetUsage(executor.Usage{InputTokens: 1200, OutputTokens: 340, CacheCreationTokens: 5000, CacheReadTokens: 90000, CostUSD: 1.23456})
+	require.NoError(t, l.Close())
+
+	content, err := os.ReadFile(l.Path())
+	require.NoError(t, err)
+	s := string(content)
+	assert.Regexp(t, `(?m)^Completed: .*\nUsage: input=1200 output=340 cache_creation=5000 cache_read=90000 cost=1\.2346\n$`, s)
+
+	// usage line must not hide the completed footer from the restart check
+	f, err := os.Open(l.Path())
+	require.NoError(t, err)
+	defer f.Close()
+	assert.True(t, isProgressCompleted(f, int64(len(content))))
+}
+
+func TestLogger_Close_WithoutUsage_NoUsageFooter(t *testing.T) {
+	tmpDir := t.TempDir()
+	origDir, _ := os.Getwd()
+	require.NoError(t, os.Chdir(tmpDir))
+	defer func() { _ = os.Chdir(origDir) }()
+
+	l, err := NewLogger(Config{Mode: "full", Branch: "test"}, testColors(), &status.PhaseHolder{})
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+
+	content, err := os.ReadFile(l.Path())
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "Usage:")
+}
+
 func TestLogger_Close_WithoutSetFailed_WritesCompleted(t *testing.T) {
 	tmpDir := t.TempDir()
 	origDir, _ := os.Getwd()
@@ -957,6 +997,11 @@ func TestGetProgressFilename(t *testing.T) {
 	}
 }
 
+func TestFilePath(t *testing.T) {
+	assert.Equal(t, filepath.Join(".ralphex", "progress", "progress-feature.txt"), FilePath("/repo/docs/plans/feature.md", "full"))
+	assert.Equal(t, filepath.Join(".ralphex", "progress", "progress-feature-review.txt"), FilePath("docs/plans/feature.md", "review"))
+}
+
 func TestSanitizePlanName(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -0,0 +1,135 @@
+// Package queue orders pending plans by their depends_on declarations and runs them
+// concurrently, starting a plan only after all of its prerequisites succeeded.
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/umputun/ralphex/pkg/plan"
+)
+
+// Plan is a queued plan file with its prerequisites resolved to other queued plans.
+type Plan struct {
+	Path      string   // plan file path
+	Branch    string   // branch the plan runs on, derived from the file name
+	DependsOn []string // paths of queued plans that must complete first
+}
+
+// Name returns the plan file name, used in logs and summaries.
+func (p Plan) Name() string {
+	return filepath.Base(p.Path)
+}
+
+// Load parses the plan files and resolves their depends_on references. a reference names
+// another queued plan by file name, with or without .md, or by branch name. references to
+// plans already archived in the completed/ directory next to the plan are treated as done.
+// returns plans in dependency order, keeping the input order among independent plans.
+func Load(paths []string) ([]Plan, error) {
+	plans := make([]Plan, 0, len(paths))
+	refs := make([][]string, 0, len(paths))
+	for _, path := range paths {
+		parsed, err := plan.ParsePlanFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+		}
+		plans = append(plans, Plan{Path: path, Branch: plan.ExtractBranchName(path)})
+		refs = append(refs, parsed.DependsOn)
+	}
+
+	for i := range plans {
+		for _, ref := range refs[i] {
+			dep, err := resolve(plans, plans[i], ref)
+			if err != nil {
+				return nil, err
+			}
+			if dep != "" && !slices.Contains(plans[i].DependsOn, dep) {
+				plans[i].DependsOn = append(plans[i].DependsOn, dep)
+			}
+		}
+	}
+	return sortByDependencies(plans)
+}
+
+// resolve maps a depends_on reference of p to a queued plan path.
+// returns an empty path when the reference is an already completed plan.
+func resolve(plans []Plan, p Plan, ref string) (string, error) {
+	name := strings.TrimSuffix(filepath.Base(ref), ".md")
+	for _, other := range plans {
+		if strings.TrimSuffix(other.Name(), ".md") != name && other.Branch != name {
+			continue
+		}
+		if other.Path == p.Path {
+			return "", fmt.Errorf("plan %s depends on itself", p.Name())
+		}
+		return other.Path, nil
+	}
+	completed, err := filepath.Glob(filepath.Join(filepath.Dir(p.Path), "completed", "*.md"))
+	if err != nil {
+		return "", fmt.Errorf("list completed plans: %w", err)
+	}
+	for _, c := range completed {
+		if strings.TrimSuffix(filepath.Base(c), ".md") == name || plan.ExtractBranchName(c) == name {
+			return "", nil
+		}
+	}
+	if _, statErr := os.Stat(ref); statErr == nil {
+		return "", fmt.Errorf("plan %s depends on %s, which is not queued", p.Name(), ref)
+	}
+	return "", fmt.Errorf("plan %s depends on unknown plan %q", p.Name(), ref)
+}
+
+// sortByDependencies orders plans so every plan follows its prerequisites.
+// returns an error naming the cycle when the dependencies are not a DAG.
+func sortByDependencies(plans []Plan) ([]Plan, error) {
+	byPath := make(map[string]Plan, len(plans))
+	for _, p := range plans {
+		byPath[p.Path] = p
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(plans))
+	sorted := make([]Plan, 0, len(plans))
+	var stack []string
+
+	var visit func(p Plan) error
+	visit = func(p Plan) error {
+		switch state[p.Path] {
+		case visited:
+			return nil
+		case visiting:
+			start := slices.Index(stack, p.Path)
+			cycle := make([]string, 0, len(stack)-start+1)
+			for _, path := range append(stack[start:], p.Path) {
+				cycle = append(cycle, filepath.Base(path))
+			}
+			return errors.New("dependency cycle: " + strings.Join(cycle, " -> "))
+		}
+		state[p.Path] = visiting
+		stack = append(stack, p.Path)
+		for _, dep := range p.DependsOn {
+			if err := visit(byPath[dep]); err != nil {
+				return err
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[p.Path] = visited
+		sorted = append(sorted, p)
+		return nil
+	}
+
+	for _, p := range plans {
+		if err := visit(p); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
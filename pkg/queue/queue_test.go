@@ -0,0 +1,87 @@
+package queue
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePlan(t *testing.T, dir, name, dependsOn string) string {
+	t.Helper()
+	content := "# " + name + "\n"
+	if dependsOn != "" {
+		content += "\ndepends_on: " + dependsOn + "\n"
+	}
+	content += "\n### Task 1: do it\n- [ ] step\n"
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func names(plans []Plan) []string {
+	res := make([]string, 0, len(plans))
+	for _, p := range plans {
+		res = append(res, p.Name())
+	}
+	return res
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("orders by dependencies keeping input order", func(t *testing.T) {
+		dir := t.TempDir()
+		api := writePlan(t, dir, "2026-03-01-api.md", "auth, 20260301-db.md")
+		auth := writePlan(t, dir, "20260301-auth.md", "")
+		db := writePlan(t, dir, "20260301-db.md", "")
+		ui := writePlan(t, dir, "ui.md", "")
+
+		plans, err := Load([]string{api, auth, db, ui})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"20260301-auth.md", "20260301-db.md", "2026-03-01-api.md", "ui.md"}, names(plans))
+		assert.Equal(t, "api", plans[2].Branch)
+		assert.Equal(t, []string{auth, db}, plans[2].DependsOn)
+		assert.Empty(t, plans[3].DependsOn)
+	})
+
+	t.Run("completed prerequisite is satisfied", func(t *testing.T) {
+		dir := t.TempDir()
+		writePlan(t, dir, "completed/20260101-auth.md", "")
+		api := writePlan(t, dir, "api.md", "auth")
+
+		plans, err := Load([]string{api})
+		require.NoError(t, err)
+		require.Len(t, plans, 1)
+		assert.Empty(t, plans[0].DependsOn)
+	})
+
+	t.Run("unknown dependency", func(t *testing.T) {
+		dir := t.TempDir()
+		api := writePlan(t, dir, "api.md", "missing")
+		_, err := Load([]string{api})
+		require.ErrorContains(t, err, `plan api.md depends on unknown plan "missing"`)
+	})
+
+	t.Run("self dependency", func(t *testing.T) {
+		dir := t.TempDir()
+		api := writePlan(t, dir, "api.md", "api.md")
+		_, err := Load([]string{api})
+		require.ErrorContains(t, err, "depends on itself")
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		dir := t.TempDir()
+		a := writePlan(t, dir, "a.md", "c")
+		b := writePlan(t, dir, "b.md", "a")
+		c := writePlan(t, dir, "c.md", "b")
+		_, err := Load([]string{a, b, c})
+		require.EqualError(t, err, "dependency cycle: a.md -> c.md -> b.md -> a.md")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := Load([]string{filepath.Join(t.TempDir(), "nope.md")})
+		require.ErrorContains(t, err, "parse nope.md")
+	})
+}
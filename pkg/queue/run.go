@@ -0,0 +1,121 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Status is the outcome of a queued plan.
+type Status string
+
+// plan outcomes.
+const (
+	StatusSuccess Status = "success"
+	StatusFailed  Status = "failed"
+	StatusSkipped Status = "skipped" // not started: a prerequisite did not succeed or the queue was canceled
+)
+
+// Result is the outcome of one queued plan.
+type Result struct {
+	Plan     Plan
+	Status   Status
+	Err      error // failure or skip reason, nil on success
+	Duration time.Duration
+}
+
+// RunFunc executes a single plan. it is called concurrently for independent plans.
+type RunFunc func(ctx context.Context, p Plan) error
+
+// Run executes plans with at most parallel plans in flight, starting each plan only after
+// all of its prerequisites succeeded. plans depending on a failed or skipped plan are skipped,
+// and so are plans not yet started when ctx is canceled.
+// results follow the order of plans.
+func Run(ctx context.Context, plans []Plan, parallel int, fn RunFunc) []Result {
+	parallel = max(parallel, 1)
+	results := make([]Result, len(plans))
+	index := make(map[string]int, len(plans))
+	for i, p := range plans {
+		index[p.Path] = i
+		results[i].Plan = p
+	}
+
+	type finished struct {
+		idx int
+		err error
+		dur time.Duration
+	}
+	done := make(chan finished)
+	started := make([]bool, len(plans))
+	running := 0
+
+	for {
+		for i, p := range plans {
+			if started[i] || running >= parallel {
+				continue
+			}
+			ready, blocker := readiness(p, results, index)
+			switch {
+			case blocker != "":
+				started[i] = true
+				results[i].Status, results[i].Err = StatusSkipped, fmt.Errorf("prerequisite %s did not succeed", blocker)
+				continue
+			case !ready:
+				continue
+			case ctx.Err() != nil:
+				started[i] = true
+				results[i].Status, results[i].Err = StatusSkipped, fmt.Errorf("queue canceled: %w", ctx.Err())
+				continue
+			}
+			started[i] = true
+			running++
+			go func(idx int, p Plan) {
+				start := time.Now()
+				err := fn(ctx, p)
+				done <- finished{idx: idx, err: err, dur: time.Since(start)}
+			}(i, p)
+		}
+
+		if running == 0 {
+			break
+		}
+		f := <-done
+		running--
+		results[f.idx].Duration = f.dur
+		results[f.idx].Status, results[f.idx].Err = StatusSuccess, nil
+		if f.err != nil {
+			results[f.idx].Status, results[f.idx].Err = StatusFailed, f.err
+		}
+	}
+
+	// only plans with cyclic prerequisites can be left unstarted, Load rejects those
+	for i := range results {
+		if results[i].Status == "" {
+			results[i].Status, results[i].Err = StatusSkipped, errors.New("prerequisites never completed")
+		}
+	}
+	return results
+}
+
+// readiness reports whether all prerequisites of p succeeded, or names the first
+// prerequisite that failed or was skipped.
+func readiness(p Plan, results []Result, index map[string]int) (ready bool, blocker string) {
+	ready = true
+	for _, dep := range p.DependsOn {
+		switch results[index[dep]].Status {
+		case StatusSuccess:
+		case StatusFailed, StatusSkipped:
+			return false, results[index[dep]].Plan.Name()
+		default:
+			ready = false
+		}
+	}
+	return ready, ""
+}
+
+// Failed returns the results that did not succeed.
+func Failed(results []Result) []Result {
+	return slices.DeleteFunc(slices.Clone(results), func(r Result) bool { return r.Status == StatusSuccess })
+}
@@ -0,0 +1,110 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	t.Run("runs independent plans in parallel up to the limit", func(t *testing.T) {
+		plans := []Plan{{Path: "a.md"}, {Path: "b.md"}, {Path: "c.md"}, {Path: "d.md"}}
+		var inFlight, peak atomic.Int32
+		results := Run(t.Context(), plans, 2, func(context.Context, Plan) error {
+			n := inFlight.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			inFlight.Add(-1)
+			return nil
+		})
+		assert.Equal(t, int32(2), peak.Load())
+		require.Len(t, results, 4)
+		for _, r := range results {
+			assert.Equal(t, StatusSuccess, r.Status)
+			assert.NoError(t, r.Err)
+			assert.Positive(t, r.Duration)
+		}
+	})
+
+	t.Run("dependent starts after prerequisites", func(t *testing.T) {
+		plans := []Plan{{Path: "a.md"}, {Path: "b.md"}, {Path: "c.md", DependsOn: []string{"a.md", "b.md"}}}
+		var mu sync.Mutex
+		var finished []string
+		var startedC []string
+		results := Run(t.Context(), plans, 3, func(_ context.Context, p Plan) error {
+			mu.Lock()
+			if p.Path == "c.md" {
+				startedC = append(startedC, finished...)
+			}
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			finished = append(finished, p.Path)
+			mu.Unlock()
+			return nil
+		})
+		assert.ElementsMatch(t, []string{"a.md", "b.md"}, startedC)
+		assert.Equal(t, "c.md", results[2].Plan.Path, "results keep input order")
+		assert.Equal(t, StatusSuccess, results[2].Status)
+	})
+
+	t.Run("failure skips dependents transitively", func(t *testing.T) {
+		plans := []Plan{
+			{Path: "a.md"}, {Path: "b.md", DependsOn: []string{"a.md"}},
+			{Path: "c.md", DependsOn: []string{"b.md"}}, {Path: "d.md"},
+		}
+		var ran []string
+		var mu sync.Mutex
+		results := Run(t.Context(), plans, 1, func(_ context.Context, p Plan) error {
+			mu.Lock()
+			ran = append(ran, p.Path)
+			mu.Unlock()
+			if p.Path == "a.md" {
+				return errors.New("boom")
+			}
+			return nil
+		})
+		assert.ElementsMatch(t, []string{"a.md", "d.md"}, ran)
+		assert.Equal(t, StatusFailed, results[0].Status)
+		require.EqualError(t, results[0].Err, "boom")
+		assert.Equal(t, StatusSkipped, results[1].Status)
+		require.EqualError(t, results[1].Err, "prerequisite a.md did not succeed")
+		assert.Equal(t, StatusSkipped, results[2].Status)
+		require.EqualError(t, results[2].Err, "prerequisite b.md did not succeed")
+		assert.Equal(t, StatusSuccess, results[3].Status)
+		assert.Len(t, Failed(results), 3)
+	})
+
+	t.Run("cancel skips plans not started", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		plans := []Plan{{Path: "a.md"}, {Path: "b.md"}}
+		results := Run(ctx, plans, 1, func(ctx context.Context, _ Plan) error {
+			cancel()
+			return ctx.Err()
+		})
+		assert.Equal(t, StatusFailed, results[0].Status)
+		assert.Equal(t, StatusSkipped, results[1].Status)
+		require.ErrorIs(t, results[1].Err, context.Canceled)
+	})
+
+	t.Run("zero parallel runs one at a time", func(t *testing.T) {
+		var calls atomic.Int32
+		results := Run(t.Context(), []Plan{{Path: "a.md"}}, 0, func(context.Context, Plan) error {
+			calls.Add(1)
+			return nil
+		})
+		assert.Equal(t, int32(1), calls.Load())
+		assert.Equal(t, StatusSuccess, results[0].Status)
+	})
+}
@@ -1,33 +1,51 @@
 package status
 
-import "sync"
+import (
+	"slices"
+	"sync"
+)
 
 // PhaseHolder stores the current execution phase in a thread-safe way.
 // it is the single source of truth for the current phase across all components.
 type PhaseHolder struct {
-	mu       sync.RWMutex
-	phase    Phase
-	onChange func(old, cur Phase)
+	mu        sync.RWMutex
+	phase     Phase
+	callbacks []*phaseCallback
+}
+
+type phaseCallback struct {
+	fn func(old, cur Phase)
 }
 
 // OnChange registers a callback that fires when the phase changes.
-// only one callback is supported; subsequent calls replace the previous one.
-func (h *PhaseHolder) OnChange(fn func(old, cur Phase)) {
+// callbacks fire in registration order; the returned func unregisters this callback.
+func (h *PhaseHolder) OnChange(fn func(old, cur Phase)) (remove func()) {
+	cb := &phaseCallback{fn: fn}
 	h.mu.Lock()
-	h.onChange = fn
+	h.callbacks = append(h.callbacks, cb)
 	h.mu.Unlock()
+	return func() {
+		h.mu.Lock()
+		h.callbacks = slices.DeleteFunc(h.callbacks, func(c *phaseCallback) bool { return c == cb })
+		h.mu.Unlock()
+	}
 }
 
-// Set updates the current phase and fires the OnChange callback if the phase changed.
+// Set updates the current phase and fires the OnChange callbacks if the phase changed.
 func (h *PhaseHolder) Set(p Phase) {
 	h.mu.Lock()
 	old := h.phase
 	h.phase = p
-	cb := h.onChange
+	cbs := slices.Clone(h.callbacks)
 	h.mu.Unlock()
 
-	if old != p && cb != nil {
-		cb(old, p)
+	if old == p {
+		return
+	}
+	for _, cb := range cbs {
+		if cb.fn != nil {
+			cb.fn(old, p)
+		}
 	}
 }
 
@@ -50,6 +50,21 @@ func TestPhaseHolder_OnChange_NotFiredOnSamePhase(t *testing.T) {
 	assert.Equal(t, 1, callCount)
 }
 
+func TestPhaseHolder_OnChange_MultipleAndRemove(t *testing.T) {
+	h := &PhaseHolder{}
+
+	var order []string
+	h.OnChange(func(_, _ Phase) { order = append(order, "first") })
+	remove := h.OnChange(func(_, _ Phase) { order = append(order, "second") })
+
+	h.Set(PhaseTask)
+	assert.Equal(t, []string{"first", "second"}, order)
+
+	remove()
+	h.Set(PhaseReview)
+	assert.Equal(t, []string{"first", "second", "first"}, order)
+}
+
 func TestPhaseHolder_OnChange_NilCallbackSafe(t *testing.T) {
 	h := &PhaseHolder{}
 	// no callback registered - should not panic
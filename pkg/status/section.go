@@ -5,11 +5,11 @@ import "fmt"
 // SectionType represents the semantic type of a section header.
 // the web layer uses these types to emit appropriate boundary events:
 //   - SectionTaskIteration: emits task_start/task_end events
-//   - SectionInternalReview, SectionCodexIteration: emits iteration_start events
+//   - SectionInternalReview, SectionCodexIteration, SectionExternalIteration: emits iteration_start events
 //   - SectionGeneric, SectionClaudeEval: no boundary events, just section headers
 //
 // invariants:
-//   - Iteration > 0 for SectionTaskIteration, SectionCodexIteration
+//   - Iteration > 0 for SectionTaskIteration, SectionCodexIteration, SectionExternalIteration
 //   - Iteration >= 0 for SectionInternalReview (first review pass uses 0)
 //   - Iteration == 0 for SectionGeneric, SectionClaudeEval
 //
@@ -32,6 +32,8 @@ const (
 	SectionPlanIteration
 	// SectionCustomIteration represents a custom review tool iteration.
 	SectionCustomIteration
+	// SectionExternalIteration represents an iteration of several external review tools run in parallel.
+	SectionExternalIteration
 )
 
 // Section carries structured information about a section header.
@@ -120,3 +122,13 @@ func NewCustomIterationSection(iteration int) Section {
 		Label:     fmt.Sprintf("custom review iteration %d", iteration),
 	}
 }
+
+// NewExternalIterationSection creates a section for an iteration of several external review tools,
+// tools is their joined name, e.g. "codex+custom:gemini".
+func NewExternalIterationSection(tools string, iteration int) Section {
+	return Section{
+		Type:      SectionExternalIteration,
+		Iteration: iteration,
+		Label:     fmt.Sprintf("%s review iteration %d", tools, iteration),
+	}
+}
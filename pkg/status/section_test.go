@@ -56,6 +56,8 @@ func TestSections_TypeAndLabelContract(t *testing.T) {
 		{name: "codex", section: NewCodexIterationSection(2), wantType: SectionCodexIteration, wantIteration: 2, wantLabel: "codex iteration 2"},
 		{name: "plan", section: NewPlanIterationSection(4), wantType: SectionPlanIteration, wantIteration: 4, wantLabel: "plan iteration 4"},
 		{name: "custom", section: NewCustomIterationSection(7), wantType: SectionCustomIteration, wantIteration: 7, wantLabel: "custom review iteration 7"},
+		{name: "external", section: NewExternalIterationSection("codex+custom:gemini", 3), wantType: SectionExternalIteration,
+			wantIteration: 3, wantLabel: "codex+custom:gemini review iteration 3"},
 		{name: "claude eval", section: NewClaudeEvalSection(), wantType: SectionClaudeEval, wantIteration: 0, wantLabel: "claude evaluating codex findings"},
 		{name: "generic", section: NewGenericSection("finalize"), wantType: SectionGeneric, wantIteration: 0, wantLabel: "finalize"},
 	}
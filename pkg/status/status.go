@@ -11,6 +11,7 @@ const (
 	Question   = "<<<RALPHEX:QUESTION>>>"
 	PlanReady  = "<<<RALPHEX:PLAN_READY>>>"
 	PlanDraft  = "<<<RALPHEX:PLAN_DRAFT>>>"
+	Findings   = "<<<RALPHEX:FINDINGS>>>"
 )
 
 // Phase represents execution phase for color coding.
@@ -0,0 +1,219 @@
+package web
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// authCookie keeps a browser signed in after it opened the dashboard with ?token=<token>.
+const authCookie = "ralphex_token"
+
+// authCookieMaxAge is how long the sign-in cookie stays valid.
+const authCookieMaxAge = 30 * 24 * time.Hour
+
+// selfSignedValidity is the lifetime of a generated self-signed certificate.
+const selfSignedValidity = 365 * 24 * time.Hour
+
+// TLSConfig selects HTTPS for the dashboard, with a certificate and key pair or a self-signed certificate.
+type TLSConfig struct {
+	CertFile   string // PEM certificate, used with KeyFile
+	KeyFile    string // PEM private key, used with CertFile
+	SelfSigned bool   // generate a self-signed certificate at startup
+}
+
+// Enabled reports whether the dashboard is served over HTTPS.
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != "" || c.SelfSigned
+}
+
+// Validate checks that either a complete cert/key pair or a self-signed certificate is configured.
+func (c TLSConfig) Validate() error {
+	if c.SelfSigned && (c.CertFile != "" || c.KeyFile != "") {
+		return errors.New("self-signed TLS conflicts with a TLS certificate and key")
+	}
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return errors.New("TLS requires both a certificate and a key")
+	}
+	return nil
+}
+
+// Scheme returns the URL scheme of a dashboard served with this configuration.
+func (c TLSConfig) Scheme() string {
+	if c.Enabled() {
+		return "https"
+	}
+	return "http"
+}
+
+// authEnabled reports whether every route requires a token or basic auth credentials.
+func (c ServerConfig) authEnabled() bool {
+	return c.Token != "" || c.User != ""
+}
+
+// requireAuth guards every route when a token or basic auth is configured. a request is let in with
+// the bearer token (header, ?token= query param or sign-in cookie) or the basic auth credentials.
+// a valid ?token= sets the sign-in cookie, and the index page then redirects to drop the token from the URL.
+func (s *Server) requireAuth(next http.Handler) http.Handler {
+	if !s.cfg.authEnabled() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("token"); token != "" && s.validToken(token) {
+			http.SetCookie(w, &http.Cookie{Name: authCookie, Value: token, Path: "/", MaxAge: int(authCookieMaxAge.Seconds()),
+				HttpOnly: true, Secure: s.cfg.TLS.Enabled(), SameSite: http.SameSiteStrictMode})
+			if r.Method == http.MethodGet && r.URL.Path == "/" {
+				u := *r.URL
+				q := u.Query()
+				q.Del("token")
+				u.RawQuery = q.Encode()
+				http.Redirect(w, r, u.RequestURI(), http.StatusSeeOther)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		if s.hasToken(r) || s.hasBasicAuth(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if s.cfg.User != "" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="ralphex"`)
+		} else {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="ralphex"`)
+		}
+		http.Error(w, "unauthorized: sign in with /?token=<token> or an Authorization header", http.StatusUnauthorized)
+	})
+}
+
+// hasToken reports whether the request carries the configured token as a bearer header,
+// a ?token= query param (EventSource can't set headers) or the sign-in cookie.
+func (s *Server) hasToken(r *http.Request) bool {
+	if token, ok := bearerToken(r); ok && s.validToken(token) {
+		return true
+	}
+	if token := r.URL.Query().Get("token"); token != "" && s.validToken(token) {
+		return true
+	}
+	if c, err := r.Cookie(authCookie); err == nil && s.validToken(c.Value) {
+		return true
+	}
+	return false
+}
+
+// hasBasicAuth reports whether the request carries the configured basic auth credentials.
+func (s *Server) hasBasicAuth(r *http.Request) bool {
+	if s.cfg.User == "" {
+		return false
+	}
+	user, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+	userOK := subtle.ConstantTimeCompare([]byte(user), []byte(s.cfg.User)) == 1
+	passwordOK := subtle.ConstantTimeCompare([]byte(password), []byte(s.cfg.Password)) == 1
+	return userOK && passwordOK
+}
+
+// validToken compares token with the configured one in constant time; an empty configured token never matches.
+func (s *Server) validToken(token string) bool {
+	return s.cfg.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.Token)) == 1
+}
+
+// tlsConfig returns the TLS configuration of the server, loading the certificate pair or generating
+// a self-signed certificate once; later calls return the same certificate.
+func (s *Server) tlsConfig() (*tls.Config, error) {
+	s.certOnce.Do(func() {
+		if s.cfg.TLS.SelfSigned {
+			s.cert, s.certErr = selfSignedCert(s.cfg.host())
+			return
+		}
+		cert, err := tls.LoadX509KeyPair(s.cfg.TLS.CertFile, s.cfg.TLS.KeyFile)
+		if err != nil {
+			s.certErr = fmt.Errorf("load TLS certificate: %w", err)
+			return
+		}
+		s.cert = &cert
+	})
+	if s.certErr != nil {
+		return nil, s.certErr
+	}
+	return &tls.Config{Certificates: []tls.Certificate{*s.cert}, MinVersion: tls.VersionTLS12}, nil
+}
+
+// CertFingerprint returns the SHA-256 fingerprint of the server's TLS certificate, so users can
+// verify a self-signed certificate before trusting it. empty without TLS.
+func (s *Server) CertFingerprint() (string, error) {
+	if !s.cfg.TLS.Enabled() {
+		return "", nil
+	}
+	cfg, err := s.tlsConfig()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(cfg.Certificates[0].Certificate[0])
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":"), nil
+}
+
+// selfSignedCert generates an ECDSA certificate for localhost, the loopback addresses, the machine's
+// hostname and host, the address the dashboard binds to.
+func selfSignedCert(host string) (*tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate TLS key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("generate TLS serial: %w", err)
+	}
+
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"ralphex"}, CommonName: "ralphex dashboard"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	if hostname, hostErr := os.Hostname(); hostErr == nil && hostname != "" && hostname != "localhost" {
+		tmpl.DNSNames = append(tmpl.DNSNames, hostname)
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if !ip.IsUnspecified() && !ip.IsLoopback() {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		}
+	} else if host != "" && host != "localhost" {
+		tmpl.DNSNames = append(tmpl.DNSNames, host)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("create TLS certificate: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("parse TLS certificate: %w", err)
+	}
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, nil
+}
@@ -0,0 +1,255 @@
+package web
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAccessTest(t *testing.T, cfg ServerConfig) http.Handler {
+	t.Helper()
+	session := NewSession("main", "/tmp/progress-test.txt")
+	t.Cleanup(session.Close)
+	srv, err := NewServer(cfg, session)
+	require.NoError(t, err)
+	h, err := srv.handler()
+	require.NoError(t, err)
+	return h
+}
+
+func TestServer_RequireAuth(t *testing.T) {
+	t.Run("open without auth", func(t *testing.T) {
+		h := newAccessTest(t, ServerConfig{})
+		for _, path := range []string{"/", "/api/sessions", "/static/app.js"} {
+			w := doRunAPI(h, http.MethodGet, path, "", "")
+			assert.Equal(t, http.StatusOK, w.Code, path)
+		}
+	})
+
+	t.Run("token on every route", func(t *testing.T) {
+		h := newAccessTest(t, ServerConfig{Token: "s3cret"})
+		for _, path := range []string{"/", "/api/sessions", "/api/plan", "/static/app.js"} {
+			w := doRunAPI(h, http.MethodGet, path, "", "")
+			assert.Equal(t, http.StatusUnauthorized, w.Code, path)
+			assert.Equal(t, `Bearer realm="ralphex"`, w.Header().Get("WWW-Authenticate"))
+			w = doRunAPI(h, http.MethodGet, path, "wrong", "")
+			assert.Equal(t, http.StatusUnauthorized, w.Code, path)
+		}
+		w := doRunAPI(h, http.MethodGet, "/api/sessions", "s3cret", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("token query param signs in", func(t *testing.T) {
+		h := newAccessTest(t, ServerConfig{Token: "s3cret"})
+		w := doRunAPI(h, http.MethodGet, "/?token=s3cret&session=main", "", "")
+		require.Equal(t, http.StatusSeeOther, w.Code)
+		assert.Equal(t, "/?session=main", w.Header().Get("Location"))
+		cookies := w.Result().Cookies()
+		require.Len(t, cookies, 1)
+		assert.Equal(t, authCookie, cookies[0].Name)
+		assert.True(t, cookies[0].HttpOnly)
+		assert.False(t, cookies[0].Secure)
+
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.AddCookie(cookies[0])
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		w = doRunAPI(h, http.MethodGet, "/api/sessions?token=s3cret", "", "")
+		assert.Equal(t, http.StatusOK, w.Code, "non-index routes are served without a redirect")
+		w = doRunAPI(h, http.MethodGet, "/?token=wrong", "", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Empty(t, w.Result().Cookies())
+	})
+
+	t.Run("basic auth", func(t *testing.T) {
+		h := newAccessTest(t, ServerConfig{User: "admin", Password: "pass"})
+		w := doRunAPI(h, http.MethodGet, "/", "", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, `Basic realm="ralphex"`, w.Header().Get("WWW-Authenticate"))
+
+		for _, tc := range []struct {
+			user, password string
+			code           int
+		}{{"admin", "pass", http.StatusOK}, {"admin", "wrong", http.StatusUnauthorized}, {"other", "pass", http.StatusUnauthorized}} {
+			req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+			req.SetBasicAuth(tc.user, tc.password)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			assert.Equal(t, tc.code, rec.Code, tc.user+":"+tc.password)
+		}
+
+		w = doRunAPI(h, http.MethodGet, "/?token=anything", "", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code, "no token configured")
+	})
+
+	t.Run("token or basic auth", func(t *testing.T) {
+		h := newAccessTest(t, ServerConfig{Token: "s3cret", User: "admin", Password: "pass"})
+		w := doRunAPI(h, http.MethodGet, "/", "s3cret", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.SetBasicAuth("admin", "pass")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestServer_RunAPIRejectsBasicAuth(t *testing.T) {
+	h, _ := newRunAPITest(t, "s3cret")
+	req := httptest.NewRequest(http.MethodGet, "/api/runs", http.NoBody)
+	req.SetBasicAuth("admin", "pass")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestTLSConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     TLSConfig
+		enabled bool
+		wantErr string
+	}{
+		{name: "disabled", cfg: TLSConfig{}},
+		{name: "cert and key", cfg: TLSConfig{CertFile: "c.pem", KeyFile: "k.pem"}, enabled: true},
+		{name: "self-signed", cfg: TLSConfig{SelfSigned: true}, enabled: true},
+		{name: "cert without key", cfg: TLSConfig{CertFile: "c.pem"}, enabled: true, wantErr: "both a certificate and a key"},
+		{name: "key without cert", cfg: TLSConfig{KeyFile: "k.pem"}, enabled: true, wantErr: "both a certificate and a key"},
+		{name: "self-signed with cert", cfg: TLSConfig{SelfSigned: true, CertFile: "c.pem", KeyFile: "k.pem"},
+			enabled: true, wantErr: "conflicts"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.enabled, tc.cfg.Enabled())
+			if tc.enabled {
+				assert.Equal(t, "https", tc.cfg.Scheme())
+			} else {
+				assert.Equal(t, "http", tc.cfg.Scheme())
+			}
+			err := tc.cfg.Validate()
+			if tc.wantErr != "" {
+				require.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestServer_CertFingerprint(t *testing.T) {
+	t.Run("without TLS", func(t *testing.T) {
+		srv, err := NewServer(ServerConfig{}, NewSession("main", "/tmp/progress-test.txt"))
+		require.NoError(t, err)
+		fp, err := srv.CertFingerprint()
+		require.NoError(t, err)
+		assert.Empty(t, fp)
+	})
+
+	t.Run("cert and key files", func(t *testing.T) {
+		dir := t.TempDir()
+		cert, err := selfSignedCert("127.0.0.1")
+		require.NoError(t, err)
+		certFile, keyFile := writeKeyPair(t, dir, cert)
+
+		srv, err := NewServer(ServerConfig{TLS: TLSConfig{CertFile: certFile, KeyFile: keyFile}}, NewSession("main", "/tmp/progress-test.txt"))
+		require.NoError(t, err)
+		fp, err := srv.CertFingerprint()
+		require.NoError(t, err)
+		sum := sha256.Sum256(cert.Certificate[0])
+		assert.Equal(t, strings.ReplaceAll(fmt.Sprintf("% X", sum), " ", ":"), fp)
+	})
+
+	t.Run("missing files", func(t *testing.T) {
+		srv, err := NewServer(ServerConfig{TLS: TLSConfig{CertFile: "/nonexistent/c.pem", KeyFile: "/nonexistent/k.pem"}},
+			NewSession("main", "/tmp/progress-test.txt"))
+		require.NoError(t, err)
+		_, err = srv.CertFingerprint()
+		require.ErrorContains(t, err, "load TLS certificate")
+	})
+}
+
+func TestSelfSignedCert(t *testing.T) {
+	cert, err := selfSignedCert("192.168.1.10")
+	require.NoError(t, err)
+	require.NoError(t, cert.Leaf.VerifyHostname("localhost"))
+	require.NoError(t, cert.Leaf.VerifyHostname("127.0.0.1"))
+	require.NoError(t, cert.Leaf.VerifyHostname("192.168.1.10"))
+	assert.True(t, cert.Leaf.NotAfter.After(time.Now().Add(300*24*time.Hour)))
+
+	cert, err = selfSignedCert("dashboard.example.com")
+	require.NoError(t, err)
+	require.NoError(t, cert.Leaf.VerifyHostname("dashboard.example.com"))
+}
+
+func TestServer_StartTLS(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	require.NoError(t, ln.Close())
+
+	session := NewSession("main", "/tmp/progress-test.txt")
+	defer session.Close()
+	srv, err := NewServer(ServerConfig{Port: port, Token: "s3cret", TLS: TLSConfig{SelfSigned: true}}, session)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.Start(ctx) }()
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec // self-signed test certificate
+	}}
+	url := fmt.Sprintf("https://127.0.0.1:%d/api/sessions?token=s3cret", port)
+	var resp *http.Response
+	require.Eventually(t, func() bool {
+		req, reqErr := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+		require.NoError(t, reqErr)
+		resp, err = client.Do(req) //nolint:bodyclose // closed below
+		return err == nil
+	}, 2*time.Second, 20*time.Millisecond)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotNil(t, resp.TLS)
+
+	fp, err := srv.CertFingerprint()
+	require.NoError(t, err)
+	sum := sha256.Sum256(resp.TLS.PeerCertificates[0].Raw)
+	assert.Equal(t, strings.ReplaceAll(fmt.Sprintf("% X", sum), " ", ":"), fp, "fingerprint of the served certificate")
+
+	cancel()
+	select {
+	case err := <-errCh:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+	}
+}
+
+// writeKeyPair writes cert and its private key as PEM files to dir.
+func writeKeyPair(t *testing.T, dir string, cert *tls.Certificate) (certFile, keyFile string) {
+	t.Helper()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0o600))
+	keyDER, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0o600))
+	return certFile, keyFile
+}
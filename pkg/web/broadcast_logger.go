@@ -92,7 +92,7 @@ func (b *BroadcastLogger) PrintSection(section status.Section) {
 	case status.SectionInternalReview:
 		b.broadcast(NewIterationStartEvent(b.holder.Get(), section.Iteration, section.Label))
 
-	case status.SectionCodexIteration:
+	case status.SectionCodexIteration, status.SectionExternalIteration:
 		b.broadcast(NewIterationStartEvent(b.holder.Get(), section.Iteration, section.Label))
 
 	case status.SectionGeneric, status.SectionClaudeEval:
@@ -0,0 +1,221 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// errors returned by RunControl, answered with 409 Conflict by the control endpoints.
+var (
+	ErrNotPaused      = errors.New("run is not paused")
+	ErrNotInTaskPhase = errors.New("run can only be paused during task execution")
+)
+
+// ControlState is the response of the session control endpoints.
+type ControlState struct {
+	Paused bool         `json:"paused"`
+	Phase  status.Phase `json:"phase,omitempty"`
+}
+
+// RunControl steers the live run of a --serve dashboard from the browser. break and pause feed
+// the runner's break channel, resume and abort answer its pause handler; the terminal keeps
+// working alongside, and whichever answers a pause first wins.
+type RunControl struct {
+	holder  *status.PhaseHolder
+	abort   func() // stops the run when it is not paused
+	breakCh chan struct{}
+	answer  chan bool // resume (true) or abort (false) of the current pause
+
+	mu     sync.Mutex
+	paused bool
+}
+
+// NewRunControl creates a RunControl for the run reporting its phase to holder. abort cancels
+// the run; it is used when the run is aborted while not paused.
+func NewRunControl(holder *status.PhaseHolder, abort func()) *RunControl {
+	return &RunControl{holder: holder, abort: abort, breakCh: make(chan struct{}, 1), answer: make(chan bool, 1)}
+}
+
+// BreakCh returns the channel to pass to Runner.SetBreakCh, fed by Break and Pause and by every
+// value received from signals, e.g. the SIGQUIT channel. signals may be nil.
+func (c *RunControl) BreakCh(signals <-chan struct{}) <-chan struct{} {
+	if signals != nil {
+		go func() {
+			for range signals {
+				c.sendBreak()
+			}
+		}()
+	}
+	return c.breakCh
+}
+
+// PauseHandler wraps the terminal pause handler for Runner.SetPauseHandler. a pause ends with
+// the first of a terminal resume, a Resume or Abort call, or ctx cancellation. a terminal that
+// returns false without ctx being canceled (stdin closed) leaves the pause to the dashboard.
+func (c *RunControl) PauseHandler(terminal func(ctx context.Context) bool) func(ctx context.Context) bool {
+	return func(ctx context.Context) bool {
+		c.setPaused(true)
+		defer c.setPaused(false)
+
+		termCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		termCh := make(chan bool, 1)
+		if terminal != nil {
+			go func() { termCh <- terminal(termCtx) }()
+		}
+		for {
+			select {
+			case resume := <-c.answer:
+				return resume
+			case resume := <-termCh:
+				if resume {
+					return true
+				}
+				termCh = nil
+			case <-ctx.Done():
+				return false
+			}
+		}
+	}
+}
+
+// Break sends the break signal, as Ctrl+\ would: a task iteration pauses, a review loop ends.
+func (c *RunControl) Break() ControlState {
+	c.sendBreak()
+	return c.State()
+}
+
+// Pause breaks the current task iteration and waits for Resume or Abort. unlike Break it is
+// refused outside the task phase, where a break ends review loops rather than pausing.
+func (c *RunControl) Pause() (ControlState, error) {
+	st := c.State()
+	if st.Paused {
+		return st, nil
+	}
+	if st.Phase != status.PhaseTask {
+		return st, ErrNotInTaskPhase
+	}
+	c.sendBreak()
+	return st, nil
+}
+
+// Resume continues a paused run.
+func (c *RunControl) Resume() (ControlState, error) {
+	if err := c.answerPause(true); err != nil {
+		return c.State(), err
+	}
+	st := c.State()
+	st.Paused = false
+	return st, nil
+}
+
+// Abort stops the run: a paused run ends as aborted by the user, a running one is canceled.
+func (c *RunControl) Abort() ControlState {
+	if err := c.answerPause(false); err != nil && c.abort != nil {
+		c.abort()
+	}
+	st := c.State()
+	st.Paused = false
+	return st
+}
+
+// State returns whether the run is paused and its current phase.
+func (c *RunControl) State() ControlState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st := ControlState{Paused: c.paused}
+	if c.holder != nil {
+		st.Phase = c.holder.Get()
+	}
+	return st
+}
+
+// answerPause ends the current pause with resume, failing when the run is not paused.
+func (c *RunControl) answerPause(resume bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.paused {
+		return ErrNotPaused
+	}
+	select {
+	case c.answer <- resume:
+	default: // already answered, the pause is ending
+	}
+	return nil
+}
+
+func (c *RunControl) setPaused(paused bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.paused = paused
+	// drop an answer left over from a previous pause
+	select {
+	case <-c.answer:
+	default:
+	}
+}
+
+func (c *RunControl) sendBreak() {
+	select {
+	case c.breakCh <- struct{}{}:
+	default: // a break is already pending
+	}
+}
+
+// SetControl enables the control endpoints for the live session sessionID. they are registered
+// only when a token is configured, so a dashboard without one can never steer the run.
+func (s *Server) SetControl(sessionID string, c *RunControl) {
+	s.controlID, s.control = sessionID, c
+}
+
+// controlEnabled reports whether the session control endpoints are served.
+func (s *Server) controlEnabled() bool {
+	return s.control != nil && s.cfg.Token != ""
+}
+
+// registerControlRoutes adds the session control endpoints, every route guarded by the bearer token.
+func (s *Server) registerControlRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/sessions/{id}/control", s.requireToken(s.handleControlState))
+	mux.HandleFunc("POST /api/sessions/{id}/{action}", s.requireToken(s.handleSessionControl))
+}
+
+// handleControlState returns whether the live session is paused and its phase.
+func (s *Server) handleControlState(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("id") != s.controlID {
+		http.Error(w, "session not found or not controllable: "+r.PathValue("id"), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.control.State())
+}
+
+// handleSessionControl breaks, pauses, resumes or aborts the live session.
+func (s *Server) handleSessionControl(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("id") != s.controlID {
+		http.Error(w, "session not found or not controllable: "+r.PathValue("id"), http.StatusNotFound)
+		return
+	}
+	var st ControlState
+	var err error
+	switch r.PathValue("action") {
+	case "break":
+		st = s.control.Break()
+	case "pause":
+		st, err = s.control.Pause()
+	case "resume":
+		st, err = s.control.Resume()
+	case "abort":
+		st = s.control.Abort()
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	writeJSON(w, http.StatusOK, st)
+}
@@ -0,0 +1,303 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// startPause runs the control's pause handler in the background and waits until it is paused.
+func startPause(t *testing.T, ctx context.Context, c *RunControl, terminal func(ctx context.Context) bool) <-chan bool {
+	t.Helper()
+	res := make(chan bool, 1)
+	go func() { res <- c.PauseHandler(terminal)(ctx) }()
+	require.Eventually(t, func() bool { return c.State().Paused }, time.Second, 5*time.Millisecond)
+	return res
+}
+
+func waitResult(t *testing.T, res <-chan bool) bool {
+	t.Helper()
+	select {
+	case v := <-res:
+		return v
+	case <-time.After(time.Second):
+		t.Fatal("pause handler did not return")
+		return false
+	}
+}
+
+// blockingTerminal is a terminal pause handler waiting for input that never comes.
+func blockingTerminal(ctx context.Context) bool {
+	<-ctx.Done()
+	return false
+}
+
+func TestRunControl_Break(t *testing.T) {
+	signals := make(chan struct{})
+	c := NewRunControl(&status.PhaseHolder{}, nil)
+	breakCh := c.BreakCh(signals)
+
+	st := c.Break()
+	assert.False(t, st.Paused)
+	c.Break() // coalesced with the pending break
+	<-breakCh
+	select {
+	case <-breakCh:
+		t.Fatal("breaks should be coalesced")
+	default:
+	}
+
+	signals <- struct{}{}
+	select {
+	case <-breakCh:
+	case <-time.After(time.Second):
+		t.Fatal("signal was not forwarded")
+	}
+	close(signals)
+}
+
+func TestRunControl_Pause(t *testing.T) {
+	holder := &status.PhaseHolder{}
+	c := NewRunControl(holder, nil)
+	breakCh := c.BreakCh(nil)
+
+	holder.Set(status.PhaseReview)
+	st, err := c.Pause()
+	require.ErrorIs(t, err, ErrNotInTaskPhase)
+	assert.Equal(t, status.PhaseReview, st.Phase)
+	assert.Empty(t, breakCh)
+
+	holder.Set(status.PhaseTask)
+	st, err = c.Pause()
+	require.NoError(t, err)
+	assert.Equal(t, status.PhaseTask, st.Phase)
+	assert.Len(t, breakCh, 1)
+	<-breakCh
+
+	res := startPause(t, t.Context(), c, blockingTerminal)
+	st, err = c.Pause()
+	require.NoError(t, err, "pausing a paused run is a no-op")
+	assert.True(t, st.Paused)
+	assert.Empty(t, breakCh)
+
+	_, err = c.Resume()
+	require.NoError(t, err)
+	assert.True(t, waitResult(t, res))
+}
+
+func TestRunControl_PauseHandler(t *testing.T) {
+	t.Run("resume from dashboard", func(t *testing.T) {
+		c := NewRunControl(nil, nil)
+		res := startPause(t, t.Context(), c, blockingTerminal)
+		st, err := c.Resume()
+		require.NoError(t, err)
+		assert.False(t, st.Paused)
+		assert.True(t, waitResult(t, res))
+		assert.False(t, c.State().Paused)
+	})
+
+	t.Run("abort from dashboard", func(t *testing.T) {
+		aborted := false
+		c := NewRunControl(nil, func() { aborted = true })
+		res := startPause(t, t.Context(), c, blockingTerminal)
+		c.Abort()
+		assert.False(t, waitResult(t, res))
+		assert.False(t, aborted, "a paused run is aborted through the pause handler")
+	})
+
+	t.Run("terminal resumes first", func(t *testing.T) {
+		c := NewRunControl(nil, nil)
+		enter := make(chan struct{})
+		res := startPause(t, t.Context(), c, func(ctx context.Context) bool {
+			select {
+			case <-enter:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+		close(enter)
+		assert.True(t, waitResult(t, res))
+		_, err := c.Resume()
+		require.ErrorIs(t, err, ErrNotPaused)
+	})
+
+	t.Run("terminal eof leaves the pause to the dashboard", func(t *testing.T) {
+		c := NewRunControl(nil, nil)
+		res := startPause(t, t.Context(), c, func(context.Context) bool { return false })
+		select {
+		case <-res:
+			t.Fatal("closed stdin should not abort the run")
+		case <-time.After(50 * time.Millisecond):
+		}
+		_, err := c.Resume()
+		require.NoError(t, err)
+		assert.True(t, waitResult(t, res))
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		c := NewRunControl(nil, nil)
+		ctx, cancel := context.WithCancel(t.Context())
+		res := startPause(t, ctx, c, nil)
+		cancel()
+		assert.False(t, waitResult(t, res))
+	})
+
+	t.Run("stale answer is dropped", func(t *testing.T) {
+		c := NewRunControl(nil, nil)
+		enter := make(chan struct{})
+		res := startPause(t, t.Context(), c, func(ctx context.Context) bool {
+			select {
+			case <-enter:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+		// queue an answer and let the terminal win the race before it is read
+		c.mu.Lock()
+		c.answer <- false
+		c.mu.Unlock()
+		close(enter)
+		waitResult(t, res)
+
+		res = startPause(t, t.Context(), c, blockingTerminal)
+		select {
+		case <-res:
+			t.Fatal("answer of the previous pause ended the new one")
+		case <-time.After(50 * time.Millisecond):
+		}
+		_, err := c.Resume()
+		require.NoError(t, err)
+		assert.True(t, waitResult(t, res))
+	})
+}
+
+func TestRunControl_AbortRunning(t *testing.T) {
+	aborted := false
+	c := NewRunControl(nil, func() { aborted = true })
+	st := c.Abort()
+	assert.True(t, aborted)
+	assert.False(t, st.Paused)
+
+	_, err := c.Resume()
+	require.ErrorIs(t, err, ErrNotPaused)
+}
+
+func newControlTest(t *testing.T, token string) (http.Handler, *RunControl, *status.PhaseHolder) {
+	t.Helper()
+	session := NewSession("main", "/tmp/progress-test.txt")
+	t.Cleanup(session.Close)
+	srv, err := NewServer(ServerConfig{Token: token}, session)
+	require.NoError(t, err)
+	holder := &status.PhaseHolder{}
+	c := NewRunControl(holder, nil)
+	srv.SetControl("main", c)
+	h, err := srv.handler()
+	require.NoError(t, err)
+	return h, c, holder
+}
+
+func TestServer_SessionControl(t *testing.T) {
+	const token = "s3cret"
+
+	t.Run("break", func(t *testing.T) {
+		h, c, holder := newControlTest(t, token)
+		breakCh := c.BreakCh(nil)
+		holder.Set(status.PhaseTask)
+		w := doRunAPI(h, http.MethodPost, "/api/sessions/main/break", token, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"paused":false,"phase":"task"}`, w.Body.String())
+		assert.Len(t, breakCh, 1)
+	})
+
+	t.Run("pause outside task phase", func(t *testing.T) {
+		h, _, holder := newControlTest(t, token)
+		holder.Set(status.PhaseCodex)
+		w := doRunAPI(h, http.MethodPost, "/api/sessions/main/pause", token, "")
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), ErrNotInTaskPhase.Error())
+	})
+
+	t.Run("pause, state and resume", func(t *testing.T) {
+		h, c, holder := newControlTest(t, token)
+		holder.Set(status.PhaseTask)
+		w := doRunAPI(h, http.MethodPost, "/api/sessions/main/resume", token, "")
+		assert.Equal(t, http.StatusConflict, w.Code, "nothing to resume")
+
+		res := startPause(t, t.Context(), c, blockingTerminal)
+		w = doRunAPI(h, http.MethodGet, "/api/sessions/main/control", token, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"paused":true,"phase":"task"}`, w.Body.String())
+
+		w = doRunAPI(h, http.MethodPost, "/api/sessions/main/resume", token, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, waitResult(t, res))
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		h, c, _ := newControlTest(t, token)
+		res := startPause(t, t.Context(), c, blockingTerminal)
+		w := doRunAPI(h, http.MethodPost, "/api/sessions/main/abort", token, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.False(t, waitResult(t, res))
+	})
+
+	t.Run("unknown session or action", func(t *testing.T) {
+		h, _, _ := newControlTest(t, token)
+		w := doRunAPI(h, http.MethodPost, "/api/sessions/other/break", token, "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = doRunAPI(h, http.MethodGet, "/api/sessions/other/control", token, "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = doRunAPI(h, http.MethodPost, "/api/sessions/main/explode", token, "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("requires token", func(t *testing.T) {
+		h, c, _ := newControlTest(t, token)
+		breakCh := c.BreakCh(nil)
+		w := doRunAPI(h, http.MethodPost, "/api/sessions/main/break", "", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		w = doRunAPI(h, http.MethodPost, "/api/sessions/main/break", "nope", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Empty(t, breakCh)
+	})
+
+	t.Run("disabled without token", func(t *testing.T) {
+		h, c, _ := newControlTest(t, "")
+		breakCh := c.BreakCh(nil)
+		w := doRunAPI(h, http.MethodPost, "/api/sessions/main/break", "", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Empty(t, breakCh)
+
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), `id="run-controls"`)
+	})
+
+	t.Run("index renders controls", func(t *testing.T) {
+		h, _, _ := newControlTest(t, token)
+		rec := doRunAPI(h, http.MethodGet, "/", token, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		body, err := io.ReadAll(rec.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), `id="run-controls" data-session="main"`)
+	})
+}
+
+func TestControlState_JSON(t *testing.T) {
+	data, err := json.Marshal(ControlState{Paused: true})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"paused":true}`, string(data))
+}
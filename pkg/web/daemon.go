@@ -0,0 +1,196 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxRunRequestSize bounds the body of a run submission, inline plans included.
+const maxRunRequestSize = 1 << 20
+
+// errors returned by RunController, mapped to HTTP status codes by the run API.
+var (
+	ErrRunNotFound  = errors.New("run not found")
+	ErrRunNotActive = errors.New("run is not running")
+	ErrInvalidRun   = errors.New("invalid run request")
+)
+
+// RunState is the lifecycle state of a daemon run.
+type RunState string
+
+// daemon run states.
+const (
+	RunQueued    RunState = "queued"
+	RunRunning   RunState = "running"
+	RunCompleted RunState = "completed"
+	RunFailed    RunState = "failed"
+	RunCanceled  RunState = "canceled"
+)
+
+// RunRequest is a plan submitted to the daemon run API. exactly one of PlanFile and Markdown is set.
+type RunRequest struct {
+	Repo      string `json:"repo"`                 // registered repository name
+	PlanFile  string `json:"plan_file,omitempty"`  // plan path relative to the repository root
+	Markdown  string `json:"markdown,omitempty"`   // inline plan, written to the repository's plans directory
+	Name      string `json:"name,omitempty"`       // file name of an inline plan, derived from its title when empty
+	TasksOnly bool   `json:"tasks_only,omitempty"` // run only the task phase
+}
+
+// RunInfo describes a daemon run.
+type RunInfo struct {
+	ID       string   `json:"id"`
+	Repo     string   `json:"repo"`
+	PlanFile string   `json:"plan_file"`
+	Branch   string   `json:"branch"`
+	Mode     string   `json:"mode"`
+	State    RunState `json:"state"`
+	Error    string   `json:"error,omitempty"`
+	// SessionID is the dashboard session streaming the run, usable as /events?session=<id>.
+	SessionID   string    `json:"session_id"`
+	SubmittedAt time.Time `json:"submitted_at"`
+	StartedAt   time.Time `json:"started_at,omitzero"`
+	FinishedAt  time.Time `json:"finished_at,omitzero"`
+}
+
+// RunController submits and controls daemon runs, implemented by serve --daemon.
+type RunController interface {
+	Repos() []string
+	Submit(req RunRequest) (RunInfo, error)
+	Runs() []RunInfo
+	Run(id string) (RunInfo, error)
+	Cancel(id string) error
+	Break(id string) error
+	Resume(id string) error
+}
+
+// SessionIDForPath returns the id of the dashboard session of a progress file.
+func SessionIDForPath(progressPath string) string {
+	return sessionIDFromPath(progressPath)
+}
+
+// registerRunRoutes adds the run API, every route guarded by the bearer token.
+func (s *Server) registerRunRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/repos", s.requireToken(s.handleRepos))
+	mux.HandleFunc("GET /api/runs", s.requireToken(s.handleRuns))
+	mux.HandleFunc("POST /api/runs", s.requireToken(s.handleSubmitRun))
+	mux.HandleFunc("GET /api/runs/{id}", s.requireToken(s.handleRun))
+	mux.HandleFunc("POST /api/runs/{id}/{action}", s.requireToken(s.handleRunAction))
+}
+
+// requireToken rejects requests without the configured token, sent as "Authorization: Bearer <token>",
+// ?token= query param or sign-in cookie; basic auth is not enough to steer runs.
+// with no token configured every request is rejected, so the API is never open by mistake.
+func (s *Server) requireToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.hasToken(r) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="ralphex"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// bearerToken extracts the token of an "Authorization: Bearer <token>" header.
+func bearerToken(r *http.Request) (string, bool) {
+	const prefix = "Bearer "
+	auth := r.Header.Get("Authorization")
+	if len(auth) < len(prefix) || !strings.EqualFold(auth[:len(prefix)], prefix) {
+		return "", false
+	}
+	return auth[len(prefix):], true
+}
+
+// handleRepos lists the registered repository names.
+func (s *Server) handleRepos(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.runs.Repos())
+}
+
+// handleRuns lists queued, running and finished runs.
+func (s *Server) handleRuns(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.runs.Runs())
+}
+
+// handleRun returns a single run.
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	info, err := s.runs.Run(r.PathValue("id"))
+	if err != nil {
+		writeRunError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+// handleSubmitRun queues a plan for execution.
+func (s *Server) handleSubmitRun(w http.ResponseWriter, r *http.Request) {
+	var req RunRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRunRequestSize)).Decode(&req); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	info, err := s.runs.Submit(req)
+	if err != nil {
+		writeRunError(w, err)
+		return
+	}
+	log.Printf("[INFO] run %s submitted: %s %s", info.ID, info.Repo, info.PlanFile)
+	writeJSON(w, http.StatusCreated, info)
+}
+
+// handleRunAction cancels, breaks or resumes a run and returns its updated state.
+func (s *Server) handleRunAction(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	var err error
+	switch r.PathValue("action") {
+	case "cancel":
+		err = s.runs.Cancel(id)
+	case "break":
+		err = s.runs.Break(id)
+	case "resume":
+		err = s.runs.Resume(id)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		writeRunError(w, err)
+		return
+	}
+	info, err := s.runs.Run(id)
+	if err != nil {
+		writeRunError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, info)
+}
+
+// writeRunError maps RunController errors to HTTP status codes.
+func writeRunError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrRunNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrRunNotActive):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, ErrInvalidRun):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Printf("[WARN] run api: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("[WARN] failed to encode response: %v", err)
+		http.Error(w, "unable to encode response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
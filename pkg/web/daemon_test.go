@@ -0,0 +1,248 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunController records submissions and actions, serving runs from a map.
+type fakeRunController struct {
+	mu        sync.Mutex
+	runs      map[string]RunInfo
+	submitted []RunRequest
+	actions   []string
+	submitErr error
+	actionErr error
+}
+
+func (f *fakeRunController) Repos() []string { return []string{"api", "web"} }
+
+func (f *fakeRunController) Submit(req RunRequest) (RunInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.submitErr != nil {
+		return RunInfo{}, f.submitErr
+	}
+	f.submitted = append(f.submitted, req)
+	info := RunInfo{ID: "3", Repo: req.Repo, PlanFile: req.PlanFile, State: RunQueued}
+	f.runs[info.ID] = info
+	return info, nil
+}
+
+func (f *fakeRunController) Runs() []RunInfo {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	res := make([]RunInfo, 0, len(f.runs))
+	for _, id := range []string{"1", "2", "3"} {
+		if r, ok := f.runs[id]; ok {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+func (f *fakeRunController) Run(id string) (RunInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r, ok := f.runs[id]
+	if !ok {
+		return RunInfo{}, fmt.Errorf("%w: %s", ErrRunNotFound, id)
+	}
+	return r, nil
+}
+
+func (f *fakeRunController) action(name, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.runs[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrRunNotFound, id)
+	}
+	if f.actionErr != nil {
+		return f.actionErr
+	}
+	f.actions = append(f.actions, name+" "+id)
+	return nil
+}
+
+func (f *fakeRunController) Cancel(id string) error { return f.action("cancel", id) }
+func (f *fakeRunController) Break(id string) error  { return f.action("break", id) }
+func (f *fakeRunController) Resume(id string) error { return f.action("resume", id) }
+
+func newRunAPITest(t *testing.T, token string) (http.Handler, *fakeRunController) {
+	t.Helper()
+	runs := &fakeRunController{runs: map[string]RunInfo{
+		"1": {ID: "1", Repo: "api", PlanFile: "docs/plans/a.md", State: RunCompleted},
+		"2": {ID: "2", Repo: "web", PlanFile: "docs/plans/b.md", State: RunRunning, StartedAt: time.Now()},
+	}}
+	srv, err := NewDaemonServer(ServerConfig{Token: token}, NewSessionManager(), runs)
+	require.NoError(t, err)
+	h, err := srv.handler()
+	require.NoError(t, err)
+	return h, runs
+}
+
+func doRunAPI(h http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestServer_RunAPIAuth(t *testing.T) {
+	h, _ := newRunAPITest(t, "s3cret")
+
+	t.Run("missing token", func(t *testing.T) {
+		w := doRunAPI(h, http.MethodGet, "/api/runs", "", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Bearer")
+	})
+
+	t.Run("wrong token", func(t *testing.T) {
+		w := doRunAPI(h, http.MethodPost, "/api/runs/2/cancel", "nope", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("basic auth is not a bearer token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/runs", http.NoBody)
+		req.SetBasicAuth("user", "s3cret")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("scheme is case insensitive", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/runs", http.NoBody)
+		req.Header.Set("Authorization", "bearer s3cret")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("no token configured rejects everything", func(t *testing.T) {
+		open, _ := newRunAPITest(t, "")
+		w := doRunAPI(open, http.MethodGet, "/api/runs", "", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		req := httptest.NewRequest(http.MethodGet, "/api/runs", http.NoBody)
+		req.Header.Set("Authorization", "Bearer ")
+		w = httptest.NewRecorder()
+		open.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestServer_RunAPI(t *testing.T) {
+	const token = "s3cret"
+
+	t.Run("repos", func(t *testing.T) {
+		h, _ := newRunAPITest(t, token)
+		w := doRunAPI(h, http.MethodGet, "/api/repos", token, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `["api","web"]`, w.Body.String())
+	})
+
+	t.Run("list runs", func(t *testing.T) {
+		h, _ := newRunAPITest(t, token)
+		w := doRunAPI(h, http.MethodGet, "/api/runs", token, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		var runs []map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &runs))
+		require.Len(t, runs, 2)
+		assert.Equal(t, "completed", runs[0]["state"])
+		assert.NotContains(t, runs[0], "started_at", "zero times are omitted")
+		assert.Equal(t, "running", runs[1]["state"])
+		assert.Contains(t, runs[1], "started_at")
+	})
+
+	t.Run("get run", func(t *testing.T) {
+		h, _ := newRunAPITest(t, token)
+		w := doRunAPI(h, http.MethodGet, "/api/runs/2", token, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var info RunInfo
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &info))
+		assert.Equal(t, "web", info.Repo)
+
+		w = doRunAPI(h, http.MethodGet, "/api/runs/42", token, "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("submit", func(t *testing.T) {
+		h, runs := newRunAPITest(t, token)
+		w := doRunAPI(h, http.MethodPost, "/api/runs", token,
+			`{"repo":"api","markdown":"# Plan\n","name":"fix-login","tasks_only":true}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.Len(t, runs.submitted, 1)
+		assert.Equal(t, RunRequest{Repo: "api", Markdown: "# Plan\n", Name: "fix-login", TasksOnly: true}, runs.submitted[0])
+		var info RunInfo
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &info))
+		assert.Equal(t, RunQueued, info.State)
+	})
+
+	t.Run("submit invalid body", func(t *testing.T) {
+		h, runs := newRunAPITest(t, token)
+		w := doRunAPI(h, http.MethodPost, "/api/runs", token, `{"repo":`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Empty(t, runs.submitted)
+	})
+
+	t.Run("submit rejected", func(t *testing.T) {
+		h, runs := newRunAPITest(t, token)
+		runs.submitErr = fmt.Errorf("%w: unknown repo %q", ErrInvalidRun, "nope")
+		w := doRunAPI(h, http.MethodPost, "/api/runs", token, `{"repo":"nope","plan_file":"a.md"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `unknown repo "nope"`)
+	})
+
+	t.Run("actions", func(t *testing.T) {
+		h, runs := newRunAPITest(t, token)
+		for _, action := range []string{"break", "resume", "cancel"} {
+			w := doRunAPI(h, http.MethodPost, "/api/runs/2/"+action, token, "")
+			assert.Equal(t, http.StatusOK, w.Code, action)
+		}
+		assert.Equal(t, []string{"break 2", "resume 2", "cancel 2"}, runs.actions)
+
+		w := doRunAPI(h, http.MethodPost, "/api/runs/2/explode", token, "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = doRunAPI(h, http.MethodPost, "/api/runs/42/cancel", token, "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = doRunAPI(h, http.MethodGet, "/api/runs/2/cancel", token, "")
+		assert.Equal(t, http.StatusNotFound, w.Code, "actions are POST only")
+		assert.Empty(t, runs.actions[3:])
+	})
+
+	t.Run("action on finished run", func(t *testing.T) {
+		h, runs := newRunAPITest(t, token)
+		runs.actionErr = fmt.Errorf("%w: run 1 is completed", ErrRunNotActive)
+		w := doRunAPI(h, http.MethodPost, "/api/runs/1/break", token, "")
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("no run api without daemon", func(t *testing.T) {
+		srv, err := NewServerWithSessions(ServerConfig{Token: token}, NewSessionManager())
+		require.NoError(t, err)
+		h, err := srv.handler()
+		require.NoError(t, err)
+		w := doRunAPI(h, http.MethodGet, "/api/runs", token, "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestSessionIDForPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".ralphex", "progress", "progress-feature.txt")
+	id := SessionIDForPath(path)
+	assert.Equal(t, sessionIDFromPath(path), id)
+	assert.True(t, strings.HasPrefix(id, "feature-"))
+}
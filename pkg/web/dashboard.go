@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net"
 	"path/filepath"
 	"time"
 
@@ -24,6 +25,11 @@ func ConnectHost(host string) string {
 	return host
 }
 
+// DashboardURL returns the user-facing address of a dashboard bound to host and port.
+func DashboardURL(host string, port int, tls TLSConfig) string {
+	return fmt.Sprintf("%s://%s:%d", tls.Scheme(), ConnectHost(host), port)
+}
+
 // DashboardConfig holds configuration for dashboard initialization.
 type DashboardConfig struct {
 	BaseLog         Logger           // base progress logger
@@ -35,6 +41,12 @@ type DashboardConfig struct {
 	WatchDirs       []string         // CLI watch directories
 	ConfigWatchDirs []string         // config file watch directories
 	Colors          *progress.Colors // colors for output
+	Token           string           // bearer token required by the run API and the session controls, and by every route when set
+	User            string           // basic auth user accepted on every route, with Password
+	Password        string           // basic auth password
+	TLS             TLSConfig        // serve HTTPS instead of HTTP
+	Control         *RunControl      // steers the live run from the dashboard, enabled only with Token
+	Input           *InputCollector  // answers plan creation questions and drafts from the dashboard
 }
 
 // Dashboard manages web server and file watching for progress monitoring.
@@ -48,7 +60,14 @@ type Dashboard struct {
 	watchDirs       []string
 	configWatchDirs []string
 	colors          *progress.Colors
+	token           string
+	user            string
+	password        string
+	tls             TLSConfig
+	control         *RunControl
+	input           *InputCollector
 	holder          *status.PhaseHolder
+	done            chan struct{} // closed once the server started by Start stops
 }
 
 // NewDashboard creates a new dashboard with the given configuration.
@@ -63,7 +82,14 @@ func NewDashboard(cfg DashboardConfig, holder *status.PhaseHolder) *Dashboard {
 		watchDirs:       cfg.WatchDirs,
 		configWatchDirs: cfg.ConfigWatchDirs,
 		colors:          cfg.Colors,
+		token:           cfg.Token,
+		user:            cfg.User,
+		password:        cfg.Password,
+		tls:             cfg.TLS,
+		control:         cfg.Control,
+		input:           cfg.Input,
 		holder:          holder,
+		done:            make(chan struct{}),
 	}
 }
 
@@ -88,6 +114,10 @@ func (d *Dashboard) Start(ctx context.Context) (*BroadcastLogger, error) {
 		Branch:    d.branch,
 		RunParams: d.runParams,
 		PlanFile:  d.planFile,
+		Token:     d.token,
+		User:      d.user,
+		Password:  d.password,
+		TLS:       d.tls,
 	}
 
 	// determine if we should use multi-session mode
@@ -127,6 +157,14 @@ func (d *Dashboard) Start(ctx context.Context) (*BroadcastLogger, error) {
 		}
 	}
 
+	if d.control != nil {
+		srv.SetControl(session.ID, d.control)
+	}
+	if d.input != nil {
+		d.input.attach(session)
+		srv.SetInput(d.input)
+	}
+
 	// start server with startup check
 	srvErrCh, err := startServerAsync(ctx, srv, d.port)
 	if err != nil {
@@ -146,15 +184,32 @@ func (d *Dashboard) Start(ctx context.Context) (*BroadcastLogger, error) {
 	// monitor for late server errors in background
 	// these are logged but don't fail the main execution since the dashboard is supplementary
 	go func() {
+		defer close(d.done)
 		if srvErr := <-srvErrCh; srvErr != nil {
 			log.Printf("[WARN] web server error during execution: %v", srvErr)
+			return
 		}
+		// shut down by ctx: end open event streams, so browsers reconnect to whichever dashboard serves the port next
+		session.Close()
 	}()
 
-	d.colors.Info().Printf("web dashboard: http://%s:%d\n", ConnectHost(d.host), d.port)
+	d.colors.Info().Printf("web dashboard: %s\n", d.url())
+	d.printAccess(srv)
+	if srv.controlEnabled() {
+		d.colors.Info().Printf("dashboard controls enabled: break, pause, resume and abort\n")
+	}
+	if d.input != nil {
+		d.colors.Info().Printf("plan questions and drafts can be answered in the dashboard\n")
+	}
 	return broadcastLog, nil
 }
 
+// Done returns a channel closed once the server started by Start has stopped after its ctx was canceled,
+// so another dashboard can take over the port.
+func (d *Dashboard) Done() <-chan struct{} {
+	return d.done
+}
+
 // RunWatchOnly runs the web dashboard in watch-only mode without plan execution.
 // monitors directories for progress files and serves the multi-session dashboard.
 func (d *Dashboard) RunWatchOnly(ctx context.Context, dirs []string) error {
@@ -164,7 +219,7 @@ func (d *Dashboard) RunWatchOnly(ctx context.Context, dirs []string) error {
 	}
 
 	// setup server and watcher
-	srvErrCh, watchErrCh, err := d.setupWatchMode(ctx, dirs)
+	srvErrCh, watchErrCh, err := d.setupWatchMode(ctx, dirs, nil)
 	if err != nil {
 		return err
 	}
@@ -176,9 +231,31 @@ func (d *Dashboard) RunWatchOnly(ctx context.Context, dirs []string) error {
 	return d.monitorErrors(ctx, srvErrCh, watchErrCh)
 }
 
-// setupWatchMode creates and starts the web server and file watcher for watch-only mode.
-// returns error channels for monitoring both components.
-func (d *Dashboard) setupWatchMode(ctx context.Context, dirs []string) (chan error, chan error, error) {
+// RunDaemon serves the multi-session dashboard together with the run API of serve --daemon
+// until ctx is canceled. dirs are watched for the progress files of the daemon's runs.
+func (d *Dashboard) RunDaemon(ctx context.Context, dirs []string, runs RunController) error {
+	if len(dirs) == 0 {
+		return errors.New("no watch directories configured")
+	}
+
+	srvErrCh, watchErrCh, err := d.setupWatchMode(ctx, dirs, runs)
+	if err != nil {
+		return err
+	}
+
+	d.colors.Info().Printf("daemon mode: accepting runs for %d repositories\n", len(dirs))
+	for _, dir := range dirs {
+		d.colors.Info().Printf("  %s\n", dir)
+	}
+	d.colors.Info().Printf("web dashboard and run API: %s\n", d.url())
+	d.colors.Info().Printf("press Ctrl+C to exit\n")
+
+	return d.monitorErrors(ctx, srvErrCh, watchErrCh)
+}
+
+// setupWatchMode creates and starts the web server and file watcher for watch-only and daemon mode.
+// runs, when set, adds the run API of serve --daemon. returns error channels for monitoring both components.
+func (d *Dashboard) setupWatchMode(ctx context.Context, dirs []string, runs RunController) (chan error, chan error, error) {
 	sm := NewSessionManager()
 	watcher, err := NewWatcher(dirs, sm)
 	if err != nil {
@@ -191,9 +268,19 @@ func (d *Dashboard) setupWatchMode(ctx context.Context, dirs []string) (chan err
 		PlanName: "(watch mode)",
 		Branch:   "",
 		PlanFile: "",
+		Token:    d.token,
+		User:     d.user,
+		Password: d.password,
+		TLS:      d.tls,
 	}
 
-	srv, err := NewServerWithSessions(serverCfg, sm)
+	var srv *Server
+	if runs != nil {
+		serverCfg.PlanName = "(daemon)"
+		srv, err = NewDaemonServer(serverCfg, sm, runs)
+	} else {
+		srv, err = NewServerWithSessions(serverCfg, sm)
+	}
 	if err != nil {
 		return nil, nil, fmt.Errorf("create web server: %w", err)
 	}
@@ -203,6 +290,7 @@ func (d *Dashboard) setupWatchMode(ctx context.Context, dirs []string) (chan err
 	if err != nil {
 		return nil, nil, err
 	}
+	d.printAccess(srv)
 
 	// start watcher in background
 	watchErrCh := make(chan error, 1)
@@ -271,12 +359,39 @@ func (d *Dashboard) monitorErrors(ctx context.Context, srvErrCh, watchErrCh chan
 	}
 }
 
+// url returns the user-facing address of the dashboard.
+func (d *Dashboard) url() string {
+	return DashboardURL(d.host, d.port, d.tls)
+}
+
+// printAccess prints the fingerprint of a self-signed certificate, and warns when the dashboard
+// listens beyond loopback without authentication.
+func (d *Dashboard) printAccess(srv *Server) {
+	if d.tls.SelfSigned {
+		if fp, err := srv.CertFingerprint(); err == nil {
+			d.colors.Info().Printf("self-signed TLS certificate, SHA-256 fingerprint: %s\n", fp)
+		}
+	}
+	if !srv.cfg.authEnabled() && !isLoopbackHost(d.host) {
+		d.colors.Warn().Printf("web dashboard on %s has no authentication, set web_token or web_user and web_password\n", d.host)
+	}
+}
+
+// isLoopbackHost reports whether host only accepts local connections; empty means the 127.0.0.1 default.
+func isLoopbackHost(host string) bool {
+	if host == "" || host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 // printWatchInfo prints startup information for watch-only mode.
 func (d *Dashboard) printWatchInfo(dirs []string) {
 	d.colors.Info().Printf("watch-only mode: monitoring %d directories\n", len(dirs))
 	for _, dir := range dirs {
 		d.colors.Info().Printf("  %s\n", dir)
 	}
-	d.colors.Info().Printf("web dashboard: http://%s:%d\n", ConnectHost(d.host), d.port)
+	d.colors.Info().Printf("web dashboard: %s\n", d.url())
 	d.colors.Info().Printf("press Ctrl+C to exit\n")
 }
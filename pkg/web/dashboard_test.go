@@ -2,8 +2,10 @@ package web
 
 import (
 	"context"
+	"net"
 	"os"
 	"path/filepath"
+	"strconv"
 	"testing"
 	"time"
 
@@ -77,6 +79,43 @@ func TestDashboard_Start_SingleSession(t *testing.T) {
 	assert.Equal(t, baseLog.Path(), broadcastLog.Path())
 }
 
+func TestDashboard_Done(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldWd, wdErr := os.Getwd()
+	require.NoError(t, wdErr)
+	require.NoError(t, os.Chdir(tmpDir))
+	t.Cleanup(func() { _ = os.Chdir(oldWd) })
+
+	colors := testColors()
+	holder := &status.PhaseHolder{}
+	baseLog, err := progress.NewLogger(progress.Config{PlanDescription: "add caching", Mode: "plan", Branch: "main", NoColor: true},
+		colors, holder)
+	require.NoError(t, err)
+	defer baseLog.Close()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	require.NoError(t, ln.Close())
+
+	d := NewDashboard(DashboardConfig{BaseLog: baseLog, Port: port, Colors: colors, Input: NewInputCollector(nil)}, holder)
+	ctx, cancel := context.WithCancel(t.Context())
+	_, err = d.Start(ctx)
+	require.NoError(t, err)
+
+	cancel()
+	select {
+	case <-d.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("dashboard did not stop")
+	}
+
+	// the port is free for the dashboard of the next phase
+	ln, err = net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	require.NoError(t, err)
+	require.NoError(t, ln.Close())
+}
+
 func TestDashboard_Start_MultiSession(t *testing.T) {
 	tmpDir := t.TempDir()
 	oldWd, wdErr := os.Getwd()
@@ -164,7 +203,7 @@ func TestDashboard_SetupWatchMode(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
-	srvErrCh, watchErrCh, err := d.setupWatchMode(ctx, []string{tmpDir})
+	srvErrCh, watchErrCh, err := d.setupWatchMode(ctx, []string{tmpDir}, nil)
 	require.NoError(t, err)
 	assert.NotNil(t, srvErrCh)
 	assert.NotNil(t, watchErrCh)
@@ -319,3 +358,17 @@ func TestConnectHost(t *testing.T) {
 		})
 	}
 }
+
+func TestDashboardURL(t *testing.T) {
+	assert.Equal(t, "http://localhost:8080", DashboardURL("127.0.0.1", 8080, TLSConfig{}))
+	assert.Equal(t, "https://192.168.1.10:8443", DashboardURL("192.168.1.10", 8443, TLSConfig{SelfSigned: true}))
+}
+
+func TestIsLoopbackHost(t *testing.T) {
+	for _, host := range []string{"", "localhost", "127.0.0.1", "::1"} {
+		assert.True(t, isLoopbackHost(host), host)
+	}
+	for _, host := range []string{"0.0.0.0", "::", "192.168.1.10", "myhost.local"} {
+		assert.False(t, isLoopbackHost(host), host)
+	}
+}
@@ -23,6 +23,9 @@ const (
 	EventTypeTaskStart      EventType = "task_start"      // task execution started
 	EventTypeTaskEnd        EventType = "task_end"        // task execution ended
 	EventTypeIterationStart EventType = "iteration_start" // review/codex iteration started
+	EventTypeQuestion       EventType = "question"        // plan creation question waiting for an answer
+	EventTypeDraft          EventType = "draft"           // plan draft waiting for review
+	EventTypeInputDone      EventType = "input_done"      // question or draft answered, text tells by whom
 )
 
 const (
@@ -42,6 +45,9 @@ type Event struct {
 	Signal       string       `json:"signal,omitempty"`
 	TaskNum      int          `json:"task_num,omitempty"`      // 1-based task position in plan (array index + 1)
 	IterationNum int          `json:"iteration_num,omitempty"` // 1-based iteration index for review/codex phases
+	InputID      string       `json:"input_id,omitempty"`      // id of a question or draft, used to answer it
+	Options      []string     `json:"options,omitempty"`       // options of a question
+	Plan         string       `json:"plan,omitempty"`          // plan content of a draft
 }
 
 // NewOutputEvent creates an output event with current timestamp.
@@ -109,6 +115,40 @@ func NewIterationStartEvent(phase status.Phase, iterationNum int, text string) E
 	}
 }
 
+// NewQuestionEvent creates a plan creation question event. the input id is set when it is asked.
+func NewQuestionEvent(question string, options []string) Event {
+	return Event{
+		Type:      EventTypeQuestion,
+		Phase:     status.PhasePlan,
+		Text:      question,
+		Options:   options,
+		Timestamp: time.Now(),
+	}
+}
+
+// NewDraftEvent creates a plan draft review event. the input id is set when it is asked.
+func NewDraftEvent(question, planContent string) Event {
+	return Event{
+		Type:      EventTypeDraft,
+		Phase:     status.PhasePlan,
+		Text:      question,
+		Plan:      planContent,
+		Timestamp: time.Now(),
+	}
+}
+
+// NewInputDoneEvent creates the event closing question or draft id, answered by "dashboard",
+// "terminal" or "canceled".
+func NewInputDoneEvent(id, by string) Event {
+	return Event{
+		Type:      EventTypeInputDone,
+		Phase:     status.PhasePlan,
+		Text:      by,
+		InputID:   id,
+		Timestamp: time.Now(),
+	}
+}
+
 // MarshalJSON implements json.Marshaler for SSE streaming.
 // this allows Event to be used directly with json.Marshal.
 func (e Event) MarshalJSON() ([]byte, error) {
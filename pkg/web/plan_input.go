@@ -0,0 +1,278 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/umputun/ralphex/pkg/input"
+)
+
+// maxInputRequestSize bounds the body of a plan input answer, draft feedback included.
+const maxInputRequestSize = 1 << 20
+
+// errors returned by InputCollector, answered with 409 Conflict and 400 Bad Request by the input endpoints.
+var (
+	ErrNoPendingInput = errors.New("no pending question or draft with this id")
+	ErrInvalidInput   = errors.New("invalid input")
+)
+
+// TerminalInput is the terminal side of plan creation input, implemented by input.TerminalCollector.
+type TerminalInput interface {
+	AskQuestion(ctx context.Context, question string, options []string) (string, error)
+	AskDraftReview(ctx context.Context, question, planContent string) (action, feedback string, err error)
+}
+
+// InputCollector answers plan creation questions and draft reviews from the dashboard. each question
+// and draft is pushed to the browser as an SSE event and asked on the terminal at the same time;
+// whichever answers first wins, and an input_done event tells the browsers it was answered.
+type InputCollector struct {
+	terminal TerminalInput
+
+	mu      sync.Mutex
+	session *Session
+	lastID  int
+	pending *pendingInput
+}
+
+// pendingInput is the question or draft waiting for an answer.
+type pendingInput struct {
+	id     string
+	kind   EventType // EventTypeQuestion or EventTypeDraft
+	answer chan inputAnswer
+}
+
+// inputAnswer is the answer to a question (Answer) or a draft review (Action and Feedback).
+type inputAnswer struct {
+	Answer   string `json:"answer,omitempty"`
+	Action   string `json:"action,omitempty"`
+	Feedback string `json:"feedback,omitempty"`
+}
+
+// NewInputCollector creates an InputCollector racing the dashboard against terminal, which may be nil
+// to answer from the dashboard only. it publishes to the live session once the dashboard is started.
+func NewInputCollector(terminal TerminalInput) *InputCollector {
+	return &InputCollector{terminal: terminal}
+}
+
+// AskQuestion asks the question on the terminal and in the dashboard, returning the first answer.
+func (c *InputCollector) AskQuestion(ctx context.Context, question string, options []string) (string, error) {
+	if len(options) == 0 {
+		return "", errors.New("no options provided")
+	}
+	var terminal func(ctx context.Context) (inputAnswer, error)
+	if c.terminal != nil {
+		terminal = func(ctx context.Context) (inputAnswer, error) {
+			answer, err := c.terminal.AskQuestion(ctx, question, options)
+			return inputAnswer{Answer: answer}, err //nolint:wrapcheck // terminal errors are only logged
+		}
+	}
+	ev := NewQuestionEvent(question, options)
+	res, err := c.ask(ctx, ev, terminal)
+	if err != nil {
+		return "", err
+	}
+	return res.Answer, nil
+}
+
+// AskDraftReview shows the plan draft on the terminal and in the dashboard, returning the first
+// action ("accept", "revise" or "reject") and the feedback of a revision.
+func (c *InputCollector) AskDraftReview(ctx context.Context, question, planContent string) (string, string, error) {
+	var terminal func(ctx context.Context) (inputAnswer, error)
+	if c.terminal != nil {
+		terminal = func(ctx context.Context) (inputAnswer, error) {
+			action, feedback, err := c.terminal.AskDraftReview(ctx, question, planContent)
+			return inputAnswer{Action: action, Feedback: feedback}, err //nolint:wrapcheck // terminal errors are only logged
+		}
+	}
+	ev := NewDraftEvent(question, planContent)
+	res, err := c.ask(ctx, ev, terminal)
+	if err != nil {
+		return "", "", err
+	}
+	return res.Action, res.Feedback, nil
+}
+
+// ask publishes ev as the pending input and waits for the dashboard, the terminal or ctx.
+// a terminal error without ctx being canceled (stdin closed, fzf canceled) leaves the answer
+// to the dashboard, as a detached run has no usable terminal.
+func (c *InputCollector) ask(ctx context.Context, ev Event, terminal func(ctx context.Context) (inputAnswer, error)) (inputAnswer, error) {
+	p := c.open(ev)
+	defer c.close(p)
+
+	termCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type termResult struct {
+		answer inputAnswer
+		err    error
+	}
+	termCh := make(chan termResult, 1)
+	if terminal != nil {
+		go func() {
+			answer, err := terminal(termCtx)
+			termCh <- termResult{answer: answer, err: err}
+		}()
+	}
+
+	for {
+		select {
+		case answer := <-p.answer:
+			c.publish(NewInputDoneEvent(p.id, "dashboard"))
+			return answer, nil
+		case res := <-termCh:
+			if res.err == nil {
+				c.publish(NewInputDoneEvent(p.id, "terminal"))
+				return res.answer, nil
+			}
+			if ctx.Err() == nil {
+				log.Printf("[WARN] terminal input failed, waiting for the dashboard: %v", res.err)
+			}
+			termCh = nil
+		case <-ctx.Done():
+			c.publish(NewInputDoneEvent(p.id, "canceled"))
+			return inputAnswer{}, fmt.Errorf("wait for input: %w", ctx.Err())
+		}
+	}
+}
+
+// Answer answers the pending question id from the dashboard.
+func (c *InputCollector) Answer(id, answer string) error {
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return fmt.Errorf("%w: answer cannot be empty", ErrInvalidInput)
+	}
+	return c.submit(id, EventTypeQuestion, inputAnswer{Answer: answer})
+}
+
+// ReviewDraft answers the pending draft review id from the dashboard. revise requires feedback.
+func (c *InputCollector) ReviewDraft(id, action, feedback string) error {
+	feedback = strings.TrimSpace(feedback)
+	switch action {
+	case input.ActionAccept, input.ActionReject:
+		feedback = ""
+	case input.ActionRevise:
+		if feedback == "" {
+			return fmt.Errorf("%w: revision feedback cannot be empty", ErrInvalidInput)
+		}
+	default:
+		return fmt.Errorf("%w: unknown action %q", ErrInvalidInput, action)
+	}
+	return c.submit(id, EventTypeDraft, inputAnswer{Action: action, Feedback: feedback})
+}
+
+// submit delivers answer to the pending input id of the given kind, failing when it is not pending.
+func (c *InputCollector) submit(id string, kind EventType, answer inputAnswer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending == nil || c.pending.id != id || c.pending.kind != kind {
+		return fmt.Errorf("%w: %s", ErrNoPendingInput, id)
+	}
+	select {
+	case c.pending.answer <- answer:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s is already answered", ErrNoPendingInput, id)
+	}
+}
+
+// open makes ev the pending input under a new id and publishes it.
+func (c *InputCollector) open(ev Event) *pendingInput {
+	c.mu.Lock()
+	c.lastID++
+	p := &pendingInput{id: strconv.Itoa(c.lastID), kind: ev.Type, answer: make(chan inputAnswer, 1)}
+	c.pending = p
+	c.mu.Unlock()
+
+	ev.InputID = p.id
+	c.publish(ev)
+	return p
+}
+
+func (c *InputCollector) close(p *pendingInput) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending == p {
+		c.pending = nil
+	}
+}
+
+// attach sets the session the questions and drafts are published to.
+func (c *InputCollector) attach(session *Session) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.session = session
+}
+
+func (c *InputCollector) publish(ev Event) {
+	c.mu.Lock()
+	session := c.session
+	c.mu.Unlock()
+	if session == nil {
+		return
+	}
+	if err := session.Publish(ev); err != nil {
+		log.Printf("[WARN] failed to publish input event: %v", err)
+	}
+}
+
+// SetInput enables the plan input endpoints answering c from the dashboard. with a token
+// configured they require it, as the rest of the steering endpoints do.
+func (s *Server) SetInput(c *InputCollector) {
+	s.input = c
+}
+
+// registerInputRoutes adds the plan input endpoints.
+func (s *Server) registerInputRoutes(mux *http.ServeMux) {
+	guard := func(h http.HandlerFunc) http.HandlerFunc { return h }
+	if s.cfg.Token != "" {
+		guard = s.requireToken
+	}
+	mux.HandleFunc("POST /api/input/{id}/answer", guard(s.handleInputAnswer))
+	mux.HandleFunc("POST /api/input/{id}/draft", guard(s.handleInputDraft))
+}
+
+// handleInputAnswer answers a pending question, body {"answer": "..."}.
+func (s *Server) handleInputAnswer(w http.ResponseWriter, r *http.Request) {
+	var req inputAnswer
+	if !decodeInput(w, r, &req) {
+		return
+	}
+	writeInputResult(w, s.input.Answer(r.PathValue("id"), req.Answer))
+}
+
+// handleInputDraft accepts, revises or rejects a pending draft, body {"action": "...", "feedback": "..."}.
+func (s *Server) handleInputDraft(w http.ResponseWriter, r *http.Request) {
+	var req inputAnswer
+	if !decodeInput(w, r, &req) {
+		return
+	}
+	writeInputResult(w, s.input.ReviewDraft(r.PathValue("id"), req.Action, req.Feedback))
+}
+
+func decodeInput(w http.ResponseWriter, r *http.Request, v *inputAnswer) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxInputRequestSize)).Decode(v); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// writeInputResult maps InputCollector errors to HTTP status codes.
+func writeInputResult(w http.ResponseWriter, err error) {
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, ErrInvalidInput):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNoPendingInput):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
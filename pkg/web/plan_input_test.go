@@ -0,0 +1,313 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/umputun/ralphex/pkg/input"
+	"github.com/umputun/ralphex/pkg/status"
+)
+
+// fakeTerminal answers plan input like input.TerminalCollector, blocking until ctx is canceled when
+// no answer is set.
+type fakeTerminal struct {
+	answer   string
+	action   string
+	feedback string
+	err      error
+	block    bool
+}
+
+func (f *fakeTerminal) AskQuestion(ctx context.Context, _ string, _ []string) (string, error) {
+	if f.block {
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+	return f.answer, f.err
+}
+
+func (f *fakeTerminal) AskDraftReview(ctx context.Context, _, _ string) (string, string, error) {
+	if f.block {
+		<-ctx.Done()
+		return "", "", ctx.Err()
+	}
+	return f.action, f.feedback, f.err
+}
+
+func newInputTest(t *testing.T, terminal TerminalInput, token string) (*InputCollector, http.Handler) {
+	t.Helper()
+	session := NewSession("main", "/tmp/progress-plan.txt")
+	t.Cleanup(session.Close)
+	// replay starts after the first event of a session; real runs always open with the progress header
+	require.NoError(t, session.Publish(NewOutputEvent(status.PhasePlan, "plan creation started")))
+	c := NewInputCollector(terminal)
+	c.attach(session)
+	srv, err := NewServer(ServerConfig{Token: token}, session)
+	require.NoError(t, err)
+	srv.SetInput(c)
+	h, err := srv.handler()
+	require.NoError(t, err)
+	return c, h
+}
+
+// waitPending waits until the collector has a pending input and returns its id.
+func waitPending(t *testing.T, c *InputCollector) string {
+	t.Helper()
+	var id string
+	require.Eventually(t, func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.pending == nil {
+			return false
+		}
+		id = c.pending.id
+		return true
+	}, time.Second, 5*time.Millisecond)
+	return id
+}
+
+// readEvents replays the session's events through the SSE endpoint until n events are read.
+func readEvents(t *testing.T, h http.Handler, n int) []Event {
+	t.Helper()
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+	ctx, cancel := context.WithTimeout(t.Context(), 2*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/events", http.NoBody)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var events []Event
+	scanner := bufio.NewScanner(resp.Body)
+	for len(events) < n && scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var ev Event
+		require.NoError(t, json.Unmarshal([]byte(data), &ev))
+		events = append(events, ev)
+	}
+	require.Len(t, events, n)
+	return events
+}
+
+func TestInputCollector_AskQuestion(t *testing.T) {
+	t.Run("dashboard answers first", func(t *testing.T) {
+		c, h := newInputTest(t, &fakeTerminal{block: true}, "")
+		res := make(chan string, 1)
+		go func() {
+			answer, err := c.AskQuestion(t.Context(), "Which database?", []string{"postgres", "sqlite"})
+			assert.NoError(t, err)
+			res <- answer
+		}()
+		id := waitPending(t, c)
+		w := doRunAPI(h, http.MethodPost, "/api/input/"+id+"/answer", "", `{"answer":"sqlite"}`)
+		require.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "sqlite", <-res)
+
+		events := readEvents(t, h, 2)
+		assert.Equal(t, EventTypeQuestion, events[0].Type)
+		assert.Equal(t, "Which database?", events[0].Text)
+		assert.Equal(t, []string{"postgres", "sqlite"}, events[0].Options)
+		assert.Equal(t, id, events[0].InputID)
+		assert.Equal(t, EventTypeInputDone, events[1].Type)
+		assert.Equal(t, id, events[1].InputID)
+		assert.Equal(t, "dashboard", events[1].Text)
+
+		w = doRunAPI(h, http.MethodPost, "/api/input/"+id+"/answer", "", `{"answer":"postgres"}`)
+		assert.Equal(t, http.StatusConflict, w.Code, "answered questions can't be answered again")
+	})
+
+	t.Run("terminal answers first", func(t *testing.T) {
+		c, h := newInputTest(t, &fakeTerminal{answer: "postgres"}, "")
+		answer, err := c.AskQuestion(t.Context(), "Which database?", []string{"postgres", "sqlite"})
+		require.NoError(t, err)
+		assert.Equal(t, "postgres", answer)
+
+		events := readEvents(t, h, 2)
+		assert.Equal(t, "terminal", events[1].Text)
+		require.ErrorIs(t, c.Answer(events[0].InputID, "sqlite"), ErrNoPendingInput)
+	})
+
+	t.Run("terminal failure leaves the answer to the dashboard", func(t *testing.T) {
+		c, _ := newInputTest(t, &fakeTerminal{err: errors.New("read input: EOF")}, "")
+		res := make(chan string, 1)
+		go func() {
+			answer, err := c.AskQuestion(t.Context(), "Which database?", []string{"postgres"})
+			assert.NoError(t, err)
+			res <- answer
+		}()
+		id := waitPending(t, c)
+		require.NoError(t, c.Answer(id, "custom answer"))
+		assert.Equal(t, "custom answer", <-res)
+	})
+
+	t.Run("dashboard only", func(t *testing.T) {
+		c := NewInputCollector(nil)
+		res := make(chan string, 1)
+		go func() {
+			answer, err := c.AskQuestion(t.Context(), "q", []string{"a"})
+			assert.NoError(t, err)
+			res <- answer
+		}()
+		id := waitPending(t, c)
+		require.ErrorIs(t, c.Answer(id, "  "), ErrInvalidInput)
+		require.ErrorIs(t, c.ReviewDraft(id, "accept", ""), ErrNoPendingInput, "a question is not a draft")
+		require.NoError(t, c.Answer(id, " a "))
+		assert.Equal(t, "a", <-res)
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		c := NewInputCollector(&fakeTerminal{block: true})
+		ctx, cancel := context.WithCancel(t.Context())
+		errCh := make(chan error, 1)
+		go func() {
+			_, err := c.AskQuestion(ctx, "q", []string{"a"})
+			errCh <- err
+		}()
+		id := waitPending(t, c)
+		cancel()
+		require.ErrorIs(t, <-errCh, context.Canceled)
+		require.ErrorIs(t, c.Answer(id, "a"), ErrNoPendingInput)
+	})
+
+	t.Run("no options", func(t *testing.T) {
+		_, err := NewInputCollector(nil).AskQuestion(t.Context(), "q", nil)
+		require.Error(t, err)
+	})
+}
+
+func TestInputCollector_AskDraftReview(t *testing.T) {
+	t.Run("dashboard revises", func(t *testing.T) {
+		c, h := newInputTest(t, &fakeTerminal{block: true}, "")
+		type result struct{ action, feedback string }
+		res := make(chan result, 1)
+		go func() {
+			action, feedback, err := c.AskDraftReview(t.Context(), "Review the plan draft", "# Plan\n")
+			assert.NoError(t, err)
+			res <- result{action, feedback}
+		}()
+		id := waitPending(t, c)
+
+		w := doRunAPI(h, http.MethodPost, "/api/input/"+id+"/draft", "", `{"action":"revise"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "revise needs feedback")
+		w = doRunAPI(h, http.MethodPost, "/api/input/"+id+"/draft", "", `{"action":"maybe"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = doRunAPI(h, http.MethodPost, "/api/input/"+id+"/answer", "", `{"answer":"yes"}`)
+		assert.Equal(t, http.StatusConflict, w.Code, "a draft is not a question")
+		w = doRunAPI(h, http.MethodPost, "/api/input/"+id+"/draft", "", `{"action":`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = doRunAPI(h, http.MethodPost, "/api/input/"+id+"/draft", "", `{"action":"revise","feedback":"split task 2"}`)
+		require.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, result{"revise", "split task 2"}, <-res)
+
+		events := readEvents(t, h, 2)
+		assert.Equal(t, EventTypeDraft, events[0].Type)
+		assert.Equal(t, "# Plan\n", events[0].Plan)
+		assert.Equal(t, EventTypeInputDone, events[1].Type)
+	})
+
+	t.Run("accept drops feedback", func(t *testing.T) {
+		c := NewInputCollector(nil)
+		type result struct{ action, feedback string }
+		res := make(chan result, 1)
+		go func() {
+			action, feedback, err := c.AskDraftReview(t.Context(), "Review the plan draft", "# Plan\n")
+			assert.NoError(t, err)
+			res <- result{action, feedback}
+		}()
+		require.NoError(t, c.ReviewDraft(waitPending(t, c), "accept", "ignored"))
+		assert.Equal(t, result{"accept", ""}, <-res)
+	})
+
+	t.Run("terminal answers the draft after a dashboard answer", func(t *testing.T) {
+		// the real terminal collector on a piped stdin: the read abandoned when the dashboard
+		// answers the first draft must not swallow the line typed for the second one
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		origStdin := os.Stdin
+		os.Stdin = r
+		t.Cleanup(func() {
+			os.Stdin = origStdin
+			_ = w.Close()
+			_ = r.Close()
+		})
+		c, h := newInputTest(t, input.NewTerminalCollector(true), "")
+
+		res := make(chan string, 1)
+		go func() {
+			action, _, askErr := c.AskDraftReview(t.Context(), "Review the plan draft", "# Plan\n")
+			assert.NoError(t, askErr)
+			res <- action
+		}()
+		id := waitPending(t, c)
+		resp := doRunAPI(h, http.MethodPost, "/api/input/"+id+"/draft", "", `{"action":"reject"}`)
+		require.Equal(t, http.StatusNoContent, resp.Code)
+		assert.Equal(t, "reject", <-res)
+
+		_, err = w.WriteString("1\n") // accept
+		require.NoError(t, err)
+		ctx, cancel := context.WithTimeout(t.Context(), 2*time.Second)
+		defer cancel()
+		action, _, err := c.AskDraftReview(ctx, "Review the plan draft", "# Plan v2\n")
+		require.NoError(t, err)
+		assert.Equal(t, "accept", action)
+	})
+
+	t.Run("terminal rejects", func(t *testing.T) {
+		c, _ := newInputTest(t, &fakeTerminal{action: "reject"}, "")
+		action, feedback, err := c.AskDraftReview(t.Context(), "Review the plan draft", "# Plan\n")
+		require.NoError(t, err)
+		assert.Equal(t, "reject", action)
+		assert.Empty(t, feedback)
+	})
+}
+
+func TestServer_InputRoutes(t *testing.T) {
+	t.Run("token required when configured", func(t *testing.T) {
+		c, h := newInputTest(t, nil, "s3cret")
+		res := make(chan string, 1)
+		go func() {
+			answer, _ := c.AskQuestion(t.Context(), "q", []string{"a"})
+			res <- answer
+		}()
+		id := waitPending(t, c)
+		w := doRunAPI(h, http.MethodPost, "/api/input/"+id+"/answer", "", `{"answer":"a"}`)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		w = doRunAPI(h, http.MethodPost, "/api/input/"+id+"/answer", "s3cret", `{"answer":"a"}`)
+		require.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "a", <-res)
+	})
+
+	t.Run("unknown id", func(t *testing.T) {
+		_, h := newInputTest(t, nil, "")
+		w := doRunAPI(h, http.MethodPost, "/api/input/7/answer", "", `{"answer":"a"}`)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("absent without collector", func(t *testing.T) {
+		session := NewSession("main", "/tmp/progress-plan.txt")
+		defer session.Close()
+		srv, err := NewServer(ServerConfig{}, session)
+		require.NoError(t, err)
+		h, err := srv.handler()
+		require.NoError(t, err)
+		w := doRunAPI(h, http.MethodPost, "/api/input/1/answer", "", `{"answer":"a"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
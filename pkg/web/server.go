@@ -2,6 +2,7 @@ package web
 
 import (
 	"context"
+	"crypto/tls"
 	"embed"
 	"encoding/json"
 	"errors"
@@ -15,6 +16,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/umputun/ralphex/pkg/plan"
@@ -25,12 +27,16 @@ var embeddedFS embed.FS
 
 // ServerConfig holds configuration for the web server.
 type ServerConfig struct {
-	Port      int    // port to listen on
-	Host      string // host/IP to bind to (default "127.0.0.1")
-	PlanName  string // plan name to display in dashboard
-	Branch    string // git branch name
-	RunParams string // formatted run parameters (executor/models) to display in dashboard
-	PlanFile  string // path to plan file for /api/plan endpoint
+	Port      int       // port to listen on
+	Host      string    // host/IP to bind to (default "127.0.0.1")
+	PlanName  string    // plan name to display in dashboard
+	Branch    string    // git branch name
+	RunParams string    // formatted run parameters (executor/models) to display in dashboard
+	PlanFile  string    // path to plan file for /api/plan endpoint
+	Token     string    // bearer token required by the run API and the session controls, and by every route when set
+	User      string    // basic auth user accepted on every route, with Password
+	Password  string    // basic auth password
+	TLS       TLSConfig // serve HTTPS instead of HTTP
 }
 
 // host returns the bind address, defaulting to "127.0.0.1" if not set.
@@ -43,11 +49,19 @@ func (c ServerConfig) host() string {
 
 // Server provides HTTP server for the real-time dashboard.
 type Server struct {
-	cfg     ServerConfig
-	session *Session        // used for single-session mode (direct execution)
-	sm      *SessionManager // used for multi-session mode (dashboard)
-	srv     *http.Server
-	tmpl    *template.Template
+	cfg       ServerConfig
+	session   *Session        // used for single-session mode (direct execution)
+	sm        *SessionManager // used for multi-session mode (dashboard)
+	runs      RunController   // run API of serve --daemon, nil otherwise
+	control   *RunControl     // steers the live session, nil when not controllable
+	controlID string          // id of the session steered by control
+	input     *InputCollector // answers plan creation input from the dashboard, nil otherwise
+	srv       *http.Server
+	tmpl      *template.Template
+
+	certOnce sync.Once        // loads or generates the TLS certificate once
+	cert     *tls.Certificate // TLS certificate, set by tlsConfig
+	certErr  error            // error of loading or generating the certificate
 }
 
 // NewServer creates a new web server for single-session mode (direct execution).
@@ -80,27 +94,28 @@ func NewServerWithSessions(cfg ServerConfig, sm *SessionManager) (*Server, error
 	}, nil
 }
 
+// NewDaemonServer creates a multi-session web server that also serves the run API of serve --daemon.
+// returns an error if the embedded template fails to parse.
+func NewDaemonServer(cfg ServerConfig, sm *SessionManager, runs RunController) (*Server, error) {
+	srv, err := NewServerWithSessions(cfg, sm)
+	if err != nil {
+		return nil, err
+	}
+	srv.runs = runs
+	return srv, nil
+}
+
 // Start begins listening for HTTP requests.
 // blocks until the server is stopped or an error occurs.
 func (s *Server) Start(ctx context.Context) error {
-	mux := http.NewServeMux()
-
-	// register routes
-	mux.HandleFunc("/", s.handleIndex)
-	mux.HandleFunc("/events", s.handleEvents)
-	mux.HandleFunc("/api/plan", s.handlePlan)
-	mux.HandleFunc("/api/sessions", s.handleSessions)
-
-	// static files
-	staticFS, err := fs.Sub(embeddedFS, "static")
+	handler, err := s.handler()
 	if err != nil {
-		return fmt.Errorf("static filesystem: %w", err)
+		return err
 	}
-	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.FS(staticFS))))
 
 	s.srv = &http.Server{
 		Addr:              net.JoinHostPort(s.cfg.host(), strconv.Itoa(s.cfg.Port)),
-		Handler:           mux,
+		Handler:           handler,
 		ReadHeaderTimeout: 10 * time.Second,
 	}
 
@@ -112,13 +127,50 @@ func (s *Server) Start(ctx context.Context) error {
 		_ = s.srv.Shutdown(shutdownCtx)
 	}()
 
-	err = s.srv.ListenAndServe()
+	if s.cfg.TLS.Enabled() {
+		tlsCfg, tlsErr := s.tlsConfig()
+		if tlsErr != nil {
+			return tlsErr
+		}
+		s.srv.TLSConfig = tlsCfg
+		err = s.srv.ListenAndServeTLS("", "")
+	} else {
+		err = s.srv.ListenAndServe()
+	}
 	if errors.Is(err, http.ErrServerClosed) {
 		return nil
 	}
 	return fmt.Errorf("http server: %w", err)
 }
 
+// handler returns the router with all routes of the server registered.
+func (s *Server) handler() (http.Handler, error) {
+	mux := http.NewServeMux()
+
+	// register routes
+	mux.HandleFunc("/", s.handleIndex)
+	mux.HandleFunc("/events", s.handleEvents)
+	mux.HandleFunc("/api/plan", s.handlePlan)
+	mux.HandleFunc("/api/sessions", s.handleSessions)
+	if s.runs != nil {
+		s.registerRunRoutes(mux)
+	}
+	if s.input != nil {
+		s.registerInputRoutes(mux)
+	}
+	if s.controlEnabled() {
+		s.registerControlRoutes(mux)
+	}
+
+	// static files
+	staticFS, err := fs.Sub(embeddedFS, "static")
+	if err != nil {
+		return nil, fmt.Errorf("static filesystem: %w", err)
+	}
+	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.FS(staticFS))))
+	return s.requireAuth(mux), nil
+}
+
 // Stop gracefully shuts down the server.
 func (s *Server) Stop() error {
 	if s.srv == nil {
@@ -139,9 +191,10 @@ func (s *Server) Session() *Session {
 
 // templateData holds data for the dashboard template.
 type templateData struct {
-	PlanName  string
-	Branch    string
-	RunParams string
+	PlanName       string
+	Branch         string
+	RunParams      string
+	ControlSession string // id of the session the control buttons steer, empty without controls
 }
 
 // FormatRunParams builds the display string for user-set run parameters,
@@ -178,6 +231,9 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 		Branch:    s.cfg.Branch,
 		RunParams: s.cfg.RunParams,
 	}
+	if s.controlEnabled() {
+		data.ControlSession = s.controlID
+	}
 
 	if err := s.tmpl.Execute(w, data); err != nil {
 		log.Printf("[ERROR] template execution: %v", err)
@@ -338,10 +394,11 @@ type SessionInfo struct {
 	Branch   string `json:"branch,omitempty"`
 	Mode     string `json:"mode,omitempty"`
 	// RunParams is the formatted user-set run parameters (executor/models) for header display.
-	RunParams    string     `json:"runParams,omitempty"`
-	StartTime    time.Time  `json:"startTime"`
-	LastModified time.Time  `json:"lastModified"`
-	DiffStats    *DiffStats `json:"diffStats,omitempty"`
+	RunParams    string      `json:"runParams,omitempty"`
+	StartTime    time.Time   `json:"startTime"`
+	LastModified time.Time   `json:"lastModified"`
+	DiffStats    *DiffStats  `json:"diffStats,omitempty"`
+	Usage        *UsageStats `json:"usage,omitempty"`
 }
 
 // handleSessions returns a list of all discovered sessions.
@@ -391,6 +448,7 @@ func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
 			StartTime:    meta.StartTime,
 			LastModified: session.GetLastModified(),
 			DiffStats:    session.GetDiffStats(),
+			Usage:        session.GetUsageStats(),
 		})
 	}
 
@@ -95,6 +95,8 @@ type Session struct {
 
 	// diffStats holds git diff statistics when available (nil if not set)
 	diffStats *DiffStats
+	// usageStats holds token and cost accounting from the progress footer (nil if not set)
+	usageStats *UsageStats
 
 	// stopTailCh signals the tail feeder goroutine to stop
 	stopTailCh chan struct{}
@@ -253,6 +255,24 @@ func (s *Session) SetDiffStats(stats DiffStats) {
 	s.diffStats = &stats
 }
 
+// GetUsageStats returns a copy of the usage stats, or nil if not set.
+func (s *Session) GetUsageStats() *UsageStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.usageStats == nil {
+		return nil
+	}
+	copyStats := *s.usageStats
+	return &copyStats
+}
+
+// SetUsageStats stores usage stats for the session.
+func (s *Session) SetUsageStats(stats UsageStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.usageStats = &stats
+}
+
 // IsLoaded returns whether historical data has been loaded into the SSE server.
 func (s *Session) IsLoaded() bool {
 	s.mu.RLock()
@@ -293,6 +313,7 @@ func (s *Session) resetForNewRun() {
 	s.lastTask = 0
 	s.loaded = false
 	s.diffStats = nil
+	s.usageStats = nil
 }
 
 // tailerStartMode selects how startTailerLocked begins tailing.
@@ -588,6 +609,9 @@ func (s *Session) feedEvents(tailer *Tailer, stopCh, feedDone chan struct{}) {
 			if stats, ok := parseDiffStats(event.Text); ok {
 				s.SetDiffStats(stats)
 			}
+			if usage, ok := parseUsageStats(event.Text); ok {
+				s.SetUsageStats(usage)
+			}
 		}
 		if err := s.Publish(event); err != nil {
 			log.Printf("[WARN] failed to publish tailed event: %v", err)
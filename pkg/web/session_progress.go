@@ -203,7 +203,11 @@ func (m *SessionManager) processProgressLine(session *Session, parsed ParsedLine
 		}
 		m.publishEvent(session, event)
 	case ParsedLinePlain:
-		m.publishEvent(session, eventFromParsed(parsed, phase))
+		event := eventFromParsed(parsed, phase)
+		if usage, ok := parseUsageStats(event.Text); ok {
+			session.SetUsageStats(usage)
+		}
+		m.publishEvent(session, event)
 	}
 	return phase, pendingSection, currentTask
 }
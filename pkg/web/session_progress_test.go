@@ -418,6 +418,37 @@ Started: 2026-01-22 10:00:00
 		assert.Equal(t, 10, stats.Additions)
 		assert.Equal(t, 4, stats.Deletions)
 	})
+
+	t.Run("captures usage from footer", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "progress-usage.txt")
+
+		content := `# Ralphex Progress Log
+Plan: docs/plan.md
+Branch: main
+Mode: full
+Started: 2026-01-22 10:00:00
+------------------------------------------------------------
+
+[26-01-22 10:00:01] running task
+
+------------------------------------------------------------
+Completed: 2026-01-22 10:05:00 (5m0s)
+Usage: input=1200 output=340 cache_creation=5000 cache_read=90000 cost=1.2346
+`
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		m := NewSessionManager()
+		session := NewSession("test-usage", path)
+		defer session.Close()
+
+		m.loadProgressFileIntoSession(path, session)
+
+		usage := session.GetUsageStats()
+		require.NotNil(t, usage)
+		assert.Equal(t, UsageStats{InputTokens: 1200, OutputTokens: 340, CacheCreationTokens: 5000,
+			CacheReadTokens: 90000, CostUSD: 1.2346}, *usage)
+	})
 }
 
 func TestSessionManager_LoadProgressFileIntoSession_RecordsOffset(t *testing.T) {
@@ -14,6 +14,7 @@
     const statusBadge = document.getElementById('status-badge');
     const elapsedTimeEl = document.getElementById('elapsed-time');
     const diffStatsEl = document.getElementById('diff-stats');
+    const usageStatsEl = document.getElementById('usage-stats');
     const searchInput = document.getElementById('search');
     const scrollIndicator = document.getElementById('scroll-indicator');
     const scrollToBottomBtn = document.getElementById('scroll-to-bottom');
@@ -28,6 +29,9 @@
     const helpOverlay = document.getElementById('help-overlay');
     const helpCloseBtn = document.getElementById('help-close');
     const helpBtn = document.getElementById('help-btn');
+    const runControls = document.getElementById('run-controls');
+    const controlStatusEl = document.getElementById('control-status');
+    const inputPanel = document.getElementById('input-panel');
 
     // session sidebar elements
     const sessionSidebar = document.getElementById('session-sidebar');
@@ -105,7 +109,10 @@
         // event batching state for performance
         eventQueue: [],
         isProcessingQueue: false,
-        pendingScrollRestore: false
+        pendingScrollRestore: false,
+
+        // id of the plan question or draft shown in the input panel
+        pendingInputId: null
     };
 
     // initialize plan panel state
@@ -273,6 +280,7 @@
     var TASK_ITERATION_PATTERN = /^task iteration \d+$/i;
     var TASK_ITERATION_NUMBER_PATTERN = /^task iteration (\d+)$/i;
     var DIFF_STATS_PATTERN = /^DIFFSTATS:\s*files=(\d+)\s+additions=(\d+)\s+deletions=(\d+)\s*$/i;
+    var USAGE_STATS_PATTERN = /^Usage:\s*input=(\d+)\s+output=(\d+)\s+cache_creation=(\d+)\s+cache_read=(\d+)\s+cost=(\d+(?:\.\d+)?)\s*$/;
 
     // check if section text is a task iteration pattern
     function isTaskIteration(sectionText) {
@@ -338,6 +346,49 @@
         };
     }
 
+    function formatTokenCount(n) {
+        if (n >= 1000000) return (n / 1000000).toFixed(1) + 'M';
+        if (n >= 1000) return (n / 1000).toFixed(1) + 'k';
+        return String(n);
+    }
+
+    function formatUsageStats(usage) {
+        if (!usage) return '';
+        var tokens = (usage.inputTokens || 0) + (usage.outputTokens || 0) +
+            (usage.cacheCreationTokens || 0) + (usage.cacheReadTokens || 0);
+        if (!tokens && !usage.costUsd) return '';
+        var text = formatTokenCount(tokens) + ' tokens';
+        if (usage.costUsd) {
+            text += ' $' + usage.costUsd.toFixed(2);
+        }
+        return text;
+    }
+
+    function updateUsageStats(usage) {
+        if (!usageStatsEl) return;
+        var text = formatUsageStats(usage);
+        usageStatsEl.textContent = text;
+        if (!text) {
+            usageStatsEl.removeAttribute('title');
+            return;
+        }
+        usageStatsEl.title = 'in ' + usage.inputTokens + ', out ' + usage.outputTokens +
+            ', cache read ' + usage.cacheReadTokens + ', cache write ' + usage.cacheCreationTokens;
+    }
+
+    function parseUsageStatsText(text) {
+        if (!text) return null;
+        var matches = USAGE_STATS_PATTERN.exec(text);
+        if (!matches) return null;
+        return {
+            inputTokens: parseInt(matches[1], 10),
+            outputTokens: parseInt(matches[2], 10),
+            cacheCreationTokens: parseInt(matches[3], 10),
+            cacheReadTokens: parseInt(matches[4], 10),
+            costUsd: parseFloat(matches[5])
+        };
+    }
+
     // look up task title by position (1-indexed) from plan data
     function getTaskTitle(taskNum) {
         if (!state.planData || !state.planData.tasks) return null;
@@ -841,6 +892,25 @@
                 updateDiffStats(diffStats);
                 return; // metadata line, don't render
             }
+            var usageStats = parseUsageStatsText(event.text);
+            if (usageStats) {
+                if (state.currentSession) {
+                    state.currentSession.usage = usageStats;
+                }
+                updateUsageStats(usageStats);
+            }
+        }
+
+        // plan creation input waits in the input panel, the answer itself is logged as output
+        if (event.type === 'question' || event.type === 'draft') {
+            showInputPrompt(event);
+            return;
+        }
+        if (event.type === 'input_done') {
+            if (event.input_id === state.pendingInputId) {
+                clearInputPrompt();
+            }
+            return;
         }
 
         // update status badge
@@ -1473,6 +1543,7 @@
         // persist selection
         localStorage.setItem('currentSessionId', sessionId);
         window.location.hash = sessionId;
+        updateControlsVisibility();
 
         // update UI selection
         var items = sessionList.querySelectorAll('.session-item');
@@ -1515,6 +1586,7 @@
             }
             state.currentSession = session;
             updateDiffStats(session.diffStats);
+            updateUsageStats(session.usage);
             seedExecutionStartTimeFromSession(session);
         }
 
@@ -1630,12 +1702,14 @@
         state.isProcessingQueue = false;
         state.focusedSectionIndex = -1;
         state.focusedSectionElement = null;
+        clearInputPrompt();
         if (state.elapsedTimerInterval) {
             clearInterval(state.elapsedTimerInterval);
             state.elapsedTimerInterval = null;
         }
         elapsedTimeEl.textContent = '';
         updateDiffStats(null);
+        updateUsageStats(null);
         if (seedStartTime) {
             seedExecutionStartTimeFromSession({ startTime: seedStartTime });
         }
@@ -2062,6 +2136,234 @@
 
     exportBtn.addEventListener('click', exportSession);
 
+    // run controls: break, pause, resume and abort the live run of a --serve dashboard.
+    // rendered only when the server has a token. a browser signed in with /?token= sends it as a cookie,
+    // otherwise the token is asked on the first 401 and kept in localStorage.
+    var controlSession = runControls ? runControls.dataset.session : '';
+    var controlTokenKey = 'ralphex-web-token';
+
+    function controlToken() {
+        var token = localStorage.getItem(controlTokenKey);
+        if (!token) {
+            token = window.prompt('Dashboard token (web_token) to control the run:');
+            if (token) {
+                localStorage.setItem(controlTokenKey, token);
+            }
+        }
+        return token;
+    }
+
+    // tokenFetch sends a request with the stored token, asking for the token and retrying once on 401
+    function tokenFetch(url, options, retried) {
+        var headers = Object.assign({}, options.headers);
+        var token = localStorage.getItem(controlTokenKey);
+        if (token) {
+            headers['Authorization'] = 'Bearer ' + token;
+        }
+        return fetch(url, Object.assign({}, options, { headers: headers }))
+            .then(function(resp) {
+                if (resp.status !== 401 || retried) return resp;
+                localStorage.removeItem(controlTokenKey);
+                if (!controlToken()) return resp;
+                return tokenFetch(url, options, true);
+            });
+    }
+
+    function showControlStatus(text, isError) {
+        if (!controlStatusEl) return;
+        controlStatusEl.textContent = text;
+        controlStatusEl.classList.toggle('error', !!isError);
+    }
+
+    function applyControlState(st) {
+        runControls.classList.toggle('paused', st.paused);
+        runControls.querySelectorAll('.control-btn').forEach(function(btn) {
+            var action = btn.dataset.action;
+            if (action === 'resume') {
+                btn.disabled = !st.paused;
+            } else if (action === 'pause' || action === 'break') {
+                btn.disabled = st.paused;
+            }
+        });
+        showControlStatus(st.paused ? 'paused' : '', false);
+    }
+
+    function sendControl(action) {
+        if (action === 'abort' && !window.confirm('Abort the run?')) return;
+        tokenFetch('/api/sessions/' + encodeURIComponent(controlSession) + '/' + action, { method: 'POST' })
+            .then(function(resp) {
+                if (resp.status === 401) {
+                    localStorage.removeItem(controlTokenKey);
+                    throw new Error('invalid token');
+                }
+                if (!resp.ok) {
+                    return resp.text().then(function(text) { throw new Error(text.trim()); });
+                }
+                return resp.json();
+            })
+            .then(function(st) {
+                applyControlState(st);
+                if (action === 'pause' && !st.paused) {
+                    showControlStatus('pausing...', false);
+                    // the run pauses once the current iteration stops, poll until it does
+                    setTimeout(function() { refreshControlState(60); }, 1000);
+                }
+            })
+            .catch(function(err) {
+                showControlStatus(action + ' failed: ' + err.message, true);
+            });
+    }
+
+    // refreshControlState polls the paused state until a requested pause takes effect
+    function refreshControlState(attempts) {
+        tokenFetch('/api/sessions/' + encodeURIComponent(controlSession) + '/control', {})
+            .then(function(resp) { return resp.ok ? resp.json() : null; })
+            .then(function(st) {
+                if (!st) return;
+                if (st.paused || attempts <= 1) {
+                    applyControlState(st);
+                    return;
+                }
+                setTimeout(function() { refreshControlState(attempts - 1); }, 1000);
+            })
+            .catch(function() {});
+    }
+
+    // show the controls only while the controlled session is viewed
+    function updateControlsVisibility() {
+        if (!runControls) return;
+        var visible = !state.currentSessionId || state.currentSessionId === controlSession;
+        runControls.classList.toggle('is-hidden', !visible);
+    }
+
+    // plan input: questions and drafts of a --plan --serve run, answered here or in the terminal,
+    // whichever comes first. the endpoints ask for the token only when the server has one.
+    function showInputPrompt(event) {
+        if (!inputPanel) return;
+        clearElement(inputPanel);
+        state.pendingInputId = event.input_id;
+
+        var title = document.createElement('div');
+        title.className = 'input-title';
+        title.textContent = event.text;
+        inputPanel.appendChild(title);
+
+        if (event.type === 'question') {
+            renderQuestionInput(event);
+        } else {
+            renderDraftInput(event);
+        }
+
+        var errorEl = document.createElement('div');
+        errorEl.className = 'input-error';
+        inputPanel.appendChild(errorEl);
+        inputPanel.classList.remove('is-hidden');
+    }
+
+    function clearInputPrompt() {
+        state.pendingInputId = null;
+        if (!inputPanel) return;
+        clearElement(inputPanel);
+        inputPanel.classList.add('is-hidden');
+    }
+
+    function renderQuestionInput(event) {
+        var id = event.input_id;
+        var options = document.createElement('div');
+        options.className = 'input-options';
+        (event.options || []).forEach(function(opt) {
+            var btn = document.createElement('button');
+            btn.className = 'input-btn';
+            btn.textContent = opt;
+            btn.addEventListener('click', function() {
+                submitInput(id, 'answer', { answer: opt });
+            });
+            options.appendChild(btn);
+        });
+        inputPanel.appendChild(options);
+
+        var custom = document.createElement('div');
+        custom.className = 'input-custom';
+        var field = document.createElement('input');
+        field.type = 'text';
+        field.placeholder = 'Or type your own answer';
+        var send = document.createElement('button');
+        send.className = 'input-btn';
+        send.textContent = 'Send';
+        var sendCustom = function() {
+            if (field.value.trim()) {
+                submitInput(id, 'answer', { answer: field.value });
+            }
+        };
+        send.addEventListener('click', sendCustom);
+        field.addEventListener('keydown', function(e) {
+            if (e.key === 'Enter') sendCustom();
+        });
+        custom.appendChild(field);
+        custom.appendChild(send);
+        inputPanel.appendChild(custom);
+    }
+
+    function renderDraftInput(event) {
+        var id = event.input_id;
+        var plan = document.createElement('pre');
+        plan.className = 'input-plan';
+        plan.textContent = event.plan || '';
+        inputPanel.appendChild(plan);
+
+        var feedback = document.createElement('textarea');
+        feedback.className = 'input-feedback';
+        feedback.rows = 3;
+        feedback.placeholder = 'Revision feedback';
+        inputPanel.appendChild(feedback);
+
+        var actions = document.createElement('div');
+        actions.className = 'input-options';
+        [['accept', 'Accept'], ['revise', 'Revise'], ['reject', 'Reject']].forEach(function(a) {
+            var btn = document.createElement('button');
+            btn.className = 'input-btn';
+            btn.textContent = a[1];
+            btn.addEventListener('click', function() {
+                if (a[0] === 'revise' && !feedback.value.trim()) {
+                    showInputError('enter revision feedback first');
+                    return;
+                }
+                submitInput(id, 'draft', { action: a[0], feedback: a[0] === 'revise' ? feedback.value : '' });
+            });
+            actions.appendChild(btn);
+        });
+        inputPanel.appendChild(actions);
+    }
+
+    function showInputError(text) {
+        var el = inputPanel ? inputPanel.querySelector('.input-error') : null;
+        if (el) el.textContent = text;
+    }
+
+    // submitInput posts an answer; the input_done event that follows closes the panel
+    function submitInput(id, kind, body) {
+        tokenFetch('/api/input/' + encodeURIComponent(id) + '/' + kind, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(body)
+        })
+            .then(function(resp) {
+                if (!resp.ok) {
+                    return resp.text().then(function(text) { showInputError(text.trim()); });
+                }
+            })
+            .catch(function(err) {
+                showInputError('failed to send: ' + err.message);
+            });
+    }
+
+    if (runControls) {
+        runControls.querySelectorAll('.control-btn').forEach(function(btn) {
+            btn.addEventListener('click', function() { sendControl(btn.dataset.action); });
+        });
+        updateControlsVisibility();
+    }
+
     // expand/collapse all sections (user-initiated, so track preferences)
     function expandAllSections() {
         output.querySelectorAll('.section-header').forEach(function(section) {
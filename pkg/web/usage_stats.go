@@ -0,0 +1,46 @@
+package web
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// UsageStats holds token and cost accounting for a session, read from the progress file footer.
+type UsageStats struct {
+	InputTokens         int64   `json:"inputTokens"`
+	OutputTokens        int64   `json:"outputTokens"`
+	CacheCreationTokens int64   `json:"cacheCreationTokens"`
+	CacheReadTokens     int64   `json:"cacheReadTokens"`
+	CostUSD             float64 `json:"costUsd"`
+}
+
+var usageStatsPattern = regexp.MustCompile(
+	`^Usage:\s*input=(\d+)\s+output=(\d+)\s+cache_creation=(\d+)\s+cache_read=(\d+)\s+cost=(\d+(?:\.\d+)?)\s*$`)
+
+func parseUsageStats(text string) (UsageStats, bool) {
+	matches := usageStatsPattern.FindStringSubmatch(text)
+	if matches == nil {
+		return UsageStats{}, false
+	}
+
+	var counts [4]int64
+	for i := range counts {
+		n, err := strconv.ParseInt(matches[i+1], 10, 64)
+		if err != nil {
+			return UsageStats{}, false
+		}
+		counts[i] = n
+	}
+	cost, err := strconv.ParseFloat(matches[5], 64)
+	if err != nil {
+		return UsageStats{}, false
+	}
+
+	return UsageStats{
+		InputTokens:         counts[0],
+		OutputTokens:        counts[1],
+		CacheCreationTokens: counts[2],
+		CacheReadTokens:     counts[3],
+		CostUSD:             cost,
+	}, true
+}
@@ -0,0 +1,36 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUsageStats(t *testing.T) {
+	t.Run("parses valid usage footer", func(t *testing.T) {
+		stats, ok := parseUsageStats("Usage: input=1200 output=340 cache_creation=5000 cache_read=90000 cost=1.2346")
+		assert.True(t, ok)
+		assert.Equal(t, int64(1200), stats.InputTokens)
+		assert.Equal(t, int64(340), stats.OutputTokens)
+		assert.Equal(t, int64(5000), stats.CacheCreationTokens)
+		assert.Equal(t, int64(90000), stats.CacheReadTokens)
+		assert.InDelta(t, 1.2346, stats.CostUSD, 1e-9)
+	})
+
+	t.Run("parses tokens without cost", func(t *testing.T) {
+		stats, ok := parseUsageStats("Usage: input=10 output=2 cache_creation=0 cache_read=0 cost=0.0000")
+		assert.True(t, ok)
+		assert.Equal(t, int64(10), stats.InputTokens)
+		assert.Zero(t, stats.CostUSD)
+	})
+
+	t.Run("rejects regular log line", func(t *testing.T) {
+		_, ok := parseUsageStats("usage total: in 1.2k, out 340")
+		assert.False(t, ok)
+	})
+
+	t.Run("rejects invalid usage line", func(t *testing.T) {
+		_, ok := parseUsageStats("Usage: input=foo output=1 cache_creation=0 cache_read=0 cost=0")
+		assert.False(t, ok)
+	})
+}